@@ -39,16 +39,40 @@ type collectionNamer interface {
 	CollectionName() string
 }
 
-func LoadCollection[T collectionNamer](ctx context.Context, client *Client, collected *[]T) error {
+// LoadCollection appends every row of a collection to collected.
+// Rows implementing [Validatable] are checked after loading,
+// and any problems are logged with the client's log function;
+// use [LoadCollectionReport] to handle them instead.
+//
+// Filters load only the matching rows, such as the hexes of a single zone:
+//
+//	err := census.LoadCollection(ctx, client, &hexes, census.Eq("zone_id", ps2.Indar))
+func LoadCollection[T collectionNamer](ctx context.Context, client *Client, collected *[]T, filters ...Filter) error {
+	if client == nil {
+		client = DefaultClient
+	}
+	report, err := LoadCollectionReport(ctx, client, collected, filters...)
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		client.logger().log(ctx, "census collection failed validation", "collection", report.Collection, "rows", report.Rows, "problems", len(report.Problems), "report", report.String())
+	}
+	return nil
+}
+
+func loadCollection[T collectionNamer](ctx context.Context, client *Client, collected *[]T, filters ...Filter) error {
 	if client == nil {
 		client = DefaultClient
 	}
 	var n T
 	collection := n.CollectionName()
+	filter := filterQuery(filters)
 	const perPage = 5000
 	for start, more := 0, true; more; start += perPage {
 		var result map[string]json.RawMessage
-		err := client.Get(ctx, ps2.PC, fmt.Sprintf("%s?c:limit=%d&c:start=%d", collection, perPage, start), &result)
+		// full pages are expected here, so skip the client's truncation policy
+		count, err := client.getRetry(ctx, ps2.PC, fmt.Sprintf("%s?%sc:limit=%d&c:start=%d", collection, filter, perPage, start), &result)
 		if err != nil {
 			return err
 		}
@@ -58,14 +82,32 @@ func LoadCollection[T collectionNamer](ctx context.Context, client *Client, coll
 
 		rawList := result[collection+"_list"]
 		pageResults := make([]T, 0, perPage)
-		if err = json.Unmarshal(rawList, &pageResults); err != nil {
+		if err = decodeJSON(rawList, &pageResults); err != nil {
 			return err
 		}
-		more = false
-		if len(pageResults) == perPage {
-			more = true
-		}
+		more = count.Truncated()
 		*collected = append(*collected, pageResults...)
 	}
 	return nil
 }
+
+// getList requests a filtered collection from env.
+// It's meant for filters that match few enough rows to fit in one page.
+func getList[T collectionNamer](ctx context.Context, client *Client, env ps2.Environment, filter string) ([]T, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	var n T
+	var response map[string]json.RawMessage
+	query := fmt.Sprintf("%s?%s&c:limit=5000", n.CollectionName(), filter)
+	if err := client.Get(ctx, env, query, &response); err != nil {
+		return nil, err
+	}
+	var list []T
+	if raw := response[n.CollectionName()+"_list"]; raw != nil {
+		if err := decodeJSON(raw, &list); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
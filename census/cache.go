@@ -0,0 +1,197 @@
+package census
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// ResponseCache keeps successful census responses so that repeated queries for static collections,
+// like the zone, map_region, and facility_link queries made during startup,
+// are answered without a request.
+// Cached responses skip the rate limiter and circuit breaker entirely.
+//
+// Once a response is older than its TTL the next request asks census again,
+// sending the ETag and Last-Modified validators of the cached response when census provided them,
+// so an unchanged collection is answered with the cached body.
+//
+//	client.SetCache(&census.ResponseCache{
+//		TTL: map[string]time.Duration{
+//			"zone":          24 * time.Hour,
+//			"map_region":    24 * time.Hour,
+//			"facility_link": 24 * time.Hour,
+//		},
+//	})
+type ResponseCache struct {
+	// Store holds the cached responses.
+	// The default keeps them in memory.
+	Store CacheStore
+
+	// TTL is how long responses are used without asking census, by collection name.
+	TTL map[string]time.Duration
+
+	// DefaultTTL is used for collections that aren't in TTL.
+	// The default of 0 doesn't cache them at all.
+	DefaultTTL time.Duration
+
+	once sync.Once
+}
+
+// CacheStore stores responses for a [ResponseCache].
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the response stored for key.
+	Get(key string) (CachedResponse, bool)
+
+	// Put stores r for key, replacing any previous response.
+	Put(key string, r CachedResponse) error
+}
+
+// CachedResponse is a census response body and its cache validators.
+type CachedResponse struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Fetched      time.Time `json:"fetched"` // Fetched is when census last returned or confirmed the body
+}
+
+// SetCache caches the responses of c in cache.
+// A nil cache stops caching.
+func (c *Client) SetCache(cache *ResponseCache) {
+	c.cache = cache
+}
+
+func (rc *ResponseCache) store() CacheStore {
+	rc.once.Do(func() {
+		if rc.Store == nil {
+			rc.Store = NewMemoryCacheStore()
+		}
+	})
+	return rc.Store
+}
+
+// ttl returns the TTL for the collection of query.
+func (rc *ResponseCache) ttl(query string) time.Duration {
+	if ttl, found := rc.TTL[queryCollection(query)]; found {
+		return ttl
+	}
+	return rc.DefaultTTL
+}
+
+func cacheKey(env ps2.Environment, query string) string {
+	return Namespace(env) + "/" + query
+}
+
+// lookup returns the cached response for query,
+// and whether it's fresh enough to use without asking census.
+// A nil cache or an uncached collection never has a response.
+func (rc *ResponseCache) lookup(env ps2.Environment, query string, now time.Time) (r CachedResponse, fresh bool, found bool) {
+	if rc == nil {
+		return r, false, false
+	}
+	ttl := rc.ttl(query)
+	if ttl <= 0 {
+		return r, false, false
+	}
+	r, found = rc.store().Get(cacheKey(env, query))
+	return r, found && now.Sub(r.Fetched) < ttl, found
+}
+
+// put stores a successful response body for query along with the validators in header.
+func (rc *ResponseCache) put(env ps2.Environment, query string, body []byte, header http.Header, now time.Time) error {
+	if rc == nil || rc.ttl(query) <= 0 {
+		return nil
+	}
+	return rc.store().Put(cacheKey(env, query), CachedResponse{
+		Body:         body,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Fetched:      now,
+	})
+}
+
+// NewMemoryCacheStore returns a CacheStore that keeps responses in memory for the life of the process.
+func NewMemoryCacheStore() CacheStore {
+	return &memoryCacheStore{responses: make(map[string]CachedResponse)}
+}
+
+type memoryCacheStore struct {
+	mu        sync.RWMutex
+	responses map[string]CachedResponse
+}
+
+func (s *memoryCacheStore) Get(key string) (CachedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, found := s.responses[key]
+	return r, found
+}
+
+func (s *memoryCacheStore) Put(key string, r CachedResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[key] = r
+	return nil
+}
+
+// NewDiskCacheStore returns a CacheStore that keeps responses as files in dir,
+// so that they survive restarts.
+// The directory is created if it doesn't exist.
+func NewDiskCacheStore(dir string) (CacheStore, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("census.NewDiskCacheStore: %w", err)
+	}
+	return diskCacheStore{dir: dir}, nil
+}
+
+type diskCacheStore struct {
+	dir string
+}
+
+func (s diskCacheStore) name(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get treats unreadable files as missing, since the response can be requested again.
+func (s diskCacheStore) Get(key string) (CachedResponse, bool) {
+	var r CachedResponse
+	b, err := os.ReadFile(s.name(key))
+	if err != nil {
+		return r, false
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, false
+	}
+	return r, true
+}
+
+// Put writes to a temporary file first so that readers never see a partial response.
+func (s diskCacheStore) Put(key string, r CachedResponse) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, "put-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), s.name(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
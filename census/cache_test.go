@@ -0,0 +1,79 @@
+package census_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// etagServer answers every request with the same world list and an ETag,
+// and answers matching conditional requests with 304 Not Modified.
+type etagServer struct {
+	mu          sync.Mutex
+	requests    int
+	notModified int
+}
+
+func (s *etagServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": []string{`"v1"`}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"world_list":[{"world_id":"1","state":"online"}],"returned":1}`))),
+		Request:    req,
+	}
+	if req.Header.Get("If-None-Match") == `"v1"` {
+		s.notModified++
+		resp.StatusCode = http.StatusNotModified
+		resp.Body = http.NoBody
+	}
+	return resp, nil
+}
+
+func TestResponseCache(t *testing.T) {
+	srv := &etagServer{}
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: srv})
+	cache := &census.ResponseCache{TTL: map[string]time.Duration{"world": time.Hour}}
+	client.SetCache(cache)
+
+	get := func(query string) {
+		t.Helper()
+		var r struct {
+			WorldList []census.World `json:"world_list"`
+		}
+		if err := client.Get(context.Background(), ps2.PC, query, &r); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if len(r.WorldList) != 1 || r.WorldList[0].WorldID != 1 {
+			t.Fatalf("unexpected result: %+v", r)
+		}
+	}
+
+	get("world?c:limit=100")
+	get("world?c:limit=100")
+	if srv.requests != 1 {
+		t.Errorf("got %d requests; want the second query to be cached", srv.requests)
+	}
+
+	cache.TTL["world"] = time.Nanosecond
+	get("world?c:limit=100")
+	if srv.requests != 2 || srv.notModified != 1 {
+		t.Errorf("got %d requests and %d not modified; want a conditional request after the ttl", srv.requests, srv.notModified)
+	}
+
+	get("zone?c:limit=100&c:show=zone_id")
+	get("zone?c:limit=100&c:show=zone_id")
+	if srv.requests != 4 {
+		t.Errorf("got %d requests; want collections without a ttl to never be cached", srv.requests)
+	}
+}
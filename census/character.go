@@ -0,0 +1,166 @@
+package census
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Character is a row of the character collection,
+// limited to the fields needed to identify a character in events.
+type Character struct {
+	CharacterID ps2.CharacterID `json:"character_id,string"`
+	Name        struct {
+		First      string `json:"first"`
+		FirstLower string `json:"first_lower"`
+	} `json:"name"`
+	FactionID     ps2.FactionID `json:"faction_id,string"`
+	TitleID       int           `json:"title_id,string"`
+	PrestigeLevel int           `json:"prestige_level,string"`
+	BattleRank    struct {
+		Value int `json:"value,string"`
+	} `json:"battle_rank"`
+}
+
+func (Character) CollectionName() string { return "character" }
+
+// maxCharactersPerRequest is the most character IDs sent in one request,
+// which keeps the query string well under census URL limits.
+const maxCharactersPerRequest = 250
+
+// GetCharactersByID looks up characters by ID,
+// splitting the IDs into as many requests as needed.
+// Characters that don't exist, such as deleted characters, are left out of the result,
+// and the result is in no particular order.
+//
+// Characters are kept in the character cache when it's enabled with [SetCharacterCache],
+// so that trackers resolving the same characters repeatedly don't spend their rate limit on them.
+func GetCharactersByID(ctx context.Context, client *Client, env ps2.Environment, ids ...ps2.CharacterID) ([]Character, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	ids = slices.DeleteFunc(ids, func(id ps2.CharacterID) bool { return id == 0 })
+
+	characters, missing := characterCache.get(env, ids, time.Now())
+	for len(missing) > 0 {
+		batch := missing[:min(len(missing), maxCharactersPerRequest)]
+		missing = missing[len(batch):]
+		list := make([]string, len(batch))
+		for i, id := range batch {
+			list[i] = id.String()
+		}
+		r := struct {
+			CharacterList []Character `json:"character_list"`
+		}{}
+		query := fmt.Sprintf("character?character_id=%s&c:show=character_id,name,faction_id,title_id,prestige_level,battle_rank&c:limit=%d", strings.Join(list, ","), len(batch))
+		if err := client.Get(ctx, env, query, &r); err != nil {
+			return nil, fmt.Errorf("census.GetCharactersByID: %w", err)
+		}
+		characterCache.put(env, r.CharacterList, time.Now())
+		characters = append(characters, r.CharacterList...)
+	}
+	return characters, nil
+}
+
+// DefaultCharacterCacheTTL is how long cached characters are used when [SetCharacterCache] is given a ttl of 0.
+// Names and factions rarely change, but battle ranks do.
+const DefaultCharacterCacheTTL = time.Hour
+
+// characterCache holds recent [GetCharactersByID] results for every client in the process.
+// It's disabled until [SetCharacterCache] is called.
+var characterCache = &characterLRU{
+	entries: make(map[characterCacheKey]*list.Element),
+	order:   list.New(),
+}
+
+// SetCharacterCache keeps up to size characters from [GetCharactersByID] for ttl,
+// evicting the least recently used characters first.
+// A ttl of 0 or less uses [DefaultCharacterCacheTTL],
+// and a size of 0 or less disables the cache.
+func SetCharacterCache(size int, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCharacterCacheTTL
+	}
+	characterCache.mu.Lock()
+	defer characterCache.mu.Unlock()
+	characterCache.size = max(size, 0)
+	characterCache.ttl = ttl
+	characterCache.evict()
+}
+
+type characterCacheKey struct {
+	env ps2.Environment
+	id  ps2.CharacterID
+}
+
+type cachedCharacter struct {
+	key       characterCacheKey
+	character Character
+	fetched   time.Time
+}
+
+type characterLRU struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[characterCacheKey]*list.Element
+	order   *list.List // order holds *cachedCharacter, most recently used first
+}
+
+// get returns fresh cached characters for ids, along with the ids that weren't cached.
+func (c *characterLRU) get(env ps2.Environment, ids []ps2.CharacterID, now time.Time) (cached []Character, missing []ps2.CharacterID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		e, ok := c.entries[characterCacheKey{env, id}]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		entry := e.Value.(*cachedCharacter)
+		if now.Sub(entry.fetched) >= c.ttl {
+			c.order.Remove(e)
+			delete(c.entries, entry.key)
+			missing = append(missing, id)
+			continue
+		}
+		c.order.MoveToFront(e)
+		cached = append(cached, entry.character)
+	}
+	return cached, missing
+}
+
+func (c *characterLRU) put(env ps2.Environment, characters []Character, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size == 0 {
+		return
+	}
+	for _, ch := range characters {
+		key := characterCacheKey{env, ch.CharacterID}
+		if e, ok := c.entries[key]; ok {
+			c.order.Remove(e)
+		}
+		c.entries[key] = c.order.PushFront(&cachedCharacter{key: key, character: ch, fetched: now})
+	}
+	c.evict()
+}
+
+// evict removes the least recently used characters until the cache fits its size.
+// c.mu must be held.
+func (c *characterLRU) evict() {
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedCharacter).key)
+	}
+}
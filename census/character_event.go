@@ -0,0 +1,128 @@
+package census
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// Types of the characters_event collection, for [CharacterEventOptions].
+const (
+	CharacterEventKill           = "KILL"
+	CharacterEventDeath          = "DEATH"
+	CharacterEventVehicleDestroy = "VEHICLE_DESTROY"
+	CharacterEventFacility       = "FACILITY_CHARACTER"
+	CharacterEventAchievement    = "ACHIEVEMENT"
+	CharacterEventBattleRank     = "BATTLE_RANK"
+)
+
+// characterEventTables maps the table_type of characters_event rows to the event they hold.
+var characterEventTables = map[string]ps2.Event{
+	"deaths":             ps2.Death,
+	"vehicle_destroy":    ps2.VehicleDestroy,
+	"facility_character": ps2.PlayerFacilityCapture,
+	"achievements":       ps2.AchievementEarned,
+	"battle_rank":        ps2.BattleRankUp,
+}
+
+// characterEventPageSize is the most rows census returns from characters_event at once.
+const characterEventPageSize = 1000
+
+// CharacterEventOptions filters the events returned by [GetCharacterEvents].
+//
+// Zero values don't filter.
+type CharacterEventOptions struct {
+	// Types limits events to the given types, such as [CharacterEventKill].
+	Types []string
+
+	// After and Before limit events to the time between them.
+	After  time.Time
+	Before time.Time
+
+	// Limit is the most events to return, keeping the newest.
+	Limit int
+}
+
+// GetCharacterEvents returns the recent events of character from the characters_event collection,
+// oldest first, requesting as many pages as needed.
+//
+// Kills and deaths are both returned as [event.Death];
+// kills are the ones where AttackerCharacterID is character.
+// Facility rows are returned as [event.PlayerFacilityCapture].
+// Rows of other types are skipped.
+//
+// Census only keeps a limited number of recent events for each character,
+// so an old After may still return less than the full range.
+func GetCharacterEvents(ctx context.Context, client *Client, env ps2.Environment, character ps2.CharacterID, opts CharacterEventOptions) ([]event.Typer, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	var events []event.Typer
+	seen := make(map[event.UniqueKey]bool)
+	before := opts.Before
+	for page := 0; ; page++ {
+		if page == maxPages {
+			return nil, fmt.Errorf("census.GetCharacterEvents: pagination stopped after %d pages", maxPages)
+		}
+		var response struct {
+			List []struct {
+				event.Raw
+				TableType string `json:"table_type"`
+			} `json:"characters_event_list"`
+		}
+		if err := client.Get(ctx, env, characterEventQuery(character, opts, before), &response); err != nil {
+			return nil, fmt.Errorf("census.GetCharacterEvents: %w", err)
+		}
+
+		added := 0
+		for _, row := range response.List {
+			name, ok := characterEventTables[row.TableType]
+			if !ok {
+				continue
+			}
+			row.Raw.EventName = name
+			e := row.Raw.Event()
+			if k, ok := e.(event.UniqueKeyer); ok {
+				if seen[k.Key()] {
+					continue
+				}
+				seen[k.Key()] = true
+			}
+			events = append(events, e)
+			added++
+			if opts.Limit > 0 && len(events) == opts.Limit {
+				break
+			}
+		}
+		if len(response.List) < characterEventPageSize || added == 0 || (opts.Limit > 0 && len(events) == opts.Limit) {
+			break
+		}
+		// census returns the newest rows first, so the next page is everything before the oldest row;
+		// rows from the same second as the oldest row are requested again and dropped as duplicates
+		before = time.Unix(response.List[len(response.List)-1].Timestamp+1, 0)
+	}
+	slices.SortStableFunc(events, func(a, b event.Typer) int {
+		return a.(event.Timestamper).Time().Compare(b.(event.Timestamper).Time())
+	})
+	return events, nil
+}
+
+func characterEventQuery(character ps2.CharacterID, opts CharacterEventOptions, before time.Time) string {
+	q := fmt.Sprintf("characters_event?character_id=%s&c:limit=%d", character, characterEventPageSize)
+	if len(opts.Types) > 0 {
+		q += "&type=" + strings.Join(opts.Types, ",")
+	}
+	if !opts.After.IsZero() {
+		q += "&after=" + strconv.FormatInt(opts.After.Unix(), 10)
+	}
+	if !before.IsZero() {
+		q += "&before=" + strconv.FormatInt(before.Unix(), 10)
+	}
+	return q
+}
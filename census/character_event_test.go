@@ -0,0 +1,55 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestGetCharacterEvents(t *testing.T) {
+	var query string
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`{"characters_event_list":[
+				{"character_id":"2","attacker_character_id":"1","attacker_weapon_id":"80","timestamp":"1709037300","table_type":"deaths","world_id":"17","zone_id":"2"},
+				{"character_id":"1","facility_id":"7500","outfit_id":"0","timestamp":"1709037200","table_type":"facility_character","world_id":"17","zone_id":"2"},
+				{"character_id":"1","attacker_character_id":"3","timestamp":"1709037100","table_type":"deaths","world_id":"17","zone_id":"2"},
+				{"character_id":"1","timestamp":"1709037000","table_type":"something_new"}
+			],"returned":4}`)),
+			Request: req,
+		}, nil
+	})})
+
+	events, err := census.GetCharacterEvents(context.Background(), client, ps2.PC, 1, census.CharacterEventOptions{
+		Types: []string{census.CharacterEventKill, census.CharacterEventDeath, census.CharacterEventFacility},
+		After: time.Unix(1709030000, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "type=KILL,DEATH,FACILITY_CHARACTER") || !strings.Contains(query, "after=1709030000") {
+		t.Errorf("got query %q; want the types and time range", query)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events; want 3", len(events))
+	}
+	if d, ok := events[0].(event.Death); !ok || d.CharacterID != 1 || d.AttackerCharacterID != 3 {
+		t.Errorf("got %+v; want the death of character 1 first", events[0])
+	}
+	if c, ok := events[1].(event.PlayerFacilityCapture); !ok || c.FacilityID != 7500 {
+		t.Errorf("got %+v; want the capture of facility 7500", events[1])
+	}
+	if k, ok := events[2].(event.Death); !ok || k.AttackerCharacterID != 1 || k.AttackerWeaponID != 80 {
+		t.Errorf("got %+v; want the kill by character 1 last", events[2])
+	}
+}
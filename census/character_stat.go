@@ -0,0 +1,131 @@
+package census
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Stat names of the characters_stat_history collection.
+// The characters_stat collection uses its own names like "weapon_kills" or "weapon_play_time" instead.
+const (
+	StatAchievements    = "achievements"
+	StatBattleRank      = "battle_rank"
+	StatCerts           = "certs"
+	StatDeaths          = "deaths"
+	StatFacilityCapture = "facility_capture"
+	StatFacilityDefend  = "facility_defend"
+	StatKills           = "kills"
+	StatMedals          = "medals"
+	StatScore           = "score"
+	StatTime            = "time" // StatTime is play time in seconds
+)
+
+// CharacterStat is a row of the characters_stat collection,
+// which holds a character's totals for a stat on one profile (class).
+type CharacterStat struct {
+	CharacterID     ps2.CharacterID `json:"character_id,string"`
+	StatName        string          `json:"stat_name"`
+	ProfileID       ps2.ProfileID   `json:"profile_id,string"`
+	ValueForever    int64           `json:"value_forever,string"`
+	ValueMonthly    int64           `json:"value_monthly,string"`
+	ValueWeekly     int64           `json:"value_weekly,string"`
+	ValueDaily      int64           `json:"value_daily,string"`
+	ValueOneLifeMax int64           `json:"value_one_life_max,string"`
+	LastSave        UnixTime        `json:"last_save"`
+}
+
+func (CharacterStat) CollectionName() string { return "characters_stat" }
+
+// CharacterStatHistory is a row of the characters_stat_history collection,
+// which holds a character's recent values of a stat by day, week, and month.
+type CharacterStatHistory struct {
+	CharacterID ps2.CharacterID `json:"character_id,string"`
+	StatName    string          `json:"stat_name"`
+	AllTime     int64           `json:"all_time,string"`
+	OneLifeMax  int64           `json:"one_life_max,string"`
+
+	// Day holds the last 31 days, Week the last 13 weeks, and Month the last 12 months.
+	// Index 0 is the current period, which is still in progress.
+	Day   StatPeriods `json:"day"`
+	Week  StatPeriods `json:"week"`
+	Month StatPeriods `json:"month"`
+
+	LastSave UnixTime `json:"last_save"`
+}
+
+func (CharacterStatHistory) CollectionName() string { return "characters_stat_history" }
+
+// StatPeriods is a stat value for each period of a [CharacterStatHistory], most recent first.
+// Census encodes periods as objects like {"d01":"12","d02":"7"},
+// where d01 is the most recent.
+type StatPeriods []int64
+
+func (p *StatPeriods) UnmarshalJSON(data []byte) error {
+	var periods map[string]json.Number // census quotes the values, but json.Number accepts either
+	if err := json.Unmarshal(data, &periods); err != nil {
+		return fmt.Errorf("census.StatPeriods.UnmarshalJSON: %w", err)
+	}
+	var values StatPeriods
+	for key, value := range periods {
+		// keys are a one letter period followed by a one-based index
+		i, err := strconv.Atoi(key[min(len(key), 1):])
+		if err != nil || i < 1 {
+			return fmt.Errorf("census.StatPeriods.UnmarshalJSON: invalid period %q", key)
+		}
+		v, err := strconv.ParseInt(value.String(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("census.StatPeriods.UnmarshalJSON: period %q: %w", key, err)
+		}
+		if i > len(values) {
+			values = append(values, make(StatPeriods, i-len(values))...)
+		}
+		values[i-1] = v
+	}
+	*p = values
+	return nil
+}
+
+// Sum returns the total of the n most recent periods,
+// such as the kills of the last 7 days.
+func (p StatPeriods) Sum(n int) int64 {
+	var sum int64
+	for _, v := range p[:min(n, len(p))] {
+		sum += v
+	}
+	return sum
+}
+
+// GetCharacterStats returns the characters_stat rows of character,
+// limited to the named stats if any are given.
+func GetCharacterStats(ctx context.Context, client *Client, env ps2.Environment, character ps2.CharacterID, stats ...string) ([]CharacterStat, error) {
+	rows, err := getList[CharacterStat](ctx, client, env, characterStatFilter(character, stats))
+	if err != nil {
+		return nil, fmt.Errorf("census.GetCharacterStats: %w", err)
+	}
+	return rows, nil
+}
+
+// GetCharacterStatHistory returns the stat history of character, sorted by stat name,
+// limited to the named stats (like [StatKills]) if any are given.
+func GetCharacterStatHistory(ctx context.Context, client *Client, env ps2.Environment, character ps2.CharacterID, stats ...string) ([]CharacterStatHistory, error) {
+	rows, err := getList[CharacterStatHistory](ctx, client, env, characterStatFilter(character, stats))
+	if err != nil {
+		return nil, fmt.Errorf("census.GetCharacterStatHistory: %w", err)
+	}
+	slices.SortFunc(rows, func(a, b CharacterStatHistory) int { return strings.Compare(a.StatName, b.StatName) })
+	return rows, nil
+}
+
+func characterStatFilter(character ps2.CharacterID, stats []string) string {
+	filter := "character_id=" + character.String()
+	if len(stats) > 0 {
+		filter += "&stat_name=" + escapeList(stats, ",")
+	}
+	return filter
+}
@@ -0,0 +1,41 @@
+package census_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestCharacterStatHistoryUnmarshal(t *testing.T) {
+	row := `{"character_id":"5428010618015189713","stat_name":"kills","all_time":"12345","one_life_max":"0",
+		"day":{"d01":"10","d02":"0","d03":"25"},
+		"week":{"w01":"35","w02":"100"},
+		"month":{"m01":"135"},
+		"last_save":"1700000000","last_save_date":"2023-11-14 22:13:20.0"}`
+	var h census.CharacterStatHistory
+	if err := json.Unmarshal([]byte(row), &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.StatName != census.StatKills || h.AllTime != 12345 {
+		t.Errorf("got %s = %d; want kills = 12345", h.StatName, h.AllTime)
+	}
+	if want := (census.StatPeriods{10, 0, 25}); !slices.Equal(h.Day, want) {
+		t.Errorf("got days %v; want %v", h.Day, want)
+	}
+	if got := h.Day.Sum(2); got != 10 {
+		t.Errorf("got %d kills in the last 2 days; want 10", got)
+	}
+	if got := h.Week.Sum(20); got != 135 {
+		t.Errorf("got %d kills in all weeks; want 135", got)
+	}
+	if h.LastSave.Time().Unix() != 1700000000 {
+		t.Errorf("got last save %v", h.LastSave.Time())
+	}
+
+	var bad census.CharacterStatHistory
+	if err := json.Unmarshal([]byte(`{"day":{"dx":"1"}}`), &bad); err == nil {
+		t.Error("expected an error for an invalid period")
+	}
+}
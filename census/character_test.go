@@ -0,0 +1,78 @@
+package census_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// characterServer answers character queries with a row for every requested ID.
+type characterServer struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (s *characterServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+	type row struct {
+		CharacterID string `json:"character_id"`
+		FactionID   string `json:"faction_id"`
+	}
+	var rows []row
+	for _, id := range strings.Split(req.URL.Query().Get("character_id"), ",") {
+		rows = append(rows, row{CharacterID: id, FactionID: "1"})
+	}
+	body, _ := json.Marshal(map[string]any{"character_list": rows, "returned": len(rows)})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetCharactersByID(t *testing.T) {
+	srv := &characterServer{}
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: srv})
+	census.SetCharacterCache(1000, 0)
+	defer census.SetCharacterCache(0, 0)
+
+	ids := make([]ps2.CharacterID, 0, 301)
+	for i := range 300 {
+		ids = append(ids, ps2.CharacterID(5428010618015189713+i))
+	}
+	ids = append(ids, ids[0]) // duplicates are only requested once
+
+	characters, err := census.GetCharactersByID(context.Background(), client, ps2.PC, ids...)
+	if err != nil {
+		t.Fatalf("GetCharactersByID: %v", err)
+	}
+	if len(characters) != 300 {
+		t.Errorf("got %d characters; want 300", len(characters))
+	}
+	if srv.requests != 2 {
+		t.Errorf("got %d requests; want 2 batches", srv.requests)
+	}
+
+	characters, err = census.GetCharactersByID(context.Background(), client, ps2.PC, ids[:10]...)
+	if err != nil {
+		t.Fatalf("GetCharactersByID: %v", err)
+	}
+	if len(characters) != 10 || characters[0].FactionID != ps2.VS {
+		t.Errorf("got %d cached characters: %+v", len(characters), characters)
+	}
+	if srv.requests != 2 {
+		t.Errorf("cached characters were requested again")
+	}
+}
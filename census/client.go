@@ -9,6 +9,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -58,6 +59,14 @@ type Client struct {
 	// e.g. a value of 1 means if the first request fails then 1 more request will be made.
 	maxRetries uint8
 	env        ps2.Environment
+	truncation Truncation
+	httpClient *http.Client
+	recorder   *Recorder
+	cache      *ResponseCache
+	failFast   time.Duration // failFast is the total time allowed per call, or 0
+	metrics    MetricsHook
+	limiter    *Limiter            // limiter replaces the package limits when it's not nil
+	budget     *retryBudgetTracker // budget replaces the package retry budget when it's not nil
 }
 
 // Get calls DefaultClient.Get, using the default environment.
@@ -96,7 +105,7 @@ type requestError struct {
 func (e *requestError) Error() string {
 	// fs.PathError.Error: func (e *PathError) Error() string { return e.Op + " " + e.Path + ": " + e.Err.Error() }
 
-	return fmt.Sprintf("census: %s: %s; url=%q", e.op, e.e, e.url)
+	return fmt.Sprintf("census: %s: %s; url=%q", e.op, e.e, RedactURL(e.url))
 }
 
 // // Timeout reports whether this error represents a timeout.
@@ -134,42 +143,100 @@ func (e *requestError) Unwrap() error {
 //
 // It is safe to perform concurrent census requests;
 // rate and concurrency limits are automatically enforced at the package level.
-func (c Client) Get(ctx context.Context, env ps2.Environment, query string, result any) (err error) {
+// Requests waiting for the concurrency limit are sent in order of the [Priority] set on ctx with [WithPriority].
+//
+// Queries that set c:limit are checked for truncation according to the policy set with [Client.SetTruncation].
+func (c Client) Get(ctx context.Context, env ps2.Environment, query string, result any) error {
+	_, err := c.GetCount(ctx, env, query, result)
+	return err
+}
+
+// GetCount is the same as [Client.Get],
+// but also reports the number of results census returned and the c:limit of the query.
+// When the truncation policy is [TruncationPaginate] the returned count is the total for every page.
+func (c Client) GetCount(ctx context.Context, env ps2.Environment, query string, result any) (Count, error) {
+	if c.truncation == TruncationPaginate {
+		return c.getPages(ctx, env, query, result)
+	}
+	count, err := c.getRetry(ctx, env, query, result)
+	if err != nil || !count.Truncated() {
+		return count, err
+	}
+	if c.truncation == TruncationError {
+		return count, &TruncatedError{Query: query, Count: count}
+	}
+	c.logger().log(ctx, "census results were probably truncated", "query", query, "returned", count.Returned, "limit", count.Limit)
+	return count, nil
+}
+
+// getRetry performs a request with retries.
+func (c Client) getRetry(ctx context.Context, env ps2.Environment, query string, result any) (count Count, err error) {
+	count.Limit = queryLimit(query)
 	var canRetry interface{ Retryable() bool }
 	var delayRetry interface{ RetryAfter() time.Time }
 
+	ctx, done := c.withBudget(ctx, query)
+	attempts := 0
+	defer func() { err = done(err, attempts) }()
+
+	budget := c.retryBudget()
+	budget.call()
 	for retries := uint8(0); retries <= c.maxRetries; retries++ {
-		err = c.get(ctx, env, query, result, int(retries))
+		attempts++
+		err = c.get(ctx, env, query, result, &count.Returned, int(retries))
 		if err == nil {
 			break
 		}
 
 		if retries == c.maxRetries {
 			// skip checking the error result on the last attempt
-			return err
+			return count, err
 		}
 
 		if errors.As(err, &canRetry) {
 			if !canRetry.Retryable() {
-				return err
+				return count, err
 			}
 		}
 		if errors.As(err, &delayRetry) {
-			if wait := time.Until(delayRetry.RetryAfter()); wait > 5*time.Second {
+			if wait := time.Until(delayRetry.RetryAfter()); wait > 5*time.Second || !waitFits(ctx, delayRetry.RetryAfter()) {
 				// if the error can't be retried within a reasonable human-scale time frame just return the error and let the caller decide what to do.
-				return err
+				return count, err
 			} else {
 				select {
 				case <-time.After(wait):
 				case <-ctx.Done():
-					return err
+					return count, err
 				}
 			}
 		}
+		// the budget is only spent once the retry is certain to be sent
+		if !budget.withdraw() {
+			c.logger().log(ctx, "census retry budget exhausted", "query", RedactURL(query), "error", err)
+			return count, err
+		}
 	}
-	return err
+	return count, err
 }
-func (c Client) get(ctx context.Context, env ps2.Environment, query string, result any, retries int) (err error) {
+func (c Client) get(ctx context.Context, env ps2.Environment, query string, result any, returned *int, retries int) (err error) {
+	// streamed responses are never cached since the body is never held in memory
+	stream, streaming := result.(streamDecoder)
+
+	// fresh cached responses never reach census,
+	// so they're answered before logging, limits, and the circuit breaker
+	var cached CachedResponse
+	var fresh, found bool
+	if !streaming {
+		cached, fresh, found = c.cache.lookup(env, query, time.Now())
+	}
+	if fresh {
+		err = decodeCachedResponse(cached.Body, result, returned)
+		if c.metrics != nil {
+			c.metrics.Request(RequestMetrics{Collection: queryCollection(query), Env: env, Attempt: retries + 1, Cached: true, Err: err})
+		}
+		return err
+	}
+
 	var url string
 	timing := struct {
 		fnStart      time.Time
@@ -180,6 +247,8 @@ func (c Client) get(ctx context.Context, env ps2.Environment, query string, resu
 	}
 	var httpResponseCode int
 	var responseSize int
+	var responseBody []byte
+	var sent bool
 
 	// defer the logging function before any conditions that might return,
 	// so that every call here is logged
@@ -192,7 +261,7 @@ func (c Client) get(ctx context.Context, env ps2.Environment, query string, resu
 			timing.requestEnd = time.Now()
 		}
 		logger.log(ctx, "census request",
-			"url", url,
+			"url", RedactURL(url),
 			slog.Group("response",
 				"size", responseSize,
 				"statuscode", httpResponseCode,
@@ -206,13 +275,41 @@ func (c Client) get(ctx context.Context, env ps2.Environment, query string, resu
 			"error", err,
 			// "parse_duration", time.Since(timing.requestEnd),
 		)
+		if url != "" {
+			c.recorder.record(url, httpResponseCode, responseBody, err, retries+1)
+		}
+		if c.metrics != nil {
+			m := RequestMetrics{
+				Collection: queryCollection(query),
+				Env:        env,
+				Attempt:    retries + 1,
+				Wait:       timing.requestStart.Sub(timing.fnStart),
+				StatusCode: httpResponseCode,
+				Cached:     httpResponseCode == http.StatusNotModified,
+				Err:        err,
+			}
+			if sent {
+				m.Duration = timing.requestEnd.Sub(timing.requestStart)
+			}
+			c.metrics.Request(m)
+			_, open := CircuitOpen()
+			c.metrics.CircuitState(open)
+		}
 	}()
 
 	// once logging is ready and before any other conditions,
 	// check if the circuit breaker has already been tripped.
 	// this check should be after logging is set up so that failures are still logged,
 	// but before the deferred function that might modify errors or track them towards the circuit breaker limits.
-	if err = breaker.Err(); err != nil {
+	// fail fast clients skip the breaker entirely so that interactive commands always reach census.
+	if c.failFast == 0 {
+		if err = breaker.Err(); err != nil {
+			return err
+		}
+	}
+
+	// an invalid service ID is a configuration problem and shouldn't count toward the circuit breaker
+	if err = checkServiceID(c.ServiceID); err != nil {
 		return err
 	}
 
@@ -221,26 +318,37 @@ func (c Client) get(ctx context.Context, env ps2.Environment, query string, resu
 	// this means every possible error path is covered so that we can easily let the circuit breaker keep track of errors.
 	defer func() {
 		err = wrapRetryableErrors(err)
-		breaker.Track(err)
+		if c.failFast == 0 {
+			breaker.Track(err)
+		}
+		health.track(err)
 	}()
 
+	rate, inFlight, waitingCount := c.limits()
+	c.queueDepth(waitingCount.Add(1))
+	waiting := true
+	stopWaiting := func() {
+		if waiting {
+			waiting = false
+			c.queueDepth(waitingCount.Add(-1))
+		}
+	}
+	defer stopWaiting()
+	// first wait for other requests to finish
+	if err := inFlight.acquire(ctx, priorityFrom(ctx)); err != nil {
+		return fmt.Errorf("waiting for other requests to finish: %w", err)
+	}
+	defer inFlight.release()
 	select {
-	case concurrentLimiter <- struct{}{}:
-		// first wait for other requests to finish
-		defer func() { <-concurrentLimiter }()
-		select {
-		case _, ok := <-RateLimiter.Ready():
-			// then wait for the rate limiter
-			if !ok {
-				return errors.New("rate limiter stopped")
-			}
-		case <-ctx.Done():
-			return fmt.Errorf("waiting for rate limiter: %w", ctx.Err())
-
+	case _, ok := <-rate.Ready():
+		// then wait for the rate limiter
+		if !ok {
+			return errors.New("rate limiter stopped")
 		}
 	case <-ctx.Done():
-		return fmt.Errorf("waiting for other requests to finish: %w", ctx.Err())
+		return fmt.Errorf("waiting for rate limiter: %w", ctx.Err())
 	}
+	stopWaiting()
 
 	var waitduration time.Duration
 	switch retries {
@@ -266,29 +374,53 @@ func (c Client) get(ctx context.Context, env ps2.Environment, query string, resu
 	}
 	ctx, cancel := context.WithTimeout(ctx, waitduration)
 	defer cancel()
-	url = fmt.Sprintf("%s/s:%s/get/%s/%s", apiBase, c.ServiceID, Namespace(env), query)
+	url = c.requestURL(env, query)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
+	if found {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
 	timing.requestStart = time.Now()
-	resp, err := http.DefaultClient.Do(req)
+	sent = true
+	resp, err := c.http().Do(req)
 	timing.requestEnd = time.Now()
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("request failed: %w", redactError(err))
 	}
 	defer resp.Body.Close()
 	httpResponseCode = resp.StatusCode
+	if resp.StatusCode == http.StatusNotModified && found {
+		if err := c.cache.put(env, query, cached.Body, resp.Header, time.Now()); err != nil {
+			c.logger().log(ctx, "census response cache failed", "error", err)
+		}
+		return decodeCachedResponse(cached.Body, result, returned)
+	}
 	if resp.StatusCode != http.StatusOK {
 		// even internal server errors return "200 OK" with an errorCode json field.
 		return fmt.Errorf("returned http %d", resp.StatusCode)
 	}
 
+	if streaming {
+		*returned, err = stream.decodeStream(resp.Body)
+		if err != nil && !stream.started() && isMaintenanceRedirect(resp) {
+			return errServerMaintenance
+		}
+		return err
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("read body: %w", err)
 	}
 	responseSize = len(body)
+	responseBody = body
 
 	// Planetside's api follows the philosophy that the HTTP protocol is a transport layer.
 	// Any HTTP code other than 200 indicates something went wrong in transport and should generally be treated the same as if a TCP connection had an error.
@@ -310,66 +442,115 @@ func (c Client) get(ctx context.Context, env ps2.Environment, query string, resu
 	// (Usually this means a DB is down, or some data source cannot be reached).
 	// A redirect means the Apache load balancer couldn't find a Tomcat server to forward the request to.
 	// https://discord.com/channels/1019343142471880775/1019509468754608168/1278010950913359953
-	errorResponse := struct {
-		Error        string `json:"error"`
-		ErrorCode    string `json:"errorCode"`
-		ErrorMessage string `json:"errorMessage"`
-	}{}
-	if err = json.Unmarshal(body, &errorResponse); err != nil {
+	var response errorResponse
+	if err = json.Unmarshal(body, &response); err != nil {
 		if bytes.Contains(bytes.TrimSpace(body)[:512], []byte("<html")) {
-			c.logger().log(ctx, "census returned an unusual response", "final_url", resp.Request.URL.String(), "body_truncated", string(body[:512]))
+			c.logger().log(ctx, "census returned an unusual response", "final_url", RedactURL(resp.Request.URL.String()), "body_truncated", string(body[:512]))
 		}
-		if resp.Request.URL.Host == "www.daybreakgames.com" && resp.Request.URL.Path == "/home" {
-			// Census has been observed to redirect to the daybreak homepage during maintenance,
-			// which contains normal HTML.
-			// This check only works if the provided http.Client follows redirects,
-			// which it does by default.
+		if isMaintenanceRedirect(resp) {
 			return errServerMaintenance
 		}
 		return errBadJSON(err)
 	}
+	if err = response.err(); err != nil {
+		return err
+	}
 
+	if err = decodeJSON(body, result); err != nil {
+		// json decoding errors like html in the body would have been caught already when unmarshaling the errorResponse struct.
+		// If an error occurs at this stage,
+		// then it's likely to be caused by result implementing json.Unmarshaler and returning an error.
+		// Another request would probably result in the same response body
+		// (except for hitting dynamic collections or different load balancer caches),
+		// so it's better to skip retries.
+		return permanentError{errBadJSON(err)}
+	}
+
+	*returned = int(response.Returned)
+	if err := c.cache.put(env, query, body, resp.Header, time.Now()); err != nil {
+		c.logger().log(ctx, "census response cache failed", "error", err)
+	}
+	return nil
+}
+
+// decodeCachedResponse decodes a body from a [ResponseCache].
+// Only successful responses are cached, so error responses don't need to be checked for.
+func decodeCachedResponse(body []byte, result any, returned *int) error {
+	if err := decodeJSON(body, result); err != nil {
+		return permanentError{errBadJSON(err)}
+	}
+	var counted struct {
+		Returned returnedCount `json:"returned"`
+	}
+	if json.Unmarshal(body, &counted) == nil {
+		*returned = int(counted.Returned)
+	}
+	return nil
+}
+
+// errorResponse holds the error fields of a census response.
+// Every successful response also includes the number of returned rows next to the collection list,
+// which is decoded in the same pass.
+type errorResponse struct {
+	Error        string        `json:"error"`
+	ErrorCode    string        `json:"errorCode"`
+	ErrorMessage string        `json:"errorMessage"`
+	Returned     returnedCount `json:"returned"`
+}
+
+// returnedCount is the "returned" field of a response.
+// Census sometimes quotes it, and a count that can't be read is left as 0
+// rather than failing an otherwise good response.
+type returnedCount int
+
+func (n *returnedCount) UnmarshalJSON(data []byte) error {
+	i, _ := strconv.Atoi(strings.Trim(string(data), `"`))
+	*n = returnedCount(i)
+	return nil
+}
+
+// err returns the error described by the response, or nil for a successful response.
+func (r errorResponse) err() error {
 	// if the error field is present then this is a normal error response
-	if errorResponse.Error != "" {
-		if strings.HasPrefix(errorResponse.Error, "Missing Service ID") {
+	if r.Error != "" {
+		if strings.HasPrefix(r.Error, "Missing Service ID") {
 			return errRateLimitExceeded
 		}
-		if strings.HasPrefix(errorResponse.Error, "Provided Service ID is not registered") {
+		if strings.HasPrefix(r.Error, "Provided Service ID is not registered") {
 			return ErrBadServiceID
 		}
-		if errorResponse.Error == "Bad request syntax." {
+		if r.Error == "Bad request syntax." {
 			return errBadRequestSyntax
 		}
-		if errorResponse.Error == "No data found." {
+		if r.Error == "No data found." {
 			return errNotFound
 		}
-		if errorResponse.Error == "service_unavailable" {
+		if r.Error == "service_unavailable" {
 			return errServiceUnavailable
 		}
 
-		return genericServerError(errorResponse.Error)
+		return genericServerError(r.Error)
 	}
 
 	// if the errorCode field is present then this is a java exception
-	if errorResponse.ErrorCode != "" {
+	if r.ErrorCode != "" {
 		return genericInternalServerError{
-			errorCode:    errorResponse.ErrorCode,
-			errorMessage: errorResponse.ErrorMessage,
+			errorCode:    r.ErrorCode,
+			errorMessage: r.ErrorMessage,
 		}
 	}
-
-	if err = json.Unmarshal(body, result); err != nil {
-		// json decoding errors like html in the body would have been caught already when unmarshaling the errorResponse struct.
-		// If an error occurs at this stage,
-		// then it's likely to be caused by result implementing json.Unmarshaler and returning an error.
-		// Another request would probably result in the same response body
-		// (except for hitting dynamic collections or different load balancer caches),
-		// so it's better to skip retries.
-		return permanentError{errBadJSON(err)}
-	}
 	return nil
 }
 
+// isMaintenanceRedirect reports whether resp was redirected to the daybreak homepage.
+// Census has been observed to redirect there during maintenance,
+// which contains normal HTML.
+// This check only works if the provided http.Client follows redirects,
+// which it does by default.
+func isMaintenanceRedirect(resp *http.Response) bool {
+	return resp.Request.URL.Host == "www.daybreakgames.com" && resp.Request.URL.Path == "/home"
+}
+
 // SetLog sets the log function the client will use when making requests.
 //
 //	client := &census.Client{Key:"example"}
@@ -378,6 +559,25 @@ func (c *Client) SetLog(fn func(ctx context.Context, msg string, args ...any)) {
 	c.logf = fn
 }
 
+// SetHTTPClient sets the http client used for requests.
+// The default is [http.DefaultClient].
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.httpClient = hc
+}
+
+func (c Client) http() *http.Client {
+	if c.httpClient == nil {
+		return http.DefaultClient
+	}
+	return c.httpClient
+}
+
+func (c Client) queueDepth(waiting int64) {
+	if c.metrics != nil {
+		c.metrics.QueueDepth(int(waiting))
+	}
+}
+
 func (c Client) logger() logger {
 	if c.logf == nil {
 		return func(context.Context, string, ...any) {}
@@ -393,13 +593,14 @@ func (f logger) log(ctx context.Context, msg string, args ...any) {
 }
 
 // concurrentLimiter limits the number of census requests that can be in-flight concurrently.
-// This is intentionally not configurable.
+// The package limit is intentionally not configurable;
+// clients that need a different limit use their own [Limiter].
 // Two concurrent requests allows up to one to be stuck for a few moments waiting to be handled by a load balancer
 // without blocking the next request (which might hit a different load balancer).
 // Requests are ultimately still limited by the ratelimiter once the burst request limit is exhausted.
-var concurrentLimiter chan struct{} = make(chan struct{}, 2)
+var concurrentLimiter = newSlots(2)
 
-// RateLimit sets the global rate limiter used by every Client.
+// RateLimit sets the global rate limiter used by every Client without its own [Limiter].
 // burst sets the number of requests that can be sent initially without throttling,
 // and nPerSec defines how many requests can be made per second after that.
 //
@@ -410,21 +611,7 @@ var concurrentLimiter chan struct{} = make(chan struct{}, 2)
 // the concurrency limit remains at two in-flight requests.
 func RateLimit(burst, nPerSec int) {
 	stopLastLimiter()
-	if burst < 1 {
-		burst = 1
-	}
-	limiter := make(rateLimit, burst-1) //nPerSec-1 because we assume at the start of a burst there will already be a waiting send from ticker
-	ticker := time.NewTicker(time.Second / time.Duration(nPerSec))
-	stopLastLimiter = ticker.Stop
-	RateLimiter = limiter
-	for range burst - 1 {
-		limiter <- struct{}{}
-	}
-	go func() {
-		for range ticker.C {
-			limiter <- struct{}{}
-		}
-	}()
+	RateLimiter, stopLastLimiter = newRateLimit(burst, nPerSec)
 }
 
 var stopLastLimiter func() = func() {}
@@ -461,6 +648,20 @@ func (breaker *circuitBreaker) Err() error {
 	return breaker.err
 }
 
+// CircuitOpen reports whether the package circuit breaker is currently failing requests without sending them,
+// which happens after repeated errors or when census looks to be down for maintenance.
+// until is the time the breaker will next allow a request through.
+//
+// Pollers can use this to back off instead of spending their retries against the breaker.
+func CircuitOpen() (until time.Time, open bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	if breaker.err == nil || time.Now().After(breaker.resetAfter) {
+		return time.Time{}, false
+	}
+	return breaker.resetAfter, true
+}
+
 // Track inspects errors and trips the circuit breaker when specific conditions are met.
 // Consecutive errors increase the error count.
 // nil errors reset the error count.
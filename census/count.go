@@ -0,0 +1,175 @@
+package census
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Count is the number of results returned by census for a query,
+// along with the c:limit that applied to it.
+type Count struct {
+	Returned int
+
+	// Limit is the c:limit of the query, or 0 if the query didn't set one.
+	Limit int
+}
+
+// Truncated reports whether the results were probably cut short by c:limit.
+// Census doesn't say whether more results exist,
+// so a full page is the only signal available.
+//
+// Queries with c:limit=1 are never considered truncated,
+// since they are almost always deliberate lookups of a single row.
+func (c Count) Truncated() bool {
+	return c.Limit > 1 && c.Returned >= c.Limit
+}
+
+// Truncation is the policy a [Client] follows when a query returns as many results as its c:limit.
+type Truncation uint8
+
+const (
+	// TruncationLog logs truncated results and otherwise returns them as if they were complete.
+	// This is the default.
+	TruncationLog Truncation = iota
+
+	// TruncationError returns a [*TruncatedError].
+	// The truncated results are still unmarshaled into the result.
+	TruncationError
+
+	// TruncationPaginate requests following pages with c:start until a page is not full,
+	// and unmarshals the combined list into the result.
+	// Only queries that return a single collection list can be paginated.
+	TruncationPaginate
+)
+
+// SetTruncation sets the policy for queries that return as many results as their c:limit.
+func (c *Client) SetTruncation(t Truncation) {
+	c.truncation = t
+}
+
+// TruncatedError is returned by [Client.Get] with the [TruncationError] policy.
+type TruncatedError struct {
+	Query string
+	Count
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("results probably truncated: returned %d of c:limit=%d", e.Returned, e.Limit)
+}
+
+func (*TruncatedError) Retryable() bool { return false }
+
+// maxPages stops runaway pagination of queries that never return a partial page.
+const maxPages = 100
+
+// getPages requests query repeatedly with an increasing c:start until census returns a page that isn't full,
+// then unmarshals every page's list into result as if it were one response.
+func (c Client) getPages(ctx context.Context, env ps2.Environment, query string, result any) (total Count, err error) {
+	limit := queryLimit(query)
+	total.Limit = limit
+	start := queryInt(query, "c:start")
+	var listKey string
+	var list []json.RawMessage
+	for page := 0; ; page++ {
+		if page == maxPages {
+			return total, fmt.Errorf("census: pagination stopped after %d pages; query=%q", maxPages, query)
+		}
+		var response map[string]json.RawMessage
+		count, err := c.getRetry(ctx, env, withParam(query, "c:start", start), &response)
+		if err != nil {
+			return total, err
+		}
+		total.Returned += count.Returned
+
+		key, err := collectionListKey(response)
+		if err != nil {
+			return total, permanentError{err}
+		}
+		if listKey != "" && key != listKey {
+			return total, permanentError{fmt.Errorf("page %d returned %q instead of %q", page, key, listKey)}
+		}
+		listKey = key
+		var rows []json.RawMessage
+		if err := json.Unmarshal(response[key], &rows); err != nil {
+			return total, permanentError{errBadJSON(err)}
+		}
+		list = append(list, rows...)
+
+		if !count.Truncated() {
+			break
+		}
+		start += limit
+	}
+
+	combined, err := json.Marshal(map[string]any{
+		listKey:    list,
+		"returned": total.Returned,
+	})
+	if err != nil {
+		return total, err
+	}
+	if err := decodeJSON(combined, result); err != nil {
+		return total, permanentError{errBadJSON(err)}
+	}
+	return total, nil
+}
+
+// collectionListKey finds the "<collection>_list" key of a response.
+func collectionListKey(response map[string]json.RawMessage) (string, error) {
+	var key string
+	for k := range response {
+		if strings.HasSuffix(k, "_list") {
+			if key != "" {
+				return "", fmt.Errorf("response contains both %q and %q", key, k)
+			}
+			key = k
+		}
+	}
+	if key == "" {
+		return "", fmt.Errorf("response didn't contain a collection list")
+	}
+	return key, nil
+}
+
+// queryLimit returns the c:limit of query, or 0 if it isn't set.
+func queryLimit(query string) int {
+	return queryInt(query, "c:limit")
+}
+
+// queryInt returns the integer value of param in query, or 0 if it isn't set.
+func queryInt(query string, param string) int {
+	_, rawQuery, _ := strings.Cut(query, "?")
+	for _, kv := range strings.Split(rawQuery, "&") {
+		k, v, _ := strings.Cut(kv, "=")
+		if k != param {
+			continue
+		}
+		if v, err := url.QueryUnescape(v); err == nil {
+			n, _ := strconv.Atoi(v)
+			return n
+		}
+	}
+	return 0
+}
+
+// withParam returns query with param set to value, replacing any existing value.
+// The rest of the query is left exactly as given,
+// since census syntax like c:join doesn't survive a round trip through url.Values.
+func withParam(query string, param string, value int) string {
+	path, rawQuery, _ := strings.Cut(query, "?")
+	params := make([]string, 0, 8)
+	for _, kv := range strings.Split(rawQuery, "&") {
+		if k, _, _ := strings.Cut(kv, "="); k == param || kv == "" {
+			continue
+		}
+		params = append(params, kv)
+	}
+	params = append(params, param+"="+strconv.Itoa(value))
+	return path + "?" + strings.Join(params, "&")
+}
@@ -0,0 +1,149 @@
+package census_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+type worldList struct {
+	WorldList []census.World `json:"world_list"`
+}
+
+// pagedClient returns a client for a world collection of n rows,
+// answering each request with the page selected by c:start and c:limit.
+// Every requested c:start is appended to starts.
+func pagedClient(t *testing.T, n int, starts *[]int) *census.Client {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		start, _ := strconv.Atoi(q.Get("c:start"))
+		limit, _ := strconv.Atoi(q.Get("c:limit"))
+		*starts = append(*starts, start)
+		var rows []string
+		for id := start + 1; id <= n && (limit == 0 || id <= start+limit); id++ {
+			rows = append(rows, fmt.Sprintf(`{"world_id":"%d","state":"online"}`, id))
+		}
+		body := fmt.Sprintf(`{"world_list":[%s],"returned":%d}`, strings.Join(rows, ","), len(rows))
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})})
+	limiter := census.NewLimiter(100, 100, 10)
+	t.Cleanup(limiter.Stop)
+	client.SetLimiter(limiter)
+	return client
+}
+
+func TestCountTruncated(t *testing.T) {
+	tt := map[census.Count]bool{
+		{Returned: 5, Limit: 0}:       false,
+		{Returned: 4, Limit: 5}:       false,
+		{Returned: 5, Limit: 5}:       true,
+		{Returned: 5000, Limit: 5000}: true,
+		// single row lookups are deliberate
+		{Returned: 1, Limit: 1}: false,
+	}
+	for count, want := range tt {
+		if got := count.Truncated(); got != want {
+			t.Errorf("%+v: got truncated %t; want %t", count, got, want)
+		}
+	}
+}
+
+func TestGetCount(t *testing.T) {
+	// census has sent returned both quoted and unquoted
+	for _, returned := range []string{`2`, `"2"`} {
+		client := staticClient(`{"world_list":[{"world_id":"1"},{"world_id":"17"}],"returned":` + returned + `}`)
+		var worlds worldList
+		count, err := client.GetCount(context.Background(), ps2.PC, "world?c:limit=10", &worlds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != (census.Count{Returned: 2, Limit: 10}) || len(worlds.WorldList) != 2 {
+			t.Errorf("returned %s: got %+v with %d worlds", returned, count, len(worlds.WorldList))
+		}
+	}
+
+	// a response without a count is still a successful response
+	client := staticClient(`{"world_list":[{"world_id":"1"}]}`)
+	count, err := client.GetCount(context.Background(), ps2.PC, "world", &worldList{})
+	if err != nil || count != (census.Count{}) {
+		t.Errorf("got %+v, %v; want a zero count", count, err)
+	}
+}
+
+func TestGetCountCached(t *testing.T) {
+	srv := &etagServer{}
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: srv})
+	client.SetCache(&census.ResponseCache{TTL: map[string]time.Duration{"world": time.Hour}})
+	for range 2 {
+		count, err := client.GetCount(context.Background(), ps2.PC, "world?c:limit=1", &worldList{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count.Returned != 1 {
+			t.Errorf("got %+v; want the count of the cached body", count)
+		}
+	}
+	if srv.requests != 1 {
+		t.Errorf("got %d requests; want the second one cached", srv.requests)
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	var starts []int
+	client := pagedClient(t, 7, &starts)
+
+	// the default policy returns the first page as if it were complete
+	var worlds worldList
+	count, err := client.GetCount(context.Background(), ps2.PC, "world?c:limit=3", &worlds)
+	if err != nil || count != (census.Count{Returned: 3, Limit: 3}) || len(worlds.WorldList) != 3 {
+		t.Errorf("log: got %+v with %d worlds, %v", count, len(worlds.WorldList), err)
+	}
+
+	client.SetTruncation(census.TruncationError)
+	worlds = worldList{}
+	err = client.Get(context.Background(), ps2.PC, "world?c:limit=3", &worlds)
+	var truncated *census.TruncatedError
+	if !errors.As(err, &truncated) || truncated.Returned != 3 || truncated.Limit != 3 || truncated.Query != "world?c:limit=3" {
+		t.Errorf("error: got %v; want a truncated error", err)
+	}
+	if len(worlds.WorldList) != 3 {
+		t.Errorf("error: got %d worlds; want the truncated results anyway", len(worlds.WorldList))
+	}
+	if err := client.Get(context.Background(), ps2.PC, "world?c:limit=10", &worldList{}); err != nil {
+		t.Errorf("error: got %v for a partial page", err)
+	}
+
+	starts = nil
+	client.SetTruncation(census.TruncationPaginate)
+	worlds = worldList{}
+	count, err = client.GetCount(context.Background(), ps2.PC, "world?c:limit=3", &worlds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != (census.Count{Returned: 7, Limit: 3}) || len(worlds.WorldList) != 7 || worlds.WorldList[6].WorldID != 7 {
+		t.Errorf("paginate: got %+v with worlds %+v; want all 7", count, worlds.WorldList)
+	}
+	if fmt.Sprint(starts) != "[0 3 6]" {
+		t.Errorf("paginate: got pages starting at %v", starts)
+	}
+
+	// a full last page needs one more request to find the end
+	starts = nil
+	client = pagedClient(t, 6, &starts)
+	client.SetTruncation(census.TruncationPaginate)
+	count, err = client.GetCount(context.Background(), ps2.PC, "world?c:limit=3&c:start=0", &worlds)
+	if err != nil || count.Returned != 6 || fmt.Sprint(starts) != "[0 3 6]" {
+		t.Errorf("paginate: got %+v, %v from pages starting at %v", count, err, starts)
+	}
+}
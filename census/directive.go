@@ -0,0 +1,215 @@
+package census
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// DirectiveTreeCategory groups directive trees, such as "Weapons" or "Vehicles".
+type DirectiveTreeCategory struct {
+	DirectiveTreeCategoryID ps2.DirectiveTreeCategoryID `json:"directive_tree_category_id,string"`
+	Name                    ps2.Localization            `json:"name"`
+}
+
+func (DirectiveTreeCategory) CollectionName() string { return "directive_tree_category" }
+
+// DirectiveTree is a line of directives, such as a weapon category or a class.
+type DirectiveTree struct {
+	DirectiveTreeID         ps2.DirectiveTreeID         `json:"directive_tree_id,string"`
+	DirectiveTreeCategoryID ps2.DirectiveTreeCategoryID `json:"directive_tree_category_id,string"`
+	Name                    ps2.Localization            `json:"name"`
+	Description             ps2.Localization            `json:"description"`
+	ImageSetID              ps2.ImageSetID              `json:"image_set_id,string"`
+	ImageID                 ps2.ImageID                 `json:"image_id,string"`
+	ImagePath               string                      `json:"image_path"`
+}
+
+func (DirectiveTree) CollectionName() string { return "directive_tree" }
+
+func (t DirectiveTree) ImageURL() string { return apiBase + t.ImagePath }
+
+// DirectiveTier is one tier of a directive tree.
+// A tier is completed once CompletionCount of its directives are completed.
+type DirectiveTier struct {
+	DirectiveTreeID ps2.DirectiveTreeID `json:"directive_tree_id,string"`
+	DirectiveTierID ps2.DirectiveTierID `json:"directive_tier_id,string"`
+	Name            ps2.Localization    `json:"name"`
+	RewardSetID     int                 `json:"reward_set_id,string"`
+	DirectivePoints int                 `json:"directive_points,string"`
+	CompletionCount int                 `json:"completion_count,string"`
+	ImageSetID      ps2.ImageSetID      `json:"image_set_id,string"`
+	ImageID         ps2.ImageID         `json:"image_id,string"`
+	ImagePath       string              `json:"image_path"`
+}
+
+func (DirectiveTier) CollectionName() string { return "directive_tier" }
+
+// Directive is a single directive, such as earning a weapon's auraxium medal.
+type Directive struct {
+	DirectiveID          ps2.DirectiveID     `json:"directive_id,string"`
+	DirectiveTreeID      ps2.DirectiveTreeID `json:"directive_tree_id,string"`
+	DirectiveTierID      ps2.DirectiveTierID `json:"directive_tier_id,string"`
+	ObjectiveSetID       int                 `json:"objective_set_id,string"`
+	QualifyRequirementID int                 `json:"qualify_requirement_id,string"`
+	Name                 ps2.Localization    `json:"name"`
+	Description          ps2.Localization    `json:"description"`
+	ImageSetID           ps2.ImageSetID      `json:"image_set_id,string"`
+	ImageID              ps2.ImageID         `json:"image_id,string"`
+	ImagePath            string              `json:"image_path"`
+}
+
+func (Directive) CollectionName() string { return "directive" }
+
+// CharacterDirectiveTree is a character's progress in a directive tree.
+// Census only has rows for trees the character has started.
+type CharacterDirectiveTree struct {
+	CharacterID            ps2.CharacterID     `json:"character_id,string"`
+	DirectiveTreeID        ps2.DirectiveTreeID `json:"directive_tree_id,string"`
+	CurrentDirectiveTierID ps2.DirectiveTierID `json:"current_directive_tier_id,string"`
+	CurrentLevel           int                 `json:"current_level,string"`
+	CompletionTime         UnixTime            `json:"completion_time"` // CompletionTime is zero until the tree is completed
+}
+
+func (CharacterDirectiveTree) CollectionName() string { return "character_directive_tree" }
+
+// CharacterDirectiveTier is a directive tier a character has started.
+type CharacterDirectiveTier struct {
+	CharacterID     ps2.CharacterID     `json:"character_id,string"`
+	DirectiveTreeID ps2.DirectiveTreeID `json:"directive_tree_id,string"`
+	DirectiveTierID ps2.DirectiveTierID `json:"directive_tier_id,string"`
+	CompletionTime  UnixTime            `json:"completion_time"` // CompletionTime is zero until the tier is completed
+}
+
+func (CharacterDirectiveTier) CollectionName() string { return "character_directive_tier" }
+
+// CharacterDirective is a directive a character has started.
+type CharacterDirective struct {
+	CharacterID     ps2.CharacterID     `json:"character_id,string"`
+	DirectiveTreeID ps2.DirectiveTreeID `json:"directive_tree_id,string"`
+	DirectiveID     ps2.DirectiveID     `json:"directive_id,string"`
+	CompletionTime  UnixTime            `json:"completion_time"` // CompletionTime is zero until the directive is completed
+}
+
+func (CharacterDirective) CollectionName() string { return "character_directive" }
+
+// DirectiveProgress is a character's progress through a directive tree.
+type DirectiveProgress struct {
+	CharacterID ps2.CharacterID `json:"character_id"`
+	Tree        DirectiveTree   `json:"tree"`
+
+	// CurrentTierID is the tier the character is working on,
+	// or 0 if they haven't started the tree.
+	CurrentTierID ps2.DirectiveTierID `json:"current_tier_id"`
+	CurrentLevel  int                 `json:"current_level"`
+
+	// Completed is when the whole tree was completed, or the zero time.
+	Completed UnixTime `json:"completed"`
+
+	// Tiers are in tier order.
+	Tiers []DirectiveTierProgress `json:"tiers"`
+}
+
+// DirectiveTierProgress is a character's progress through one tier of a directive tree.
+type DirectiveTierProgress struct {
+	Tier DirectiveTier `json:"tier"`
+
+	// Directives is the number of directives in the tier.
+	Directives int `json:"directives"`
+
+	// DirectivesCompleted is how many of the tier's directives the character has completed.
+	// It may be more than Tier.CompletionCount, which is the number needed to complete the tier.
+	DirectivesCompleted int `json:"directives_completed"`
+
+	// Completed is when the tier was completed, or the zero time.
+	Completed UnixTime `json:"completed"`
+
+	// CompletedDirectives are the IDs of the tier's completed directives.
+	CompletedDirectives []ps2.DirectiveID `json:"completed_directives"`
+}
+
+// Done reports whether the tier is complete.
+func (p DirectiveTierProgress) Done() bool {
+	return !p.Completed.Time().IsZero() || (p.Tier.CompletionCount > 0 && p.DirectivesCompleted >= p.Tier.CompletionCount)
+}
+
+// GetDirectiveProgress returns a character's progress through a directive tree.
+// Characters who haven't started the tree have progress with no completed directives.
+func GetDirectiveProgress(ctx context.Context, client *Client, env ps2.Environment, character ps2.CharacterID, tree ps2.DirectiveTreeID) (DirectiveProgress, error) {
+	treeFilter := Eq("directive_tree_id", tree)
+	characterFilter := Eq("character_id", character).And(treeFilter)
+
+	trees, err := getList[DirectiveTree](ctx, client, env, treeFilter.String())
+	if err != nil {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", err)
+	}
+	if len(trees) == 0 {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", noResultsError{q: strconv.Itoa(int(tree))})
+	}
+	tiers, err := getList[DirectiveTier](ctx, client, env, treeFilter.String()+"&c:sort=directive_tier_id")
+	if err != nil {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", err)
+	}
+	directives, err := getList[Directive](ctx, client, env, treeFilter.String())
+	if err != nil {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", err)
+	}
+	characterTrees, err := getList[CharacterDirectiveTree](ctx, client, env, characterFilter.String())
+	if err != nil {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", err)
+	}
+	characterTiers, err := getList[CharacterDirectiveTier](ctx, client, env, characterFilter.String())
+	if err != nil {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", err)
+	}
+	characterDirectives, err := getList[CharacterDirective](ctx, client, env, characterFilter.String())
+	if err != nil {
+		return DirectiveProgress{}, fmt.Errorf("census.GetDirectiveProgress: %w", err)
+	}
+
+	progress := DirectiveProgress{
+		CharacterID: character,
+		Tree:        trees[0],
+	}
+	if len(characterTrees) > 0 {
+		progress.CurrentTierID = characterTrees[0].CurrentDirectiveTierID
+		progress.CurrentLevel = characterTrees[0].CurrentLevel
+		progress.Completed = characterTrees[0].CompletionTime
+	}
+
+	slices.SortFunc(tiers, func(a, b DirectiveTier) int { return int(a.DirectiveTierID) - int(b.DirectiveTierID) })
+	tierIndex := make(map[ps2.DirectiveTierID]int, len(tiers))
+	for i, tier := range tiers {
+		tierIndex[tier.DirectiveTierID] = i
+		progress.Tiers = append(progress.Tiers, DirectiveTierProgress{Tier: tier})
+	}
+	for _, tier := range characterTiers {
+		if i, found := tierIndex[tier.DirectiveTierID]; found {
+			progress.Tiers[i].Completed = tier.CompletionTime
+		}
+	}
+
+	completed := make(map[ps2.DirectiveID]bool, len(characterDirectives))
+	for _, d := range characterDirectives {
+		if !d.CompletionTime.Time().IsZero() {
+			completed[d.DirectiveID] = true
+		}
+	}
+	slices.SortFunc(directives, func(a, b Directive) int { return int(a.DirectiveID) - int(b.DirectiveID) })
+	for _, d := range directives {
+		i, found := tierIndex[d.DirectiveTierID]
+		if !found {
+			continue
+		}
+		tier := &progress.Tiers[i]
+		tier.Directives++
+		if completed[d.DirectiveID] {
+			tier.DirectivesCompleted++
+			tier.CompletedDirectives = append(tier.CompletedDirectives, d.DirectiveID)
+		}
+	}
+	return progress, nil
+}
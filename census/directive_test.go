@@ -0,0 +1,119 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestGetDirectiveProgress(t *testing.T) {
+	responses := map[string]string{
+		"directive_tree": `{"directive_tree_list":[{"directive_tree_id":"3","directive_tree_category_id":"2","name":{"en":"Assault Rifles"}}],"returned":1}`,
+		// tiers arrive out of order, and each needs two directives
+		"directive_tier": `{"directive_tier_list":[
+			{"directive_tree_id":"3","directive_tier_id":"2","name":{"en":"Expert"},"completion_count":"2"},
+			{"directive_tree_id":"3","directive_tier_id":"1","name":{"en":"Novice"},"completion_count":"2"}
+		],"returned":2}`,
+		"directive": `{"directive_list":[
+			{"directive_id":"12","directive_tree_id":"3","directive_tier_id":"1"},
+			{"directive_id":"11","directive_tree_id":"3","directive_tier_id":"1"},
+			{"directive_id":"13","directive_tree_id":"3","directive_tier_id":"1"},
+			{"directive_id":"21","directive_tree_id":"3","directive_tier_id":"2"},
+			{"directive_id":"22","directive_tree_id":"3","directive_tier_id":"2"}
+		],"returned":5}`,
+		"character_directive_tree": `{"character_directive_tree_list":[{"character_id":"5428010618015189713","directive_tree_id":"3","current_directive_tier_id":"2","current_level":"1","completion_time":"0"}],"returned":1}`,
+		"character_directive_tier": `{"character_directive_tier_list":[{"character_id":"5428010618015189713","directive_tree_id":"3","directive_tier_id":"1","completion_time":"1700000000"}],"returned":1}`,
+		// directive 21 was started but isn't complete
+		"character_directive": `{"character_directive_list":[
+			{"character_id":"5428010618015189713","directive_tree_id":"3","directive_id":"13","completion_time":"1690000000"},
+			{"character_id":"5428010618015189713","directive_tree_id":"3","directive_id":"11","completion_time":"1680000000"},
+			{"character_id":"5428010618015189713","directive_tree_id":"3","directive_id":"21","completion_time":"0"}
+		],"returned":3}`,
+	}
+	queries := make(map[string]string)
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		collection := path.Base(req.URL.Path)
+		queries[collection] = req.URL.RawQuery
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(responses[collection])),
+			Request:    req,
+		}, nil
+	})})
+	limiter := census.NewLimiter(100, 100, 10)
+	t.Cleanup(limiter.Stop)
+	client.SetLimiter(limiter)
+
+	progress, err := census.GetDirectiveProgress(context.Background(), client, ps2.PC, 5428010618015189713, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for collection, want := range map[string]string{
+		"directive_tree":           "directive_tree_id=3&c:limit=5000",
+		"directive_tier":           "directive_tree_id=3&c:sort=directive_tier_id&c:limit=5000",
+		"directive":                "directive_tree_id=3&c:limit=5000",
+		"character_directive_tree": "character_id=5428010618015189713&directive_tree_id=3&c:limit=5000",
+		"character_directive_tier": "character_id=5428010618015189713&directive_tree_id=3&c:limit=5000",
+		"character_directive":      "character_id=5428010618015189713&directive_tree_id=3&c:limit=5000",
+	} {
+		if queries[collection] != want {
+			t.Errorf("got %s query %q; want %q", collection, queries[collection], want)
+		}
+	}
+
+	if progress.Tree.DirectiveTreeID != 3 || progress.CurrentTierID != 2 || progress.CurrentLevel != 1 || !progress.Completed.Time().IsZero() {
+		t.Errorf("got %+v", progress)
+	}
+	if len(progress.Tiers) != 2 {
+		t.Fatalf("got %d tiers; want 2", len(progress.Tiers))
+	}
+	novice, expert := progress.Tiers[0], progress.Tiers[1]
+	if novice.Tier.DirectiveTierID != 1 || novice.Directives != 3 || novice.DirectivesCompleted != 2 || !novice.Done() {
+		t.Errorf("got novice tier %+v; want 2 of 3 directives done", novice)
+	}
+	if !slices.Equal(novice.CompletedDirectives, []ps2.DirectiveID{11, 13}) || novice.Completed.Time().Unix() != 1700000000 {
+		t.Errorf("got completed directives %v at %v", novice.CompletedDirectives, novice.Completed.Time())
+	}
+	if expert.Directives != 2 || expert.DirectivesCompleted != 0 || expert.Done() {
+		t.Errorf("got expert tier %+v; want no directives done", expert)
+	}
+}
+
+func TestGetDirectiveProgressNotStarted(t *testing.T) {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if !strings.Contains(req.URL.Path, "/ps2ps4us:v2/") {
+			t.Errorf("got request %s; want every collection from the PS4 US namespace", req.URL.Path)
+		}
+		collection := path.Base(req.URL.Path)
+		body := `{"` + collection + `_list":[],"returned":0}`
+		switch collection {
+		case "directive_tree":
+			body = `{"directive_tree_list":[{"directive_tree_id":"3"}],"returned":1}`
+		case "directive_tier":
+			body = `{"directive_tier_list":[{"directive_tree_id":"3","directive_tier_id":"1","completion_count":"1"}],"returned":1}`
+		case "directive":
+			body = `{"directive_list":[{"directive_id":"11","directive_tree_id":"3","directive_tier_id":"1"}],"returned":1}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})})
+	limiter := census.NewLimiter(100, 100, 10)
+	t.Cleanup(limiter.Stop)
+	client.SetLimiter(limiter)
+
+	progress, err := census.GetDirectiveProgress(context.Background(), client, ps2.PS4US, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.CurrentTierID != 0 || len(progress.Tiers) != 1 || progress.Tiers[0].Directives != 1 || progress.Tiers[0].Done() {
+		t.Errorf("got %+v; want one tier with nothing done", progress)
+	}
+}
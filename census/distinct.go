@@ -0,0 +1,70 @@
+package census
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// QueryDistinct returns the distinct values of field in a collection using c:distinct.
+//
+// Census responds to c:distinct with a single row containing the list of values,
+// like {"metagame_event_list":[{"type":["1","2","4"]}],"returned":1},
+// and nearly every value is a string.
+// Each value is decoded into T,
+// so numeric values can be decoded directly into ID types:
+//
+//	types, err := census.QueryDistinct[ps2.MetagameEventType](ctx, nil, ps2.PC, "metagame_event", "type")
+//
+// Census limits the number of distinct values returned,
+// so fields with very many values (like character_id) will be incomplete.
+func QueryDistinct[T any](ctx context.Context, client *Client, env ps2.Environment, collection, field string) ([]T, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	query := fmt.Sprintf("%s?c:distinct=%s", collection, url.QueryEscape(field))
+
+	var response map[string]json.RawMessage
+	if err := client.Get(ctx, env, query, &response); err != nil {
+		return nil, fmt.Errorf("census.QueryDistinct: %w", err)
+	}
+	key, err := collectionListKey(response)
+	if err != nil {
+		return nil, fmt.Errorf("census.QueryDistinct: %w", err)
+	}
+	var rows []map[string][]json.RawMessage
+	if err := json.Unmarshal(response[key], &rows); err != nil {
+		return nil, fmt.Errorf("census.QueryDistinct: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	raw := rows[0][field]
+	values := make([]T, 0, len(raw))
+	for _, r := range raw {
+		v, err := decodeDistinct[T](r)
+		if err != nil {
+			return nil, fmt.Errorf("census.QueryDistinct: %s: %w", field, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// decodeDistinct decodes a single distinct value,
+// unquoting strings when T isn't a string type.
+func decodeDistinct[T any](raw json.RawMessage) (v T, err error) {
+	if err = json.Unmarshal(raw, &v); err == nil {
+		return v, nil
+	}
+	if unquoted := bytes.Trim(raw, `"`); len(unquoted) != len(raw) {
+		if json.Unmarshal(unquoted, &v) == nil {
+			return v, nil
+		}
+	}
+	return v, err
+}
@@ -0,0 +1,149 @@
+package census
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Row is a single untyped census result,
+// for exploring collections that don't have a typed struct yet.
+type Row map[string]any
+
+// GetInto performs query with client in the client's default environment and decodes the response into result.
+//
+// result may be a pointer to a typed struct, the same as for [Client.Get].
+// For exploring unknown collections it may instead be a *[]Row or *[]map[string]any,
+// which receive the rows of the collection list (e.g. "character_list"),
+// or a *map[string]any, which receives the whole response.
+//
+//	var rows []census.Row
+//	err := census.GetInto(ctx, nil, "outfit?alias_lower=bax&c:join=outfit_member^list:1^inject_at:members", &rows)
+//	for _, r := range census.FlattenJoin(rows, "members") {
+//		fmt.Println(r.String("name"), r.String("members.character_id"))
+//	}
+func GetInto(ctx context.Context, client *Client, query string, result any) error {
+	if client == nil {
+		client = DefaultClient
+	}
+	switch result.(type) {
+	case *[]Row, *[]map[string]any:
+	default:
+		if err := client.Get(ctx, client.env, query, result); err != nil {
+			return fmt.Errorf("census.GetInto: %w", err)
+		}
+		return nil
+	}
+
+	var response map[string]json.RawMessage
+	if err := client.Get(ctx, client.env, query, &response); err != nil {
+		return fmt.Errorf("census.GetInto: %w", err)
+	}
+	key, err := collectionListKey(response)
+	if err != nil {
+		return fmt.Errorf("census.GetInto: %w", err)
+	}
+	if err := decodeJSON(response[key], result); err != nil {
+		return fmt.Errorf("census.GetInto: %w", err)
+	}
+	return nil
+}
+
+// Get returns the value at path,
+// where path is a list of keys separated by dots like "name.en" or "members.0.character_id".
+// Numeric keys index into lists.
+// Get returns nil if there is no value at path.
+func (r Row) Get(path string) any {
+	var v any = map[string]any(r)
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case Row:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// String returns the value at path formatted as a string,
+// or "" if there is no value.
+// Census returns nearly every value as a string already.
+func (r Row) String(path string) string {
+	switch v := r.Get(path).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Flatten returns a copy of r with nested objects and lists replaced by their leaf values,
+// keyed by dotted path as accepted by [Row.Get],
+// e.g. {"name": {"en": "Indar"}} becomes {"name.en": "Indar"}.
+func (r Row) Flatten() Row {
+	flat := make(Row, len(r))
+	flattenInto(flat, "", map[string]any(r))
+	return flat
+}
+
+func flattenInto(flat Row, prefix string, v any) {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			flattenInto(flat, prefix+k+".", child)
+		}
+	case Row:
+		flattenInto(flat, prefix, map[string]any(node))
+	case []any:
+		for i, child := range node {
+			flattenInto(flat, prefix+strconv.Itoa(i)+".", child)
+		}
+	default:
+		flat[strings.TrimSuffix(prefix, ".")] = v
+	}
+}
+
+// FlattenJoin expands the join injected into each row at field,
+// returning one flattened row for every item in a list join (c:join=...^list:1^inject_at:field),
+// or one flattened row for a single join.
+// Joined values are keyed as "field.key".
+// Rows without a match for the join are kept once, like a SQL left join.
+func FlattenJoin(rows []Row, field string) []Row {
+	var out []Row
+	for _, r := range rows {
+		joined, found := r[field]
+		base := make(Row, len(r))
+		for k, v := range r {
+			if k != field {
+				base[k] = v
+			}
+		}
+		items, isList := joined.([]any)
+		if !found || (isList && len(items) == 0) {
+			out = append(out, base.Flatten())
+			continue
+		}
+		if !isList {
+			items = []any{joined}
+		}
+		for _, item := range items {
+			row := base.Flatten()
+			flattenInto(row, field+".", item)
+			out = append(out, row)
+		}
+	}
+	return out
+}
@@ -165,3 +165,37 @@ func wrapRetryableErrors(err error) error {
 	}
 	return err
 }
+
+// IsNotFound reports whether err was caused by a lookup that returned no results,
+// such as a character name that doesn't exist,
+// or by a request for a collection census doesn't have.
+func IsNotFound(err error) bool {
+	var noResults noResultsError
+	return errors.As(err, &noResults) || errors.Is(err, errNotFound)
+}
+
+// IsMaintenance reports whether err was caused by census being down for maintenance.
+// Census usually stays down for some time,
+// so callers should back off for longer than they would for other errors.
+func IsMaintenance(err error) bool {
+	return errors.Is(err, errServerMaintenance)
+}
+
+// IsRateLimited reports whether err was caused by census rate limiting requests made without a registered service ID.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, errRateLimitExceeded)
+}
+
+// IsUnavailable reports whether err was caused by census being unable to serve requests at the moment,
+// either because census reported its data source as unavailable
+// or because the circuit breaker stopped the request after too many failures.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, errServiceUnavailable) || errors.Is(err, errShortCircuit)
+}
+
+// IsBadRequest reports whether err was caused by a request census will never accept,
+// such as a query with invalid syntax or an invalid service ID.
+// Retrying the same request won't succeed.
+func IsBadRequest(err error) bool {
+	return errors.Is(err, errBadRequestSyntax) || errors.Is(err, ErrBadServiceID) || errors.Is(err, errInvalidServiceID)
+}
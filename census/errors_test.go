@@ -0,0 +1,45 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		notFound bool
+		bad      bool
+	}{
+		"no results":         {body: `{"character_name_list":[],"returned":0}`, notFound: true},
+		"missing collection": {body: `{"error":"No data found."}`, notFound: true},
+		"bad syntax":         {body: `{"error":"Bad request syntax."}`, bad: true},
+	}
+	for name, tt := range tests {
+		client := &census.Client{ServiceID: "example"}
+		client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+				Request:    req,
+			}, nil
+		})})
+		_, err := census.GetCharacterIDByName(context.Background(), client, ps2.PC, "wrel")
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if census.IsNotFound(err) != tt.notFound || census.IsBadRequest(err) != tt.bad {
+			t.Errorf("%s: got IsNotFound %t, IsBadRequest %t for %v", name, census.IsNotFound(err), census.IsBadRequest(err), err)
+		}
+		if census.IsMaintenance(err) || census.IsRateLimited(err) || census.IsUnavailable(err) {
+			t.Errorf("%s: unexpected classification of %v", name, err)
+		}
+	}
+}
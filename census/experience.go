@@ -11,6 +11,13 @@ type Experience struct {
 
 func (Experience) CollectionName() string { return "experience" }
 
+func (e Experience) Validate() error {
+	if e.ExperienceID == 0 {
+		return zeroID("experience_id")
+	}
+	return nil
+}
+
 type ExperienceAwardType struct {
 	ExperienceAwardTypeID ps2.ExperienceAwardTypeID `json:"experience_award_type_id,string"`
 	Name                  string                    `json:"name"`
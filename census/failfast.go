@@ -0,0 +1,75 @@
+package census
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetFailFast limits each request made by c to budget in total, including its retries,
+// for interactive tools where a quick error is better than waiting out a slow or failing census.
+//
+// A fail fast client:
+//   - stops retrying once the budget is spent, and never waits for a RetryAfter time past it
+//   - ignores the package circuit breaker and doesn't count toward tripping it,
+//     so one bad response doesn't block the next command for minutes
+//   - returns a [*FailFastError] describing the attempts made
+//
+// A budget of 0 or less restores the default behavior, which is tuned for long running services.
+func (c *Client) SetFailFast(budget time.Duration) {
+	c.failFast = max(budget, 0)
+}
+
+// FailFastError is returned by calls from a client configured with [Client.SetFailFast].
+type FailFastError struct {
+	Query    string
+	Attempts int
+	Elapsed  time.Duration
+	Budget   time.Duration
+
+	// Category is the kind of failure of the last attempt.
+	Category ErrorCategory
+
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *FailFastError) Error() string {
+	return fmt.Sprintf("census: %s after %d attempts in %v (budget %v): %v",
+		e.Category, e.Attempts, e.Elapsed.Round(time.Millisecond), e.Budget, e.Err)
+}
+
+func (e *FailFastError) Unwrap() error { return e.Err }
+
+// Timeout reports whether the call ran out of budget.
+func (e *FailFastError) Timeout() bool { return e.Category == CategoryTimeout }
+
+// withBudget limits ctx to the fail fast budget of c.
+// done converts the final error of a call into a *FailFastError.
+func (c Client) withBudget(ctx context.Context, query string) (_ context.Context, done func(err error, attempts int) error) {
+	if c.failFast <= 0 {
+		return ctx, func(err error, _ int) error { return err }
+	}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, c.failFast)
+	return ctx, func(err error, attempts int) error {
+		cancel()
+		if err == nil {
+			return nil
+		}
+		return &FailFastError{
+			Query:    RedactURL(query),
+			Attempts: attempts,
+			Elapsed:  time.Since(start),
+			Budget:   c.failFast,
+			Category: categorize(err),
+			Err:      err,
+		}
+	}
+}
+
+// waitFits reports whether waiting until t fits within the deadline of ctx.
+func waitFits(ctx context.Context, t time.Time) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || !t.After(deadline)
+}
@@ -0,0 +1,120 @@
+package census
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Filter limits the rows returned from a collection,
+// using census search modifiers on collection fields.
+// Filters are built with functions like [Eq] and [StartsWith]:
+//
+//	var hexes []census.MapHex
+//	err := census.LoadCollection(ctx, client, &hexes, census.Eq("zone_id", ps2.Indar))
+//
+// Multiple filters must all match.
+// The zero Filter matches every row.
+type Filter struct {
+	conditions []condition
+}
+
+type condition struct {
+	field    string
+	modifier string
+	value    string
+}
+
+// FilterValue is a value that can be compared to a collection field.
+type FilterValue interface {
+	~string | ~bool | ~float32 | ~float64 |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+func newFilter[V FilterValue](field, modifier string, v V) Filter {
+	return Filter{conditions: []condition{{field: field, modifier: modifier, value: formatFilterValue(v)}}}
+}
+
+// formatFilterValue formats v by its underlying type,
+// ignoring String methods like the one on ps2.WorldID that returns a name instead of a number.
+func formatFilterValue[V FilterValue](v V) string {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		if rv.Bool() {
+			return "1"
+		}
+		return "0"
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	default:
+		return strconv.FormatUint(rv.Uint(), 10)
+	}
+}
+
+// Eq matches rows where field equals v.
+func Eq[V FilterValue](field string, v V) Filter { return newFilter(field, "", v) }
+
+// NotEq matches rows where field does not equal v.
+func NotEq[V FilterValue](field string, v V) Filter { return newFilter(field, "!", v) }
+
+// Less matches rows where field is less than v.
+func Less[V FilterValue](field string, v V) Filter { return newFilter(field, "<", v) }
+
+// LessEq matches rows where field is less than or equal to v.
+func LessEq[V FilterValue](field string, v V) Filter { return newFilter(field, "[", v) }
+
+// Greater matches rows where field is greater than v.
+func Greater[V FilterValue](field string, v V) Filter { return newFilter(field, ">", v) }
+
+// GreaterEq matches rows where field is greater than or equal to v.
+func GreaterEq[V FilterValue](field string, v V) Filter { return newFilter(field, "]", v) }
+
+// Between matches rows where field is from low to high, inclusive.
+func Between[V FilterValue](field string, low, high V) Filter {
+	return GreaterEq(field, low).And(LessEq(field, high))
+}
+
+// StartsWith matches rows where field starts with prefix.
+// Census compares case sensitively unless the field is a lowercase copy, like name.first_lower.
+func StartsWith(field, prefix string) Filter { return newFilter(field, "^", prefix) }
+
+// Contains matches rows where field contains s.
+// Census is slow to search with Contains on large collections.
+func Contains(field, s string) Filter { return newFilter(field, "*", s) }
+
+// And returns a filter matching rows that match both f and other.
+func (f Filter) And(other Filter) Filter {
+	conditions := make([]condition, 0, len(f.conditions)+len(other.conditions))
+	conditions = append(conditions, f.conditions...)
+	conditions = append(conditions, other.conditions...)
+	return Filter{conditions: conditions}
+}
+
+// String returns the filter as a query string, like "zone_id=2&facility_type_id=]5".
+func (f Filter) String() string {
+	parts := make([]string, len(f.conditions))
+	for i, c := range f.conditions {
+		parts[i] = url.QueryEscape(c.field) + "=" + url.QueryEscape(c.modifier+c.value)
+	}
+	return strings.Join(parts, "&")
+}
+
+// filterQuery joins filters into a query string prefix ending in "&",
+// or returns an empty string when there are no conditions.
+func filterQuery(filters []Filter) string {
+	var all Filter
+	for _, f := range filters {
+		all = all.And(f)
+	}
+	if len(all.conditions) == 0 {
+		return ""
+	}
+	return all.String() + "&"
+}
@@ -0,0 +1,56 @@
+package census_test
+
+import (
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestFilterString(t *testing.T) {
+	tt := map[string]struct {
+		Filter census.Filter
+		Want   string
+	}{
+		"zero":          {census.Filter{}, ""},
+		"eq":            {census.Eq("zone_id", 2), "zone_id=2"},
+		"not eq":        {census.NotEq("zone_id", 2), "zone_id=%212"},
+		"less":          {census.Less("battle_rank", 10), "battle_rank=%3C10"},
+		"less eq":       {census.LessEq("battle_rank", 10), "battle_rank=%5B10"},
+		"greater":       {census.Greater("battle_rank", 10), "battle_rank=%3E10"},
+		"greater eq":    {census.GreaterEq("battle_rank", 10), "battle_rank=%5D10"},
+		"starts with":   {census.StartsWith("name.first_lower", "wrel"), "name.first_lower=%5Ewrel"},
+		"contains":      {census.Contains("name.first_lower", "rel"), "name.first_lower=%2Arel"},
+		"between":       {census.Between("timestamp", 100, 200), "timestamp=%5D100&timestamp=%5B200"},
+		"and":           {census.Eq("zone_id", 2).And(census.Eq("world_id", 17)), "zone_id=2&world_id=17"},
+		"and zero":      {census.Filter{}.And(census.Eq("zone_id", 2)), "zone_id=2"},
+		"escaped value": {census.Eq("name.first_lower", "a b&c=d"), "name.first_lower=a+b%26c%3Dd"},
+		"escaped field": {census.Eq("odd field", 1), "odd+field=1"},
+		// the modifier is escaped with the value, so a value can't start a second condition
+		"escaped modifier": {census.StartsWith("name.first_lower", "&world_id=17"), "name.first_lower=%5E%26world_id%3D17"},
+		// ps2.WorldID has a String method returning its name
+		"named type":   {census.Eq("world_id", ps2.Emerald), "world_id=17"},
+		"bool":         {census.Eq("is_vehicle_weapon", true).And(census.Eq("is_default", false)), "is_vehicle_weapon=1&is_default=0"},
+		"float":        {census.Greater("x", 1.5), "x=%3E1.5"},
+		"negative":     {census.Less("y", -3), "y=%3C-3"},
+		"unsigned":     {census.Eq("character_id", uint64(5428010618015189713)), "character_id=5428010618015189713"},
+		"large signed": {census.Eq("character_id", ps2.CharacterID(5428010618015189713)), "character_id=5428010618015189713"},
+	}
+	for name, tc := range tt {
+		if got := tc.Filter.String(); got != tc.Want {
+			t.Errorf("%s: got %q; want %q", name, got, tc.Want)
+		}
+	}
+}
+
+func TestFilterAndDoesNotShare(t *testing.T) {
+	base := census.Eq("zone_id", 2)
+	a := base.And(census.Eq("world_id", 17))
+	b := base.And(census.Eq("world_id", 1))
+	if a.String() != "zone_id=2&world_id=17" || b.String() != "zone_id=2&world_id=1" {
+		t.Errorf("got %q and %q; want filters built from the same base to stay separate", a, b)
+	}
+	if base.String() != "zone_id=2" {
+		t.Errorf("got base %q; want it unchanged", base)
+	}
+}
@@ -0,0 +1,171 @@
+package census
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// healthWindow is how far back [Health] counts requests and errors.
+const healthWindow = 15 * time.Minute
+
+// ErrorCategory groups census errors by cause.
+type ErrorCategory string
+
+const (
+	CategoryMaintenance        ErrorCategory = "maintenance"
+	CategoryRateLimit          ErrorCategory = "rate_limit"
+	CategoryTimeout            ErrorCategory = "timeout"
+	CategoryNotFound           ErrorCategory = "not_found"
+	CategoryServiceUnavailable ErrorCategory = "service_unavailable"
+	CategoryBadRequest         ErrorCategory = "bad_request"
+	CategoryOther              ErrorCategory = "other"
+)
+
+// HealthStatus summarizes the state of census requests across every client in the process.
+// It's meant to be surfaced on a service's own health endpoint to explain census related degradation.
+type HealthStatus struct {
+	// CircuitOpen is true while the circuit breaker fails requests without sending them.
+	CircuitOpen      bool      `json:"circuit_open"`
+	CircuitOpenUntil time.Time `json:"circuit_open_until,omitempty"`
+	CircuitError     string    `json:"circuit_error,omitempty"`
+
+	// ConsecutiveErrors is the number of failed requests since the last success.
+	ConsecutiveErrors int `json:"consecutive_errors"`
+
+	// RateLimitTokens is the number of requests that can be sent right away,
+	// or -1 when a custom RateLimiter is in use.
+	RateLimitTokens int `json:"rate_limit_tokens"`
+
+	// InFlight is the number of requests currently being sent.
+	InFlight int `json:"in_flight"`
+
+	// Window is the period that Requests and Errors are counted over.
+	Window   time.Duration         `json:"window"`
+	Requests int                   `json:"requests"`
+	Errors   map[ErrorCategory]int `json:"errors"`
+
+	// RetryBudget is the number of retries that may be sent right now.
+	// RetriesSent and RetriesDenied count retries since the process started;
+	// denied retries returned their error instead of retrying because the budget was spent.
+	// See [RetryBudget].
+	RetryBudget   int `json:"retry_budget"`
+	RetriesSent   int `json:"retries_sent"`
+	RetriesDenied int `json:"retries_denied"`
+
+	LastSuccess   time.Time `json:"last_success,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorTime time.Time `json:"last_error_time,omitempty"`
+}
+
+// OK reports whether requests are currently being sent to census.
+func (h HealthStatus) OK() bool {
+	return !h.CircuitOpen
+}
+
+// Health returns the current census health.
+func Health() HealthStatus {
+	h := HealthStatus{
+		RateLimitTokens: -1,
+		InFlight:        concurrentLimiter.inUse(),
+		Window:          healthWindow,
+	}
+	if limit, ok := RateLimiter.(rateLimit); ok {
+		h.RateLimitTokens = len(limit)
+	}
+
+	breaker.mu.Lock()
+	h.ConsecutiveErrors = breaker.errorCount
+	if breaker.err != nil && time.Now().Before(breaker.resetAfter) {
+		h.CircuitOpen = true
+		h.CircuitOpenUntil = breaker.resetAfter
+		h.CircuitError = breaker.err.Error()
+	}
+	breaker.mu.Unlock()
+
+	retryBudget.mu.Lock()
+	retryBudget.prune(time.Now())
+	h.RetryBudget = max(retryBudget.available(), 0)
+	h.RetriesSent = retryBudget.retriesSent
+	h.RetriesDenied = retryBudget.retriesDenied
+	retryBudget.mu.Unlock()
+
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	health.prune(time.Now())
+	h.Requests = len(health.requests)
+	h.Errors = make(map[ErrorCategory]int)
+	for _, r := range health.requests {
+		if r.category != "" {
+			h.Errors[r.category]++
+		}
+	}
+	h.LastSuccess = health.lastSuccess
+	h.LastError = health.lastError
+	h.LastErrorTime = health.lastErrorTime
+	return h
+}
+
+var health = &healthTracker{}
+
+type healthTracker struct {
+	mu            sync.Mutex
+	requests      []trackedRequest // requests within healthWindow, oldest first
+	lastSuccess   time.Time
+	lastError     string
+	lastErrorTime time.Time
+}
+
+type trackedRequest struct {
+	at       time.Time
+	category ErrorCategory // category is empty for successful requests
+}
+
+// track records the result of a request that was sent to census.
+func (t *healthTracker) track(err error) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(now)
+	r := trackedRequest{at: now}
+	if err == nil {
+		t.lastSuccess = now
+	} else {
+		r.category = categorize(err)
+		t.lastError = RedactURL(err.Error())
+		t.lastErrorTime = now
+	}
+	t.requests = append(t.requests, r)
+}
+
+func (t *healthTracker) prune(now time.Time) {
+	cutoff := now.Add(-healthWindow)
+	i := 0
+	for i < len(t.requests) && t.requests[i].at.Before(cutoff) {
+		i++
+	}
+	t.requests = t.requests[i:]
+}
+
+// categorize returns the category of a census request error.
+func categorize(err error) ErrorCategory {
+	var netErr net.Error
+	switch {
+	case IsMaintenance(err):
+		return CategoryMaintenance
+	case IsRateLimited(err):
+		return CategoryRateLimit
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case IsNotFound(err):
+		return CategoryNotFound
+	case IsUnavailable(err):
+		return CategoryServiceUnavailable
+	case IsBadRequest(err):
+		return CategoryBadRequest
+	default:
+		return CategoryOther
+	}
+}
@@ -0,0 +1,104 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestHealthCategories(t *testing.T) {
+	body := func(s string) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(s)), Request: req}, nil
+		}
+	}
+	tests := map[census.ErrorCategory]func(*http.Request) (*http.Response, error){
+		census.CategoryRateLimit:          body(`{"error":"Missing Service ID.  A valid Service ID is required for continued api use."}`),
+		census.CategoryNotFound:           body(`{"error":"No data found."}`),
+		census.CategoryServiceUnavailable: body(`{"error":"service_unavailable"}`),
+		census.CategoryBadRequest:         body(`{"error":"Bad request syntax."}`),
+		census.CategoryOther:              body(`{"errorCode":"SERVER_ERROR","errorMessage":"INVALID_SEARCH_TERM"}`),
+		census.CategoryTimeout: func(*http.Request) (*http.Response, error) {
+			return nil, timeoutError{}
+		},
+		census.CategoryMaintenance: func(req *http.Request) (*http.Response, error) {
+			// census redirects to the daybreak home page while it's down
+			home, _ := url.Parse("https://www.daybreakgames.com/home")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("<html>" + strings.Repeat(" ", 1024) + "</html>")),
+				Request:    &http.Request{Method: req.Method, URL: home},
+			}, nil
+		},
+	}
+	for category, respond := range tests {
+		client := &census.Client{ServiceID: "example"}
+		client.SetHTTPClient(&http.Client{Transport: roundTripFunc(respond)})
+		limiter := census.NewLimiter(100, 100, 10)
+		t.Cleanup(limiter.Stop)
+		client.SetLimiter(limiter)
+		// failing fast keeps these errors from tripping the package circuit breaker
+		client.SetFailFast(10 * time.Second)
+
+		before := census.Health()
+		var worlds struct {
+			WorldList []census.World `json:"world_list"`
+		}
+		err := client.Get(context.Background(), ps2.PC, "world?world_id=17", &worlds)
+		if err == nil {
+			t.Errorf("%s: expected an error", category)
+			continue
+		}
+		after := census.Health()
+		if got := after.Errors[category] - before.Errors[category]; got != 1 {
+			t.Errorf("%s: got %d errors counted for %v; want 1", category, got, err)
+		}
+		if after.Requests-before.Requests != 1 {
+			t.Errorf("%s: got %d requests counted; want 1", category, after.Requests-before.Requests)
+		}
+		if after.LastError == "" || after.LastErrorTime.Before(before.LastErrorTime) {
+			t.Errorf("%s: got last error %q at %v", category, after.LastError, after.LastErrorTime)
+		}
+	}
+}
+
+func TestHealthSuccess(t *testing.T) {
+	client := staticClient(`{"world_list":[{"world_id":"17","state":"online","name":{"en":"Emerald"}}],"returned":1}`)
+	client.SetFailFast(10 * time.Second)
+	before := census.Health()
+	start := time.Now()
+	var worlds struct {
+		WorldList []census.World `json:"world_list"`
+	}
+	if err := client.Get(context.Background(), ps2.PC, "world?world_id=17", &worlds); err != nil {
+		t.Fatal(err)
+	}
+	after := census.Health()
+	if after.LastSuccess.Before(start) {
+		t.Errorf("got last success %v; want after %v", after.LastSuccess, start)
+	}
+	if after.Requests-before.Requests != 1 {
+		t.Errorf("got %d requests counted; want 1", after.Requests-before.Requests)
+	}
+	for category, n := range after.Errors {
+		if n != before.Errors[category] {
+			t.Errorf("got %s errors counted for a successful request", category)
+		}
+	}
+	if after.Window != 15*time.Minute || after.OK() == after.CircuitOpen {
+		t.Errorf("got %+v", after)
+	}
+}
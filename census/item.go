@@ -21,6 +21,13 @@ type Item struct {
 
 func (Item) CollectionName() string { return "item" }
 
+func (i Item) Validate() error {
+	if i.ItemID == 0 {
+		return zeroID("item_id")
+	}
+	return nil
+}
+
 func (i Item) ImageURL() string {
 	return apiBase + i.ImagePath
 }
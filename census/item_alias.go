@@ -0,0 +1,91 @@
+package census
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// ItemAliases maps items to the names players know them by,
+// for cases where the census item name differs from what is shown in-game,
+// like variant suffixes and NS weapon naming.
+// It is safe for concurrent use.
+type ItemAliases struct {
+	mu    sync.RWMutex
+	names map[ps2.ItemID]string
+}
+
+// DefaultItemAliases is used by [Item.DisplayName] and [WeaponData.Name].
+// It starts with the curated table embedded in this package,
+// and users may add or override entries with Set or Load.
+var DefaultItemAliases = mustLoadItemAliases(embeddedItemAliases)
+
+// embeddedItemAliases is a json object of item ID to display name, e.g. {"1234": "Display Name"}.
+// Entries are added as mismatched names are reported.
+//
+//go:embed item_aliases.json
+var embeddedItemAliases []byte
+
+func mustLoadItemAliases(b []byte) *ItemAliases {
+	a := &ItemAliases{}
+	if err := a.Load(bytes.NewReader(b)); err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Set sets the display name for an item.
+// An empty name removes the alias.
+func (a *ItemAliases) Set(id ps2.ItemID, name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.names == nil {
+		a.names = make(map[ps2.ItemID]string)
+	}
+	if name == "" {
+		delete(a.names, id)
+		return
+	}
+	a.names[id] = name
+}
+
+// Name returns the alias for an item, if there is one.
+func (a *ItemAliases) Name(id ps2.ItemID) (name string, ok bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	name, ok = a.names[id]
+	return name, ok
+}
+
+// Load reads a json object of item ID to display name and adds every entry,
+// replacing existing aliases for the same items.
+func (a *ItemAliases) Load(r io.Reader) error {
+	var table map[string]string
+	if err := json.NewDecoder(r).Decode(&table); err != nil {
+		return fmt.Errorf("census.ItemAliases.Load: %w", err)
+	}
+	for k, name := range table {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			return fmt.Errorf("census.ItemAliases.Load: invalid item ID %q", k)
+		}
+		a.Set(ps2.ItemID(id), name)
+	}
+	return nil
+}
+
+// DisplayName returns the name players know the item by.
+// This is the alias from [DefaultItemAliases] when one exists,
+// otherwise the census name.
+func (i Item) DisplayName() string {
+	if name, ok := DefaultItemAliases.Name(i.ItemID); ok {
+		return name
+	}
+	return i.Name.String()
+}
@@ -0,0 +1,40 @@
+package census_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestItemAliases(t *testing.T) {
+	const id ps2.ItemID = 7214
+	item := census.Item{ItemID: id}
+	item.Name.Set("NS-11A Carbine")
+	weapons := census.WeaponData{Items: map[ps2.ItemID]census.Item{id: item}}
+
+	if got := weapons.Name(id); got != "NS-11A Carbine" {
+		t.Errorf("got %q without an alias; want the census name", got)
+	}
+	if err := census.DefaultItemAliases.Load(strings.NewReader(`{"7214":"NS-11A"}`)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { census.DefaultItemAliases.Set(id, "") })
+	if got := weapons.Name(id); got != "NS-11A" {
+		t.Errorf("got %q; want the alias", got)
+	}
+	if got := weapons.Name(id + 1); got != "" {
+		t.Errorf("got %q for an unknown weapon; want an empty name", got)
+	}
+
+	census.DefaultItemAliases.Set(id, "")
+	if got := item.DisplayName(); got != "NS-11A Carbine" {
+		t.Errorf("got %q after removing the alias; want the census name", got)
+	}
+
+	var aliases census.ItemAliases
+	if err := aliases.Load(strings.NewReader(`{"not a number":"x"}`)); err == nil {
+		t.Errorf("expected an error for an invalid item ID")
+	}
+}
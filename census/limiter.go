@@ -0,0 +1,81 @@
+package census
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Limiter limits the rate and concurrency of requests sent by the clients that use it.
+// Clients share the package limits set with [RateLimit] by default,
+// so a client that sends many requests delays every other client.
+// Giving a client its own Limiter with [Client.SetLimiter] keeps it from starving the others,
+// such as separate clients for PC and PS4 trackers, each with its own service ID:
+//
+//	pc := &census.Client{ServiceID: "pc-service-id"}
+//	ps4 := &census.Client{ServiceID: "ps4-service-id"}
+//	ps4.SetLimiter(census.NewLimiter(2, 1, 2))
+//
+// Clients using separate Limiters still share the package circuit breaker and retry budget,
+// since those track the health of census itself.
+type Limiter struct {
+	rate     rateLimiter
+	inFlight *slots
+	waiting  atomic.Int64
+	stop     func()
+}
+
+// NewLimiter returns a Limiter that allows burst requests to be sent without throttling,
+// nPerSec requests per second after that,
+// and up to concurrent requests in flight at once.
+// A concurrent of 0 or less uses 2, the same as the package limit.
+//
+// Call [Limiter.Stop] once no client uses the Limiter.
+func NewLimiter(burst, nPerSec, concurrent int) *Limiter {
+	if concurrent < 1 {
+		concurrent = 2
+	}
+	rate, stop := newRateLimit(burst, nPerSec)
+	return &Limiter{
+		rate:     rate,
+		inFlight: newSlots(concurrent),
+		stop:     stop,
+	}
+}
+
+// Stop releases the ticker that refills the Limiter.
+// Requests waiting on a stopped Limiter wait until their context ends.
+func (l *Limiter) Stop() {
+	l.stop()
+}
+
+// SetLimiter sets l to limit the requests of c instead of the package limits.
+// A nil l restores the package limits.
+func (c *Client) SetLimiter(l *Limiter) {
+	c.limiter = l
+}
+
+// limits returns the rate limiter, in-flight slots, and waiting count that apply to c.
+func (c Client) limits() (rateLimiter, *slots, *atomic.Int64) {
+	if c.limiter != nil {
+		return c.limiter.rate, c.limiter.inFlight, &c.limiter.waiting
+	}
+	return RateLimiter, concurrentLimiter, &limiterWaiting
+}
+
+// newRateLimit returns a rate limiter that fills with burst tokens and refills nPerSec tokens per second,
+// along with the function that stops refilling it.
+func newRateLimit(burst, nPerSec int) (rateLimit, func()) {
+	burst = max(burst, 1)
+	nPerSec = max(nPerSec, 1)
+	limiter := make(rateLimit, burst-1) // burst-1 because we assume at the start of a burst there will already be a waiting send from ticker
+	ticker := time.NewTicker(time.Second / time.Duration(nPerSec))
+	for range burst - 1 {
+		limiter <- struct{}{}
+	}
+	go func() {
+		for range ticker.C {
+			limiter <- struct{}{}
+		}
+	}()
+	return limiter, ticker.Stop
+}
@@ -0,0 +1,65 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestClientLimiter(t *testing.T) {
+	respond := func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"world_list":[],"returned":0}`)),
+			Request:    req,
+		}
+	}
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	busy := &census.Client{ServiceID: "example"}
+	busy.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		started <- struct{}{}
+		<-release
+		return respond(req), nil
+	})})
+	limited := &census.Client{ServiceID: "example"}
+	limited.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return respond(req), nil
+	})})
+	limiter := census.NewLimiter(1, 1, 1)
+	defer limiter.Stop()
+	limited.SetLimiter(limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// fill the package limit of two requests in flight
+	done := make(chan error, 2)
+	for i := range 2 {
+		go func() {
+			var r struct{}
+			done <- busy.Get(ctx, ps2.PC, "world?world_id="+strings.Repeat("1", i+1), &r)
+		}()
+	}
+	for range 2 {
+		<-started
+	}
+
+	waitCtx, stop := context.WithTimeout(ctx, time.Second)
+	defer stop()
+	var r struct{}
+	if err := limited.Get(waitCtx, ps2.PC, "world?world_id=17", &r); err != nil {
+		t.Errorf("client with its own limiter was blocked by the package limits: %v", err)
+	}
+	close(release)
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -22,6 +22,16 @@ type MapHex struct {
 
 func (MapHex) CollectionName() string { return "map_hex" }
 
+func (h MapHex) Validate() error {
+	if h.MapRegionID == 0 {
+		return zeroID("map_region_id")
+	}
+	if h.ZoneID == 0 {
+		return zeroID("zone_id")
+	}
+	return nil
+}
+
 type MapRegion struct {
 	MapRegionID ps2.RegionID       `json:"map_region_id,string"`
 	FacilityID  ps2.FacilityID     `json:"facility_id,string"`
@@ -40,6 +50,21 @@ func (r MapRegion) FacilityType() ps2.FacilityTypeID { return r.Type }
 
 func (MapRegion) CollectionName() string { return "map_region" }
 
+// Validate checks for zero IDs and facilities without a location.
+// Regions without a facility are valid and have no location.
+func (r MapRegion) Validate() error {
+	if r.MapRegionID == 0 {
+		return zeroID("map_region_id")
+	}
+	if r.ZoneID == 0 {
+		return zeroID("zone_id")
+	}
+	if r.FacilityID != 0 && r.LocationX == 0 && r.LocationZ == 0 {
+		return fmt.Errorf("facility %d: %w", r.FacilityID, errMissingLocation)
+	}
+	return nil
+}
+
 // Facility is a capturable game facility.
 //
 // Note: The census collection this type uses is shared with MapRegion
@@ -63,6 +88,15 @@ func (f Facility) Facility() ps2.FacilityID         { return f.FacilityID }
 
 func (Facility) CollectionName() string { return "map_region" }
 
+// Validate checks for facilities without a location.
+// A zero FacilityID is valid, since the collection includes regions without a facility.
+func (f Facility) Validate() error {
+	if f.FacilityID != 0 && f.LocationX == 0 && f.LocationZ == 0 {
+		return fmt.Errorf("facility %d: %w", f.FacilityID, errMissingLocation)
+	}
+	return nil
+}
+
 type FacilityType struct {
 	FacilityTypeID ps2.FacilityTypeID `json:"facility_type_id,string"`
 	Description    string             `json:"description"`
@@ -89,6 +123,18 @@ func (fl FacilityLink) B() ps2.FacilityID { return fl.FacilityIDB }
 
 func (FacilityLink) CollectionName() string { return "facility_link" }
 
+// Validate checks for zero IDs and facilities linked to themselves.
+// Use [CheckFacilityLinks] to find links to facilities that don't exist.
+func (fl FacilityLink) Validate() error {
+	if fl.FacilityIDA == 0 || fl.FacilityIDB == 0 {
+		return fmt.Errorf("facility link %d-%d: %w", fl.FacilityIDA, fl.FacilityIDB, errZeroID)
+	}
+	if fl.FacilityIDA == fl.FacilityIDB {
+		return fmt.Errorf("facility %d is linked to itself", fl.FacilityIDA)
+	}
+	return nil
+}
+
 type Region struct {
 	RegionID         ps2.RegionID     `json:"region_id,string"`
 	ZoneID           ps2.ZoneID       `json:"zone_id,string"`
@@ -98,12 +144,57 @@ type Region struct {
 
 func (Region) CollectionName() string { return "region" }
 
+func (r Region) Validate() error {
+	if r.RegionID == 0 {
+		return zeroID("region_id")
+	}
+	return nil
+}
+
+// GetMap returns the territory ownership of zones on world.
+// The census namespace is chosen from the world's environment;
+// use [GetMapEnv] to query a different one.
+//
+// Results are cached for every client in the process (see [SetMapCacheTTL]),
+// and only zones without a fresh result are requested.
+// Concurrent calls for the same zones share one request.
+// Only clients with the same service ID, http client, and recorder share results,
+// so replayed responses are never returned to a live client or the other way around.
 func GetMap(ctx context.Context, client *Client, world ps2.WorldID, zone ...ps2.ZoneInstanceID) (zm []ZoneState, err error) {
+	return GetMapEnv(ctx, client, ps2.GetEnvironment(world), world, zone...)
+}
+
+// GetMapEnv is the same as [GetMap] but queries the namespace for env.
+// This is only needed for unusual cases,
+// like worlds that aren't known to [ps2.GetEnvironment].
+func GetMapEnv(ctx context.Context, client *Client, env ps2.Environment, world ps2.WorldID, zone ...ps2.ZoneInstanceID) (zm []ZoneState, err error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	source := client.mapSource()
+	cached, missing := mapCache.get(source, env, world, zone, time.Now())
+	if len(missing) == 0 && len(zone) > 0 {
+		return cached, nil
+	}
 	zones := make([]string, 0, 5)
-	for _, z := range zone {
+	for _, z := range missing {
 		zones = append(zones, z.StringID())
 	}
 	query := "map?world_id=" + world.StringID() + "&zone_ids=" + strings.Join(zones, ",")
+	zm, err = mapCache.do(ctx, source, Namespace(env)+"/"+query, func(ctx context.Context) ([]ZoneState, error) {
+		zm, err := getMap(ctx, client, env, world, query)
+		if err == nil {
+			mapCache.put(source, env, zm)
+		}
+		return zm, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(cached, zm...), nil
+}
+
+func getMap(ctx context.Context, client *Client, env ps2.Environment, world ps2.WorldID, query string) (zm []ZoneState, err error) {
 	var response struct {
 		MapList []struct {
 			ZoneID  ps2.ZoneInstanceID `json:"ZoneId,string"`
@@ -119,7 +210,7 @@ func GetMap(ctx context.Context, client *Client, world ps2.WorldID, zone ...ps2.
 		} `json:"map_list"`
 		Returned int `json:"returned"`
 	}
-	if err = client.Get(ctx, ps2.PC, query, &response); err != nil {
+	if err = client.Get(ctx, env, query, &response); err != nil {
 		return zm, fmt.Errorf("census.GetMap: %w", err)
 	}
 	for _, z := range response.MapList {
@@ -150,10 +241,13 @@ func (zm ZoneState) IsUnstable() bool {
 	}
 	return false
 }
+
+// IsLocked reports whether one faction owns more than one warpgate of the zone.
+// Warpgates are the regions listed in [ps2.WarpgateRegions].
 func (zm ZoneState) IsLocked() bool {
 	warpgateCount := make(map[ps2.FactionID]int)
 	for _, r := range zm.Regions {
-		if isWarpgate(r.RegionID) {
+		if ps2.IsWarpgateRegion(r.RegionID) {
 			warpgateCount[r.FactionID]++
 			if warpgateCount[r.FactionID] > 1 {
 				return true
@@ -173,7 +267,8 @@ type stringNumericBool bool
 
 func (b *stringNumericBool) UnmarshalJSON(data []byte) error {
 	data = bytes.Trim(data, "\"")
-	if bytes.Equal(data, []byte("1")) {
+	// true is accepted so that values marshaled by this package can be read back
+	if bytes.Equal(data, []byte("1")) || bytes.Equal(data, []byte("true")) {
 		*b = true
 	}
 	return nil
@@ -182,7 +277,7 @@ func (b *stringNumericBool) UnmarshalJSON(data []byte) error {
 func isMissingFacility(r ps2.RegionID) bool {
 	regions := []ps2.RegionID{
 		18328,
-		18347,
+		ps2.OshurVastExpanse,
 		18352,
 		18354,
 		18357,
@@ -192,26 +287,3 @@ func isMissingFacility(r ps2.RegionID) bool {
 	}
 	return slices.Contains(regions, r)
 }
-
-var warpgatePositions = map[ps2.RegionID]string{
-	2201:  "⬆",
-	2202:  "⬅",
-	2203:  "➡",
-	4230:  "⬅",
-	4240:  "➡",
-	4250:  "⬇",
-	6001:  "⬅",
-	6002:  "➡",
-	6003:  "⬇",
-	18029: "⬆",
-	18030: "⬇",
-	18062: "➡",
-	18303: "↗",
-	18304: "↖",
-	18305: "⬇",
-}
-
-func isWarpgate(r ps2.RegionID) bool {
-	_, found := warpgatePositions[r]
-	return found
-}
@@ -0,0 +1,146 @@
+package census
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// DefaultMapCacheTTL is how long [GetMap] results are reused.
+// Territory only changes when a facility is captured,
+// and the state manager keeps up with captures through events,
+// so a short delay isn't noticeable while bursts of identical queries are avoided.
+const DefaultMapCacheTTL = 20 * time.Second
+
+// mapCache holds recent /map results for every client in the process,
+// so that the state manager's polling and map renderers share requests.
+// Results are only shared between clients with the same [mapSource].
+var mapCache = &zoneStateCache{
+	ttl:      DefaultMapCacheTTL,
+	zones:    make(map[mapCacheKey]ZoneState),
+	inflight: make(map[mapCallKey]*mapCall),
+}
+
+// SetMapCacheTTL sets how long [GetMap] results are reused.
+// A ttl of 0 or less disables the cache.
+func SetMapCacheTTL(ttl time.Duration) {
+	mapCache.mu.Lock()
+	defer mapCache.mu.Unlock()
+	mapCache.ttl = ttl
+	if ttl <= 0 {
+		clear(mapCache.zones)
+	}
+}
+
+// mapSource identifies where a client's responses come from.
+// Clients with a different service ID, http client, or recorder never share results,
+// so that a replay client can't answer for a live one and calls are still recorded.
+type mapSource struct {
+	serviceID string
+	transport *http.Client
+	recorder  *Recorder
+}
+
+func (c Client) mapSource() mapSource {
+	return mapSource{serviceID: c.ServiceID, transport: c.http(), recorder: c.recorder}
+}
+
+type mapCacheKey struct {
+	source mapSource
+	env    ps2.Environment
+	world  ps2.WorldID
+	zone   ps2.ZoneInstanceID
+}
+
+type mapCallKey struct {
+	source mapSource
+	query  string
+}
+
+type zoneStateCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	zones    map[mapCacheKey]ZoneState
+	inflight map[mapCallKey]*mapCall
+}
+
+// mapCall is a /map request that other callers can wait on instead of sending their own.
+type mapCall struct {
+	done chan struct{}
+	zm   []ZoneState
+	err  error
+}
+
+// get returns fresh cached states for zones, along with the zones that weren't cached.
+func (c *zoneStateCache) get(source mapSource, env ps2.Environment, world ps2.WorldID, zones []ps2.ZoneInstanceID, now time.Time) (cached []ZoneState, missing []ps2.ZoneInstanceID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, z := range zones {
+		zs, ok := c.zones[mapCacheKey{source, env, world, z}]
+		if !ok || c.ttl <= 0 || now.Sub(zs.Timestamp) >= c.ttl {
+			missing = append(missing, z)
+			continue
+		}
+		zs.Regions = slices.Clone(zs.Regions)
+		cached = append(cached, zs)
+	}
+	return cached, missing
+}
+
+func (c *zoneStateCache) put(source mapSource, env ps2.Environment, zm []ZoneState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	for _, zs := range zm {
+		zs.Regions = slices.Clone(zs.Regions)
+		c.zones[mapCacheKey{source, env, zs.WorldID, zs.ZoneInstanceID}] = zs
+	}
+}
+
+// do calls fetch unless an identical query from the same source is already running,
+// in which case it waits for that result instead.
+//
+// fetch runs without the cancellation of ctx,
+// so that a caller giving up doesn't fail the call for everyone else waiting on it.
+// Every caller, including the one that started fetch, returns early when its own ctx is done.
+func (c *zoneStateCache) do(ctx context.Context, source mapSource, query string, fetch func(context.Context) ([]ZoneState, error)) ([]ZoneState, error) {
+	key := mapCallKey{source, query}
+	c.mu.Lock()
+	call, ok := c.inflight[key]
+	if !ok {
+		call = &mapCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		go func() {
+			call.zm, call.err = fetch(context.WithoutCancel(ctx))
+			c.mu.Lock()
+			delete(c.inflight, key)
+			c.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	c.mu.Unlock()
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return cloneZoneStates(call.zm), call.err
+}
+
+func cloneZoneStates(zm []ZoneState) []ZoneState {
+	if zm == nil {
+		return nil
+	}
+	cloned := make([]ZoneState, len(zm))
+	for i, zs := range zm {
+		zs.Regions = slices.Clone(zs.Regions)
+		cloned[i] = zs
+	}
+	return cloned
+}
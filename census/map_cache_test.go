@@ -0,0 +1,146 @@
+package census_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+const mapResponse = `{"map_list":[{"ZoneId":"2","Regions":{"IsList":"1","Row":[{"RowData":{"RegionId":"2201","FactionId":"1"}}]}}],"returned":1}`
+
+// mapServer answers every /map request with mapResponse,
+// first waiting for release when it isn't nil.
+type mapServer struct {
+	requests atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (s *mapServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests.Add(1)
+	if s.started != nil {
+		s.started <- struct{}{}
+	}
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(mapResponse)),
+		Request:    req,
+	}, nil
+}
+
+func mapClient(s *mapServer) *census.Client {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: s})
+	return client
+}
+
+func TestMapCacheTTL(t *testing.T) {
+	t.Cleanup(func() { census.SetMapCacheTTL(census.DefaultMapCacheTTL) })
+	ctx := context.Background()
+	s := &mapServer{}
+	client := mapClient(s)
+	for range 2 {
+		zm, err := census.GetMap(ctx, client, ps2.Osprey, ps2.ZoneInstanceID(ps2.Indar))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(zm) != 1 || len(zm[0].Regions) != 1 {
+			t.Fatalf("got %+v; want one zone with one region", zm)
+		}
+	}
+	if n := s.requests.Load(); n != 1 {
+		t.Errorf("got %d requests; want 1, with the second call answered from the cache", n)
+	}
+
+	// a client with a different transport, such as a replay, never sees the results of another
+	other := &mapServer{}
+	if _, err := census.GetMap(ctx, mapClient(other), ps2.Osprey, ps2.ZoneInstanceID(ps2.Indar)); err != nil {
+		t.Fatal(err)
+	}
+	if n := other.requests.Load(); n != 1 {
+		t.Errorf("got %d requests from another client; want 1", n)
+	}
+
+	census.SetMapCacheTTL(0)
+	if _, err := census.GetMap(ctx, client, ps2.Osprey, ps2.ZoneInstanceID(ps2.Indar)); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.requests.Load(); n != 2 {
+		t.Errorf("got %d requests with the cache disabled; want 2", n)
+	}
+}
+
+func TestMapCacheSharedCall(t *testing.T) {
+	t.Cleanup(func() { census.SetMapCacheTTL(census.DefaultMapCacheTTL) })
+	census.SetMapCacheTTL(0)
+	s := &mapServer{started: make(chan struct{}, 2), release: make(chan struct{})}
+	client := mapClient(s)
+
+	// the caller that starts the request gives up before census responds
+	leader, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := census.GetMap(leader, client, ps2.Wainwright, ps2.ZoneInstanceID(ps2.Esamir))
+		leaderDone <- err
+	}()
+	<-s.started
+
+	type result struct {
+		zm  []census.ZoneState
+		err error
+	}
+	waiterDone := make(chan result, 1)
+	go func() {
+		zm, err := census.GetMap(context.Background(), client, ps2.Wainwright, ps2.ZoneInstanceID(ps2.Esamir))
+		waiterDone <- result{zm, err}
+	}()
+	// give the waiter time to join the running call
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v from the cancelled caller; want context.Canceled", err)
+	}
+	close(s.release)
+	r := <-waiterDone
+	if r.err != nil {
+		t.Fatalf("the waiting caller got %v after the first caller was cancelled", r.err)
+	}
+	if len(r.zm) != 1 {
+		t.Errorf("got %d zones; want 1", len(r.zm))
+	}
+	if n := s.requests.Load(); n != 1 {
+		t.Errorf("got %d requests; want 1 shared by both callers", n)
+	}
+}
+
+func TestZoneStateIsLocked(t *testing.T) {
+	zone := census.ZoneState{Regions: []census.RegionControl{
+		{RegionID: ps2.IndarNorthernWarpgate, FactionID: ps2.TR},
+		{RegionID: ps2.IndarWesternWarpgate, FactionID: ps2.NC},
+		{RegionID: ps2.IndarEasternWarpgate, FactionID: ps2.VS},
+		{RegionID: 2301, FactionID: ps2.TR},
+	}}
+	if zone.IsLocked() {
+		t.Error("got locked with every warpgate owned by a different faction")
+	}
+	zone.Regions[1].FactionID = ps2.TR
+	if !zone.IsLocked() {
+		t.Error("got unlocked with two warpgates owned by TR")
+	}
+}
@@ -1,9 +1,6 @@
 package census
 
 import (
-	"encoding/json"
-	"time"
-
 	"github.com/Travis-Britz/ps2"
 )
 
@@ -13,27 +10,14 @@ type MetagameEvent struct {
 	Description     ps2.Localization      `json:"description"`
 	Type            ps2.MetagameEventType `json:"type,string"`
 	ExperienceBonus int                   `json:"experience_bonus,string"`
-	Duration        time.Duration         `json:"duration_minutes,string"`
+	Duration        ps2.Minutes           `json:"duration_minutes"`
 }
 
 func (MetagameEvent) CollectionName() string { return "metagame_event" }
 
-func (e *MetagameEvent) UnmarshalJSON(data []byte) error {
-	// shadow the type to prevent infinite recursion
-	type shadowType MetagameEvent
-	var shadowcopy shadowType
-	if err := json.Unmarshal(data, &shadowcopy); err != nil {
-		return err
+func (e MetagameEvent) Validate() error {
+	if e.MetagameEventID == 0 {
+		return zeroID("metagame_event_id")
 	}
-	shadowcopy.Duration *= time.Minute
-	*e = MetagameEvent(shadowcopy)
 	return nil
 }
-
-func (e *MetagameEvent) MarshalJSON() ([]byte, error) {
-	// shadow the types to prevent infinite recursion
-	type shadowType MetagameEvent
-	copy := *e
-	copy.Duration /= time.Minute
-	return json.Marshal(shadowType(copy))
-}
@@ -0,0 +1,227 @@
+package census
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// MetricsHook receives measurements of census requests,
+// for exporting to a monitoring system such as Prometheus or OpenTelemetry.
+// Methods are called synchronously from the goroutine making the request,
+// so they should only record the values.
+//
+// [PrometheusMetrics] is a ready-made implementation.
+type MetricsHook interface {
+	// Request is called after every request attempt,
+	// including attempts answered by the response cache or failed by the circuit breaker.
+	Request(RequestMetrics)
+
+	// CircuitState is called after every request attempt with whether the package circuit breaker is open.
+	CircuitState(open bool)
+
+	// QueueDepth is called whenever the number of requests waiting for the package concurrency and rate limits changes.
+	QueueDepth(waiting int)
+}
+
+// RequestMetrics describes one request attempt.
+type RequestMetrics struct {
+	Collection string
+	Env        ps2.Environment
+
+	// Attempt counts from 1; attempts after the first are retries.
+	Attempt int
+
+	// Wait is the time spent waiting for the concurrency and rate limits.
+	Wait time.Duration
+
+	// Duration is the time from sending the request to receiving the response headers.
+	// It's 0 for attempts that were never sent.
+	Duration time.Duration
+
+	// StatusCode is the HTTP status code, or 0 if there was no response.
+	StatusCode int
+
+	// Cached is set when the response came from the [ResponseCache],
+	// either without a request or after census answered 304 Not Modified.
+	Cached bool
+
+	Err error
+}
+
+// SetMetrics sets h to receive measurements of every request made by c.
+// A nil h stops sending measurements.
+func (c *Client) SetMetrics(h MetricsHook) {
+	c.metrics = h
+}
+
+// limiterWaiting counts requests waiting for the package limits.
+var limiterWaiting atomic.Int64
+
+// queryCollection returns the collection name of query.
+func queryCollection(query string) string {
+	collection, _, _ := strings.Cut(query, "?")
+	return strings.Trim(collection, "/")
+}
+
+// PrometheusMetrics is a [MetricsHook] that serves the measurements in the Prometheus text exposition format:
+//
+//	metrics := census.NewPrometheusMetrics()
+//	client.SetMetrics(metrics)
+//	http.Handle("/metrics", metrics)
+//
+// It doesn't depend on the Prometheus client library,
+// so it has its own handler instead of being registered with a registry.
+// It's safe for concurrent use and may be shared by several clients.
+type PrometheusMetrics struct {
+	mu       sync.Mutex
+	requests map[requestLabels]int
+	retries  map[string]int
+	latency  map[string]*latencyHistogram
+	open     bool
+	waiting  int
+}
+
+type requestLabels struct {
+	collection string
+	env        ps2.Environment
+	outcome    string
+}
+
+// latencyBuckets are the upper bounds of the request duration histogram in seconds.
+// Requests time out after between 3 and 30 seconds depending on the attempt.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type latencyHistogram struct {
+	counts []int // counts[i] is the number of observations <= latencyBuckets[i]
+	count  int
+	sum    float64
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		requests: make(map[requestLabels]int),
+		retries:  make(map[string]int),
+		latency:  make(map[string]*latencyHistogram),
+	}
+}
+
+func (m *PrometheusMetrics) Request(r RequestMetrics) {
+	outcome := "ok"
+	switch {
+	case r.Err != nil:
+		outcome = "error"
+	case r.Cached:
+		outcome = "cached"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestLabels{r.Collection, r.Env, outcome}]++
+	if r.Attempt > 1 {
+		m.retries[r.Collection]++
+	}
+	if r.Duration <= 0 {
+		return
+	}
+	h := m.latency[r.Collection]
+	if h == nil {
+		h = &latencyHistogram{counts: make([]int, len(latencyBuckets))}
+		m.latency[r.Collection] = h
+	}
+	seconds := r.Duration.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (m *PrometheusMetrics) CircuitState(open bool) {
+	m.mu.Lock()
+	m.open = open
+	m.mu.Unlock()
+}
+
+func (m *PrometheusMetrics) QueueDepth(waiting int) {
+	m.mu.Lock()
+	m.waiting = waiting
+	m.mu.Unlock()
+}
+
+// ServeHTTP writes the current measurements.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the current measurements to w in the Prometheus text exposition format.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+
+	b.WriteString("# HELP census_requests_total Census request attempts by collection, environment, and outcome.\n")
+	b.WriteString("# TYPE census_requests_total counter\n")
+	labels := make([]requestLabels, 0, len(m.requests))
+	for l := range m.requests {
+		labels = append(labels, l)
+	}
+	slices.SortFunc(labels, func(a, b requestLabels) int {
+		return strings.Compare(a.collection+"\x00"+string(a.env)+"\x00"+a.outcome, b.collection+"\x00"+string(b.env)+"\x00"+b.outcome)
+	})
+	for _, l := range labels {
+		fmt.Fprintf(&b, "census_requests_total{collection=%q,env=%q,outcome=%q} %d\n", l.collection, l.env, l.outcome, m.requests[l])
+	}
+
+	b.WriteString("# HELP census_retries_total Census request attempts after the first, by collection.\n")
+	b.WriteString("# TYPE census_retries_total counter\n")
+	for _, c := range sortedKeys(m.retries) {
+		fmt.Fprintf(&b, "census_retries_total{collection=%q} %d\n", c, m.retries[c])
+	}
+
+	b.WriteString("# HELP census_request_duration_seconds Time from sending a census request to receiving the response.\n")
+	b.WriteString("# TYPE census_request_duration_seconds histogram\n")
+	for _, c := range sortedKeys(m.latency) {
+		h := m.latency[c]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(&b, "census_request_duration_seconds_bucket{collection=%q,le=\"%g\"} %d\n", c, bound, h.counts[i])
+		}
+		fmt.Fprintf(&b, "census_request_duration_seconds_bucket{collection=%q,le=\"+Inf\"} %d\n", c, h.count)
+		fmt.Fprintf(&b, "census_request_duration_seconds_sum{collection=%q} %g\n", c, h.sum)
+		fmt.Fprintf(&b, "census_request_duration_seconds_count{collection=%q} %d\n", c, h.count)
+	}
+
+	open := 0
+	if m.open {
+		open = 1
+	}
+	b.WriteString("# HELP census_circuit_open Whether the census circuit breaker is failing requests without sending them.\n")
+	b.WriteString("# TYPE census_circuit_open gauge\n")
+	fmt.Fprintf(&b, "census_circuit_open %d\n", open)
+
+	b.WriteString("# HELP census_limiter_waiting Census requests waiting for the concurrency and rate limits.\n")
+	b.WriteString("# TYPE census_limiter_waiting gauge\n")
+	fmt.Fprintf(&b, "census_limiter_waiting %d\n", m.waiting)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,38 @@
+package census_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := census.NewPrometheusMetrics()
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: &characterServer{}})
+	client.SetMetrics(metrics)
+
+	if _, err := census.GetCharactersByID(context.Background(), client, ps2.PC, 5428010618015189713); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`census_requests_total{collection="character",env="ps2",outcome="ok"} 1`,
+		`census_request_duration_seconds_count{collection="character"} 1`,
+		`census_request_duration_seconds_bucket{collection="character",le="+Inf"} 1`,
+		"census_circuit_open 0",
+		"census_limiter_waiting 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics are missing %s:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package census
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Census usually quotes numbers, but not always:
+// some collections return the same field unquoted,
+// and joined or resolved rows are sometimes encoded differently than the collection they come from.
+// Fields tagged with ",string" fail to decode at all when a number isn't quoted,
+// and plain numeric fields fail when it is.
+//
+// Rather than giving up the usual field types,
+// responses are decoded with decodeJSON,
+// which rewrites the quoting of numbers to match each field when the standard decoder fails.
+
+// decodeJSON unmarshals a census response into v,
+// accepting numbers whether or not they are quoted.
+// Responses that already match v are decoded in a single pass;
+// the rewrite is only done after the standard decoder rejects the response.
+func decodeJSON(data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return err
+	}
+	requoted := requote(data, rv.Type().Elem(), false)
+	if bytes.Equal(requoted, data) {
+		return err
+	}
+	// start over so that nothing decoded by the failed attempt is left behind
+	rv.Elem().SetZero()
+	return json.Unmarshal(requoted, v)
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// requote returns data with its numbers quoted where t expects quoted numbers,
+// such as fields tagged with ",string", and unquoted where t expects plain numbers.
+// quoted reports whether data is the value of a field tagged with ",string".
+// Empty strings given for numbers are replaced with null, which leaves the field unset.
+// Values that can't be matched to t are returned unchanged for the decoder to report.
+func requote(data []byte, t reflect.Type, quoted bool) []byte {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return data
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// types with their own decoding are left to handle either form themselves
+	if t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return data
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return requoteNumber(data, quoted)
+	case reflect.Struct:
+		fields := structFields(t)
+		return requoteObject(data, func(key string) (reflect.Type, bool, bool) {
+			f, ok := fields.lookup(key)
+			return f.typ, f.quoted, ok
+		})
+	case reflect.Map:
+		return requoteObject(data, func(string) (reflect.Type, bool, bool) {
+			return t.Elem(), false, true
+		})
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 || data[0] != '[' {
+			return data
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return data
+		}
+		changed := false
+		for i, item := range items {
+			if r := requote(item, t.Elem(), false); !bytes.Equal(r, item) {
+				items[i], changed = r, true
+			}
+		}
+		if !changed {
+			return data
+		}
+		b, err := json.Marshal(items)
+		if err != nil {
+			return data
+		}
+		return b
+	default:
+		return data
+	}
+}
+
+// requoteNumber quotes or unquotes a single number.
+func requoteNumber(data []byte, quoted bool) []byte {
+	if bytes.Equal(data, []byte(`""`)) {
+		return []byte("null")
+	}
+	if data[0] == '"' {
+		if quoted {
+			return data
+		}
+		var s string
+		if json.Unmarshal(data, &s) != nil || !isNumber(s) {
+			return data
+		}
+		return []byte(s)
+	}
+	if quoted && isNumber(string(data)) {
+		return append(append([]byte{'"'}, data...), '"')
+	}
+	return data
+}
+
+// isNumber reports whether s is a JSON number.
+func isNumber(s string) bool {
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return false
+	}
+	var n json.Number
+	return json.Unmarshal([]byte(s), &n) == nil
+}
+
+// requoteObject requotes the values of a JSON object,
+// using field to find the type of each key.
+func requoteObject(data []byte, field func(key string) (t reflect.Type, quoted bool, ok bool)) []byte {
+	if data[0] != '{' {
+		return data
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(data, &object) != nil {
+		return data
+	}
+	changed := false
+	for key, value := range object {
+		t, quoted, ok := field(key)
+		if !ok {
+			continue
+		}
+		if r := requote(value, t, quoted); !bytes.Equal(r, value) {
+			object[key], changed = r, true
+		}
+	}
+	if !changed {
+		return data
+	}
+	b, err := json.Marshal(object)
+	if err != nil {
+		return data
+	}
+	return b
+}
+
+type jsonField struct {
+	typ    reflect.Type
+	quoted bool // quoted is set for fields tagged with ",string"
+}
+
+// jsonFields are the fields of a struct by their JSON name,
+// including the fields promoted from embedded structs.
+type jsonFields map[string]jsonField
+
+// lookup finds the field for key the same way encoding/json does,
+// preferring an exact match over a case-insensitive one.
+func (fields jsonFields) lookup(key string) (jsonField, bool) {
+	if f, ok := fields[key]; ok {
+		return f, true
+	}
+	for name, f := range fields {
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+var structFieldCache sync.Map // map[reflect.Type]jsonFields
+
+func structFields(t reflect.Type) jsonFields {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.(jsonFields)
+	}
+	fields := make(jsonFields)
+	addStructFields(fields, t)
+	structFieldCache.Store(t, fields)
+	return fields
+}
+
+// addStructFields adds the fields of t that aren't already in fields.
+// Fields of embedded structs are added after the fields of t,
+// so that they are hidden by fields of the same name like they are with encoding/json.
+func addStructFields(fields jsonFields, t reflect.Type) {
+	var embedded []reflect.Type
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, exists := fields[name]; !exists {
+			fields[name] = jsonField{typ: f.Type, quoted: strings.Contains(","+opts+",", ",string,")}
+		}
+	}
+	for _, ft := range embedded {
+		addStructFields(fields, ft)
+	}
+}
@@ -0,0 +1,101 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// staticClient returns a client that answers every request with body.
+func staticClient(body string) *census.Client {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})})
+	return client
+}
+
+func TestNumberQuoting(t *testing.T) {
+	// the same region as returned by the map_region collection,
+	// by a zone join with unquoted numbers,
+	// and with a mix of both
+	payloads := []string{
+		`{"map_region_list":[{"map_region_id":"2201","zone_id":"2","facility_id":"210002","facility_name":"The Crown","facility_type_id":"3","location_x":"-215.23","location_y":"112.5","location_z":"-441.77"}],"returned":1}`,
+		`{"map_region_list":[{"map_region_id":2201,"zone_id":2,"facility_id":210002,"facility_name":"The Crown","facility_type_id":3,"location_x":-215.23,"location_y":112.5,"location_z":-441.77}],"returned":1}`,
+		`{"map_region_list":[{"map_region_id":"2201","zone_id":2,"facility_id":210002,"facility_name":"The Crown","facility_type_id":"3","location_x":-215.23,"location_y":"112.5","location_z":"-441.77"}],"returned":"1"}`,
+	}
+	for _, payload := range payloads {
+		var response struct {
+			MapRegionList []census.MapRegion `json:"map_region_list"`
+			Returned      int                `json:"returned"`
+		}
+		if err := staticClient(payload).Get(context.Background(), ps2.PC, "map_region?map_region_id=2201", &response); err != nil {
+			t.Errorf("%s: %v", payload, err)
+			continue
+		}
+		if response.Returned != 1 || len(response.MapRegionList) != 1 {
+			t.Errorf("%s: got %+v; want one region", payload, response)
+			continue
+		}
+		r := response.MapRegionList[0]
+		if r.MapRegionID != 2201 || r.FacilityID != 210002 || r.Type != 3 {
+			t.Errorf("%s: got region %d, facility %d, type %d; want 2201, 210002, 3", payload, r.MapRegionID, r.FacilityID, r.Type)
+		}
+		if r.LocationX != -215.23 || r.LocationZ != -441.77 {
+			t.Errorf("%s: got %v, %v; want -215.23, -441.77", payload, r.LocationX, r.LocationZ)
+		}
+	}
+}
+
+func TestNumberQuotingCustomUnmarshal(t *testing.T) {
+	// Zone has its own UnmarshalJSON, which must be just as tolerant
+	var zones struct {
+		ZoneList []census.Zone `json:"zone_list"`
+	}
+	body := `{"zone_list":[{"zone_id":2,"code":"Indar","hex_size":"115","geometry_id":2,"dynamic":"0"}],"returned":1}`
+	if err := staticClient(body).Get(context.Background(), ps2.PC, "zone?zone_id=2", &zones); err != nil {
+		t.Fatal(err)
+	}
+	if len(zones.ZoneList) != 1 || zones.ZoneList[0].ZoneID != 2 || zones.ZoneList[0].HexSize != 115 || zones.ZoneList[0].ContinentID != ps2.Indar {
+		t.Errorf("got %+v; want Indar with hex size 115", zones.ZoneList)
+	}
+}
+
+func TestNumberQuotingEmpty(t *testing.T) {
+	// empty strings and nulls leave a number unset instead of failing the whole response
+	var response struct {
+		MapHexList []census.MapHex `json:"map_hex_list"`
+	}
+	body := `{"map_hex_list":[{"map_region_id":"2201","x":"","y":null,"hex_type":"0"},{"map_region_id":2202,"x":-12,"y":"7","hex_type":"0"}],"returned":2}`
+	if err := staticClient(body).Get(context.Background(), ps2.PC, "map_hex?map_region_id=2201,2202", &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.MapHexList) != 2 {
+		t.Fatalf("got %d hexes; want 2", len(response.MapHexList))
+	}
+	if h := response.MapHexList[0]; h.MapRegionID != 2201 || h.X != 0 || h.Y != 0 {
+		t.Errorf("got %+v; want region 2201 at 0,0", h)
+	}
+	if h := response.MapHexList[1]; h.MapRegionID != 2202 || h.X != -12 || h.Y != 7 {
+		t.Errorf("got %+v; want region 2202 at -12,7", h)
+	}
+}
+
+func TestNumberQuotingInvalid(t *testing.T) {
+	var response struct {
+		MapHexList []census.MapHex `json:"map_hex_list"`
+	}
+	body := `{"map_hex_list":[{"map_region_id":"2201","x":"twelve"}],"returned":1}`
+	if err := staticClient(body).Get(context.Background(), ps2.PC, "map_hex?map_region_id=2201", &response); err == nil {
+		t.Errorf("expected an error for a field that isn't a number")
+	}
+}
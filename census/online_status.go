@@ -0,0 +1,103 @@
+package census
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// OnlineStatus is a row of the characters_online_status collection.
+type OnlineStatus struct {
+	CharacterID ps2.CharacterID `json:"character_id,string"`
+
+	// WorldID is the world the character is logged in to, or 0 when the character is offline.
+	// Census reports the world as the online status.
+	WorldID ps2.WorldID `json:"online_status,string"`
+}
+
+func (OnlineStatus) CollectionName() string { return "characters_online_status" }
+
+// Online reports whether the character is logged in.
+func (s OnlineStatus) Online() bool { return s.WorldID != 0 }
+
+// GetOnlineStatus looks up whether characters are online, and on which world,
+// splitting the IDs into as many requests as needed.
+// Characters that don't exist are left out of the result,
+// and the result is in no particular order.
+//
+// Online status isn't cached, since it's only useful while it's current.
+func GetOnlineStatus(ctx context.Context, client *Client, env ps2.Environment, ids ...ps2.CharacterID) ([]OnlineStatus, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	ids = slices.DeleteFunc(ids, func(id ps2.CharacterID) bool { return id == 0 })
+
+	statuses := make([]OnlineStatus, 0, len(ids))
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), maxCharactersPerRequest)]
+		ids = ids[len(batch):]
+		list := make([]string, len(batch))
+		for i, id := range batch {
+			list[i] = id.String()
+		}
+		r := struct {
+			List []OnlineStatus `json:"characters_online_status_list"`
+		}{}
+		query := fmt.Sprintf("characters_online_status?character_id=%s&c:limit=%d", strings.Join(list, ","), len(batch))
+		if err := client.Get(ctx, env, query, &r); err != nil {
+			return nil, fmt.Errorf("census.GetOnlineStatus: %w", err)
+		}
+		statuses = append(statuses, r.List...)
+	}
+	return statuses, nil
+}
+
+// GetOutfitOnline returns the online status of every member of the outfits who is logged in,
+// resolving characters_online_status through a join on outfit_member
+// so that the members don't have to be looked up first.
+// Large outfits are requested a page at a time.
+func GetOutfitOnline(ctx context.Context, client *Client, env ps2.Environment, outfits ...ps2.OutfitID) ([]OnlineStatus, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	if len(outfits) == 0 {
+		return nil, nil
+	}
+	list := make([]string, len(outfits))
+	for i, id := range outfits {
+		list[i] = strconv.FormatInt(int64(id), 10)
+	}
+	const perPage = 5000
+	var online []OnlineStatus
+	for start := 0; ; start += perPage {
+		r := struct {
+			List []struct {
+				CharacterID ps2.CharacterID `json:"character_id,string"`
+				Status      *OnlineStatus   `json:"online"`
+			} `json:"outfit_member_list"`
+		}{}
+		query := fmt.Sprintf(
+			"outfit_member?outfit_id=%s&c:show=character_id&c:join=characters_online_status^inject_at:online^show:character_id'online_status&c:limit=%d&c:start=%d",
+			strings.Join(list, ","), perPage, start,
+		)
+		if err := client.Get(ctx, env, query, &r); err != nil {
+			return nil, fmt.Errorf("census.GetOutfitOnline: %w", err)
+		}
+		for _, member := range r.List {
+			// members whose status census doesn't know about are left out like offline members
+			if member.Status != nil && member.Status.Online() {
+				online = append(online, *member.Status)
+			}
+		}
+		if len(r.List) < perPage {
+			return online, nil
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestGetOutfitOnline(t *testing.T) {
+	var query string
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`{"outfit_member_list":[
+				{"character_id":"1","online":{"character_id":"1","online_status":"17"}},
+				{"character_id":"2","online":{"character_id":"2","online_status":"0"}},
+				{"character_id":"3"}
+			],"returned":3}`)),
+			Request: req,
+		}, nil
+	})})
+
+	online, err := census.GetOutfitOnline(context.Background(), client, ps2.PC, 100, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(online) != 1 || online[0] != (census.OnlineStatus{CharacterID: 1, WorldID: ps2.Emerald}) {
+		t.Errorf("got %+v; want only character 1 online on Emerald", online)
+	}
+	if !strings.Contains(query, "outfit_id=100,200") {
+		t.Errorf("got query %q; want both outfits", query)
+	}
+}
@@ -0,0 +1,171 @@
+package census
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// OutfitWar is an Outfit Wars season on a world.
+// Matches are played in instances of [ps2.Nexus]; see [IsOutfitWarZone].
+type OutfitWar struct {
+	OutfitWarID             ps2.OutfitWarID  `json:"outfit_war_id,string"`
+	WorldID                 ps2.WorldID      `json:"world_id,string"`
+	Title                   ps2.Localization `json:"title"`
+	OutfitSizeRequirement   int              `json:"outfit_size_requirement,string"`
+	OutfitSignupRequirement int              `json:"outfit_signup_requirement,string"`
+	MaxRegistrations        int              `json:"max_registrations,string"`
+	StartTime               UnixTime         `json:"start_time"`
+	EndTime                 UnixTime         `json:"end_time"`
+	ImageSetID              ps2.ImageSetID   `json:"imageset_id,string"`
+	ImageID                 ps2.ImageID      `json:"image_id,string"`
+	ImagePath               string           `json:"image_path"`
+}
+
+func (OutfitWar) CollectionName() string { return "outfit_war" }
+
+// OutfitWarRegistration is an outfit signed up for an Outfit Wars season.
+type OutfitWarRegistration struct {
+	OutfitID          ps2.OutfitID    `json:"outfit_id,string"`
+	FactionID         ps2.FactionID   `json:"faction_id,string"`
+	WorldID           ps2.WorldID     `json:"world_id,string"`
+	OutfitWarID       ps2.OutfitWarID `json:"outfit_war_id,string"`
+	RegistrationOrder int             `json:"registration_order,string"`
+	Status            string          `json:"status"` // Status is "Full" once the outfit has enough members signed up
+	MemberSignupCount int             `json:"member_signup_count,string"`
+}
+
+func (OutfitWarRegistration) CollectionName() string { return "outfit_war_registration" }
+
+// OutfitWarRounds is the schedule of rounds for an Outfit Wars season.
+type OutfitWarRounds struct {
+	OutfitWarID    ps2.OutfitWarID      `json:"outfit_war_id,string"`
+	PrimaryRoundID ps2.OutfitWarRoundID `json:"primary_round_id,string"`
+	Rounds         []OutfitWarRound     `json:"rounds"`
+}
+
+func (OutfitWarRounds) CollectionName() string { return "outfit_war_rounds" }
+
+// OutfitWarRound is one round of an Outfit Wars season.
+type OutfitWarRound struct {
+	RoundID   ps2.OutfitWarRoundID `json:"round_id,string"`
+	Order     int                  `json:"order,string"`
+	Stage     string               `json:"stage"`
+	StartTime UnixTime             `json:"start_time"`
+	EndTime   UnixTime             `json:"end_time"`
+}
+
+// OutfitWarMatch is a scheduled match between two outfits.
+type OutfitWarMatch struct {
+	MatchID          string          `json:"match_id"`
+	OutfitWarID      ps2.OutfitWarID `json:"outfit_war_id,string"`
+	WorldID          ps2.WorldID     `json:"world_id,string"`
+	OutfitAID        ps2.OutfitID    `json:"outfit_a_id,string"`
+	OutfitAFactionID ps2.FactionID   `json:"outfit_a_faction_id,string"`
+	OutfitBID        ps2.OutfitID    `json:"outfit_b_id,string"`
+	OutfitBFactionID ps2.FactionID   `json:"outfit_b_faction_id,string"`
+	StartTime        UnixTime        `json:"start_time"`
+	Order            int             `json:"order,string"`
+}
+
+func (OutfitWarMatch) CollectionName() string { return "outfit_war_match" }
+
+// OutfitWarRanking is an outfit's standing in a round.
+type OutfitWarRanking struct {
+	RoundID   ps2.OutfitWarRoundID `json:"round_id,string"`
+	OutfitID  ps2.OutfitID         `json:"outfit_id,string"`
+	FactionID ps2.FactionID        `json:"faction_id,string"`
+	WorldID   ps2.WorldID          `json:"world_id,string"`
+	Order     int                  `json:"order,string"`
+
+	// RankingParameters holds the scores used to rank outfits,
+	// such as "Wins", "Losses", "TotalScore", and "Kills".
+	// Census has changed the set of parameters between seasons.
+	RankingParameters map[string]int `json:"-"`
+}
+
+func (OutfitWarRanking) CollectionName() string { return "outfit_war_ranking" }
+
+func (r *OutfitWarRanking) UnmarshalJSON(data []byte) error {
+	type ranking OutfitWarRanking
+	var v struct {
+		ranking
+		RankingParameters map[string]json.RawMessage `json:"ranking_parameters"`
+	}
+	if err := decodeJSON(data, &v); err != nil {
+		return fmt.Errorf("census.OutfitWarRanking.UnmarshalJSON: %w", err)
+	}
+	*r = OutfitWarRanking(v.ranking)
+	r.RankingParameters = make(map[string]int, len(v.RankingParameters))
+	for name, raw := range v.RankingParameters {
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				continue
+			}
+			n = json.Number(s)
+		}
+		if i, err := strconv.Atoi(n.String()); err == nil {
+			r.RankingParameters[name] = i
+		}
+	}
+	return nil
+}
+
+// IsOutfitWarZone reports whether zone is an instance of Nexus,
+// which is where Outfit Wars matches are played.
+func IsOutfitWarZone(zone ps2.ZoneInstanceID) bool {
+	return zone.ZoneID() == ps2.Nexus && zone.IsInstanced()
+}
+
+// GetOutfitWars returns the Outfit Wars seasons of world.
+func GetOutfitWars(ctx context.Context, client *Client, world ps2.WorldID) ([]OutfitWar, error) {
+	wars, err := getList[OutfitWar](ctx, client, ps2.GetEnvironment(world), Eq("world_id", world).String())
+	if err != nil {
+		return nil, fmt.Errorf("census.GetOutfitWars: %w", err)
+	}
+	return wars, nil
+}
+
+// GetOutfitWarRegistrations returns the outfits registered for war on world.
+func GetOutfitWarRegistrations(ctx context.Context, client *Client, world ps2.WorldID, war ps2.OutfitWarID) ([]OutfitWarRegistration, error) {
+	regs, err := getList[OutfitWarRegistration](ctx, client, ps2.GetEnvironment(world), Eq("outfit_war_id", war).String()+"&c:sort=registration_order")
+	if err != nil {
+		return nil, fmt.Errorf("census.GetOutfitWarRegistrations: %w", err)
+	}
+	return regs, nil
+}
+
+// GetOutfitWarRounds returns the round schedule of war on world.
+func GetOutfitWarRounds(ctx context.Context, client *Client, world ps2.WorldID, war ps2.OutfitWarID) (OutfitWarRounds, error) {
+	rounds, err := getList[OutfitWarRounds](ctx, client, ps2.GetEnvironment(world), Eq("outfit_war_id", war).String())
+	if err != nil {
+		return OutfitWarRounds{}, fmt.Errorf("census.GetOutfitWarRounds: %w", err)
+	}
+	if len(rounds) == 0 {
+		return OutfitWarRounds{}, fmt.Errorf("census.GetOutfitWarRounds: %w", noResultsError{q: strconv.Itoa(int(war))})
+	}
+	return rounds[0], nil
+}
+
+// GetOutfitWarMatches returns the scheduled matches of war on world.
+func GetOutfitWarMatches(ctx context.Context, client *Client, world ps2.WorldID, war ps2.OutfitWarID) ([]OutfitWarMatch, error) {
+	matches, err := getList[OutfitWarMatch](ctx, client, ps2.GetEnvironment(world), Eq("outfit_war_id", war).And(Eq("world_id", world)).String()+"&c:sort=start_time")
+	if err != nil {
+		return nil, fmt.Errorf("census.GetOutfitWarMatches: %w", err)
+	}
+	return matches, nil
+}
+
+// GetOutfitWarRankings returns the outfit rankings of a round on world, in ranked order.
+func GetOutfitWarRankings(ctx context.Context, client *Client, world ps2.WorldID, round ps2.OutfitWarRoundID) ([]OutfitWarRanking, error) {
+	rankings, err := getList[OutfitWarRanking](ctx, client, ps2.GetEnvironment(world), Eq("round_id", round).String()+"&c:sort=order")
+	if err != nil {
+		return nil, fmt.Errorf("census.GetOutfitWarRankings: %w", err)
+	}
+	return rankings, nil
+}
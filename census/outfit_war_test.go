@@ -0,0 +1,149 @@
+package census_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// queryClient returns a client that answers every request with body
+// and stores the query string of the last request in query.
+func queryClient(query *string, body string) *census.Client {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*query = req.URL.RawQuery
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})})
+	return client
+}
+
+func TestUnixTime(t *testing.T) {
+	at := time.Unix(1700000000, 0).UTC()
+	tt := map[string]struct {
+		JSON string
+		Want time.Time
+	}{
+		"quoted":   {`"1700000000"`, at},
+		"unquoted": {`1700000000`, at},
+		"zero":     {`"0"`, time.Time{}},
+		"empty":    {`""`, time.Time{}},
+		"null":     {`null`, time.Time{}},
+	}
+	for name, tc := range tt {
+		var got census.UnixTime
+		if err := json.Unmarshal([]byte(tc.JSON), &got); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !got.Time().Equal(tc.Want) {
+			t.Errorf("%s: got %v; want %v", name, got.Time(), tc.Want)
+		}
+	}
+
+	var invalid census.UnixTime
+	if err := json.Unmarshal([]byte(`"soon"`), &invalid); err == nil {
+		t.Error("expected an error for a time that isn't a number")
+	}
+
+	for _, want := range []time.Time{at, {}} {
+		b, err := json.Marshal(census.UnixTime(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got census.UnixTime
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Time().Equal(want) {
+			t.Errorf("got %v after a round trip through %s; want %v", got.Time(), b, want)
+		}
+	}
+}
+
+func TestGetOutfitWarMatches(t *testing.T) {
+	var query string
+	client := queryClient(&query, `{"outfit_war_match_list":[
+		{"match_id":"abc","outfit_war_id":"40","world_id":"17","outfit_a_id":"100","outfit_a_faction_id":"1","outfit_b_id":"200","outfit_b_faction_id":"3","start_time":"1700000000","order":"1"}
+	],"returned":1}`)
+
+	matches, err := census.GetOutfitWarMatches(context.Background(), client, ps2.Emerald, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "outfit_war_id=40&world_id=17&c:sort=start_time&c:limit=5000" {
+		t.Errorf("got query %q", query)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches; want 1", len(matches))
+	}
+	m := matches[0]
+	if m.OutfitAID != 100 || m.OutfitBFactionID != ps2.TR || m.StartTime.Time().Unix() != 1700000000 {
+		t.Errorf("got %+v", m)
+	}
+}
+
+func TestGetOutfitWarRankings(t *testing.T) {
+	var query string
+	// ranking parameters have been sent as both strings and numbers
+	client := queryClient(&query, `{"outfit_war_ranking_list":[
+		{"round_id":"8","outfit_id":"100","faction_id":"1","world_id":"17","order":"1","ranking_parameters":{"Wins":"3","Losses":0,"TotalScore":"1500","Note":"tied"}}
+	],"returned":1}`)
+
+	rankings, err := census.GetOutfitWarRankings(context.Background(), client, ps2.Emerald, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "round_id=8&c:sort=order&c:limit=5000" {
+		t.Errorf("got query %q", query)
+	}
+	if len(rankings) != 1 {
+		t.Fatalf("got %d rankings; want 1", len(rankings))
+	}
+	params := rankings[0].RankingParameters
+	if len(params) != 3 || params["Wins"] != 3 || params["Losses"] != 0 || params["TotalScore"] != 1500 {
+		t.Errorf("got parameters %v; want the numeric ones", params)
+	}
+}
+
+func TestGetOutfitWarRounds(t *testing.T) {
+	var query string
+	client := queryClient(&query, `{"outfit_war_rounds_list":[
+		{"outfit_war_id":"40","primary_round_id":"8","rounds":[{"round_id":"8","order":"1","stage":"Qualifier","start_time":"1700000000","end_time":"1700600000"}]}
+	],"returned":1}`)
+
+	rounds, err := census.GetOutfitWarRounds(context.Background(), client, ps2.Emerald, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "outfit_war_id=40&c:limit=5000" {
+		t.Errorf("got query %q", query)
+	}
+	if len(rounds.Rounds) != 1 || rounds.Rounds[0].EndTime.Time().Sub(rounds.Rounds[0].StartTime.Time()) != 600000*time.Second {
+		t.Errorf("got %+v", rounds)
+	}
+
+	client = queryClient(&query, `{"outfit_war_rounds_list":[],"returned":0}`)
+	if _, err := census.GetOutfitWarRounds(context.Background(), client, ps2.Emerald, 41); !census.IsNotFound(err) {
+		t.Errorf("got error %v; want a not found error", err)
+	}
+}
+
+func TestIsOutfitWarZone(t *testing.T) {
+	if census.IsOutfitWarZone(ps2.ZoneInstanceID(ps2.Nexus)) {
+		t.Error("got the uninstanced Nexus as an outfit war zone")
+	}
+	if census.IsOutfitWarZone(ps2.ZoneInstanceID(ps2.Indar)) {
+		t.Error("got Indar as an outfit war zone")
+	}
+}
@@ -0,0 +1,118 @@
+package census
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Priority orders requests waiting for the concurrency limit.
+// Requests with a higher priority are sent before any waiting request with a lower priority,
+// and requests with the same priority are sent in the order they started waiting.
+// Priority doesn't affect the rate limit or requests that are already in flight.
+type Priority int8
+
+const (
+	// PriorityBulk is for requests nobody is waiting on, like backfills and cache warming.
+	PriorityBulk Priority = -1
+
+	// PriorityNormal is the priority of requests made without [WithPriority].
+	PriorityNormal Priority = 0
+
+	// PriorityInteractive is for requests a person is waiting on, like a single character lookup for a chat command.
+	PriorityInteractive Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx that gives census requests made with it priority p.
+//
+//	ctx = census.WithPriority(ctx, census.PriorityInteractive)
+//	characters, err := census.GetCharactersByID(ctx, nil, ps2.PC, id)
+//
+// Bulk requests keep waiting as long as higher priority requests keep arriving,
+// so interactive priority should only be given to requests that are few in number.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityFrom returns the priority set on ctx with WithPriority.
+func priorityFrom(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return min(max(p, PriorityBulk), PriorityInteractive)
+}
+
+// slots limits the number of requests in flight,
+// giving free slots to the highest priority request waiting for one.
+type slots struct {
+	mu      sync.Mutex
+	size    int
+	used    int
+	waiting [PriorityInteractive - PriorityBulk + 1][]chan struct{} // waiting is indexed by priority, starting from PriorityBulk
+}
+
+func newSlots(size int) *slots {
+	return &slots{size: size}
+}
+
+// acquire waits for a free slot.
+// Each successful call must be followed by a call to release.
+func (s *slots) acquire(ctx context.Context, p Priority) error {
+	s.mu.Lock()
+	if s.used < s.size && s.queued() == 0 {
+		s.used++
+		s.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	i := p - PriorityBulk
+	s.waiting[i] = append(s.waiting[i], ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		n := len(s.waiting[i])
+		s.waiting[i] = slices.DeleteFunc(s.waiting[i], func(c chan struct{}) bool { return c == ready })
+		granted := len(s.waiting[i]) == n
+		s.mu.Unlock()
+		if granted {
+			// the slot was handed over while ctx was cancelled
+			s.release()
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it directly to the highest priority waiting request if there is one.
+func (s *slots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.waiting) - 1; i >= 0; i-- {
+		if len(s.waiting[i]) > 0 {
+			close(s.waiting[i][0])
+			s.waiting[i] = s.waiting[i][1:]
+			return
+		}
+	}
+	s.used--
+}
+
+// inUse returns the number of requests holding a slot.
+func (s *slots) inUse() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used
+}
+
+// queued returns the number of requests waiting for a slot.
+// s.mu must be held.
+func (s *slots) queued() int {
+	var n int
+	for _, w := range s.waiting {
+		n += len(w)
+	}
+	return n
+}
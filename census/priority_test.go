@@ -0,0 +1,105 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestPriority(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		id := req.URL.Query().Get("world_id")
+		if id == "1" {
+			<-release
+		}
+		mu.Lock()
+		order = append(order, id)
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"world_list":[],"returned":0}`)),
+			Request:    req,
+		}, nil
+	})})
+	limiter := census.NewLimiter(10, 10, 1)
+	defer limiter.Stop()
+	client.SetLimiter(limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	get := func(ctx context.Context, world string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r struct{}
+			if err := client.Get(ctx, ps2.PC, "world?world_id="+world, &r); err != nil {
+				t.Error(err)
+			}
+		}()
+		// give the request time to start waiting
+		time.Sleep(50 * time.Millisecond)
+	}
+	get(ctx, "1") // holds the only slot until released
+	get(census.WithPriority(ctx, census.PriorityBulk), "10")
+	get(ctx, "13")
+	get(census.WithPriority(ctx, census.PriorityInteractive), "17")
+	close(release)
+	wg.Wait()
+
+	want := []string{"1", "17", "13", "10"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("got requests in order %v; want %v", order, want)
+	}
+}
+
+func TestPriorityCancelled(t *testing.T) {
+	release := make(chan struct{})
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"world_list":[],"returned":0}`)),
+			Request:    req,
+		}, nil
+	})})
+	limiter := census.NewLimiter(10, 10, 1)
+	defer limiter.Stop()
+	client.SetLimiter(limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		var r struct{}
+		done <- client.Get(ctx, ps2.PC, "world?world_id=1", &r)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// a request that gives up waiting must not keep its place in the queue
+	waitCtx, stop := context.WithTimeout(census.WithPriority(ctx, census.PriorityInteractive), 50*time.Millisecond)
+	defer stop()
+	var r struct{}
+	if err := client.Get(waitCtx, ps2.PC, "world?world_id=17", &r); err == nil {
+		t.Errorf("expected an error while the only slot is in use")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Get(ctx, ps2.PC, "world?world_id=13", &r); err != nil {
+		t.Errorf("slot was lost after a cancelled request: %v", err)
+	}
+}
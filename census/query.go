@@ -0,0 +1,381 @@
+package census
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Query builds a census query string,
+// so that commands like c:join and c:show don't have to be assembled by hand:
+//
+//	q := census.NewQuery("character").
+//		Where(census.Eq("name.first_lower", "auroram")).
+//		Show("character_id", "name.first", "faction_id").
+//		Join(census.NewJoin("characters_world").InjectAt("world")).
+//		Lang(ps2.En)
+//	err := client.Get(ctx, ps2.PC, q.String(), &result)
+//
+// Queries are values; every method returns a modified copy.
+//
+// Field names and filter values are escaped.
+// Census splits commands on its own delimiters before unescaping them, however,
+// so names and values used inside joins and trees can't contain ^ ' : , ( or ).
+type Query struct {
+	collection string
+	filter     Filter
+	show       []string
+	hide       []string
+	sort       []string
+	has        []string
+	resolve    []string
+	joins      []Join
+	tree       *Tree
+	lang       ps2.Locale
+	limit      int
+	start      int
+	distinct   string
+	caseSens   *bool
+	exactFirst bool
+}
+
+// NewQuery starts a query for collection.
+func NewQuery(collection string) Query {
+	return Query{collection: collection}
+}
+
+// Where adds filters that rows must match.
+func (q Query) Where(filters ...Filter) Query {
+	for _, f := range filters {
+		q.filter = q.filter.And(f)
+	}
+	return q
+}
+
+// Show limits the returned fields to fields (c:show).
+func (q Query) Show(fields ...string) Query {
+	q.show = appendCopy(q.show, fields)
+	return q
+}
+
+// Hide removes fields from the results (c:hide).
+func (q Query) Hide(fields ...string) Query {
+	q.hide = appendCopy(q.hide, fields)
+	return q
+}
+
+// Sort orders results by field (c:sort).
+// Later calls break ties of earlier ones.
+func (q Query) Sort(field string, descending bool) Query {
+	if descending {
+		field += ":-1"
+	}
+	q.sort = appendCopy(q.sort, []string{field})
+	return q
+}
+
+// Has limits results to rows that have a value for every field (c:has).
+func (q Query) Has(fields ...string) Query {
+	q.has = appendCopy(q.has, fields)
+	return q
+}
+
+// Resolve adds resolves to the results, like "outfit" or "online_status" for characters (c:resolve).
+func (q Query) Resolve(resolves ...string) Query {
+	q.resolve = appendCopy(q.resolve, resolves)
+	return q
+}
+
+// Join adds joins to the query (c:join).
+func (q Query) Join(joins ...Join) Query {
+	q.joins = appendCopy(q.joins, joins)
+	return q
+}
+
+// Tree rearranges the results into an object keyed by a field (c:tree).
+func (q Query) Tree(t Tree) Query {
+	q.tree = &t
+	return q
+}
+
+// Lang limits localized strings to one locale (c:lang).
+func (q Query) Lang(l ps2.Locale) Query {
+	q.lang = l
+	return q
+}
+
+// Limit sets the maximum number of results (c:limit).
+// Census returns a single row when the limit isn't set.
+func (q Query) Limit(n int) Query {
+	q.limit = n
+	return q
+}
+
+// Start skips the first n results (c:start).
+func (q Query) Start(n int) Query {
+	q.start = n
+	return q
+}
+
+// Distinct returns the distinct values of field instead of rows (c:distinct).
+func (q Query) Distinct(field string) Query {
+	q.distinct = field
+	return q
+}
+
+// Case sets whether string filters are case sensitive (c:case).
+// Census searches are case sensitive by default.
+func (q Query) Case(sensitive bool) Query {
+	q.caseSens = &sensitive
+	return q
+}
+
+// ExactMatchFirst sorts exact matches of a StartsWith or Contains filter before other results (c:exactMatchFirst).
+func (q Query) ExactMatchFirst() Query {
+	q.exactFirst = true
+	return q
+}
+
+// String returns the query string, like "character?character_id=5428010618015189713&c:show=name".
+func (q Query) String() string {
+	var params []string
+	if len(q.filter.conditions) > 0 {
+		params = append(params, q.filter.String())
+	}
+	param := func(command, value string) {
+		params = append(params, command+"="+value)
+	}
+	list := func(command string, values []string) {
+		if len(values) > 0 {
+			param(command, escapeList(values, ","))
+		}
+	}
+	list("c:show", q.show)
+	list("c:hide", q.hide)
+	list("c:sort", q.sort)
+	list("c:has", q.has)
+	list("c:resolve", q.resolve)
+	if q.caseSens != nil {
+		param("c:case", strconv.FormatBool(*q.caseSens))
+	}
+	if q.exactFirst {
+		param("c:exactMatchFirst", "true")
+	}
+	if q.distinct != "" {
+		param("c:distinct", url.QueryEscape(q.distinct))
+	}
+	if q.lang != "" {
+		param("c:lang", url.QueryEscape(string(q.lang)))
+	}
+	if q.limit > 0 {
+		param("c:limit", strconv.Itoa(q.limit))
+	}
+	if q.start > 0 {
+		param("c:start", strconv.Itoa(q.start))
+	}
+	if len(q.joins) > 0 {
+		joins := make([]string, len(q.joins))
+		for i, j := range q.joins {
+			joins[i] = j.String()
+		}
+		param("c:join", strings.Join(joins, ","))
+	}
+	if q.tree != nil {
+		param("c:tree", q.tree.String())
+	}
+
+	s := url.PathEscape(q.collection)
+	if len(params) > 0 {
+		s += "?" + strings.Join(params, "&")
+	}
+	return s
+}
+
+// Join adds rows from another collection to each result.
+// Joins are built with [NewJoin]:
+//
+//	census.NewJoin("outfit_member").
+//		On("outfit_id").
+//		List().
+//		InjectAt("members").
+//		Join(census.NewJoin("character").On("character_id").InjectAt("character").Show("name.first"))
+type Join struct {
+	collection string
+	on         string
+	to         string
+	injectAt   string
+	list       bool
+	inner      bool
+	show       []string
+	hide       []string
+	terms      Filter
+	joins      []Join
+}
+
+// NewJoin starts a join to collection.
+func NewJoin(collection string) Join {
+	return Join{collection: collection}
+}
+
+// On sets the field of the parent row to join on.
+// It defaults to the ID field of the joined collection, like "character_id".
+func (j Join) On(field string) Join {
+	j.on = field
+	return j
+}
+
+// To sets the field of the joined collection that On is matched to.
+// It defaults to the same name as On.
+func (j Join) To(field string) Join {
+	j.to = field
+	return j
+}
+
+// InjectAt sets the field name the joined rows are added at.
+// It defaults to a generated name like "character_id_join_character".
+func (j Join) InjectAt(field string) Join {
+	j.injectAt = field
+	return j
+}
+
+// List joins every matching row as a list instead of only the first.
+func (j Join) List() Join {
+	j.list = true
+	return j
+}
+
+// Inner removes parent rows that have nothing to join,
+// instead of including them without the joined field.
+func (j Join) Inner() Join {
+	j.inner = true
+	return j
+}
+
+// Show limits the fields of the joined rows.
+func (j Join) Show(fields ...string) Join {
+	j.show = appendCopy(j.show, fields)
+	return j
+}
+
+// Hide removes fields from the joined rows.
+func (j Join) Hide(fields ...string) Join {
+	j.hide = appendCopy(j.hide, fields)
+	return j
+}
+
+// Terms adds filters that joined rows must match.
+func (j Join) Terms(filters ...Filter) Join {
+	for _, f := range filters {
+		j.terms = j.terms.And(f)
+	}
+	return j
+}
+
+// Join nests joins inside j, which are joined on the rows j adds.
+func (j Join) Join(joins ...Join) Join {
+	j.joins = appendCopy(j.joins, joins)
+	return j
+}
+
+// String returns j in c:join syntax, like "outfit_member^on:outfit_id^list:1^inject_at:members".
+func (j Join) String() string {
+	parts := []string{url.QueryEscape(j.collection)}
+	option := func(name, value string) {
+		if value != "" {
+			parts = append(parts, name+":"+value)
+		}
+	}
+	option("on", url.QueryEscape(j.on))
+	option("to", url.QueryEscape(j.to))
+	if j.list {
+		option("list", "1")
+	}
+	option("inject_at", url.QueryEscape(j.injectAt))
+	option("show", escapeList(j.show, "'"))
+	option("hide", escapeList(j.hide, "'"))
+	if len(j.terms.conditions) > 0 {
+		terms := make([]string, len(j.terms.conditions))
+		for i, c := range j.terms.conditions {
+			terms[i] = url.QueryEscape(c.field) + "=" + url.QueryEscape(c.modifier+c.value)
+		}
+		option("terms", strings.Join(terms, "'"))
+	}
+	if j.inner {
+		option("outer", "0")
+	}
+	s := strings.Join(parts, "^")
+	if len(j.joins) > 0 {
+		nested := make([]string, len(j.joins))
+		for i, n := range j.joins {
+			nested[i] = n.String()
+		}
+		s += "(" + strings.Join(nested, ",") + ")"
+	}
+	return s
+}
+
+// Tree rearranges results into an object keyed by the values of a field.
+// Trees are built with [NewTree].
+type Tree struct {
+	field  string
+	list   bool
+	prefix string
+	start  string
+}
+
+// NewTree starts a tree keyed by field.
+func NewTree(field string) Tree {
+	return Tree{field: field}
+}
+
+// List groups rows with the same key into a list instead of keeping only one.
+func (t Tree) List() Tree {
+	t.list = true
+	return t
+}
+
+// Prefix is added to the start of every key.
+func (t Tree) Prefix(prefix string) Tree {
+	t.prefix = prefix
+	return t
+}
+
+// Start sets a nested field to build the tree from instead of the top level rows,
+// such as the field a join was injected at.
+func (t Tree) Start(field string) Tree {
+	t.start = field
+	return t
+}
+
+// String returns t in c:tree syntax, like "field:zone_id^list:1".
+func (t Tree) String() string {
+	parts := []string{}
+	if t.start != "" {
+		parts = append(parts, "start:"+url.QueryEscape(t.start))
+	}
+	parts = append(parts, "field:"+url.QueryEscape(t.field))
+	if t.list {
+		parts = append(parts, "list:1")
+	}
+	if t.prefix != "" {
+		parts = append(parts, "prefix:"+url.QueryEscape(t.prefix))
+	}
+	return strings.Join(parts, "^")
+}
+
+// escapeList escapes values and joins them with sep.
+func escapeList(values []string, sep string) string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = url.QueryEscape(v)
+	}
+	return strings.Join(escaped, sep)
+}
+
+// appendCopy appends values to a copy of s,
+// so that queries built from the same base don't share a backing array.
+func appendCopy[T any](s []T, values []T) []T {
+	return append(s[:len(s):len(s)], values...)
+}
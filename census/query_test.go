@@ -0,0 +1,78 @@
+package census_test
+
+import (
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestQueryString(t *testing.T) {
+	tt := map[string]struct {
+		Query census.Query
+		Want  string
+	}{
+		"collection only": {
+			census.NewQuery("world"),
+			"world",
+		},
+		"filters and commands": {
+			census.NewQuery("map_region").
+				Where(census.Eq("zone_id", ps2.Indar), census.GreaterEq("facility_type_id", 5)).
+				Show("map_region_id", "facility_name").
+				Sort("facility_name", false).
+				Lang(ps2.En).
+				Limit(5000).
+				Start(10),
+			"map_region?zone_id=2&facility_type_id=%5D5&c:show=map_region_id,facility_name&c:sort=facility_name&c:lang=en&c:limit=5000&c:start=10",
+		},
+		"escaping": {
+			census.NewQuery("character").
+				Where(census.Eq("name.first_lower", "a b&c=d")).
+				Hide("odd field").
+				Join(census.NewJoin("outfit_member").Terms(census.Eq("rank", "x&y"))),
+			"character?name.first_lower=a+b%26c%3Dd&c:hide=odd+field&c:join=outfit_member^terms:rank=x%26y",
+		},
+		"join options": {
+			census.NewQuery("outfit").
+				Join(census.NewJoin("outfit_member").On("outfit_id").To("outfit_id").List().InjectAt("members").Show("character_id", "rank").Inner()),
+			"outfit?c:join=outfit_member^on:outfit_id^to:outfit_id^list:1^inject_at:members^show:character_id'rank^outer:0",
+		},
+		"nested joins": {
+			census.NewQuery("outfit").
+				Join(
+					census.NewJoin("outfit_member").List().InjectAt("members").Join(
+						census.NewJoin("character").InjectAt("character").Join(
+							census.NewJoin("characters_online_status").InjectAt("online"),
+						),
+						census.NewJoin("characters_world").InjectAt("world"),
+					),
+					census.NewJoin("character").On("leader_character_id").To("character_id").InjectAt("leader"),
+				),
+			"outfit?c:join=outfit_member^list:1^inject_at:members(character^inject_at:character(characters_online_status^inject_at:online),characters_world^inject_at:world),character^on:leader_character_id^to:character_id^inject_at:leader",
+		},
+		"tree": {
+			census.NewQuery("map_hex").Tree(census.NewTree("map_region_id").List().Prefix("r")),
+			"map_hex?c:tree=field:map_region_id^list:1^prefix:r",
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.Query.String(); got != tc.Want {
+				t.Errorf("got  %s\nwant %s", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestQueryCopies(t *testing.T) {
+	base := census.NewQuery("character").Show("character_id")
+	a := base.Show("name")
+	b := base.Show("faction_id")
+	if got, want := a.String(), "character?c:show=character_id,name"; got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+	if got, want := b.String(), "character?c:show=character_id,faction_id"; got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+}
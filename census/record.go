@@ -0,0 +1,255 @@
+package census
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recorder keeps the requests and responses of census calls so that they can be attached to a bug report.
+// Service IDs are redacted from every recorded URL and error.
+//
+//	rec := &census.Recorder{}
+//	client.SetRecorder(rec)
+//	...
+//	f, _ := os.Create("census-bundle.zip")
+//	rec.WriteBundle(f)
+//
+// The bundle can be replayed in tests with [OpenBundle].
+type Recorder struct {
+	// All records every call instead of only the calls that failed.
+	All bool
+
+	// Max is the number of calls to keep, dropping the oldest first.
+	// The default is 100.
+	Max int
+
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+// RecordedCall is a single census request and its response.
+type RecordedCall struct {
+	URL        string    `json:"url"`
+	Time       time.Time `json:"time"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"` // StatusCode is 0 when no response was received
+	Error      string    `json:"error,omitempty"`
+	Body       []byte    `json:"-"`
+}
+
+// SetRecorder records the calls made by c to r.
+// A nil recorder stops recording.
+func (c *Client) SetRecorder(r *Recorder) {
+	c.recorder = r
+}
+
+func (r *Recorder) record(u string, status int, body []byte, err error, attempt int) {
+	if r == nil || (err == nil && !r.All) {
+		return
+	}
+	call := RecordedCall{
+		URL:        RedactURL(u),
+		Time:       time.Now(),
+		Attempt:    attempt,
+		StatusCode: status,
+		Body:       body,
+	}
+	if err != nil {
+		call.Error = RedactURL(err.Error())
+		// the url is already recorded,
+		// so only keep the cause of transport errors to avoid repeating it when they're replayed
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			call.Error = RedactURL(urlErr.Err.Error())
+		}
+	}
+	max := r.Max
+	if max <= 0 {
+		max = 100
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	if len(r.calls) > max {
+		r.calls = append(r.calls[:0], r.calls[len(r.calls)-max:]...)
+	}
+}
+
+// Calls returns the recorded calls, oldest first.
+func (r *Recorder) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedCall(nil), r.calls...)
+}
+
+// bundleManifest is the index of a bundle,
+// stored as manifest.json next to one file per response body.
+type bundleManifest struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Calls   []bundleCallRef `json:"calls"`
+}
+
+type bundleCallRef struct {
+	RecordedCall
+	BodyFile string `json:"body_file,omitempty"`
+}
+
+const bundleVersion = 1
+
+// WriteBundle writes the recorded calls to w as a zip archive.
+func (r *Recorder) WriteBundle(w io.Writer) error {
+	calls := r.Calls()
+	manifest := bundleManifest{
+		Version: bundleVersion,
+		Created: time.Now(),
+		Calls:   make([]bundleCallRef, len(calls)),
+	}
+	z := zip.NewWriter(w)
+	for i, call := range calls {
+		manifest.Calls[i].RecordedCall = call
+		if call.Body == nil {
+			continue
+		}
+		name := fmt.Sprintf("responses/%04d.json", i+1)
+		manifest.Calls[i].BodyFile = name
+		f, err := z.Create(name)
+		if err != nil {
+			return fmt.Errorf("census.Recorder.WriteBundle: %w", err)
+		}
+		if _, err := f.Write(call.Body); err != nil {
+			return fmt.Errorf("census.Recorder.WriteBundle: %w", err)
+		}
+	}
+	f, err := z.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("census.Recorder.WriteBundle: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return fmt.Errorf("census.Recorder.WriteBundle: %w", err)
+	}
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("census.Recorder.WriteBundle: %w", err)
+	}
+	return nil
+}
+
+// Replay serves recorded responses in place of census.
+// It implements [http.RoundTripper]:
+//
+//	replay, err := census.OpenBundle("testdata/census-bundle.zip")
+//	client := &census.Client{ServiceID: "example"}
+//	client.SetHTTPClient(&http.Client{Transport: replay})
+//
+// Requests are matched by URL with the service ID ignored.
+// When a URL was recorded more than once the responses are served in order,
+// and the last one is repeated after that.
+type Replay struct {
+	mu    sync.Mutex
+	calls map[string][]RecordedCall
+}
+
+// OpenBundle loads a bundle written by [Recorder.WriteBundle].
+func OpenBundle(name string) (*Replay, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("census.OpenBundle: %w", err)
+	}
+	return LoadBundle(bytes.NewReader(b), int64(len(b)))
+}
+
+// LoadBundle loads a bundle written by [Recorder.WriteBundle].
+func LoadBundle(r io.ReaderAt, size int64) (*Replay, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("census.LoadBundle: %w", err)
+	}
+	var manifest bundleManifest
+	if err := readZipJSON(z, "manifest.json", &manifest); err != nil {
+		return nil, fmt.Errorf("census.LoadBundle: %w", err)
+	}
+	if manifest.Version > bundleVersion {
+		return nil, fmt.Errorf("census.LoadBundle: bundle version %d is newer than the supported version %d", manifest.Version, bundleVersion)
+	}
+	replay := &Replay{calls: make(map[string][]RecordedCall)}
+	for _, ref := range manifest.Calls {
+		call := ref.RecordedCall
+		if ref.BodyFile != "" {
+			if call.Body, err = readZipFile(z, ref.BodyFile); err != nil {
+				return nil, fmt.Errorf("census.LoadBundle: %w", err)
+			}
+		}
+		key := replayKey(call.URL)
+		replay.calls[key] = append(replay.calls[key], call)
+	}
+	return replay, nil
+}
+
+func readZipFile(z *zip.Reader, name string) ([]byte, error) {
+	f, err := z.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func readZipJSON(z *zip.Reader, name string, v any) error {
+	b, err := readZipFile(z, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// replayKey normalizes a request URL so that recorded and replayed URLs compare equal.
+func replayKey(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return RedactURL(u)
+	}
+	return RedactURL(parsed.String())
+}
+
+// RoundTrip returns the next recorded response for req.
+// Calls that were recorded without a response return their recorded error.
+func (r *Replay) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := replayKey(req.URL.String())
+	r.mu.Lock()
+	calls := r.calls[key]
+	if len(calls) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("census.Replay: no recorded response for %s", key)
+	}
+	call := calls[0]
+	if len(calls) > 1 {
+		r.calls[key] = calls[1:]
+	}
+	r.mu.Unlock()
+
+	if call.StatusCode == 0 {
+		return nil, errors.New(call.Error)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", call.StatusCode, http.StatusText(call.StatusCode)),
+		StatusCode:    call.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(call.Body)),
+		ContentLength: int64(len(call.Body)),
+		Request:       req,
+	}, nil
+}
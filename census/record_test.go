@@ -0,0 +1,124 @@
+package census_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestRecordReplay(t *testing.T) {
+	// the first world query changes between calls, and zone fails to connect
+	worlds := []string{
+		`{"world_list":[{"world_id":"17","state":"online"}],"returned":1}`,
+		`{"world_list":[{"world_id":"17","state":"locked"}],"returned":1}`,
+	}
+	client := &census.Client{ServiceID: "secret"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/zone") {
+			return nil, errors.New("connection refused")
+		}
+		body := worlds[0]
+		worlds = worlds[1:]
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})})
+	limiter := census.NewLimiter(100, 100, 10)
+	t.Cleanup(limiter.Stop)
+	client.SetLimiter(limiter)
+	client.SetFailFast(10 * time.Second)
+	rec := &census.Recorder{All: true}
+	client.SetRecorder(rec)
+
+	ctx := context.Background()
+	var first, second worldList
+	if err := client.Get(ctx, ps2.PC, "world?world_id=17", &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Get(ctx, ps2.PC, "world?world_id=17", &second); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Get(ctx, ps2.PC, "zone?zone_id=2", &struct{}{}); err == nil {
+		t.Fatal("expected an error for the zone query")
+	}
+
+	calls := rec.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("got %d recorded calls; want 3", len(calls))
+	}
+	for _, call := range calls {
+		if strings.Contains(call.URL, "secret") || strings.Contains(call.Error, "secret") {
+			t.Errorf("got the service ID in recorded call %+v", call)
+		}
+	}
+	if calls[2].StatusCode != 0 || calls[2].Error != "connection refused" || calls[2].Body != nil {
+		t.Errorf("got %+v; want only the cause of the transport error", calls[2])
+	}
+
+	var bundle bytes.Buffer
+	if err := rec.WriteBundle(&bundle); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := census.LoadBundle(bytes.NewReader(bundle.Bytes()), int64(bundle.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the replaying client has a different service ID, which is ignored
+	replayed := &census.Client{ServiceID: "other"}
+	replayed.SetHTTPClient(&http.Client{Transport: replay})
+	replayed.SetLimiter(limiter)
+	replayed.SetFailFast(10 * time.Second)
+	for i, want := range []census.World{first.WorldList[0], second.WorldList[0], second.WorldList[0]} {
+		var got worldList
+		if err := replayed.Get(ctx, ps2.PC, "world?world_id=17", &got); err != nil {
+			t.Fatalf("replay %d: %v", i, err)
+		}
+		// the last response is repeated once the recorded ones run out
+		if len(got.WorldList) != 1 || got.WorldList[0].State != want.State {
+			t.Errorf("replay %d: got %+v; want %+v", i, got.WorldList, want)
+		}
+	}
+	if err := replayed.Get(ctx, ps2.PC, "zone?zone_id=2", &struct{}{}); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("got error %v; want the recorded transport error", err)
+	}
+	if err := replayed.Get(ctx, ps2.PC, "zone?zone_id=4", &struct{}{}); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("got error %v; want no recorded response", err)
+	}
+}
+
+func TestRecorderFailuresOnly(t *testing.T) {
+	client := staticClient(`{"error":"No data found."}`)
+	client.SetFailFast(10 * time.Second)
+	rec := &census.Recorder{Max: 2}
+	client.SetRecorder(rec)
+	for _, query := range []string{"a", "b", "c"} {
+		client.Get(context.Background(), ps2.PC, query+"?c:limit=1", &struct{}{})
+	}
+	calls := rec.Calls()
+	if len(calls) != 2 || !strings.HasSuffix(calls[0].URL, "/b?c:limit=1") || !strings.HasSuffix(calls[1].URL, "/c?c:limit=1") {
+		t.Fatalf("got %+v; want the last 2 failed calls", calls)
+	}
+	if calls[1].StatusCode != http.StatusOK || calls[1].Error == "" || string(calls[1].Body) != `{"error":"No data found."}` {
+		t.Errorf("got %+v; want the census error and its body", calls[1])
+	}
+
+	ok := staticClient(`{"world_list":[],"returned":0}`)
+	ok.SetRecorder(rec)
+	ok.Get(context.Background(), ps2.PC, "world", &worldList{})
+	if len(rec.Calls()) != 2 {
+		t.Error("got a successful call recorded without All")
+	}
+}
+
+func TestLoadBundleInvalid(t *testing.T) {
+	if _, err := census.LoadBundle(strings.NewReader("not a zip"), 9); err == nil {
+		t.Error("expected an error for a file that isn't a bundle")
+	}
+}
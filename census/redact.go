@@ -0,0 +1,60 @@
+package census
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// serviceIDSegment matches the service ID path segment of a census URL, e.g. "/s:example/".
+var serviceIDSegment = regexp.MustCompile(`/s:[^/?#]*`)
+
+// redactedServiceID replaces service IDs in display-safe URLs.
+const redactedServiceID = "/s:REDACTED"
+
+// RedactURL returns u with the service ID replaced,
+// keeping the rest of the URL intact.
+//
+//	https://census.daybreakgames.com/s:mykey/get/ps2:v2/world
+//	https://census.daybreakgames.com/s:REDACTED/get/ps2:v2/world
+//
+// The client already redacts the URLs it logs and the errors it returns.
+func RedactURL(u string) string {
+	return serviceIDSegment.ReplaceAllString(u, redactedServiceID)
+}
+
+// DisplayURL returns the URL that c would request for query in env,
+// with the service ID redacted so that it is safe to show in shared logs or chat.
+func (c Client) DisplayURL(env ps2.Environment, query string) string {
+	return RedactURL(c.requestURL(env, query))
+}
+
+func (c Client) requestURL(env ps2.Environment, query string) string {
+	return fmt.Sprintf("%s/s:%s/get/%s/%s", apiBase, c.ServiceID, Namespace(env), query)
+}
+
+// errInvalidServiceID is returned without making a request when the service ID would change the meaning of the request URL.
+var errInvalidServiceID = permanentError{errors.New("invalid service ID")}
+
+// checkServiceID guards against service IDs that would alter the request path,
+// such as a key accidentally pasted with a trailing slash or query string.
+func checkServiceID(id string) error {
+	if id == "" || strings.ContainsAny(id, "/?#% \t\r\n") {
+		return errInvalidServiceID
+	}
+	return nil
+}
+
+// redactError removes the service ID from the URL of a failed http request,
+// since *url.Error includes the full URL in its message.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = RedactURL(urlErr.URL)
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package census
+
+import (
+	"sync"
+	"time"
+)
+
+// retryBudgetWindow is how far back the retry budget counts requests.
+const retryBudgetWindow = time.Minute
+
+var retryBudget = &retryBudgetTracker{
+	ratio:   0.2,
+	minimum: 10,
+}
+
+// RetryBudget limits automatic retries to a fraction of recent request volume,
+// shared by every Client in the process without its own budget set with [Client.SetRetryBudget].
+// Retries are allowed while the retries sent in the last minute are fewer than
+// minimum plus ratio times the number of new calls in the same minute.
+//
+// Retries spend the same rate limit tokens as new requests,
+// so without a budget a census outage turns every call into several requests and crowds out new work.
+// Once the budget is spent calls return the error of their last attempt instead of retrying.
+//
+// The default is a ratio of 0.2 with a minimum of 10.
+func RetryBudget(ratio float64, minimum int) {
+	retryBudget.mu.Lock()
+	defer retryBudget.mu.Unlock()
+	retryBudget.ratio = max(ratio, 0)
+	retryBudget.minimum = max(minimum, 0)
+}
+
+// SetRetryBudget gives c its own retry budget instead of the package budget set with [RetryBudget],
+// so that retries during an outage of one environment or service ID don't spend the budget of other clients.
+// The ratio and minimum work the same as for [RetryBudget].
+func (c *Client) SetRetryBudget(ratio float64, minimum int) {
+	c.budget = &retryBudgetTracker{
+		ratio:   max(ratio, 0),
+		minimum: max(minimum, 0),
+	}
+}
+
+// retryBudget returns the retry budget that applies to c.
+func (c Client) retryBudget() *retryBudgetTracker {
+	if c.budget != nil {
+		return c.budget
+	}
+	return retryBudget
+}
+
+type retryBudgetTracker struct {
+	mu      sync.Mutex
+	ratio   float64
+	minimum int
+
+	calls   []time.Time // new calls within retryBudgetWindow, oldest first
+	retries []time.Time // retries sent within retryBudgetWindow, oldest first
+
+	retriesSent   int
+	retriesDenied int
+}
+
+// call records a new call, which adds ratio to the budget.
+func (b *retryBudgetTracker) call() {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(now)
+	b.calls = append(b.calls, now)
+}
+
+// withdraw reports whether a retry may be sent, and spends from the budget if it can.
+func (b *retryBudgetTracker) withdraw() bool {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(now)
+	if b.available() <= 0 {
+		b.retriesDenied++
+		return false
+	}
+	b.retries = append(b.retries, now)
+	b.retriesSent++
+	return true
+}
+
+// available is the number of retries that may be sent right now.
+// b.mu must be held.
+func (b *retryBudgetTracker) available() int {
+	return b.minimum + int(b.ratio*float64(len(b.calls))) - len(b.retries)
+}
+
+func (b *retryBudgetTracker) prune(now time.Time) {
+	cutoff := now.Add(-retryBudgetWindow)
+	b.calls = pruneTimes(b.calls, cutoff)
+	b.retries = pruneTimes(b.retries, cutoff)
+}
+
+// pruneTimes drops the times before cutoff from the front of times.
+func pruneTimes(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,86 @@
+package census_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// retryingClient returns a copy of DefaultClient, which retries failed requests,
+// that sends its requests to respond.
+// It fails fast so that the failures it's tested with don't trip the package circuit breaker.
+func retryingClient(t *testing.T, respond func(n int) string) (client *census.Client, requests *atomic.Int32) {
+	requests = &atomic.Int32{}
+	c := *census.DefaultClient
+	client = &c
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := requests.Add(1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(respond(int(n)))),
+			Request:    req,
+		}, nil
+	})})
+	limiter := census.NewLimiter(100, 100, 10)
+	t.Cleanup(limiter.Stop)
+	client.SetLimiter(limiter)
+	client.SetFailFast(10 * time.Second)
+	return client, requests
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	client, requests := retryingClient(t, func(int) string { return `{"error":"service_unavailable"}` })
+	client.SetRetryBudget(0, 1)
+
+	var r struct{}
+	if err := client.Get(context.Background(), ps2.PC, "world?world_id=1", &r); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("first call sent %d requests; want 2, the request and the one retry in the budget", n)
+	}
+	if err := client.Get(context.Background(), ps2.PC, "world?world_id=1", &r); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("got %d requests after the second call; want 3, since the budget is spent", n)
+	}
+}
+
+func TestRetryBudgetRetryAfter(t *testing.T) {
+	client, requests := retryingClient(t, func(n int) string {
+		switch n {
+		case 1:
+			// rate limited responses are retried after a minute, which is too long to wait
+			return `{"error":"Missing Service ID."}`
+		case 2:
+			return `{"error":"service_unavailable"}`
+		default:
+			return `{"world_list":[],"returned":0}`
+		}
+	})
+	client.SetRetryBudget(0, 1)
+
+	var r struct{}
+	err := client.Get(context.Background(), ps2.PC, "world?world_id=1", &r)
+	if !census.IsRateLimited(err) {
+		t.Fatalf("got %v; want a rate limit error", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests; want 1, since the retry would have to wait too long", n)
+	}
+	// the retry that was never sent must not have spent the budget
+	if err := client.Get(context.Background(), ps2.PC, "world?world_id=1", &r); err != nil {
+		t.Errorf("expected the retry to succeed; got %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("got %d requests; want 3", n)
+	}
+}
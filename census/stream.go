@@ -0,0 +1,162 @@
+package census
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// streamDecoder is implemented by results that decode the response body as it's read,
+// instead of after the whole body has been read into memory.
+type streamDecoder interface {
+	decodeStream(io.Reader) (returned int, err error)
+
+	// started reports whether any rows were decoded,
+	// after which a failed request can't be retried without repeating rows.
+	started() bool
+}
+
+// streamList decodes the rows of a census list one at a time and passes each one to fn.
+type streamList[T any] struct {
+	list    string // list is the key of the rows, like "map_hex_list"
+	fn      func(T) error
+	rows    int
+	stopped error // stopped is the error returned by fn
+}
+
+func (s *streamList[T]) started() bool { return s.rows > 0 }
+
+func (s *streamList[T]) decodeStream(r io.Reader) (returned int, err error) {
+	// failures before the first row are handled like any other bad response,
+	// but once rows were passed to fn a retry would pass them again
+	defer func() {
+		if err != nil && s.started() {
+			err = permanentError{err}
+		}
+	}()
+
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, errBadJSON(err)
+	}
+	var response errorResponse
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return returned, errBadJSON(err)
+		}
+		key, _ := t.(string)
+		switch key {
+		case s.list:
+			if err := s.decodeRows(dec); err != nil {
+				return returned, err
+			}
+			if s.stopped != nil {
+				// the rest of the body is left unread;
+				// closing it drops the connection
+				return returned, nil
+			}
+		case "returned":
+			err = dec.Decode(&returned)
+		case "error":
+			err = dec.Decode(&response.Error)
+		case "errorCode":
+			err = dec.Decode(&response.ErrorCode)
+		case "errorMessage":
+			err = dec.Decode(&response.ErrorMessage)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return returned, errBadJSON(err)
+		}
+	}
+	return returned, response.err()
+}
+
+// decodeRows decodes the list at the current position of dec.
+func (s *streamList[T]) decodeRows(dec *json.Decoder) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return errBadJSON(err)
+	}
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return errBadJSON(err)
+		}
+		var row T
+		if err := decodeJSON(raw, &row); err != nil {
+			return errBadJSON(err)
+		}
+		s.rows++
+		if err := s.fn(row); err != nil {
+			s.stopped = err
+			return nil
+		}
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return errBadJSON(err)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected %s; got %v", delim, t)
+	}
+	return nil
+}
+
+// Stream performs a census query and calls fn with each row of the result as it's decoded,
+// so that the whole response never has to be held in memory.
+// The rows are read from the list named after the collection of the query,
+// like "map_hex_list" for a query of "map_hex?zone_id=2".
+//
+// Streamed responses skip the client's response cache and truncation policy.
+// Requests are retried as usual until the first row is passed to fn;
+// after that a failure is returned instead of repeating rows.
+// If fn returns an error, Stream stops reading and returns it.
+func Stream[T any](ctx context.Context, client *Client, env ps2.Environment, query string, fn func(T) error) (Count, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	s := &streamList[T]{list: queryCollection(query) + "_list", fn: fn}
+	count, err := client.getRetry(ctx, env, query, s)
+	if err != nil {
+		return count, fmt.Errorf("census.Stream: %w", err)
+	}
+	if s.stopped != nil {
+		return count, s.stopped
+	}
+	return count, nil
+}
+
+// StreamCollection calls fn with every row of a collection as it's decoded,
+// requesting the collection one page at a time.
+// It's the streaming version of [LoadCollection] for large collections like map_hex,
+// where loading every row at once uses far more memory than processing them one at a time.
+// Rows aren't validated.
+//
+// If fn returns an error, StreamCollection stops and returns it.
+func StreamCollection[T collectionNamer](ctx context.Context, client *Client, fn func(T) error, filters ...Filter) error {
+	var n T
+	collection := n.CollectionName()
+	filter := filterQuery(filters)
+	const perPage = 5000
+	for start, more := 0, true; more; start += perPage {
+		count, err := Stream(ctx, client, ps2.PC, fmt.Sprintf("%s?%sc:limit=%d&c:start=%d", collection, filter, perPage, start), fn)
+		if err != nil {
+			return err
+		}
+		more = count.Truncated()
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package census_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// hexServer answers map_hex queries with total rows split into pages of c:limit.
+type hexServer struct {
+	total    int
+	requests int
+}
+
+func (s *hexServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests++
+	q := req.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("c:limit"))
+	start, _ := strconv.Atoi(q.Get("c:start"))
+	var rows []string
+	for i := start; i < min(start+limit, s.total); i++ {
+		rows = append(rows, fmt.Sprintf(`{"zone_id":"2","map_region_id":"%d","x":"%d","y":"0","hex_type":"0"}`, i/10, i))
+	}
+	// the list comes before other fields so that it has to be skipped or streamed
+	body := fmt.Sprintf(`{"map_hex_list":[%s],"unknown":{"nested":[1,2]},"returned":%d}`, strings.Join(rows, ","), len(rows))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestStreamCollection(t *testing.T) {
+	srv := &hexServer{total: 12000}
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: srv})
+
+	var n int
+	err := census.StreamCollection(context.Background(), client, func(h census.MapHex) error {
+		if h.X != n {
+			return fmt.Errorf("got hex %d at position %d", h.X, n)
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != srv.total || srv.requests != 3 {
+		t.Errorf("got %d rows in %d requests; want %d rows in 3 requests", n, srv.requests, srv.total)
+	}
+
+	stop := errors.New("stop")
+	n = 0
+	err = census.StreamCollection(context.Background(), client, func(census.MapHex) error {
+		if n++; n == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || n != 10 {
+		t.Errorf("got %d rows and error %v; want 10 rows and the callback error", n, err)
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"error":"Bad request syntax."}`)),
+			Request:    req,
+		}, nil
+	})})
+	_, err := census.Stream(context.Background(), client, ps2.PC, "map_hex?zone_id=2", func(census.MapHex) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "bad request syntax") {
+		t.Errorf("got error %v; want bad request syntax", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
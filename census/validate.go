@@ -0,0 +1,113 @@
+package census
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Validatable is implemented by collection rows that can check themselves for bad data,
+// such as zero IDs or missing locations.
+// [LoadCollection] checks every row of types that implement it.
+type Validatable interface {
+	Validate() error
+}
+
+// ValidationReport lists the rows of a collection that failed validation.
+type ValidationReport struct {
+	Collection string
+	Rows       int // Rows is the number of rows checked
+	Problems   []RowProblem
+}
+
+// RowProblem is a row that failed validation.
+// Index is the position of the row in the loaded collection.
+type RowProblem struct {
+	Index int
+	Err   error
+}
+
+// OK reports whether every row passed validation.
+func (r ValidationReport) OK() bool { return len(r.Problems) == 0 }
+
+func (r ValidationReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("%s: %d rows ok", r.Collection, r.Rows)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d of %d rows failed validation", r.Collection, len(r.Problems), r.Rows)
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "\n\trow %d: %v", p.Index, p.Err)
+	}
+	return b.String()
+}
+
+// Validate checks every row of rows that implements [Validatable].
+func Validate[T any](collection string, rows []T) ValidationReport {
+	report := ValidationReport{Collection: collection, Rows: len(rows)}
+	for i, row := range rows {
+		v, ok := any(row).(Validatable)
+		if !ok {
+			continue
+		}
+		if err := v.Validate(); err != nil {
+			report.Problems = append(report.Problems, RowProblem{Index: i, Err: err})
+		}
+	}
+	return report
+}
+
+// LoadCollectionReport is the same as [LoadCollection],
+// but returns the validation report for the loaded rows instead of logging it.
+// Rows that fail validation are still added to collected.
+func LoadCollectionReport[T collectionNamer](ctx context.Context, client *Client, collected *[]T, filters ...Filter) (ValidationReport, error) {
+	start := len(*collected)
+	if err := loadCollection(ctx, client, collected, filters...); err != nil {
+		return ValidationReport{}, err
+	}
+	var n T
+	report := Validate(n.CollectionName(), (*collected)[start:])
+	for i := range report.Problems {
+		report.Problems[i].Index += start
+	}
+	return report, nil
+}
+
+// CheckFacilityLinks reports links that reference facilities missing from facilities,
+// which psmap.Summarize and other lattice code would otherwise fail on much later.
+// Facilities are usually loaded from the map_region collection with [Facility] or [MapRegion].
+func CheckFacilityLinks[F interface{ Facility() ps2.FacilityID }](links []FacilityLink, facilities []F) ValidationReport {
+	known := make(map[ps2.FacilityID]bool, len(facilities))
+	for _, f := range facilities {
+		if id := f.Facility(); id != 0 {
+			known[id] = true
+		}
+	}
+	report := ValidationReport{Collection: FacilityLink{}.CollectionName(), Rows: len(links)}
+	for i, link := range links {
+		var errs []error
+		if !known[link.FacilityIDA] {
+			errs = append(errs, fmt.Errorf("facility_id_a %d does not exist", link.FacilityIDA))
+		}
+		if !known[link.FacilityIDB] {
+			errs = append(errs, fmt.Errorf("facility_id_b %d does not exist", link.FacilityIDB))
+		}
+		if err := errors.Join(errs...); err != nil {
+			report.Problems = append(report.Problems, RowProblem{Index: i, Err: err})
+		}
+	}
+	return report
+}
+
+var (
+	errZeroID          = errors.New("missing id")
+	errMissingLocation = errors.New("missing location")
+)
+
+// zeroID returns an error for a required ID field that is 0.
+func zeroID(field string) error {
+	return fmt.Errorf("%s: %w", field, errZeroID)
+}
@@ -0,0 +1,218 @@
+package census
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// ItemToWeapon links an item to the weapon it equips.
+// The item ID is what events like Death report as AttackerWeaponID.
+type ItemToWeapon struct {
+	ItemID   ps2.ItemID   `json:"item_id,string"`
+	WeaponID ps2.WeaponID `json:"weapon_id,string"`
+}
+
+func (ItemToWeapon) CollectionName() string { return "item_to_weapon" }
+
+func (i ItemToWeapon) Validate() error {
+	if i.ItemID == 0 {
+		return zeroID("item_id")
+	}
+	if i.WeaponID == 0 {
+		return zeroID("weapon_id")
+	}
+	return nil
+}
+
+// Weapon holds the handling stats shared by every fire mode of a weapon.
+type Weapon struct {
+	WeaponID              ps2.WeaponID `json:"weapon_id,string"`
+	WeaponGroupID         int          `json:"weapon_group_id,string"`
+	TurnModifier          float64      `json:"turn_modifier,string"`
+	MoveModifier          float64      `json:"move_modifier,string"`
+	SprintRecoveryMs      int          `json:"sprint_recovery_ms,string"`
+	EquipMs               int          `json:"equip_ms,string"`
+	UnequipMs             int          `json:"unequip_ms,string"`
+	ToIronSightsMs        int          `json:"to_iron_sights_ms,string"`
+	FromIronSightsMs      int          `json:"from_iron_sights_ms,string"`
+	HeatCapacity          int          `json:"heat_capacity,string"`
+	HeatBleedOffRate      float64      `json:"heat_bleed_off_rate,string"`
+	HeatOverheatPenaltyMs int          `json:"heat_overheat_penalty_ms,string"`
+}
+
+func (Weapon) CollectionName() string { return "weapon" }
+
+func (w Weapon) Validate() error {
+	if w.WeaponID == 0 {
+		return zeroID("weapon_id")
+	}
+	return nil
+}
+
+// WeaponDatasheet holds the summary stats shown on the in-game weapon info screen.
+// Range is a description like "Medium-Long" rather than a distance.
+type WeaponDatasheet struct {
+	ItemID         ps2.ItemID        `json:"item_id,string"`
+	DirectDamage   int               `json:"direct_damage,string"`
+	IndirectDamage int               `json:"indirect_damage,string"`
+	Damage         int               `json:"damage,string"`
+	DamageMin      int               `json:"damage_min,string"`
+	DamageMax      int               `json:"damage_max,string"`
+	FireCone       float64           `json:"fire_cone,string"`
+	FireRateMs     int               `json:"fire_rate_ms,string"`
+	ReloadMs       int               `json:"reload_ms,string"`
+	ClipSize       int               `json:"clip_size,string"`
+	Capacity       int               `json:"capacity,string"`
+	Range          ps2.Localization  `json:"range"`
+	ShowClipSize   stringNumericBool `json:"show_clip_size"`
+	ShowFireModes  stringNumericBool `json:"show_fire_modes"`
+	ShowRange      stringNumericBool `json:"show_range"`
+}
+
+func (WeaponDatasheet) CollectionName() string { return "weapon_datasheet" }
+
+func (d WeaponDatasheet) Validate() error {
+	if d.ItemID == 0 {
+		return zeroID("item_id")
+	}
+	return nil
+}
+
+// FireMode is a row of the fire_mode_2 collection,
+// which holds the damage, recoil, and cone of fire of a single fire mode.
+// Distances are in meters and durations in milliseconds.
+type FireMode struct {
+	FireModeID              ps2.FireModeID   `json:"fire_mode_id,string"`
+	FireModeTypeID          int              `json:"fire_mode_type_id,string"`
+	Description             ps2.Localization `json:"description"`
+	ArmorPenetration        float64          `json:"armor_penetration,string"`
+	MaxDamage               int              `json:"max_damage,string"`
+	MaxDamageRange          float64          `json:"max_damage_range,string"`
+	MinDamage               int              `json:"min_damage,string"`
+	MinDamageRange          float64          `json:"min_damage_range,string"`
+	MaxDamageIndirect       float64          `json:"max_damage_ind,string"`
+	MaxDamageIndirectRadius float64          `json:"max_damage_ind_radius,string"`
+	MinDamageIndirect       float64          `json:"min_damage_ind,string"`
+	MinDamageIndirectRadius float64          `json:"min_damage_ind_radius,string"`
+	HeadMultiplier          float64          `json:"damage_head_multiplier,string"`
+	LegsMultiplier          float64          `json:"damage_legs_multiplier,string"`
+	ShieldBypassPct         float64          `json:"shield_bypass_pct,string"`
+	FireRefireMs            int              `json:"fire_refire_ms,string"`
+	FireBurstCount          int              `json:"fire_burst_count,string"`
+	FireAmmoPerShot         int              `json:"fire_ammo_per_shot,string"`
+	FirePelletsPerShot      int              `json:"fire_pellets_per_shot,string"`
+	ReloadTimeMs            int              `json:"reload_time_ms,string"`
+	ReloadChamberMs         int              `json:"reload_chamber_ms,string"`
+	ProjectileSpeedOverride float64          `json:"projectile_speed_override,string"`
+	CofRecoil               float64          `json:"cof_recoil,string"`
+	CofScalar               float64          `json:"cof_scalar,string"`
+	CofScalarMoving         float64          `json:"cof_scalar_moving,string"`
+	RecoilAngleMin          float64          `json:"recoil_angle_min,string"`
+	RecoilAngleMax          float64          `json:"recoil_angle_max,string"`
+	RecoilMagnitudeMin      float64          `json:"recoil_magnitude_min,string"`
+	RecoilMagnitudeMax      float64          `json:"recoil_magnitude_max,string"`
+	RecoilHorizontalMin     float64          `json:"recoil_horizontal_min,string"`
+	RecoilHorizontalMax     float64          `json:"recoil_horizontal_max,string"`
+	RecoilFirstShotModifier float64          `json:"recoil_first_shot_modifier,string"`
+	ZoomDefault             float64          `json:"zoom_default,string"`
+	MoveModifier            float64          `json:"move_modifier,string"`
+	TurnModifier            float64          `json:"turn_modifier,string"`
+}
+
+func (FireMode) CollectionName() string { return "fire_mode_2" }
+
+func (f FireMode) Validate() error {
+	if f.FireModeID == 0 {
+		return zeroID("fire_mode_id")
+	}
+	return nil
+}
+
+// WeaponData holds the static weapon collections, indexed by ID,
+// for resolving the AttackerWeaponID of events to names and stats.
+type WeaponData struct {
+	Items       map[ps2.ItemID]Item
+	ItemWeapons map[ps2.ItemID]ps2.WeaponID
+	Weapons     map[ps2.WeaponID]Weapon
+	Datasheets  map[ps2.ItemID]WeaponDatasheet
+	FireModes   map[ps2.FireModeID]FireMode
+}
+
+// weaponItemType is the item_type_id of weapon items.
+const weaponItemType ps2.ItemTypeID = 26
+
+// LoadWeaponData loads every weapon item and the weapon collections.
+//
+//	weapons, err := census.LoadWeaponData(ctx, client)
+//	...
+//	name := weapons.Name(death.AttackerWeaponID)
+func LoadWeaponData(ctx context.Context, client *Client) (WeaponData, error) {
+	var (
+		items       []Item
+		itemWeapons []ItemToWeapon
+		weapons     []Weapon
+		datasheets  []WeaponDatasheet
+		fireModes   []FireMode
+	)
+	if err := LoadCollection(ctx, client, &items, Eq("item_type_id", weaponItemType)); err != nil {
+		return WeaponData{}, fmt.Errorf("census.LoadWeaponData: %w", err)
+	}
+	if err := LoadCollection(ctx, client, &itemWeapons); err != nil {
+		return WeaponData{}, fmt.Errorf("census.LoadWeaponData: %w", err)
+	}
+	if err := LoadCollection(ctx, client, &weapons); err != nil {
+		return WeaponData{}, fmt.Errorf("census.LoadWeaponData: %w", err)
+	}
+	if err := LoadCollection(ctx, client, &datasheets); err != nil {
+		return WeaponData{}, fmt.Errorf("census.LoadWeaponData: %w", err)
+	}
+	if err := LoadCollection(ctx, client, &fireModes); err != nil {
+		return WeaponData{}, fmt.Errorf("census.LoadWeaponData: %w", err)
+	}
+
+	data := WeaponData{
+		Items:       make(map[ps2.ItemID]Item, len(items)),
+		ItemWeapons: make(map[ps2.ItemID]ps2.WeaponID, len(itemWeapons)),
+		Weapons:     make(map[ps2.WeaponID]Weapon, len(weapons)),
+		Datasheets:  make(map[ps2.ItemID]WeaponDatasheet, len(datasheets)),
+		FireModes:   make(map[ps2.FireModeID]FireMode, len(fireModes)),
+	}
+	for _, i := range items {
+		data.Items[i.ItemID] = i
+	}
+	for _, i := range itemWeapons {
+		data.ItemWeapons[i.ItemID] = i.WeaponID
+	}
+	for _, w := range weapons {
+		data.Weapons[w.WeaponID] = w
+	}
+	for _, d := range datasheets {
+		data.Datasheets[d.ItemID] = d
+	}
+	for _, f := range fireModes {
+		data.FireModes[f.FireModeID] = f
+	}
+	return data, nil
+}
+
+// Name returns the name players know the weapon item id by (see [Item.DisplayName]),
+// or an empty string if it isn't a known weapon.
+func (d WeaponData) Name(id ps2.ItemID) string {
+	item, ok := d.Items[id]
+	if !ok {
+		return ""
+	}
+	return item.DisplayName()
+}
+
+// Weapon returns the weapon equipped by item id.
+func (d WeaponData) Weapon(id ps2.ItemID) (Weapon, bool) {
+	weaponID, ok := d.ItemWeapons[id]
+	if !ok {
+		return Weapon{}, false
+	}
+	w, ok := d.Weapons[weaponID]
+	return w, ok
+}
@@ -10,3 +10,10 @@ type World struct {
 }
 
 func (World) CollectionName() string { return "world" }
+
+func (w World) Validate() error {
+	if w.WorldID == 0 {
+		return zeroID("world_id")
+	}
+	return nil
+}
@@ -2,8 +2,8 @@ package census
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -57,6 +57,51 @@ func GetFacilityControlEvents(ctx context.Context, c *Client, env ps2.Environmen
 	return
 }
 
+// GetMetagameEvents returns METAGAME world events (alert starts and ends) after the given time, oldest first.
+// A nil after returns the most recent events census has.
+func GetMetagameEvents(ctx context.Context, c *Client, env ps2.Environment, after *time.Time, worlds ...ps2.WorldID) ([]event.MetagameEvent, error) {
+	if c == nil {
+		c = DefaultClient
+	}
+	var response worldEventResponse
+	q := "world_event?type=METAGAME&c:limit=1000"
+	if after != nil {
+		q += "&after=" + strconv.FormatInt(after.Unix(), 10)
+	}
+	if worlds != nil {
+		s := make([]string, 0, len(worlds))
+		for _, w := range worlds {
+			s = append(s, w.StringID())
+		}
+		q += "&world_id=" + strings.Join(s, ",")
+	}
+	if err := c.Get(ctx, env, q, &response); err != nil {
+		return nil, fmt.Errorf("census.GetMetagameEvents: %w", err)
+	}
+
+	// world_event rows don't include an event_name,
+	// so they can't be converted with Raw.Event
+	events := make([]event.MetagameEvent, 0, len(response.WorldEventList))
+	for _, r := range response.WorldEventList {
+		events = append(events, event.MetagameEvent{
+			ExperienceBonus:        r.ExperienceBonus,
+			FactionNC:              r.FactionNc,
+			FactionTR:              r.FactionTr,
+			FactionVS:              r.FactionVs,
+			InstanceID:             r.InstanceId,
+			MetagameEventID:        r.MetagameEventId,
+			MetagameEventState:     r.MetagameEventState,
+			MetagameEventStateName: r.MetagameEventStateName,
+			Timestamp:              time.Unix(r.Timestamp, 0).UTC(),
+			WorldID:                r.WorldId,
+			ZoneID:                 r.ZoneId,
+		})
+	}
+	// census returns the newest events first
+	slices.SortStableFunc(events, func(a, b event.MetagameEvent) int { return a.Timestamp.Compare(b.Timestamp) })
+	return events, nil
+}
+
 type worldEventResponse struct {
 	WorldEventList []struct {
 		event.Raw
@@ -66,32 +111,31 @@ type worldEventResponse struct {
 	Returned int `json:"returned"`
 }
 
-// duration represents a census duration for unmarshaling, which is in seconds.
-type duration time.Duration
+// UnixTime is a time encoded by census as a (usually quoted) number of seconds since the unix epoch.
+type UnixTime time.Time
+
+func (t UnixTime) Time() time.Time { return time.Time(t) }
 
-func (d *duration) UnmarshalJSON(b []byte) error {
-	var dd time.Duration
-	if err := json.Unmarshal(b, &dd); err != nil {
-		return fmt.Errorf("unmarshal census.duration: %w", err)
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	if time.Time(t).IsZero() {
+		return []byte(`"0"`), nil
 	}
-	*d = duration(dd) * 1e9
-	return nil
+	return []byte(strconv.Quote(strconv.FormatInt(time.Time(t).Unix(), 10))), nil
 }
-func (d *duration) Use(dd time.Duration) {
-	*d = duration(dd)
-}
-
-func (d duration) Duration() time.Duration { return time.Duration(d) }
-
-type timestamp time.Time
 
-func (t *timestamp) UnmarshalJSON(b []byte) error {
-	var i int64
-	if err := json.Unmarshal(b, &i); err != nil {
-		return fmt.Errorf("fisu.timestamp.UnmarshalJSON: %w", err)
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
 	}
-	*t = timestamp(time.Unix(i, 0).UTC())
+	if s == "" || s == "0" || s == "null" {
+		*t = UnixTime{}
+		return nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("census.UnixTime.UnmarshalJSON: %w", err)
+	}
+	*t = UnixTime(time.Unix(i, 0).UTC())
 	return nil
 }
-
-func (t timestamp) Time() time.Time { return time.Time(t) }
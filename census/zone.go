@@ -1,7 +1,6 @@
 package census
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/Travis-Britz/ps2"
@@ -37,11 +36,18 @@ type Zone struct {
 
 func (Zone) CollectionName() string { return "zone" }
 
+func (z Zone) Validate() error {
+	if z.ZoneID == 0 {
+		return zeroID("zone_id")
+	}
+	return nil
+}
+
 // UnmarshalJSON implements json.Unmarshaler
 func (z *Zone) UnmarshalJSON(b []byte) error {
 	type zone Zone // aliased type to prevent recursion
 	var shadow zone
-	if err := json.Unmarshal(b, &shadow); err != nil {
+	if err := decodeJSON(b, &shadow); err != nil {
 		return fmt.Errorf("census.Zone.UnmarshalJSON: %w", err)
 	}
 
@@ -54,3 +60,26 @@ func (z *Zone) UnmarshalJSON(b []byte) error {
 	*z = Zone(shadow)
 	return nil
 }
+
+// ZoneInfo converts z for use with [ps2.RegisterZone].
+func (z Zone) ZoneInfo() ps2.ZoneInfo {
+	name := z.Name[ps2.En]
+	if name == "" {
+		name = z.Name.String()
+	}
+	return ps2.ZoneInfo{
+		ContinentID: z.ContinentID,
+		ZoneID:      z.ZoneID,
+		GeometryID:  z.GeometryID,
+		Dynamic:     bool(z.Dynamic),
+		Name:        name,
+	}
+}
+
+// RegisterZones adds zones from the census zone collection to the ps2 zone registry,
+// so that the zone conversion methods of package ps2 know about zones added after the package was released.
+func RegisterZones(zones ...Zone) {
+	for _, z := range zones {
+		ps2.RegisterZone(z.ZoneInfo())
+	}
+}
@@ -0,0 +1,62 @@
+package census_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+func TestRegisterZones(t *testing.T) {
+	var zones []census.Zone
+	err := json.Unmarshal([]byte(`[
+		{"zone_id":"96","code":"VR_Indar","hex_size":"200","name":{"en":"VR Training"},"geometry_id":"2","dynamic":"0"},
+		{"zone_id":"401","code":"NewZone","hex_size":"200","name":{"en":"New Zone"},"geometry_id":"402","dynamic":"1"}
+	]`), &zones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	census.RegisterZones(zones...)
+
+	if c, err := ps2.ZoneID(401).ContinentID(); err != nil || c != 402 {
+		t.Errorf("got continent %v, %v for zone 401; want 402", c, err)
+	}
+	if c, err := ps2.GeometryID(402).ContinentID(); err != nil || c != 402 {
+		t.Errorf("got continent %v, %v for geometry 402; want 402", c, err)
+	}
+	if got := ps2.ContinentID(402).String(); got != "New Zone" {
+		t.Errorf("got name %q; want %q", got, "New Zone")
+	}
+	// VR training shares its geometry with Indar, which was registered first
+	if c, err := ps2.GeometryID(2).ContinentID(); err != nil || c != ps2.Indar {
+		t.Errorf("got continent %v, %v for geometry 2; want Indar", c, err)
+	}
+	if c, err := ps2.ZoneID(96).ContinentID(); err != nil || c != 96 {
+		t.Errorf("got continent %v, %v for zone 96; want 96", c, err)
+	}
+}
+
+func TestZoneRoundTrip(t *testing.T) {
+	// dynamic is "0" or "1" from census but true or false once marshaled,
+	// and zones saved by a program have to decode to the same continent
+	var zone census.Zone
+	err := json.Unmarshal([]byte(`{"zone_id":"364","code":"Sanctuary","hex_size":"200","geometry_id":"362","dynamic":"1"}`), &zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !zone.ZoneInfo().Dynamic || zone.ContinentID != 362 {
+		t.Fatalf("got continent %d, dynamic %v; want 362, true", zone.ContinentID, zone.ZoneInfo().Dynamic)
+	}
+	b, err := json.Marshal(zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded census.Zone
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("%s: %v", b, err)
+	}
+	if decoded.ZoneInfo() != zone.ZoneInfo() {
+		t.Errorf("got %+v from %s; want %+v", decoded.ZoneInfo(), b, zone.ZoneInfo())
+	}
+}
@@ -10,10 +10,12 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Travis-Britz/ps2"
 	"github.com/Travis-Britz/ps2/census"
@@ -26,11 +28,14 @@ var config = struct {
 	PlanetsideEnvironment     ps2.Environment
 	PlanetsideCharacterIDs    []ps2.CharacterID
 	PlanetsideWorldID         ps2.WorldID
+	MetricsAddr               string
 }{
 	PlanetsideCensusServiceID: "example",
 }
 
-func init() {
+// configure parses flags and looks up the players to track.
+// It isn't done in init so that tests can run without the flags being parsed.
+func configure() {
 	var envString string
 	var players characterArray
 	var world int
@@ -40,6 +45,7 @@ func init() {
 	flag.Var(&players, "player", "Player to track")
 	flag.IntVar(&world, "world", 0, "World ID to subscribe to")
 	flag.BoolVar(&verbose, "v", false, "Enable verbose log output")
+	flag.StringVar(&config.MetricsAddr, "metrics", "", "Serve Prometheus metrics on this address (e.g. :9100) and reconnect on errors")
 	flag.Parse()
 
 	if verbose {
@@ -48,6 +54,7 @@ func init() {
 
 	config.PlanetsideWorldID = ps2.WorldID(world)
 	censusClient := &census.Client{ServiceID: config.PlanetsideCensusServiceID}
+	censusClient.SetFailFast(10 * time.Second)
 
 	switch envString {
 	case "pc":
@@ -68,6 +75,7 @@ func init() {
 }
 
 func main() {
+	configure()
 	ctx, shutdown := context.WithCancelCause(context.Background())
 	go func() {
 		stop := make(chan os.Signal, 1)
@@ -105,8 +113,40 @@ func run(ctx context.Context) (err error) {
 		subscribe.AllWorlds()
 	}
 
+	var stats *metrics
+	if config.MetricsAddr != "" {
+		stats = newMetrics()
+		tee := client.Tee(1000, wsc.DropOldest)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case message := <-tee.C:
+					stats.observe(message, time.Now())
+				}
+			}
+		}()
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", stats)
+		server := &http.Server{Addr: config.MetricsAddr, Handler: mux}
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+		go func() {
+			slog.Info("serving metrics", "addr", config.MetricsAddr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("metrics server stopped", "error", err)
+			}
+		}()
+	}
+
 	client.SetConnectHandler(func() {
 		slog.Info("websocket connected")
+		if stats != nil {
+			stats.connected()
+		}
 		client.Send(subscribe)
 	})
 
@@ -162,6 +202,10 @@ func run(ctx context.Context) (err error) {
 	}
 
 	client.SetMessageLogger(&wsc.MessageLogger{R: writer, S: os.Stderr, SentPrefix: "-> "})
+	if stats != nil {
+		// a canary should keep reporting through disconnects instead of exiting
+		return wsc.WithRetry(client, ctx)
+	}
 	err = client.Run(ctx)
 	return err
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// lagBuckets are the upper bounds in seconds of the stream lag histogram.
+// Event timestamps only have one second resolution.
+var lagBuckets = []float64{1, 2, 5, 10, 30, 60, 300}
+
+// metrics counts stream activity and serves it in the Prometheus text format.
+// It's written by hand to avoid pulling in the Prometheus client for a handful of series.
+type metrics struct {
+	mu             sync.Mutex
+	events         map[eventKey]uint64
+	decodeFailures uint64
+	connections    uint64
+	lagCounts      []uint64 // lagCounts has one count per bucket plus +Inf
+	lagSum         float64
+	lagCount       uint64
+}
+
+type eventKey struct {
+	name  string
+	world ps2.WorldID
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		events:    make(map[eventKey]uint64),
+		lagCounts: make([]uint64, len(lagBuckets)+1),
+	}
+}
+
+// connected records a successful connection.
+// Every connection after the first is a restart.
+func (m *metrics) connected() {
+	m.mu.Lock()
+	m.connections++
+	m.mu.Unlock()
+}
+
+// observe records a raw message received from the event stream.
+func (m *metrics) observe(message []byte, received time.Time) {
+	var envelope struct {
+		Type    string `json:"type"`
+		Payload struct {
+			EventName string `json:"event_name"`
+			WorldID   string `json:"world_id"`
+			Timestamp string `json:"timestamp"`
+		} `json:"payload"`
+	}
+	err := json.Unmarshal(message, &envelope)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.decodeFailures++
+		return
+	}
+	p := envelope.Payload
+	if envelope.Type != "serviceMessage" || p.EventName == "" {
+		return
+	}
+	world, _ := strconv.Atoi(p.WorldID)
+	m.events[eventKey{p.EventName, ps2.WorldID(world)}]++
+
+	ts, err := strconv.ParseInt(p.Timestamp, 10, 64)
+	if err != nil || ts == 0 {
+		return
+	}
+	lag := max(received.Sub(time.Unix(ts, 0)).Seconds(), 0)
+	i, _ := slices.BinarySearch(lagBuckets, lag)
+	m.lagCounts[i]++
+	m.lagSum += lag
+	m.lagCount++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writeTo(w)
+}
+
+func (m *metrics) writeTo(w io.Writer) {
+	keys := make([]eventKey, 0, len(m.events))
+	for k := range m.events {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b eventKey) int {
+		if a.name != b.name {
+			if a.name < b.name {
+				return -1
+			}
+			return 1
+		}
+		return int(a.world) - int(b.world)
+	})
+	fmt.Fprintln(w, "# HELP eventclient_events_total Events received by type and world.")
+	fmt.Fprintln(w, "# TYPE eventclient_events_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(w, "eventclient_events_total{type=%q,world=%q} %d\n", k.name, k.world.StringID(), m.events[k])
+	}
+
+	fmt.Fprintln(w, "# HELP eventclient_decode_failures_total Messages that could not be decoded as JSON.")
+	fmt.Fprintln(w, "# TYPE eventclient_decode_failures_total counter")
+	fmt.Fprintf(w, "eventclient_decode_failures_total %d\n", m.decodeFailures)
+
+	fmt.Fprintln(w, "# HELP eventclient_connection_restarts_total Reconnections after the first successful connection.")
+	fmt.Fprintln(w, "# TYPE eventclient_connection_restarts_total counter")
+	fmt.Fprintf(w, "eventclient_connection_restarts_total %d\n", max(m.connections, 1)-1)
+
+	fmt.Fprintln(w, "# HELP eventclient_stream_lag_seconds Delay between an event's timestamp and when it was received.")
+	fmt.Fprintln(w, "# TYPE eventclient_stream_lag_seconds histogram")
+	var cumulative uint64
+	for i, le := range lagBuckets {
+		cumulative += m.lagCounts[i]
+		fmt.Fprintf(w, "eventclient_stream_lag_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(le, 'f', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "eventclient_stream_lag_seconds_bucket{le=\"+Inf\"} %d\n", m.lagCount)
+	fmt.Fprintf(w, "eventclient_stream_lag_seconds_sum %s\n", strconv.FormatFloat(m.lagSum, 'f', -1, 64))
+	fmt.Fprintf(w, "eventclient_stream_lag_seconds_count %d\n", m.lagCount)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	received := time.Unix(1709037300, 0)
+	m := newMetrics()
+	m.connected()
+	m.connected()
+	for _, msg := range []string{
+		// one second of lag is in the le="1" bucket
+		`{"payload":{"event_name":"Death","world_id":"17","timestamp":"1709037299"},"service":"event","type":"serviceMessage"}`,
+		// timestamps in the future count as no lag
+		`{"payload":{"event_name":"Death","world_id":"17","timestamp":"1709037305"},"service":"event","type":"serviceMessage"}`,
+		`{"payload":{"event_name":"Death","world_id":"1","timestamp":"1709037297"},"service":"event","type":"serviceMessage"}`,
+		`{"payload":{"event_name":"GainExperience","world_id":"17","timestamp":"1709036900"},"service":"event","type":"serviceMessage"}`,
+		// events without a timestamp are counted without a lag
+		`{"payload":{"event_name":"FacilityControl","world_id":"17","timestamp":"0"},"service":"event","type":"serviceMessage"}`,
+		// messages other than events are ignored
+		`{"online":{"EventServerEndpoint_Connery_1":"true"},"service":"event","type":"heartbeat"}`,
+		`{"connected":"true","service":"push","type":"connectionStateChanged"}`,
+		`not json`,
+	} {
+		m.observe([]byte(msg), received)
+	}
+
+	want := `# HELP eventclient_events_total Events received by type and world.
+# TYPE eventclient_events_total counter
+eventclient_events_total{type="Death",world="1"} 1
+eventclient_events_total{type="Death",world="17"} 2
+eventclient_events_total{type="FacilityControl",world="17"} 1
+eventclient_events_total{type="GainExperience",world="17"} 1
+# HELP eventclient_decode_failures_total Messages that could not be decoded as JSON.
+# TYPE eventclient_decode_failures_total counter
+eventclient_decode_failures_total 1
+# HELP eventclient_connection_restarts_total Reconnections after the first successful connection.
+# TYPE eventclient_connection_restarts_total counter
+eventclient_connection_restarts_total 1
+# HELP eventclient_stream_lag_seconds Delay between an event's timestamp and when it was received.
+# TYPE eventclient_stream_lag_seconds histogram
+eventclient_stream_lag_seconds_bucket{le="1"} 2
+eventclient_stream_lag_seconds_bucket{le="2"} 2
+eventclient_stream_lag_seconds_bucket{le="5"} 3
+eventclient_stream_lag_seconds_bucket{le="10"} 3
+eventclient_stream_lag_seconds_bucket{le="30"} 3
+eventclient_stream_lag_seconds_bucket{le="60"} 3
+eventclient_stream_lag_seconds_bucket{le="300"} 3
+eventclient_stream_lag_seconds_bucket{le="+Inf"} 4
+eventclient_stream_lag_seconds_sum 404
+eventclient_stream_lag_seconds_count 4
+`
+	var got strings.Builder
+	m.writeTo(&got)
+	if got.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", got.String(), want)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4" || rec.Body.String() != want {
+		t.Errorf("got content type %q and body\n%s", ct, rec.Body.String())
+	}
+}
+
+func TestMetricsEmpty(t *testing.T) {
+	// restarts aren't negative before the first connection
+	var got strings.Builder
+	newMetrics().writeTo(&got)
+	if !strings.Contains(got.String(), "\neventclient_connection_restarts_total 0\n") || !strings.Contains(got.String(), "\neventclient_stream_lag_seconds_count 0\n") {
+		t.Errorf("got\n%s", got.String())
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/psmap"
+	"github.com/anthonynsimon/bild/transform"
+)
+
+// alertLookupTimeout limits the census requests made to annotate a single map,
+// so that a slow census doesn't hold up the map itself.
+const alertLookupTimeout = 10 * time.Second
+
+// alertWindow is how far back to look for the start of a running alert.
+const alertWindow = 2 * time.Hour
+
+// renderAlertPNG returns a renderingFn that draws a size x size PNG image with terrain,
+// annotated with warpgate ownership and the status of the running alert:
+// its name, the time remaining, and each faction's share of the territory.
+// Maps without a running alert still show territory.
+// The result is meant to be used directly as the image of a Discord alert embed.
+func renderAlertPNG(size int) renderingFn {
+	return func(data psmap.Map, mapstate psmap.State) io.ReadCloser {
+		r, w := io.Pipe()
+		img := image.NewRGBA(image.Rect(0, 0, size, size))
+		var background image.Image = getMapTerrainImage(mapstate.ZoneID.ZoneID())
+		if background.Bounds().Dx() != size {
+			background = transform.Resize(background, size, size, transform.Linear)
+		}
+		draw.Draw(img, img.Bounds(), background, background.Bounds().Min, draw.Src)
+		if err := psmap.Draw(img, data, mapstate); err != nil {
+			w.CloseWithError(fmt.Errorf("unable to draw map: %w", err))
+			return r
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), alertLookupTimeout)
+		defer cancel()
+		status, err := alertStatus(ctx, mapstate.WorldID, mapstate.ZoneID)
+		if err != nil {
+			// the map is still useful without the alert
+			slog.Info("alert lookup failed", "world", mapstate.WorldID, "zone", mapstate.ZoneID, "error", err)
+		}
+		a := psmap.Annotations{Warpgates: true, Alert: &status}
+		if err := psmap.DrawAnnotations(img, data, mapstate, a); err != nil {
+			w.CloseWithError(fmt.Errorf("unable to draw annotations: %w", err))
+			return r
+		}
+		go func() {
+			w.CloseWithError(png.Encode(w, img))
+		}()
+		return r
+	}
+}
+
+// alertStatus returns the name and remaining time of the alert running in zone,
+// or an empty status when there isn't one.
+func alertStatus(ctx context.Context, world ps2.WorldID, zone ps2.ZoneInstanceID) (psmap.AlertStatus, error) {
+	if world == 0 {
+		return psmap.AlertStatus{}, nil
+	}
+	after := time.Now().Add(-alertWindow)
+	events, err := census.GetMetagameEvents(ctx, nil, ps2.GetEnvironment(world), &after, world)
+	if err != nil {
+		return psmap.AlertStatus{}, err
+	}
+	// events are oldest first, so the last event for the zone is its current state
+	var started *time.Time
+	var id ps2.MetagameEventID
+	for _, e := range events {
+		if e.ZoneID != zone {
+			continue
+		}
+		switch e.MetagameEventState {
+		case ps2.Started:
+			started, id = &e.Timestamp, e.MetagameEventID
+		case ps2.Ended, ps2.Cancelled:
+			started = nil
+		}
+	}
+	if started == nil {
+		return psmap.AlertStatus{}, nil
+	}
+	def, err := getMetagameEvent(ctx, id)
+	if err != nil {
+		return psmap.AlertStatus{}, err
+	}
+	return psmap.AlertStatus{
+		Name:      def.Name.String(),
+		Remaining: time.Until(started.Add(def.Duration.Duration())),
+	}, nil
+}
+
+// metagameEvents caches alert definitions, which only change with game updates.
+var metagameEvents = struct {
+	sync.Mutex
+	byID map[ps2.MetagameEventID]census.MetagameEvent
+}{}
+
+// getMetagameEvent returns the definition of an alert,
+// loading every definition from census the first time it's called.
+func getMetagameEvent(ctx context.Context, id ps2.MetagameEventID) (census.MetagameEvent, error) {
+	metagameEvents.Lock()
+	defer metagameEvents.Unlock()
+	if metagameEvents.byID == nil {
+		var all []census.MetagameEvent
+		if err := census.LoadCollection(ctx, nil, &all); err != nil {
+			return census.MetagameEvent{}, fmt.Errorf("unable to load alert definitions: %w", err)
+		}
+		metagameEvents.byID = make(map[ps2.MetagameEventID]census.MetagameEvent, len(all))
+		for _, e := range all {
+			metagameEvents.byID[e.MetagameEventID] = e
+		}
+	}
+	e, found := metagameEvents.byID[id]
+	if !found {
+		return census.MetagameEvent{}, fmt.Errorf("unknown alert %d", id)
+	}
+	return e, nil
+}
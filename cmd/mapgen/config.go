@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+	"github.com/anthonynsimon/bild/transform"
+)
+
+// configFile is the format of the json file given to -config.
+//
+//	{
+//	    "service_id": "example",
+//	    "serve": "localhost:8080",
+//	    "outputdir": "/var/lib/mapgen",
+//	    "profiles": [
+//	        {"name": "pc", "worlds": ["osprey", "wainwright"], "formats": ["image", "json"], "interval": "2m"},
+//	        {"name": "console", "worlds": ["genudine", "ceres"], "zones": ["indar"], "formats": ["thumbnail"], "size": 256, "output": "ps4", "interval": "15m"}
+//	    ]
+//	}
+//
+// Flags given on the command line override the matching values from the file,
+// including -world, -zone, and -format, which apply to every profile.
+type configFile struct {
+	ServiceID string          `json:"service_id"`
+	Bind      string          `json:"serve"`
+	Verbose   bool            `json:"verbose"`
+	OutputDir string          `json:"outputdir"`
+	Warmup    bool            `json:"warmup"`
+	Profiles  []profileConfig `json:"profiles"`
+}
+
+// profileConfig is a set of maps to render together.
+type profileConfig struct {
+	Name string `json:"name"`
+
+	// Worlds and Zones are names like "osprey" and "indar".
+	// All worlds or zones are rendered when empty.
+	Worlds []string `json:"worlds"`
+	Zones  []string `json:"zones"`
+
+	// Formats are the names accepted by -format.
+	// The default is "image".
+	Formats []string `json:"formats"`
+
+	// Size is the width and height of rendered images in pixels.
+	// The default depends on the format.
+	Size int `json:"size"`
+
+	// Output is a directory relative to -outputdir where the profile's maps are written.
+	// Profiles write directly to -outputdir when empty.
+	Output string `json:"output"`
+
+	// Interval is how often maps are updated in server mode, e.g. "5m".
+	Interval string `json:"interval"`
+}
+
+// renderProfile is a validated profileConfig.
+type renderProfile struct {
+	name     string
+	worlds   []ps2.WorldID
+	zones    []ps2.ContinentID
+	formats  []string
+	size     int
+	output   string
+	interval time.Duration
+}
+
+const defaultUpdateInterval = 5 * time.Minute
+
+func readConfigFile(name string) (cf configFile, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return cf, err
+	}
+	defer f.Close()
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cf); err != nil {
+		return cf, fmt.Errorf("read config %q: %w", name, err)
+	}
+	return cf, nil
+}
+
+// profile validates pc and converts names to IDs.
+func (pc profileConfig) profile() (p renderProfile, err error) {
+	p.name = pc.Name
+	p.size = pc.Size
+	p.output = pc.Output
+	for _, name := range pc.Worlds {
+		w := parseWorld(name)
+		if w == 0 {
+			return p, fmt.Errorf("profile %q: unknown world %q", pc.Name, name)
+		}
+		p.worlds = append(p.worlds, w)
+	}
+	for _, name := range pc.Zones {
+		z := parseZone(name)
+		if z == 0 {
+			return p, fmt.Errorf("profile %q: unknown zone %q", pc.Name, name)
+		}
+		p.zones = append(p.zones, z)
+	}
+	p.formats = pc.Formats
+	if len(p.formats) == 0 {
+		p.formats = []string{"image"}
+	}
+	for _, f := range p.formats {
+		if _, found := formats[f]; !found {
+			return p, fmt.Errorf("profile %q: invalid format %q", pc.Name, f)
+		}
+	}
+	p.interval = defaultUpdateInterval
+	if pc.Interval != "" {
+		if p.interval, err = time.ParseDuration(pc.Interval); err != nil {
+			return p, fmt.Errorf("profile %q: interval: %w", pc.Name, err)
+		}
+		if p.interval < time.Minute {
+			return p, fmt.Errorf("profile %q: interval must be at least 1m", pc.Name)
+		}
+	}
+	return p, nil
+}
+
+// defaultProfile builds a profile from the command line flags.
+func defaultProfile(format string) renderProfile {
+	p := renderProfile{
+		formats:  []string{format},
+		interval: defaultUpdateInterval,
+	}
+	if config.World != 0 {
+		p.worlds = []ps2.WorldID{config.World}
+	}
+	if config.Zone != 0 {
+		p.zones = []ps2.ContinentID{config.Zone}
+	}
+	return p
+}
+
+// applyConfigFile sets config values from cf that weren't set on the command line.
+func applyConfigFile(cf configFile, setFlags map[string]bool) error {
+	if !setFlags["s"] && cf.ServiceID != "" {
+		config.ServiceID = cf.ServiceID
+	}
+	if !setFlags["serve"] && cf.Bind != "" {
+		config.Bind = cf.Bind
+	}
+	if !setFlags["v"] && cf.Verbose {
+		config.VerboseLog = true
+	}
+	if !setFlags["outputdir"] && cf.OutputDir != "" {
+		config.OutputDir = cf.OutputDir
+	}
+	if !setFlags["warmup"] && cf.Warmup {
+		config.Warmup = true
+	}
+	if len(cf.Profiles) == 0 {
+		return errors.New("config file has no profiles")
+	}
+	for _, pc := range cf.Profiles {
+		p, err := pc.profile()
+		if err != nil {
+			return err
+		}
+		if setFlags["world"] {
+			p.worlds = []ps2.WorldID{config.World}
+		}
+		if setFlags["zone"] {
+			p.zones = []ps2.ContinentID{config.Zone}
+		}
+		if setFlags["format"] {
+			p.formats = []string{config.OutputFormat}
+		}
+		config.Profiles = append(config.Profiles, p)
+	}
+	return nil
+}
+
+// profiles returns the profiles from the config file,
+// or a single profile built from flags if there wasn't one.
+func profiles(format string) []renderProfile {
+	if len(config.Profiles) > 0 {
+		return config.Profiles
+	}
+	return []renderProfile{defaultProfile(format)}
+}
+
+// newRenderer returns the renderingFn for format,
+// drawing images at size when size is not 0.
+func newRenderer(format string, size int) (renderingFn, error) {
+	f, found := formats[format]
+	if !found {
+		return nil, fmt.Errorf("invalid format %q", format)
+	}
+	if size == 0 {
+		return f.fn, nil
+	}
+	if size < 0 || size > 8192 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	switch format {
+	case "image":
+		return renderSizedPNG(size, true), nil
+	case "transparent", "thumbnail":
+		return renderSizedPNG(size, false), nil
+	case "alert":
+		return renderAlertPNG(size), nil
+	default:
+		// size doesn't apply to non-image formats
+		return f.fn, nil
+	}
+}
+
+// renderSizedPNG returns a renderingFn that draws a size x size PNG image,
+// optionally with the embedded terrain image scaled to fit.
+func renderSizedPNG(size int, terrain bool) renderingFn {
+	return func(data psmap.Map, mapstate psmap.State) io.ReadCloser {
+		r, w := io.Pipe()
+		img := image.NewRGBA(image.Rect(0, 0, size, size))
+		if terrain {
+			var background image.Image = getMapTerrainImage(mapstate.ZoneID.ZoneID())
+			if background.Bounds().Dx() != size {
+				background = transform.Resize(background, size, size, transform.Linear)
+			}
+			draw.Draw(img, img.Bounds(), background, background.Bounds().Min, draw.Src)
+		}
+		if err := psmap.Draw(img, data, mapstate); err != nil {
+			w.CloseWithError(fmt.Errorf("unable to draw map: %w", err))
+			return r
+		}
+		go func() {
+			w.CloseWithError(png.Encode(w, img))
+		}()
+		return r
+	}
+}
@@ -46,6 +46,8 @@ var config = struct {
 	OutputDir    string
 	OutputFormat string
 	Mode         mode
+	Profiles     []renderProfile // Profiles are loaded from -config
+	Warmup       bool            // Warmup staggers census requests during server startup
 }{}
 
 type renderable struct {
@@ -70,6 +72,11 @@ var formats = map[string]renderable{
 		".png",
 		"image/png",
 	},
+	"alert": {
+		renderAlertPNG(terrainDimensions),
+		".png",
+		"image/png",
+	},
 	"json": {
 		RenderMapStateJSON,
 		".json",
@@ -95,6 +102,8 @@ func (m mode) String() string {
 		return "ZoneLoc"
 	case AllRegions:
 		return "AllRegions"
+	case Tiles:
+		return "Tiles"
 	default:
 		return fmt.Sprintf("%d", m)
 	}
@@ -108,12 +117,14 @@ const (
 	SingleRegion
 	ZoneLoc
 	AllRegions
+	Tiles
 )
 
 func main() {
-	var environment, world, zone, location string
+	var environment, world, zone, location, configFileName string
 	var datamode bool
 	var cropregionmode bool
+	var tilesmode bool
 	flag.StringVar(&config.Bind, "serve", config.Bind, "Serve will start the process as a small HTTP server bound to the given network interface such as \"localhost:8080\".")
 	flag.StringVar(&config.ServiceID, "s", config.ServiceID, "Service ID: https://census.daybreakgames.com/#service-id")
 	flag.BoolVar(&config.VerboseLog, "v", config.VerboseLog, "Enable writing verbose logging information to stderr.")
@@ -122,14 +133,32 @@ func main() {
 	flag.StringVar(&world, "world", "", "The world to check (emerald, soltech, etc.)")
 	flag.StringVar(&zone, "zone", "", "The zone to check (indar, hossin, esamir, amerish, oshur)")
 	flag.StringVar(&config.OutputDir, "outputdir", ".", "File paths will be appended to this directory")
-	flag.StringVar(&config.OutputFormat, "format", "image", "The output format for a map (image, thumbnail, json).")
+	flag.StringVar(&config.OutputFormat, "format", "image", "The output format for a map (image, thumbnail, alert, json).")
 	flag.IntVar((*int)(&config.Region), "region", 0, "Draw a map region PNG.")
-	flag.BoolVar(&cropregionmode, "regions", false, "Generate cropped region and facility images.")
+	flag.BoolVar(&cropregionmode, "regions", false, "Generate cropped region and facility images. Use with -zone to generate a single continent.")
+	flag.BoolVar(&tilesmode, "tiles", false, "Generate XYZ tile pyramids of the terrain for web maps. Use with -zone to generate a single continent, and with -world to add a territory layer.")
 	flag.StringVar(&location, "loc", "", "Location as reported by the /loc command in-game, e.g. -loc \"3211.266 470.785 3136.692\". A fourth value, heading, is optional.")
+	flag.BoolVar(&config.Warmup, "warmup", config.Warmup, "Stage server startup to conserve census quota: live maps are generated one world at a time before region images, and progress is reported on /health. Always enabled for the \"example\" service ID.")
+	flag.StringVar(&configFileName, "config", "", "Path to a json config file defining render profiles. Flags given on the command line override values from the file.")
 	// flag.StringVar(&config.DataFile, "datafile", "", "Use a provided map data file to override the embedded map data.")
 	flag.Parse()
 
 	config.Output = flag.Arg(0)
+	config.World = parseWorld(world)
+	config.Zone = parseZone(zone)
+
+	if configFileName != "" {
+		setFlags := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		cf, err := readConfigFile(configFileName)
+		if err == nil {
+			err = applyConfigFile(cf, setFlags)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
 
 	switch environment {
 	case "ps4us":
@@ -142,9 +171,6 @@ func main() {
 		census.DefaultClient.ServiceID = config.ServiceID
 	}
 
-	config.World = parseWorld(world)
-	config.Zone = parseZone(zone)
-
 	locParams := strings.Split(location, " ")
 	if len(locParams) >= 3 {
 		config.Loc.X, _ = strconv.ParseFloat(locParams[0], 64)
@@ -174,6 +200,8 @@ func main() {
 		config.Mode = ZoneLoc
 	case cropregionmode:
 		config.Mode = AllRegions
+	case tilesmode:
+		config.Mode = Tiles
 	case datamode:
 		config.Mode = MapDataFile
 	case config.Output == "":
@@ -239,22 +267,32 @@ func run(ctx context.Context) error {
 	case HTTPServer:
 		census.RateLimit(2, 1)
 		sd := filepath.Join(config.OutputDir, "maps-public") // explicitly set a public dir because we're serving static files and don't want to accidentally serve anything but the ones we generate
-		slog.Info("starting", "mode", config.Mode, "service_id", config.ServiceID, "bind", config.Bind, "serve_directory", sd)
-		return runHTTPServerMode(ctx, config.Bind, sd)
+		slog.Info("starting", "mode", config.Mode, "bind", config.Bind, "serve_directory", sd)
+		return runHTTPServerMode(ctx, config.Bind, sd, profiles("image"))
 	case MultiFile:
 		census.RateLimit(6, 1)
-		slog.Info("starting", "mode", config.Mode, "service_id", config.ServiceID, "outputdir", config.OutputDir, "world", config.World, "zone", config.Zone, "renderer", config.OutputFormat)
-		return runMultiFileMode(ctx, config.OutputDir, renderFn, config.World, config.Zone)
+		slog.Info("starting", "mode", config.Mode, "outputdir", config.OutputDir, "world", config.World, "zone", config.Zone, "renderer", config.OutputFormat, "profiles", len(config.Profiles))
+		for _, p := range profiles(config.OutputFormat) {
+			if err := runProfile(ctx, config.OutputDir, p); err != nil {
+				return err
+			}
+		}
+		return nil
 	case MapDataFile:
-		slog.Info("starting", "mode", config.Mode, "service_id", config.ServiceID, "output", config.Output, "environment", config.Env)
+		slog.Info("starting", "mode", config.Mode, "output", config.Output, "environment", config.Env)
 		rc := NewAllMapDataJSONReader(ctx, config.Env)
 		defer rc.Close()
 		return writeToOutput(rc, config.Output)
 	case AllRegions:
-		slog.Info("starting", "mode", config.Mode, "outputdir", config.OutputDir)
-		return runCropAllRegionsMode(ctx, config.OutputDir)
+		slog.Info("starting", "mode", config.Mode, "outputdir", config.OutputDir, "zone", config.Zone)
+		return runCropAllRegionsMode(ctx, config.OutputDir, config.Zone)
+	case Tiles:
+		slog.Info("starting", "mode", config.Mode, "outputdir", config.OutputDir, "world", config.World, "zone", config.Zone)
+		return runTilesMode(ctx, config.OutputDir, config.World, config.Zone)
 	case SingleFile:
-		slog.Info("starting", "mode", config.Mode, "service_id", config.ServiceID, "world", config.World, "zone", config.Zone, "renderer", config.OutputFormat)
+		// a single map is requested from the command line, so report census problems right away instead of retrying
+		census.DefaultClient.SetFailFast(10 * time.Second)
+		slog.Info("starting", "mode", config.Mode, "world", config.World, "zone", config.Zone, "renderer", config.OutputFormat)
 		rc := NewRenderZoneReader(ctx, config.World, config.Zone, renderFn)
 		defer rc.Close()
 		return writeToOutput(rc, config.Output)
@@ -298,79 +336,44 @@ func writeToOutput(r io.Reader, output string) error {
 	return nil
 }
 
-// crop function? masked vs unmasked
-func runCropAllRegionsMode(_ context.Context, dir string) error {
-
-	if err := os.MkdirAll(filepath.Join(dir, "regions"), 0750); err != nil {
-		return err
-	}
-	if err := os.MkdirAll(filepath.Join(dir, "facilities"), 0750); err != nil {
-		return err
-	}
-
-	var terrainLOD image.Image
-
-	for _, mapdata := range maps {
-		continent, err := mapdata.ZoneID.ContinentID()
+// runProfile renders every map in p once.
+func runProfile(ctx context.Context, dir string, p renderProfile) error {
+	dir = filepath.Join(dir, p.output)
+	renderers := make([]profileRenderer, 0, len(p.formats))
+	for _, name := range p.formats {
+		fn, err := newRenderer(name, p.size)
 		if err != nil {
-			slog.Debug("skipping zone", "zone", mapdata.ZoneID, "error", err)
-			continue
+			return fmt.Errorf("profile %q: %w", p.name, err)
 		}
-		// img might be very large, so re-use the same variable to hopefully nuke references for garbage collection
-		terrainLOD = getFullsizeMapTerrainImage(continent)
-
-		for _, region := range mapdata.Regions {
-			if len(region.Hexes) == 0 {
-				slog.Debug("skipping region", "region", region.RegionID, "error", "empty hex list")
-				continue
-			}
-
-			var w io.Writer = nil
-
-			var regionfile, facilityfile *os.File
-
-			regionfile, err = os.Create(filepath.Join(dir, "regions", fmt.Sprintf("%d.png", region.RegionID)))
-			if err != nil {
-				slog.Info("failed to create region file", "region", region.RegionID, "error", err)
-				continue
-			}
-			if region.FacilityID == 0 {
-				facilityfile = nil
-				w = regionfile
-			} else {
-				facilityfile, err = os.Create(filepath.Join(dir, "facilities", fmt.Sprintf("%d.png", region.FacilityID)))
-				if err != nil {
-					slog.Info("failed to create facility file", "region", region.RegionID, "error", err)
-					regionfile.Close()
-					continue
-				}
-				w = io.MultiWriter(regionfile, facilityfile)
-			}
-			imgrc := RenderCroppedMapRegionPNG(terrainLOD, mapdata, region, true)
-			_, err := io.Copy(w, imgrc)
-			if err != nil {
-				slog.Info("error white writing map region", "region", region.RegionID, "error", err)
-			}
-			imgrc.Close()
-			regionfile.Close()
-			facilityfile.Close()
+		r := profileRenderer{name: name, fn: fn, extension: formats[name].extension}
+		if len(p.formats) > 1 {
+			// formats like image and transparent share a file extension,
+			// so they get their own directories
+			r.subdir = name
 		}
-		// let GC clear references?
-		terrainLOD = nil
-
+		renderers = append(renderers, r)
 	}
-	return nil
+	return runMultiFileMode(ctx, dir, renderers, p.worlds, p.zones)
 }
 
-func runMultiFileMode(ctx context.Context, dir string, renderFn renderingFn, world ps2.WorldID, zone ps2.ContinentID) error {
-	zones := []ps2.ContinentID{ps2.Indar, ps2.Hossin, ps2.Amerish, ps2.Esamir, ps2.Oshur}
-	if zone != 0 {
-		zones = []ps2.ContinentID{zone}
+// profileRenderer is one output format of a profile.
+type profileRenderer struct {
+	name      string
+	fn        renderingFn
+	extension string
+	subdir    string
+}
+
+// defaultWorlds are rendered when no worlds are selected.
+var defaultWorlds = []ps2.WorldID{ps2.Osprey, ps2.Wainwright, ps2.Jaeger, ps2.SolTech, ps2.Genudine, ps2.Ceres}
+
+func runMultiFileMode(ctx context.Context, dir string, renderers []profileRenderer, worlds []ps2.WorldID, zones []ps2.ContinentID) error {
+	if len(zones) == 0 {
+		zones = []ps2.ContinentID{ps2.Indar, ps2.Hossin, ps2.Amerish, ps2.Esamir, ps2.Oshur}
 	}
 
-	worlds := []ps2.WorldID{ps2.Osprey, ps2.Wainwright, ps2.Jaeger, ps2.SolTech, ps2.Genudine, ps2.Ceres}
-	if world != 0 {
-		worlds = []ps2.WorldID{config.World}
+	if len(worlds) == 0 {
+		worlds = defaultWorlds
 	}
 
 	zids := []ps2.ZoneInstanceID{}
@@ -386,9 +389,11 @@ func runMultiFileMode(ctx context.Context, dir string, renderFn renderingFn, wor
 	var retryable interface{ Retryable() bool }
 
 	for _, world := range worlds {
-		subdir := filepath.Join(dir, worldName(world))
-		if err := os.MkdirAll(subdir, 0750); err != nil {
-			return fmt.Errorf("failed to create directory %q: %w", subdir, err)
+		for _, r := range renderers {
+			subdir := filepath.Join(dir, r.subdir, worldName(world))
+			if err := os.MkdirAll(subdir, 0750); err != nil {
+				return fmt.Errorf("failed to create directory %q: %w", subdir, err)
+			}
 		}
 		mapstates, err := psmap.GetMapState(ctx, world, zids...)
 		if errors.As(err, &retryable) && !retryable.Retryable() {
@@ -406,33 +411,35 @@ func runMultiFileMode(ctx context.Context, dir string, renderFn renderingFn, wor
 				continue
 			}
 
-			fileName := filepath.Join(dir, worldName(world), zoneName(continent)+formats[config.OutputFormat].extension)
+			for _, r := range renderers {
+				fileName := filepath.Join(dir, r.subdir, worldName(world), zoneName(continent)+r.extension)
 
-			renderer := renderFn(mapdata, state)
-			defer renderer.Close()
+				renderer := r.fn(mapdata, state)
 
-			// encode to a buffer first so that if there were an encoding error for some reason,
-			// we don't truncate an existing map file
-			buf := bytes.Buffer{}
-			_, err = io.Copy(&buf, renderer)
-			if err != nil {
-				// a rendering error could be caused by missing map data,
-				// but rendering the working maps is better than returning with a failure here
-				slog.Info("error rendering map", "zone", zoneName(continent), "format", config.OutputFormat, "error", err)
-				continue
-			}
-			f, err := os.Create(fileName)
-			if err != nil {
-				// if we can't create a file here then something is wrong in a way that will prevent this program from executing as the user expected,
-				// so we want to report back a failure.
-				// this includes file create permissions.
-				return fmt.Errorf("unable to create file %q: %w", fileName, err)
-			}
-			_, err = io.Copy(f, &buf)
-			f.Close() // not deferred because we're in a loop, so be extra careful not to miss any return paths
-			if err != nil {
-				slog.Info("error while writing image", "file", fileName, "error", err)
-				continue
+				// encode to a buffer first so that if there were an encoding error for some reason,
+				// we don't truncate an existing map file
+				buf := bytes.Buffer{}
+				_, err = io.Copy(&buf, renderer)
+				renderer.Close()
+				if err != nil {
+					// a rendering error could be caused by missing map data,
+					// but rendering the working maps is better than returning with a failure here
+					slog.Info("error rendering map", "zone", zoneName(continent), "format", r.name, "error", err)
+					continue
+				}
+				f, err := os.Create(fileName)
+				if err != nil {
+					// if we can't create a file here then something is wrong in a way that will prevent this program from executing as the user expected,
+					// so we want to report back a failure.
+					// this includes file create permissions.
+					return fmt.Errorf("unable to create file %q: %w", fileName, err)
+				}
+				_, err = io.Copy(f, &buf)
+				f.Close() // not deferred because we're in a loop, so be extra careful not to miss any return paths
+				if err != nil {
+					slog.Info("error while writing image", "file", fileName, "error", err)
+					continue
+				}
 			}
 		}
 	}
@@ -462,32 +469,54 @@ func injectCorrelationID(next http.Handler) http.HandlerFunc {
 	}
 }
 
-func runHTTPServerMode(ctx context.Context, bind string, dir string) error {
+func runHTTPServerMode(ctx context.Context, bind string, dir string, profiles []renderProfile) error {
 	ctx, shutdown := context.WithCancelCause(ctx)
 	defer shutdown(nil)
 	var err error
 
-	updateInterval := 5 * time.Minute
+	// the shortest interval is used for cache headers since all live maps are served from the same file server
+	updateInterval := profiles[0].interval
+	for _, p := range profiles {
+		updateInterval = min(updateInterval, p.interval)
+	}
 
 	err = os.MkdirAll(dir, 0750)
 	if err != nil {
 		return fmt.Errorf("setup failed: create dir %q: %w", dir, err)
 	}
 
-	slog.Info("retrieving game state from census")
-	err = runMultiFileMode(ctx, dir, RenderMapImageDefaultPNG, 0, 0)
-	if err != nil {
-		return fmt.Errorf("setup failed: initial map state: %w", err)
-	}
+	// a staged warm-up starts serving right away and fills in maps over time,
+	// rather than risking the census rate limit with a burst of requests for every world
+	staged := config.Warmup || census.DefaultClient.ServiceID == lowQuotaServiceID
+	status := newServerStatus(staged)
+	if !staged {
+		slog.Info("retrieving game state from census")
+		for _, p := range profiles {
+			err = runProfile(ctx, dir, p)
+			if err != nil {
+				return fmt.Errorf("setup failed: initial map state: %w", err)
+			}
+		}
+		status.mapRun(nil)
 
-	slog.Info("generating map region images")
-	err = runCropAllRegionsMode(ctx, dir)
-	if err != nil {
-		return fmt.Errorf("setup failed: generate regions: %w", err)
+		slog.Info("generating map region images")
+		err = runCropAllRegionsMode(ctx, dir, 0)
+		if err != nil {
+			return fmt.Errorf("setup failed: generate regions: %w", err)
+		}
+		status.update(func(s *serverStatus) {
+			s.Stage = "ready"
+			s.ReadySince = time.Now()
+		})
 	}
 
 	cacheControl := func(next http.Handler) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" || r.URL.Path == "/render" {
+				// these set their own headers
+				next.ServeHTTP(w, r)
+				return
+			}
 			if strings.HasPrefix(r.URL.Path, "/regions/") ||
 				strings.HasPrefix(r.URL.Path, "/facilities/") {
 				w.Header().Set("Cache-Control", "public, max-age=2592000")
@@ -511,6 +540,8 @@ func runHTTPServerMode(ctx context.Context, bind string, dir string) error {
 
 	router := http.NewServeMux()
 	router.Handle("/", http.FileServer(http.Dir(dir)))
+	router.Handle("/health", status)
+	router.Handle("/render", newRenderServer())
 
 	var h http.Handler = router
 	h = cacheControl(h)
@@ -524,22 +555,34 @@ func runHTTPServerMode(ctx context.Context, bind string, dir string) error {
 
 	wg := sync.WaitGroup{}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(updateInterval):
-				slog.Info("retrieving game state from census")
-				runerr := runMultiFileMode(ctx, dir, RenderMapImageDefaultPNG, 0, 0)
-				if runerr != nil {
-					slog.Info("failed to generate new maps", "error", runerr)
+	if staged {
+		slog.Info("starting staged warm-up", "spacing", warmupSpacing)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			warmUp(ctx, dir, profiles, status)
+		}()
+	}
+
+	for _, p := range profiles {
+		wg.Add(1)
+		go func(p renderProfile) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(p.interval):
+					slog.Info("retrieving game state from census", "profile", p.name)
+					runerr := runProfile(ctx, dir, p)
+					if runerr != nil {
+						slog.Info("failed to generate new maps", "profile", p.name, "error", runerr)
+					}
+					status.mapRun(runerr)
 				}
 			}
-		}
-	}()
+		}(p)
+	}
 
 	wg.Add(1)
 	go func() {
@@ -586,6 +629,44 @@ func getMapTerrainImage(continent ps2.ContinentID) image.Image {
 }
 
 var mapdownloadmutex = sync.Mutex{}
+var maptilemutex = sync.Mutex{}
+
+// terrainTileSize is the size of the tiles that full size terrain images are split into.
+const terrainTileSize = 512
+
+// getFullsizeMapTerrain returns the full size terrain of continent as tiles,
+// so that renders only decode the part of the terrain they need.
+// The full image is only decoded the first time, to write the tiles.
+// The low resolution embedded image is returned when the full size image isn't available.
+func getFullsizeMapTerrain(continent ps2.ContinentID) psmap.Terrain {
+	tiledir := filepath.Join(os.TempDir(), "mapgen-cache", continent.String()+"-tiles")
+	if tiles, err := psmap.OpenTiles(tiledir, 0); err == nil {
+		return tiles
+	}
+
+	maptilemutex.Lock()
+	defer maptilemutex.Unlock()
+	// another render may have written the tiles while we were waiting
+	if tiles, err := psmap.OpenTiles(tiledir, 0); err == nil {
+		return tiles
+	}
+	img := getFullsizeMapTerrainImage(continent)
+	if img.Bounds().Dx() <= terrainDimensions {
+		// the fallback image is small enough to keep in memory
+		return psmap.ImageTerrain(img)
+	}
+	slog.Debug("writing terrain tiles", "zone", continent, "dir", tiledir)
+	if err := psmap.WriteImageTiles(tiledir, img, terrainTileSize); err != nil {
+		slog.Info("failed to write terrain tiles", "zone", continent, "error", err)
+		return psmap.ImageTerrain(img)
+	}
+	tiles, err := psmap.OpenTiles(tiledir, 0)
+	if err != nil {
+		slog.Info("failed to open terrain tiles", "zone", continent, "error", err)
+		return psmap.ImageTerrain(img)
+	}
+	return tiles
+}
 
 func getFullsizeMapTerrainImage(continent ps2.ContinentID) image.Image {
 	mapdownloadmutex.Lock()
@@ -628,7 +709,7 @@ func getFullsizeMapTerrainImage(continent ps2.ContinentID) image.Image {
 
 		response, err := http.Get(url)
 		if err != nil {
-			slog.Info(errstring, "zone", continent, "error", err, "statuscode", response.StatusCode, "url", url)
+			slog.Info(errstring, "zone", continent, "error", err, "url", url)
 			return getMapTerrainImage(continent)
 		}
 		defer response.Body.Close()
@@ -814,7 +895,7 @@ func RenderMapStateJSON(data psmap.Map, mapstate psmap.State) io.ReadCloser {
 	return r
 }
 
-func RenderCroppedMapRegionPNG(terrainLOD image.Image, mapdata psmap.Map, reg psmap.Region, trim bool) io.ReadCloser {
+func RenderCroppedMapRegionPNG(terrainLOD psmap.Terrain, mapdata psmap.Map, reg psmap.Region, trim bool) io.ReadCloser {
 	// img := image.NewRGBA(image.Rect(0, 0, 200, 200))
 	// img := base.SubImage(image.Rect(200, 200, 400, 400))
 	r, w := io.Pipe()
@@ -832,8 +913,12 @@ func RenderCroppedMapRegionPNG(terrainLOD image.Image, mapdata psmap.Map, reg ps
 		return renderErr(err)
 	}
 
+	terrain, err := terrainLOD.ReadRect(regionBounds)
+	if err != nil {
+		return renderErr(err)
+	}
 	img := image.NewRGBA(regionBounds)
-	draw.Draw(img, regionBounds, terrainLOD, regionBounds.Min, draw.Src)
+	draw.Draw(img, regionBounds, terrain, regionBounds.Min, draw.Src)
 
 	scale := float64(terrainLOD.Bounds().Dx()) / float64(mapdata.Size)
 
@@ -876,17 +961,21 @@ func RenderMapRegionPNG(region ps2.RegionID) io.ReadCloser {
 	if err != nil {
 		return renderErr(err)
 	}
-	terrainImage := getFullsizeMapTerrainImage(continent) //todo: get LOD0
+	terrainLOD := getFullsizeMapTerrain(continent) //todo: get LOD0
 
-	regionBounds, err := psmap.Bounds(terrainImage.Bounds(), mapdata, reg.Hexes)
+	regionBounds, err := psmap.Bounds(terrainLOD.Bounds(), mapdata, reg.Hexes)
 	if err != nil {
 		return renderErr(err)
 	}
 
+	terrainImage, err := terrainLOD.ReadRect(regionBounds)
+	if err != nil {
+		return renderErr(err)
+	}
 	img := image.NewRGBA(regionBounds)
 	draw.Draw(img, regionBounds, terrainImage, regionBounds.Min, draw.Src)
 
-	scale := float64(terrainImage.Bounds().Dx()) / float64(mapdata.Size)
+	scale := float64(terrainLOD.Bounds().Dx()) / float64(mapdata.Size)
 	mask, err := psmap.GenerateMask(regionBounds, mapdata, reg.Hexes, scale, regionBounds.Min, color.Transparent, color.Opaque)
 	if err != nil {
 		return renderErr(err)
@@ -914,10 +1003,14 @@ func RenderMapLoc(zone ps2.ContinentID, loc psmap.Loc) io.ReadCloser {
 		return renderErr(err)
 	}
 
-	terrainImage := getFullsizeMapTerrainImage(zone)
+	terrainLOD := getFullsizeMapTerrain(zone)
 
 	// get bounds for an area within the map terrain image with our loc in the center
-	regionBounds, err := psmap.LocBounds(terrainImage.Bounds(), mapdata, loc)
+	regionBounds, err := psmap.LocBounds(terrainLOD.Bounds(), mapdata, loc)
+	if err != nil {
+		return renderErr(err)
+	}
+	terrainImage, err := terrainLOD.ReadRect(regionBounds)
 	if err != nil {
 		return renderErr(err)
 	}
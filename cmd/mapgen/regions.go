@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+// regionRenderVersion is part of every region hash.
+// Increment it when a change to rendering should regenerate every region image.
+const regionRenderVersion = 1
+
+// regionMemoryBudget limits the estimated memory used by region images being rendered at once.
+// Regions cropped from full size terrain can be thousands of pixels across,
+// so the number of workers alone doesn't bound memory.
+const regionMemoryBudget = 512 << 20
+
+// regionBytesPerPixel estimates the memory used per pixel of a region being rendered:
+// the terrain read from the tiles, the cropped image, and its mask.
+const regionBytesPerPixel = 12
+
+// regionHashFile stores the hash of every region image written to a directory,
+// so that later runs can skip regions that haven't changed.
+const regionHashFile = "region-hashes.json"
+
+// runCropAllRegionsMode writes cropped region and facility images for zone,
+// or for every zone when zone is 0.
+// Regions are rendered by a pool of workers,
+// and regions whose hash matches the previous run are skipped when their images still exist.
+func runCropAllRegionsMode(ctx context.Context, dir string, zone ps2.ContinentID) error {
+	if err := os.MkdirAll(filepath.Join(dir, "regions"), 0750); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "facilities"), 0750); err != nil {
+		return err
+	}
+	hashes := readRegionHashes(dir)
+	defer func() {
+		if err := writeRegionHashes(dir, hashes); err != nil {
+			slog.Info("failed to save region hashes", "error", err)
+		}
+	}()
+
+	for _, mapdata := range maps {
+		continent, err := mapdata.ZoneID.ContinentID()
+		if err != nil {
+			slog.Debug("skipping zone", "zone", mapdata.ZoneID, "error", err)
+			continue
+		}
+		if zone != 0 && continent != zone {
+			continue
+		}
+		// tiles are only decoded as regions need them, and the tile cache is dropped before the next zone
+		cropZoneRegions(ctx, dir, getFullsizeMapTerrain(continent), mapdata, hashes)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cropZoneRegions renders the regions of mapdata that changed since their hash was saved in hashes,
+// updating hashes for every region that was written.
+func cropZoneRegions(ctx context.Context, dir string, terrainLOD psmap.Terrain, mapdata psmap.Map, hashes *regionHashes) {
+	budget := newMemoryBudget(regionMemoryBudget)
+	jobs := make(chan psmap.Region)
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for region := range jobs {
+				hash := regionHash(terrainLOD, mapdata, region)
+				if hashes.get(region.RegionID) == hash && regionFilesExist(dir, region) {
+					slog.Debug("skipping unchanged region", "region", region.RegionID)
+					continue
+				}
+				bounds, err := psmap.Bounds(terrainLOD.Bounds(), mapdata, region.Hexes)
+				if err != nil {
+					slog.Info("failed to find region bounds", "region", region.RegionID, "error", err)
+					continue
+				}
+				cost := int64(bounds.Dx()) * int64(bounds.Dy()) * regionBytesPerPixel
+				if err := budget.acquire(ctx, cost); err != nil {
+					continue
+				}
+				err = writeRegionFiles(dir, terrainLOD, mapdata, region)
+				budget.release(cost)
+				if err != nil {
+					slog.Info("failed to write map region", "region", region.RegionID, "error", err)
+					continue
+				}
+				hashes.set(region.RegionID, hash)
+			}
+		}()
+	}
+
+	for _, region := range mapdata.Regions {
+		if len(region.Hexes) == 0 {
+			slog.Debug("skipping region", "region", region.RegionID, "error", "empty hex list")
+			continue
+		}
+		select {
+		case jobs <- region:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// writeRegionFiles writes the cropped image of region to the regions directory,
+// and to the facilities directory when the region has a facility.
+func writeRegionFiles(dir string, terrainLOD psmap.Terrain, mapdata psmap.Map, region psmap.Region) error {
+	regionfile, err := os.Create(filepath.Join(dir, "regions", fmt.Sprintf("%d.png", region.RegionID)))
+	if err != nil {
+		return err
+	}
+	defer regionfile.Close()
+	var w io.Writer = regionfile
+	if region.FacilityID != 0 {
+		facilityfile, err := os.Create(filepath.Join(dir, "facilities", fmt.Sprintf("%d.png", region.FacilityID)))
+		if err != nil {
+			return err
+		}
+		defer facilityfile.Close()
+		w = io.MultiWriter(regionfile, facilityfile)
+	}
+	imgrc := RenderCroppedMapRegionPNG(terrainLOD, mapdata, region, true)
+	defer imgrc.Close()
+	_, err = io.Copy(w, imgrc)
+	return err
+}
+
+// regionFilesExist reports whether the images written by writeRegionFiles for region exist.
+func regionFilesExist(dir string, region psmap.Region) bool {
+	if _, err := os.Stat(filepath.Join(dir, "regions", fmt.Sprintf("%d.png", region.RegionID))); err != nil {
+		return false
+	}
+	if region.FacilityID == 0 {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "facilities", fmt.Sprintf("%d.png", region.FacilityID)))
+	return err == nil
+}
+
+// regionHash returns a hash of everything that affects the image of region:
+// its hexes, the size of the map, and the size of the terrain it's cropped from.
+// The terrain size changes when full size terrain replaces the embedded fallback image.
+func regionHash(terrainLOD psmap.Terrain, mapdata psmap.Map, region psmap.Region) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d %d %d %v %d\n", regionRenderVersion, mapdata.Size, mapdata.HexSize, terrainLOD.Bounds(), region.FacilityID)
+	for _, x := range region.Hexes {
+		fmt.Fprintf(h, "%d,%d,%d\n", x.X, x.Y, x.Type)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// regionHashes holds the hash of each region image written so far.
+type regionHashes struct {
+	mu     sync.Mutex
+	hashes map[ps2.RegionID]string
+}
+
+func (h *regionHashes) get(id ps2.RegionID) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hashes[id]
+}
+
+func (h *regionHashes) set(id ps2.RegionID, hash string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hashes[id] = hash
+}
+
+// readRegionHashes reads the region hashes saved in dir.
+// A missing or unreadable file regenerates every region.
+func readRegionHashes(dir string) *regionHashes {
+	h := &regionHashes{hashes: make(map[ps2.RegionID]string)}
+	b, err := os.ReadFile(filepath.Join(dir, "regions", regionHashFile))
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(b, &h.hashes); err != nil {
+		slog.Info("ignoring invalid region hashes", "error", err)
+		h.hashes = make(map[ps2.RegionID]string)
+	}
+	return h
+}
+
+func writeRegionHashes(dir string, h *regionHashes) error {
+	h.mu.Lock()
+	b, err := json.Marshal(h.hashes)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "regions", regionHashFile), b, 0640)
+}
+
+// memoryBudget limits the total size of work in progress.
+type memoryBudget struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int64
+	used  int64
+}
+
+func newMemoryBudget(limit int64) *memoryBudget {
+	b := &memoryBudget{limit: limit}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// acquire waits until n bytes fit in the budget.
+// A single request larger than the whole budget waits until nothing else is using it,
+// so that huge regions are still rendered, one at a time.
+func (b *memoryBudget) acquire(ctx context.Context, n int64) error {
+	stop := context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.cond.Broadcast()
+	})
+	defer stop()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.used > 0 && b.used+n > b.limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.used += n
+	return nil
+}
+
+func (b *memoryBudget) release(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	b.cond.Broadcast()
+}
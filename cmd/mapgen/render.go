@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// renderCacheTTL is how long maps rendered by /render are reused.
+// It's short enough that bots always get a current map,
+// and long enough that a burst of requests for the same map only renders it once.
+const renderCacheTTL = 30 * time.Second
+
+// renderTimeout limits a single on-demand render, including its census request.
+const renderTimeout = 30 * time.Second
+
+// renderServer renders single maps on request:
+//
+//	/render?world=osprey&zone=indar&format=thumbnail
+//
+// format is one of the keys of formats and defaults to "image".
+// Concurrent requests for the same map share one render.
+type renderServer struct {
+	mu       sync.Mutex
+	rendered map[renderKey]*renderCall
+}
+
+type renderKey struct {
+	world  ps2.WorldID
+	zone   ps2.ContinentID
+	format string
+}
+
+// renderCall is a render that other requests can wait on instead of starting their own.
+// It stays in the cache after it finishes until it expires.
+type renderCall struct {
+	done    chan struct{}
+	body    []byte
+	err     error
+	expires time.Time
+}
+
+func newRenderServer() *renderServer {
+	return &renderServer{rendered: make(map[renderKey]*renderCall)}
+}
+
+func (s *renderServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	key := renderKey{
+		world:  parseWorld(q.Get("world")),
+		zone:   parseZone(q.Get("zone")),
+		format: q.Get("format"),
+	}
+	if key.format == "" {
+		key.format = "image"
+	}
+	format, found := formats[key.format]
+	if !found {
+		http.Error(w, "unknown format", http.StatusBadRequest)
+		return
+	}
+	if key.world == 0 || key.zone == 0 {
+		http.Error(w, "world and zone must be valid names", http.StatusBadRequest)
+		return
+	}
+
+	call := s.render(r.Context(), key, format)
+	select {
+	case <-call.done:
+	case <-r.Context().Done():
+		return
+	}
+	if call.err != nil {
+		slog.InfoContext(r.Context(), "on-demand render failed", "world", key.world, "zone", key.zone, "format", key.format, "error", call.err)
+		if errors.Is(call.err, errNotFound) {
+			http.Error(w, "map not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "unable to render map", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", format.mimetype)
+	w.Header().Set("Content-Length", strconv.Itoa(len(call.body)))
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(time.Until(call.expires)/time.Second)))
+	w.Write(call.body)
+}
+
+// render returns the cached render of key, starting a new one if there isn't one that's current.
+func (s *renderServer) render(ctx context.Context, key renderKey, format renderable) *renderCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, c := range s.rendered {
+		if c.finished() && now.After(c.expires) {
+			delete(s.rendered, k)
+		}
+	}
+	if c, found := s.rendered[key]; found {
+		return c
+	}
+
+	call := &renderCall{done: make(chan struct{})}
+	s.rendered[key] = call
+	go func() {
+		// a shared render shouldn't fail because the request that started it went away
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), renderTimeout)
+		defer cancel()
+		rc := NewRenderZoneReader(ctx, key.world, key.zone, format.fn)
+		call.body, call.err = io.ReadAll(rc)
+		rc.Close()
+
+		s.mu.Lock()
+		call.expires = time.Now().Add(renderCacheTTL)
+		if call.err != nil {
+			// failures aren't cached so that the next request tries again
+			delete(s.rendered, key)
+		}
+		s.mu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
+
+func (c *renderCall) finished() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+// webTileSize is the size of the tiles in a tile pyramid,
+// which is the default tile size of web map libraries like Leaflet.
+const webTileSize = 256
+
+// tilePyramid describes the tile pyramids of a zone.
+// It's written next to the layers so that web maps know how far they can zoom in.
+type tilePyramid struct {
+	MaxZoom  int `json:"max_zoom"`
+	TileSize int `json:"tile_size"`
+}
+
+// runTilesMode writes XYZ tile pyramids for zone, or for every zone when zone is 0.
+// The terrain is written to dir/tiles/{zone}/terrain/{z}/{x}/{y}.png.
+// When world is given, the territory of the world is also written as a transparent layer to dir/tiles/{zone}/{world}/{z}/{x}/{y}.png,
+// to be shown on top of the terrain.
+// The deepest zoom level has the resolution of the full size terrain,
+// and each level above it is half the size of the one below.
+func runTilesMode(ctx context.Context, dir string, world ps2.WorldID, zone ps2.ContinentID) error {
+	for _, mapdata := range maps {
+		continent, err := mapdata.ZoneID.ContinentID()
+		if err != nil {
+			slog.Debug("skipping zone", "zone", mapdata.ZoneID, "error", err)
+			continue
+		}
+		if zone != 0 && continent != zone {
+			continue
+		}
+		zonedir := filepath.Join(dir, "tiles", zoneName(continent))
+		terrain := getFullsizeMapTerrain(continent)
+		pyramid := tilePyramid{MaxZoom: maxTileZoom(terrain.Bounds().Dx()), TileSize: webTileSize}
+		slog.Debug("writing terrain tiles", "zone", continent, "max_zoom", pyramid.MaxZoom)
+		if err := writeTilePyramid(ctx, filepath.Join(zonedir, "terrain"), terrain.Bounds(), pyramid.MaxZoom, terrain.ReadRect); err != nil {
+			return fmt.Errorf("terrain tiles for %s: %w", zoneName(continent), err)
+		}
+		if err := writeTilePyramidIndex(zonedir, pyramid); err != nil {
+			return err
+		}
+		if world == 0 {
+			continue
+		}
+
+		states, err := psmap.GetMapState(ctx, world, ps2.ZoneInstanceID(continent))
+		if err != nil {
+			return fmt.Errorf("failed to get map state: %w", err)
+		}
+		if len(states) < 1 {
+			return errNotFound
+		}
+		// the overlay is drawn once at the size of the terrain so that outlines line up at every zoom level
+		overlay := image.NewRGBA(image.Rect(0, 0, terrain.Bounds().Dx(), terrain.Bounds().Dx()))
+		if err := psmap.Draw(overlay, mapdata, states[0]); err != nil {
+			return fmt.Errorf("unable to draw map: %w", err)
+		}
+		readOverlay := func(r image.Rectangle) (image.Image, error) {
+			return overlay.SubImage(r.Sub(terrain.Bounds().Min)), nil
+		}
+		slog.Debug("writing territory tiles", "zone", continent, "world", world)
+		if err := writeTilePyramid(ctx, filepath.Join(zonedir, worldName(world)), terrain.Bounds(), pyramid.MaxZoom, readOverlay); err != nil {
+			return fmt.Errorf("territory tiles for %s: %w", zoneName(continent), err)
+		}
+	}
+	return nil
+}
+
+// maxTileZoom returns the zoom level where one tile covers at most webTileSize pixels of an image that's size pixels across.
+func maxTileZoom(size int) int {
+	z := 0
+	for webTileSize<<z < size {
+		z++
+	}
+	return z
+}
+
+// writeTilePyramid writes tiles of the image inside bounds to dir for every zoom level from maxZoom to 0.
+// The tiles of maxZoom are read with read,
+// and the tiles of each level above are scaled down from the four tiles below them,
+// so that the whole image never has to be in memory at once.
+func writeTilePyramid(ctx context.Context, dir string, bounds image.Rectangle, maxZoom int, read func(image.Rectangle) (image.Image, error)) error {
+	n := 1 << maxZoom
+	for x := range n {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for y := range n {
+			r := image.Rect(
+				bounds.Min.X+x*bounds.Dx()/n,
+				bounds.Min.Y+y*bounds.Dy()/n,
+				bounds.Min.X+(x+1)*bounds.Dx()/n,
+				bounds.Min.Y+(y+1)*bounds.Dy()/n,
+			)
+			src, err := read(r)
+			if err != nil {
+				return err
+			}
+			if err := writeTile(dir, maxZoom, x, y, src, r); err != nil {
+				return err
+			}
+		}
+	}
+
+	children := image.NewRGBA(image.Rect(0, 0, 2*webTileSize, 2*webTileSize))
+	for z := maxZoom - 1; z >= 0; z-- {
+		n := 1 << z
+		for x := range n {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for y := range n {
+				for i := range 4 {
+					dx, dy := i%2, i/2
+					child, err := readTile(dir, z+1, 2*x+dx, 2*y+dy)
+					if err != nil {
+						return err
+					}
+					at := image.Rect(dx*webTileSize, dy*webTileSize, (dx+1)*webTileSize, (dy+1)*webTileSize)
+					draw.Draw(children, at, child, child.Bounds().Min, draw.Src)
+				}
+				if err := writeTile(dir, z, x, y, children, children.Bounds()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeTile scales the part of src inside r to a single tile and writes it to dir/{z}/{x}/{y}.png.
+func writeTile(dir string, z, x, y int, src image.Image, r image.Rectangle) error {
+	tile := image.NewRGBA(image.Rect(0, 0, webTileSize, webTileSize))
+	if r.Dx() == webTileSize && r.Dy() == webTileSize {
+		draw.Draw(tile, tile.Bounds(), src, r.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(tile, tile.Bounds(), src, r, draw.Src, nil)
+	}
+	name := tileFilename(dir, z, x, y)
+	if err := os.MkdirAll(filepath.Dir(name), 0750); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, tile); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readTile(dir string, z, x, y int) (image.Image, error) {
+	f, err := os.Open(tileFilename(dir, z, x, y))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func tileFilename(dir string, z, x, y int) string {
+	return filepath.Join(dir, fmt.Sprint(z), fmt.Sprint(x), fmt.Sprintf("%d.png", y))
+}
+
+func writeTilePyramidIndex(dir string, pyramid tilePyramid) error {
+	b, err := json.Marshal(pyramid)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "tiles.json"), b, 0640)
+}
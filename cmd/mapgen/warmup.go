@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// warmupSpacing is the delay between census requests during a staged warm-up.
+// The "example" service ID is heavily rate limited,
+// and a burst of failures at startup would trip the census circuit breaker.
+const warmupSpacing = 5 * time.Second
+
+// lowQuotaServiceID is the public service ID that always gets a staged warm-up.
+const lowQuotaServiceID = "example"
+
+// serverStatus tracks server mode startup and is served as json on /health.
+type serverStatus struct {
+	mu sync.Mutex
+
+	// Stage is "maps" while rendering live maps, "regions" while cropping region images, and "ready" after.
+	Stage       string    `json:"stage"`
+	MapsDone    int       `json:"maps_done"`  // MapsDone is the number of world batches rendered so far
+	MapsTotal   int       `json:"maps_total"` // MapsTotal is the number of world batches to render during warm-up
+	MapErrors   int       `json:"map_errors"`
+	Started     time.Time `json:"started"`
+	ReadySince  time.Time `json:"ready_since"`
+	Staged      bool      `json:"staged"`
+	LastMapRun  time.Time `json:"last_map_run"`
+	LastMapFail string    `json:"last_map_error,omitempty"`
+}
+
+func newServerStatus(staged bool) *serverStatus {
+	return &serverStatus{Stage: "maps", Started: time.Now(), Staged: staged}
+}
+
+func (s *serverStatus) update(fn func(s *serverStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s)
+}
+
+// mapRun records the result of rendering a batch of live maps.
+func (s *serverStatus) mapRun(err error) {
+	s.update(func(s *serverStatus) {
+		s.LastMapRun = time.Now()
+		if err != nil {
+			s.MapErrors++
+			s.LastMapFail = err.Error()
+		}
+	})
+}
+
+// ServeHTTP responds with the status as json.
+// The response code is 503 until live maps are available so that load balancers can wait for warm-up.
+func (s *serverStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	b, err := json.Marshal(s)
+	available := s.Stage != "maps"
+	s.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !available {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(b)
+}
+
+// warmUp renders live maps one world at a time with warmupSpacing between census requests,
+// then generates the region and facility crops that don't change between updates.
+func warmUp(ctx context.Context, dir string, profiles []renderProfile, status *serverStatus) {
+	type batch struct {
+		profile renderProfile
+		world   ps2.WorldID
+	}
+	var batches []batch
+	for _, p := range profiles {
+		worlds := p.worlds
+		if len(worlds) == 0 {
+			worlds = defaultWorlds
+		}
+		for _, w := range worlds {
+			batches = append(batches, batch{p, w})
+		}
+	}
+	status.update(func(s *serverStatus) { s.MapsTotal = len(batches) })
+
+	for i, b := range batches {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(warmupSpacing):
+			}
+		}
+		p := b.profile
+		p.worlds = []ps2.WorldID{b.world}
+		err := runProfile(ctx, dir, p)
+		if err != nil {
+			slog.Info("warm-up: failed to generate maps", "profile", p.name, "world", worldName(b.world), "error", err)
+		}
+		status.mapRun(err)
+		status.update(func(s *serverStatus) { s.MapsDone++ })
+	}
+
+	status.update(func(s *serverStatus) { s.Stage = "regions" })
+	slog.Info("warm-up: generating map region images")
+	if err := runCropAllRegionsMode(ctx, dir, 0); err != nil {
+		slog.Info("warm-up: failed to generate regions", "error", err)
+	}
+	status.update(func(s *serverStatus) {
+		s.Stage = "ready"
+		s.ReadySince = time.Now()
+	})
+	slog.Info("warm-up complete")
+}
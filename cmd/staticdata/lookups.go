@@ -0,0 +1,52 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// PrintLookups writes the region and facility lookup tables used by the ps2 package:
+// the facility, facility type, and continent of every region,
+// and the region of every facility.
+func PrintLookups(ctx context.Context, w io.Writer) error {
+	var regions []census.MapRegion
+	if err := census.LoadCollection(ctx, client, &regions); err != nil {
+		return fmt.Errorf("PrintLookups: %w", err)
+	}
+	writeLookups(w, regions)
+	return nil
+}
+
+// writeLookups writes the lookup tables for every region on a playable continent.
+func writeLookups(w io.Writer, regions []census.MapRegion) {
+	regions = slices.DeleteFunc(slices.Clone(regions), func(r census.MapRegion) bool {
+		return !ps2.IsPlayableZone(ps2.ContinentID(r.ZoneID))
+	})
+	slices.SortFunc(regions, func(a, b census.MapRegion) int { return cmp.Compare(a.MapRegionID, b.MapRegionID) })
+	regions = slices.CompactFunc(regions, func(a, b census.MapRegion) bool { return a.MapRegionID == b.MapRegionID })
+
+	fmt.Fprintln(w, "// Code generated by \"go run ./cmd/staticdata -lookups\"; DO NOT EDIT.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "package ps2")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "var regionTable = [...]regionEntry{")
+	for _, r := range regions {
+		fmt.Fprintf(w, "\t{%d, %d, %d},\n", r.MapRegionID, r.FacilityID, r.ZoneID)
+	}
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+
+	facilities := slices.DeleteFunc(slices.Clone(regions), func(r census.MapRegion) bool { return r.FacilityID == 0 })
+	slices.SortFunc(facilities, func(a, b census.MapRegion) int { return cmp.Compare(a.FacilityID, b.FacilityID) })
+	fmt.Fprintln(w, "var facilityTable = [...]facilityEntry{")
+	for _, r := range facilities {
+		fmt.Fprintf(w, "\t{%d, %d, %d},\n", r.FacilityID, r.MapRegionID, r.Type)
+	}
+	fmt.Fprintln(w, "}")
+}
@@ -17,13 +17,30 @@ var client *census.Client
 func main() {
 	var typ census.Zone
 	var censusKey string
+	var warpgates bool
+	var lookups bool
 	flag.StringVar(&censusKey, "key", "example", "Census API client key")
+	flag.BoolVar(&warpgates, "warpgates", false, "Print the warpgate region declarations for the ps2 package instead of saving collections")
+	flag.BoolVar(&lookups, "lookups", false, "Print the region and facility lookup tables for the ps2 package instead of saving collections")
 	flag.Parse()
 
 	client = &census.Client{
 		ServiceID: censusKey,
 	}
 
+	if warpgates {
+		if err := PrintWarpgates(context.Background(), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if lookups {
+		if err := PrintLookups(context.Background(), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := SaveCollectionToFile(".", typ); err != nil {
 		log.Fatal("couldn't save file: ", err)
 	}
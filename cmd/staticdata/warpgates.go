@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// PrintWarpgates writes the warpgate region declarations used by the ps2 package.
+func PrintWarpgates(ctx context.Context, w io.Writer) error {
+	var response struct {
+		Regions []census.MapRegion `json:"map_region_list"`
+	}
+	query := fmt.Sprintf("map_region?facility_type_id=%d&c:limit=500&c:sort=map_region_id", ps2.Warpgate)
+	if err := client.Get(ctx, ps2.PC, query, &response); err != nil {
+		return fmt.Errorf("PrintWarpgates: %w", err)
+	}
+	regions := slices.DeleteFunc(response.Regions, func(r census.MapRegion) bool {
+		return !ps2.IsPlayableZone(ps2.ContinentID(r.ZoneID))
+	})
+
+	fmt.Fprintln(w, "const (")
+	for _, r := range regions {
+		fmt.Fprintf(w, "\t%s RegionID = %d\n", warpgateConst(r), r.MapRegionID)
+	}
+	fmt.Fprintln(w, ")")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "var WarpgateRegions = map[ContinentID][]WarpgateRegion{")
+	var zone ps2.ZoneID
+	for _, r := range regions {
+		if r.ZoneID != zone {
+			if zone != 0 {
+				fmt.Fprintln(w, "\t},")
+			}
+			zone = r.ZoneID
+			fmt.Fprintf(w, "\t%d: {\n", zone)
+		}
+		fmt.Fprintf(w, "\t\t{%s, %d, %s, %q},\n", warpgateConst(r), r.ZoneID, compassConst(r), r.Name)
+	}
+	if zone != 0 {
+		fmt.Fprintln(w, "\t},")
+	}
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+func warpgateConst(r census.MapRegion) string {
+	return strings.Join(strings.Fields(r.Name), "")
+}
+
+// compassConst returns the compass position named by the facility,
+// falling back to the direction of the facility location from the center of the continent.
+// Locations alone don't work well because the gates aren't evenly placed around the center;
+// the Esamir Northern Warpgate is closer to northwest.
+func compassConst(r census.MapRegion) string {
+	names := []string{"North", "Northeast", "East", "Southeast", "South", "Southwest", "West", "Northwest"}
+	for _, word := range strings.Fields(r.Name) {
+		word = strings.TrimSuffix(word, "ern")
+		for _, name := range names {
+			if word == name {
+				return "Compass" + name
+			}
+		}
+	}
+	bearing := math.Atan2(r.LocationZ, r.LocationX) * 180 / math.Pi
+	i := int(math.Round(bearing/45)+8) % 8
+	return "Compass" + names[i]
+}
@@ -0,0 +1,106 @@
+// Package experience classifies experience IDs from the census experience collection,
+// for building GainExperience subscriptions and sorting GainExperience events by what they were awarded for.
+//
+//	catalog, err := experience.Load(ctx, nil)
+//	sub := wsc.Subscribe{ExperienceIDs: catalog.AllReviveIDs()}
+//	client.AddHandler(func(e event.GainExperience) {
+//		if catalog.AwardType(e.ExperienceID) == ps2.SquadRevive { ... }
+//	})
+package experience
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// Catalog looks up experience by ID.
+// A Catalog is safe for concurrent use since it's never modified after it's built.
+type Catalog struct {
+	byID map[ps2.ExperienceID]census.Experience
+}
+
+// Load builds a Catalog from the census experience collection.
+// A nil client uses [census.DefaultClient].
+func Load(ctx context.Context, client *census.Client) (*Catalog, error) {
+	var rows []census.Experience
+	if err := census.LoadCollection(ctx, client, &rows); err != nil {
+		return nil, fmt.Errorf("experience.Load: %w", err)
+	}
+	return New(rows), nil
+}
+
+// New builds a Catalog from rows of the experience collection,
+// such as a copy saved from an earlier [Load].
+func New(rows []census.Experience) *Catalog {
+	c := &Catalog{byID: make(map[ps2.ExperienceID]census.Experience, len(rows))}
+	for _, row := range rows {
+		c.byID[row.ExperienceID] = row
+	}
+	return c
+}
+
+// Lookup returns the experience row for id.
+func (c *Catalog) Lookup(id ps2.ExperienceID) (census.Experience, bool) {
+	e, found := c.byID[id]
+	return e, found
+}
+
+// Description returns the census description of id, like "Revive",
+// or an empty string when id isn't in the catalog.
+func (c *Catalog) Description(id ps2.ExperienceID) string {
+	return c.byID[id].Description
+}
+
+// AwardType returns the award type of id, like [ps2.Revive],
+// or 0 when id isn't in the catalog.
+func (c *Catalog) AwardType(id ps2.ExperienceID) ps2.ExperienceAwardTypeID {
+	return c.byID[id].ExperienceAwardTypeID
+}
+
+// IDs returns every experience ID with one of the award types, sorted.
+func (c *Catalog) IDs(types ...ps2.ExperienceAwardTypeID) []ps2.ExperienceID {
+	var ids []ps2.ExperienceID
+	for id, e := range c.byID {
+		if slices.Contains(types, e.ExperienceAwardTypeID) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// AllReviveIDs returns the experience IDs awarded for reviving a player, including squad revives.
+func (c *Catalog) AllReviveIDs() []ps2.ExperienceID {
+	return c.IDs(ps2.Revive, ps2.SquadRevive)
+}
+
+// AllRepairIDs returns the experience IDs awarded for repairing vehicles, turrets, and other objects,
+// including squad repairs.
+func (c *Catalog) AllRepairIDs() []ps2.ExperienceID {
+	return c.IDs(ps2.Repair, ps2.SquadRepair)
+}
+
+// AllSpawnIDs returns the experience IDs awarded when a player spawns on something someone else deployed,
+// such as a vehicle, a squad member, or a generic NPC spawn point.
+func (c *Catalog) AllSpawnIDs() []ps2.ExperienceID {
+	return c.IDs(ps2.PlayerSpawnAtVehicle, ps2.SquadSpawn, ps2.GenericNpcSpawn)
+}
+
+// EventObjectiveIDs returns the experience IDs awarded for the objectives of alerts that are won by points,
+// Aerial Anomalies and Forgotten Carrier, recognized by their descriptions.
+func (c *Catalog) EventObjectiveIDs() []ps2.ExperienceID {
+	var ids []ps2.ExperienceID
+	for id, e := range c.byID {
+		d := strings.ToLower(e.Description)
+		if strings.Contains(d, "anomaly") || strings.Contains(d, "carrier") {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
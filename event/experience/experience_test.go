@@ -0,0 +1,46 @@
+package experience_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event/experience"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog := experience.New([]census.Experience{
+		{ExperienceID: 53, Description: "Squad Revive", ExperienceAwardTypeID: ps2.SquadRevive},
+		{ExperienceID: 7, Description: "Revive", ExperienceAwardTypeID: ps2.Revive},
+		{ExperienceID: 90, Description: "Repair Sunderer", ExperienceAwardTypeID: ps2.Repair},
+		{ExperienceID: 56, Description: "Squad Spawn", ExperienceAwardTypeID: ps2.SquadSpawn},
+		{ExperienceID: 1, Description: "Kill Player", ExperienceAwardTypeID: ps2.Kill},
+	})
+	if got, want := catalog.AllReviveIDs(), []ps2.ExperienceID{7, 53}; !slices.Equal(got, want) {
+		t.Errorf("got revive IDs %v; want %v", got, want)
+	}
+	if got, want := catalog.AllRepairIDs(), []ps2.ExperienceID{90}; !slices.Equal(got, want) {
+		t.Errorf("got repair IDs %v; want %v", got, want)
+	}
+	if got, want := catalog.AllSpawnIDs(), []ps2.ExperienceID{56}; !slices.Equal(got, want) {
+		t.Errorf("got spawn IDs %v; want %v", got, want)
+	}
+	if catalog.AwardType(53) != ps2.SquadRevive || catalog.Description(1) != "Kill Player" {
+		t.Errorf("lookups returned the wrong rows")
+	}
+	if _, found := catalog.Lookup(2); found {
+		t.Errorf("found an experience ID that isn't in the catalog")
+	}
+}
+
+func TestEventObjectiveIDs(t *testing.T) {
+	catalog := experience.New([]census.Experience{
+		{ExperienceID: 1, Description: "Kill Player", ExperienceAwardTypeID: ps2.Kill},
+		{ExperienceID: 1993, Description: "Aerial Anomaly Capture", ExperienceAwardTypeID: ps2.MetaGameEvent},
+		{ExperienceID: 1409, Description: "Carrier Damage", ExperienceAwardTypeID: ps2.MetaGameEvent},
+	})
+	if got, want := catalog.EventObjectiveIDs(), []ps2.ExperienceID{1409, 1993}; !slices.Equal(got, want) {
+		t.Errorf("got objective IDs %v; want %v", got, want)
+	}
+}
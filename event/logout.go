@@ -0,0 +1,186 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// DefaultLogoutWindow is how long a character can go without any events before a logout is inferred.
+// Census sometimes drops PlayerLogout events,
+// and two hours is long enough that even an AFK player in a sanctuary will usually have been kicked.
+const DefaultLogoutWindow = 2 * time.Hour
+
+// LogoutInferred is a derived event for a character that stopped appearing in events without a PlayerLogout.
+// It is created by a [LogoutInferrer] and never sent by census.
+type LogoutInferred struct {
+	CharacterID ps2.CharacterID
+	WorldID     ps2.WorldID
+
+	// LastSeen is the timestamp of the last event the character appeared in,
+	// which is the best estimate of when they logged out.
+	LastSeen time.Time
+
+	// Timestamp is when the logout was inferred.
+	Timestamp time.Time
+}
+
+func (LogoutInferred) Type() ps2.Event   { return ps2.LogoutInferred }
+func (e LogoutInferred) Time() time.Time { return e.Timestamp }
+
+// LogoutInferrer watches character events and emits [LogoutInferred] for characters
+// that haven't been seen within a window and never logged out.
+//
+//	inferrer := event.NewLogoutInferrer(event.DefaultLogoutWindow)
+//	inferrer.AttachHandlers(client)
+//	inferrer.OnLogoutInferred(func(e event.LogoutInferred) { ... })
+//	go inferrer.Run(ctx)
+type LogoutInferrer struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	seen     map[ps2.CharacterID]lastSeen
+	handlers []func(LogoutInferred)
+}
+
+type lastSeen struct {
+	world ps2.WorldID
+	at    time.Time
+}
+
+// NewLogoutInferrer creates a LogoutInferrer that infers a logout after window without events.
+// A window of 0 uses [DefaultLogoutWindow].
+func NewLogoutInferrer(window time.Duration) *LogoutInferrer {
+	if window <= 0 {
+		window = DefaultLogoutWindow
+	}
+	return &LogoutInferrer{
+		window: window,
+		seen:   make(map[ps2.CharacterID]lastSeen),
+	}
+}
+
+// OnLogoutInferred registers f to be called for every inferred logout.
+func (l *LogoutInferrer) OnLogoutInferred(f func(LogoutInferred)) {
+	l.mu.Lock()
+	l.handlers = append(l.handlers, f)
+	l.mu.Unlock()
+}
+
+// AttachHandlers registers handlers for every character event with client,
+// such as a *wsc.Client.
+func (l *LogoutInferrer) AttachHandlers(client interface{ AddHandler(any) }) {
+	client.AddHandler(func(e PlayerLogin) { l.Observe(e) })
+	client.AddHandler(func(e PlayerLogout) { l.Observe(e) })
+	client.AddHandler(func(e GainExperience) { l.Observe(e) })
+	client.AddHandler(func(e VehicleDestroy) { l.Observe(e) })
+	client.AddHandler(func(e Death) { l.Observe(e) })
+	client.AddHandler(func(e AchievementEarned) { l.Observe(e) })
+	client.AddHandler(func(e BattleRankUp) { l.Observe(e) })
+	client.AddHandler(func(e ItemAdded) { l.Observe(e) })
+	client.AddHandler(func(e PlayerFacilityCapture) { l.Observe(e) })
+	client.AddHandler(func(e PlayerFacilityDefend) { l.Observe(e) })
+	client.AddHandler(func(e SkillAdded) { l.Observe(e) })
+	client.AddHandler(func(e FishScan) { l.Observe(e) })
+}
+
+// Observe records the characters in e as online,
+// or forgets them for PlayerLogout.
+// Events without characters are ignored.
+func (l *LogoutInferrer) Observe(e Typer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch e := e.(type) {
+	case PlayerLogout:
+		delete(l.seen, e.CharacterID)
+	case PlayerLogin:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case GainExperience:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case VehicleDestroy:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+		l.see(e.AttackerCharacterID, e.WorldID, e.Timestamp)
+	case Death:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+		l.see(e.AttackerCharacterID, e.WorldID, e.Timestamp)
+	case AchievementEarned:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case BattleRankUp:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case ItemAdded:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case PlayerFacilityCapture:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case PlayerFacilityDefend:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case SkillAdded:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	case FishScan:
+		l.see(e.CharacterID, e.WorldID, e.Timestamp)
+	}
+}
+
+// Seen records id as online in world at time at,
+// for characters known to be online without an event, such as from their census online status.
+func (l *LogoutInferrer) Seen(id ps2.CharacterID, world ps2.WorldID, at time.Time) {
+	l.mu.Lock()
+	l.see(id, world, at)
+	l.mu.Unlock()
+}
+
+func (l *LogoutInferrer) see(id ps2.CharacterID, world ps2.WorldID, at time.Time) {
+	if id == 0 {
+		return
+	}
+	if prev, ok := l.seen[id]; ok && prev.at.After(at) {
+		// events can arrive out of order
+		at = prev.at
+	}
+	l.seen[id] = lastSeen{world: world, at: at}
+}
+
+// Sweep emits LogoutInferred for every character not seen since now minus the window,
+// and stops tracking them.
+// The inferred logouts are also returned.
+func (l *LogoutInferrer) Sweep(now time.Time) []LogoutInferred {
+	l.mu.Lock()
+	var inferred []LogoutInferred
+	for id, s := range l.seen {
+		if now.Sub(s.at) < l.window {
+			continue
+		}
+		delete(l.seen, id)
+		inferred = append(inferred, LogoutInferred{
+			CharacterID: id,
+			WorldID:     s.world,
+			LastSeen:    s.at,
+			Timestamp:   now,
+		})
+	}
+	handlers := l.handlers
+	l.mu.Unlock()
+
+	for _, e := range inferred {
+		for _, h := range handlers {
+			h(e)
+		}
+	}
+	return inferred
+}
+
+// Run calls Sweep periodically until ctx is cancelled.
+func (l *LogoutInferrer) Run(ctx context.Context) {
+	interval := min(max(l.window/10, time.Second), 5*time.Minute)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.Sweep(now)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package event_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestLogoutInferredType(t *testing.T) {
+	var e event.Typer = event.LogoutInferred{}
+	if e.Type() != ps2.LogoutInferred || e.Type().String() != "LogoutInferred" {
+		t.Errorf("got type %v; want LogoutInferred", e.Type())
+	}
+	for _, e := range ps2.AllEvents() {
+		if e == ps2.LogoutInferred {
+			t.Error("AllEvents includes LogoutInferred, which census never sends")
+		}
+	}
+}
+
+func TestLogoutInferrer(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	l := event.NewLogoutInferrer(time.Hour)
+	var handled []event.LogoutInferred
+	l.OnLogoutInferred(func(e event.LogoutInferred) { handled = append(handled, e) })
+
+	l.Observe(event.PlayerLogin{CharacterID: 1, WorldID: ps2.Emerald, Timestamp: start})
+	l.Observe(event.Death{CharacterID: 2, AttackerCharacterID: 3, WorldID: ps2.Emerald, Timestamp: start})
+	// events can arrive out of order, and the latest one counts
+	l.Observe(event.GainExperience{CharacterID: 2, WorldID: ps2.Emerald, Timestamp: start.Add(30 * time.Minute)})
+	l.Observe(event.GainExperience{CharacterID: 2, WorldID: ps2.Emerald, Timestamp: start.Add(10 * time.Minute)})
+	// character 4 logged out properly
+	l.Observe(event.PlayerLogin{CharacterID: 4, WorldID: ps2.Emerald, Timestamp: start})
+	l.Observe(event.PlayerLogout{CharacterID: 4, WorldID: ps2.Emerald, Timestamp: start.Add(time.Minute)})
+	// character 5 was found online without an event
+	l.Seen(5, ps2.Wainwright, start)
+	// events without a character are ignored
+	l.Observe(event.FacilityControl{WorldID: ps2.Emerald, Timestamp: start})
+
+	if inferred := l.Sweep(start.Add(59 * time.Minute)); len(inferred) != 0 {
+		t.Fatalf("got %d logouts inferred within the window; want 0", len(inferred))
+	}
+
+	inferred := l.Sweep(start.Add(time.Hour))
+	got := make(map[ps2.CharacterID]event.LogoutInferred)
+	for _, e := range inferred {
+		got[e.CharacterID] = e
+	}
+	if len(got) != 3 || len(handled) != 3 {
+		t.Fatalf("got %d logouts inferred and %d handled; want characters 1, 3, and 5", len(got), len(handled))
+	}
+	if e := got[1]; e.WorldID != ps2.Emerald || !e.LastSeen.Equal(start) || !e.Timestamp.Equal(start.Add(time.Hour)) {
+		t.Errorf("got %+v for character 1", e)
+	}
+	if _, found := got[3]; !found {
+		t.Error("attacker 3 wasn't tracked")
+	}
+	if e := got[5]; e.WorldID != ps2.Wainwright {
+		t.Errorf("got %+v for character 5; want Wainwright", e)
+	}
+
+	// character 2 was last seen half an hour later
+	inferred = l.Sweep(start.Add(90 * time.Minute))
+	if len(inferred) != 1 || inferred[0].CharacterID != 2 || !inferred[0].LastSeen.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("got %+v; want character 2 last seen at 30 minutes", inferred)
+	}
+
+	// inferred characters are only inferred once
+	if inferred := l.Sweep(start.Add(24 * time.Hour)); len(inferred) != 0 {
+		t.Errorf("got %+v after every character was inferred; want none", inferred)
+	}
+}
+
+func TestLogoutInferrerDefaultWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	l := event.NewLogoutInferrer(0)
+	l.Observe(event.PlayerLogin{CharacterID: 1, WorldID: ps2.Emerald, Timestamp: start})
+	if inferred := l.Sweep(start.Add(event.DefaultLogoutWindow - time.Second)); len(inferred) != 0 {
+		t.Errorf("got %+v before the default window; want none", inferred)
+	}
+	if inferred := l.Sweep(start.Add(event.DefaultLogoutWindow)); len(inferred) != 1 {
+		t.Errorf("got %+v after the default window; want character 1", inferred)
+	}
+}
@@ -0,0 +1,266 @@
+// Package merge combines events from several sources into a single stream ordered by timestamp,
+// dropping the duplicates of events received from more than one source.
+// Running redundant sources, such as a census connection alongside a Nanite Systems connection,
+// covers for the gaps of either one without counting every event twice.
+//
+//	live := wsc.New(serviceID, ps2.PC)
+//	nss := wsc.New(serviceID, ps2.PC)
+//	nss.SetURL("wss://push.nanite-systems.net/streaming?environment=all&service-id=s:" + serviceID)
+//
+//	m := merge.New(merge.DefaultDelay)
+//	m.AttachHandlers(live)
+//	m.AttachHandlers(nss)
+//	manager.AttachHandlers(m)
+//	go m.Run(ctx)
+//
+// Any source with an AddHandler method that accepts event handlers can be merged,
+// including replay.Client for recorded logs.
+package merge
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/event/wsc"
+)
+
+// DefaultDelay is how long events are held by default,
+// which is long enough for the same event to arrive from every source on a healthy connection.
+const DefaultDelay = 2 * time.Second
+
+// keyWindow is how long the keys of received events are remembered to detect duplicates.
+// Sources that reconnect after an outage may send events much later than the others.
+const keyWindow = 5 * time.Minute
+
+// pruneInterval is how often keys older than keyWindow are forgotten.
+const pruneInterval = time.Minute
+
+// Merger merges the events of every source it's attached to.
+//
+// Events are held for a delay after they're received,
+// so that an event received late from one source can still be handled in order,
+// and are then handled in order of their timestamps.
+// Events that arrive after newer events have already been handled are still handled once they're ready,
+// out of order, rather than being dropped.
+// Events with the same [event.UniqueKey] as an event received within the last five minutes are dropped.
+type Merger struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	pending  pendingEvents
+	seen     map[event.UniqueKey]time.Time // seen holds the time each key was first received
+	received int                           // received numbers events in the order they arrived, to keep ties stable
+	pruned   time.Time                     // pruned is when keys were last pruned
+
+	handlers    map[ps2.Event][]func(event.Typer)
+	allHandlers []func(event.Typer)
+	duplicates  int
+}
+
+// New creates a Merger that holds events for delay before handling them.
+// A delay of 0 uses [DefaultDelay].
+func New(delay time.Duration) *Merger {
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	return &Merger{
+		delay:    delay,
+		seen:     make(map[event.UniqueKey]time.Time),
+		handlers: make(map[ps2.Event][]func(event.Typer)),
+	}
+}
+
+// AttachHandlers registers handlers for every event type with the source client,
+// such as a *wsc.Client or *replay.Client.
+func (m *Merger) AttachHandlers(client interface{ AddHandler(any) }) {
+	client.AddHandler(func(e event.PlayerLogin) { m.Observe(e) })
+	client.AddHandler(func(e event.PlayerLogout) { m.Observe(e) })
+	client.AddHandler(func(e event.GainExperience) { m.Observe(e) })
+	client.AddHandler(func(e event.VehicleDestroy) { m.Observe(e) })
+	client.AddHandler(func(e event.Death) { m.Observe(e) })
+	client.AddHandler(func(e event.AchievementEarned) { m.Observe(e) })
+	client.AddHandler(func(e event.BattleRankUp) { m.Observe(e) })
+	client.AddHandler(func(e event.ItemAdded) { m.Observe(e) })
+	client.AddHandler(func(e event.MetagameEvent) { m.Observe(e) })
+	client.AddHandler(func(e event.FacilityControl) { m.Observe(e) })
+	client.AddHandler(func(e event.PlayerFacilityCapture) { m.Observe(e) })
+	client.AddHandler(func(e event.PlayerFacilityDefend) { m.Observe(e) })
+	client.AddHandler(func(e event.SkillAdded) { m.Observe(e) })
+	client.AddHandler(func(e event.ContinentLock) { m.Observe(e) })
+	client.AddHandler(func(e event.FishScan) { m.Observe(e) })
+}
+
+// Observe adds e to the merged stream.
+// It's safe to call from the handlers of several sources at once.
+// Duplicates of events already received are dropped right away.
+func (m *Merger) Observe(e event.Typer) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := e.(event.UniqueKeyer); ok {
+		key := k.Key()
+		if _, dup := m.seen[key]; dup {
+			m.duplicates++
+			return
+		}
+		m.seen[key] = now
+	}
+	at := now
+	if ts, ok := e.(event.Timestamper); ok && !ts.Time().IsZero() {
+		at = ts.Time()
+	}
+	m.received++
+	heap.Push(&m.pending, pendingEvent{event: e, at: at, ready: now.Add(m.delay), order: m.received})
+}
+
+// Duplicates returns the number of duplicate events dropped so far.
+func (m *Merger) Duplicates() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.duplicates
+}
+
+// AddHandler registers h to be called for every merged event of the matching type.
+// It accepts the same handler types as wsc.Client.AddHandler,
+// so that code written for a single client, like state.Manager.AttachHandlers, can use the merged stream instead;
+// handlers for service messages like wsc.Heartbeat are accepted but never called,
+// since they belong to a single connection.
+// A func(event.Typer) is called for every event.
+// AddHandler panics for any other type.
+//
+// Handlers are called on the goroutine running [Merger.Run],
+// and must be added before it starts.
+func (m *Merger) AddHandler(h any) {
+	switch v := h.(type) {
+	case func(event.Typer):
+		m.allHandlers = append(m.allHandlers, v)
+	case func(event.PlayerLogin):
+		addHandler(m, ps2.PlayerLogin, v)
+	case func(event.PlayerLogout):
+		addHandler(m, ps2.PlayerLogout, v)
+	case func(event.GainExperience):
+		addHandler(m, ps2.GainExperience, v)
+	case func(event.VehicleDestroy):
+		addHandler(m, ps2.VehicleDestroy, v)
+	case func(event.Death):
+		addHandler(m, ps2.Death, v)
+	case func(event.AchievementEarned):
+		addHandler(m, ps2.AchievementEarned, v)
+	case func(event.BattleRankUp):
+		addHandler(m, ps2.BattleRankUp, v)
+	case func(event.ItemAdded):
+		addHandler(m, ps2.ItemAdded, v)
+	case func(event.MetagameEvent):
+		addHandler(m, ps2.Metagame, v)
+	case func(event.FacilityControl):
+		addHandler(m, ps2.FacilityControl, v)
+	case func(event.PlayerFacilityCapture):
+		addHandler(m, ps2.PlayerFacilityCapture, v)
+	case func(event.PlayerFacilityDefend):
+		addHandler(m, ps2.PlayerFacilityDefend, v)
+	case func(event.SkillAdded):
+		addHandler(m, ps2.SkillAdded, v)
+	case func(event.ContinentLock):
+		addHandler(m, ps2.ContinentLock, v)
+	case func(event.FishScan):
+		addHandler(m, ps2.FishScan, v)
+	case func(wsc.Heartbeat), func(wsc.ServiceStateChanged), func(wsc.ConnectionStateChanged),
+		func(wsc.WorldPopulation), func(wsc.ServiceMessage), func(wsc.ClockSkew):
+	default:
+		panic(fmt.Sprintf("AddHandler: invalid type '%T'", h))
+	}
+}
+
+func addHandler[E event.Typer](m *Merger, t ps2.Event, h func(E)) {
+	m.handlers[t] = append(m.handlers[t], func(e event.Typer) { h(e.(E)) })
+}
+
+// Run handles merged events as they become ready until ctx is cancelled.
+// Events still held when ctx is cancelled are handled before Run returns.
+func (m *Merger) Run(ctx context.Context) {
+	ticker := time.NewTicker(max(m.delay/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.flush(time.Time{})
+			return
+		case now := <-ticker.C:
+			m.flush(now)
+		}
+	}
+}
+
+// flush handles every held event that is ready at now,
+// or every held event when now is zero.
+func (m *Merger) flush(now time.Time) {
+	for {
+		m.mu.Lock()
+		if len(m.pending) == 0 || (!now.IsZero() && m.pending[0].ready.After(now)) {
+			m.prune(now)
+			m.mu.Unlock()
+			return
+		}
+		next := heap.Pop(&m.pending).(pendingEvent)
+		m.mu.Unlock()
+		m.handle(next.event)
+	}
+}
+
+func (m *Merger) handle(e event.Typer) {
+	for _, h := range m.handlers[e.Type()] {
+		h(e)
+	}
+	for _, h := range m.allHandlers {
+		h(e)
+	}
+}
+
+// prune forgets the keys of events received more than keyWindow before now,
+// at most once every pruneInterval.
+// m.mu must be held.
+func (m *Merger) prune(now time.Time) {
+	if now.IsZero() || now.Sub(m.pruned) < pruneInterval {
+		return
+	}
+	m.pruned = now
+	cutoff := now.Add(-keyWindow)
+	for k, at := range m.seen {
+		if at.Before(cutoff) {
+			delete(m.seen, k)
+		}
+	}
+}
+
+type pendingEvent struct {
+	event event.Typer
+	at    time.Time // at is the event timestamp
+	ready time.Time // ready is when the event has been held long enough
+	order int
+}
+
+// pendingEvents is a heap of events ordered by timestamp.
+// An event is released once it's the oldest event held and it's ready,
+// so an event that isn't ready yet holds back newer events that are.
+type pendingEvents []pendingEvent
+
+func (p pendingEvents) Len() int { return len(p) }
+func (p pendingEvents) Less(i, j int) bool {
+	if !p[i].at.Equal(p[j].at) {
+		return p[i].at.Before(p[j].at)
+	}
+	return p[i].order < p[j].order
+}
+func (p pendingEvents) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p *pendingEvents) Push(x any)   { *p = append(*p, x.(pendingEvent)) }
+func (p *pendingEvents) Pop() any {
+	old := *p
+	e := old[len(old)-1]
+	*p = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,93 @@
+package merge_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/event/merge"
+)
+
+// source is a minimal event source like wsc.Client.
+type source struct {
+	logins []func(event.PlayerLogin)
+}
+
+func (s *source) AddHandler(h any) {
+	if f, ok := h.(func(event.PlayerLogin)); ok {
+		s.logins = append(s.logins, f)
+	}
+}
+
+func (s *source) send(e event.PlayerLogin) {
+	for _, f := range s.logins {
+		f(e)
+	}
+}
+
+func TestMerger(t *testing.T) {
+	m := merge.New(50 * time.Millisecond)
+	live, backup := &source{}, &source{}
+	m.AttachHandlers(live)
+	m.AttachHandlers(backup)
+
+	got := make(chan ps2.CharacterID, 10)
+	m.AddHandler(func(e event.PlayerLogin) { got <- e.CharacterID })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	start := time.Unix(1709037290, 0)
+	login := func(id ps2.CharacterID, offset int) event.PlayerLogin {
+		return event.PlayerLogin{CharacterID: id, WorldID: ps2.Emerald, Timestamp: start.Add(time.Duration(offset) * time.Second)}
+	}
+	// the backup source received event 2 that the live source missed,
+	// and events arrive out of order between the two
+	live.send(login(1, 0))
+	live.send(login(3, 2))
+	backup.send(login(1, 0))
+	backup.send(login(2, 1))
+	backup.send(login(3, 2))
+
+	for _, want := range []ps2.CharacterID{1, 2, 3} {
+		select {
+		case id := <-got:
+			if id != want {
+				t.Errorf("got login for %d; want %d", id, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected login for %d", want)
+		}
+	}
+	cancel()
+	<-done
+	select {
+	case id := <-got:
+		t.Errorf("unexpected duplicate login for %d", id)
+	default:
+	}
+	if n := m.Duplicates(); n != 2 {
+		t.Errorf("got %d duplicates; want 2", n)
+	}
+}
+
+func TestMergerFlushesOnCancel(t *testing.T) {
+	m := merge.New(time.Hour)
+	var got []event.Typer
+	m.AddHandler(func(e event.Typer) { got = append(got, e) })
+	m.Observe(event.ContinentLock{WorldID: ps2.Emerald, Timestamp: time.Unix(1709037291, 0)})
+	m.Observe(event.PlayerLogout{CharacterID: 1, Timestamp: time.Unix(1709037290, 0)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.Run(ctx)
+	if len(got) != 2 || got[0].Type() != ps2.PlayerLogout {
+		t.Errorf("got %v; want both events, oldest first", got)
+	}
+}
@@ -0,0 +1,98 @@
+package event
+
+import (
+	"github.com/Travis-Britz/ps2"
+)
+
+// Role is the part a character played in a Death or VehicleDestroy event.
+type Role uint8
+
+const (
+	Uninvolved Role = iota
+	Victim          // Victim is the character that died or lost a vehicle
+	Attacker        // Attacker is the character that caused it
+	Self            // Self is both, like a suicide or destroying your own vehicle
+)
+
+func (r Role) String() string {
+	switch r {
+	case Victim:
+		return "victim"
+	case Attacker:
+		return "attacker"
+	case Self:
+		return "self"
+	default:
+		return "uninvolved"
+	}
+}
+
+// perspective works out the role of id given the victim and attacker of an event.
+// A zero id is never involved,
+// since the attacker is 0 for deaths with no known cause.
+func perspective(id, victim, attacker ps2.CharacterID) (Role, ps2.CharacterID) {
+	switch {
+	case id == 0:
+		return Uninvolved, 0
+	case id == victim && id == attacker:
+		return Self, id
+	case id == victim:
+		return Victim, attacker
+	case id == attacker:
+		return Attacker, victim
+	default:
+		return Uninvolved, 0
+	}
+}
+
+// Involves reports whether id is the victim or the attacker.
+func (e Death) Involves(id ps2.CharacterID) bool {
+	role, _ := e.PerspectiveOf(id)
+	return role != Uninvolved
+}
+
+// PerspectiveOf returns the role of id in the death and the other character involved.
+// other is 0 for a victim when the attacker is unknown (fall damage, the pain field, etc.).
+// other is id itself for a suicide.
+//
+//	switch role, other := e.PerspectiveOf(me); role {
+//	case event.Attacker:
+//		fmt.Println("killed", other)
+//	case event.Victim:
+//		fmt.Println("killed by", other)
+//	}
+func (e Death) PerspectiveOf(id ps2.CharacterID) (role Role, other ps2.CharacterID) {
+	return perspective(id, e.CharacterID, e.AttackerCharacterID)
+}
+
+// IsTeamkill reports whether the attacker killed someone on their own team.
+// Suicides are not teamkills.
+// Deaths missing the attacker team (see AttackerTeamID) are never counted as teamkills.
+func (e Death) IsTeamkill() bool {
+	return e.AttackerCharacterID != 0 &&
+		!e.IsSuicide() &&
+		e.AttackerTeamID != 0 &&
+		e.AttackerTeamID == e.TeamID
+}
+
+// Involves reports whether id owned the vehicle or destroyed it.
+func (e VehicleDestroy) Involves(id ps2.CharacterID) bool {
+	role, _ := e.PerspectiveOf(id)
+	return role != Uninvolved
+}
+
+// PerspectiveOf returns the role of id in the vehicle kill and the other character involved.
+// The victim is the character that owned the vehicle.
+// other is 0 for a victim when the attacker is unknown.
+func (e VehicleDestroy) PerspectiveOf(id ps2.CharacterID) (role Role, other ps2.CharacterID) {
+	return perspective(id, e.CharacterID, e.AttackerCharacterID)
+}
+
+// IsTeamkill reports whether the attacker destroyed a vehicle belonging to their own team.
+// Destroying your own vehicle is not a teamkill.
+func (e VehicleDestroy) IsTeamkill() bool {
+	return e.AttackerCharacterID != 0 &&
+		e.AttackerCharacterID != e.CharacterID &&
+		e.AttackerTeamID != 0 &&
+		e.AttackerTeamID == e.TeamID
+}
@@ -0,0 +1,137 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Replay plays back a recorded event log.
+//
+// Logs are read one message per line.
+// Lines may be full websocket messages as written by wsc.MessageLogger,
+// or bare event payloads.
+// Anything before the first '{' on a line is ignored, which allows for log prefixes.
+// Lines that are not events (heartbeats, subscriptions, etc.) are skipped.
+//
+//	f, _ := os.Open("websocket.log")
+//	replay := event.Replay{Speed: 60, SkipToMetagame: true}
+//	err := replay.Play(ctx, f, func(e event.Typer) { fmt.Println(e) })
+type Replay struct {
+	// Speed is the playback rate relative to the recorded time,
+	// e.g. 1 is real time and 60 plays an hour of events in a minute.
+	// The zero value plays events as fast as they can be read.
+	Speed float64
+
+	// Start and End clamp playback to a time window.
+	// Events before Start or after End are skipped.
+	// The zero value leaves that side of the window open.
+	Start time.Time
+	End   time.Time
+
+	// SkipToMetagame skips every event before the first MetagameEvent in the window.
+	SkipToMetagame bool
+}
+
+// replayOrderSlack is how far past End an event may be before playback stops.
+// Census doesn't always send events in order,
+// so a single event past the end of the window doesn't mean the rest are.
+const replayOrderSlack = time.Minute
+
+// Play reads events from log and calls fn with each one,
+// waiting between events according to Speed.
+// Play returns when log is exhausted, the End of the window is passed, or ctx is cancelled.
+// The returned error is nil if log was read to the end or the window ended.
+func (r Replay) Play(ctx context.Context, log io.Reader, fn func(Typer)) error {
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var first time.Time   // timestamp of the first played event
+	var started time.Time // wall time the first event was played
+	skipping := r.SkipToMetagame
+
+	for scanner.Scan() {
+		e := parseReplayLine(scanner.Bytes())
+		if e == nil {
+			continue
+		}
+		ts, ok := e.(Timestamper)
+		if !ok {
+			continue
+		}
+		t := ts.Time()
+		if !r.Start.IsZero() && t.Before(r.Start) {
+			continue
+		}
+		if !r.End.IsZero() && t.After(r.End) {
+			if t.After(r.End.Add(replayOrderSlack)) {
+				return nil
+			}
+			continue
+		}
+		if skipping {
+			if e.Type() != ps2.Metagame {
+				continue
+			}
+			skipping = false
+		}
+
+		if first.IsZero() {
+			first = t
+			started = time.Now()
+		} else if r.Speed > 0 {
+			due := started.Add(time.Duration(float64(t.Sub(first)) / r.Speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fn(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("event.Replay.Play: %w", err)
+	}
+	return nil
+}
+
+// parseReplayLine returns the event on a log line,
+// or nil if the line isn't an event.
+func parseReplayLine(line []byte) Typer {
+	i := bytes.IndexByte(line, '{')
+	if i < 0 {
+		return nil
+	}
+	line = line[i:]
+
+	var message struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(line, &message); err != nil {
+		return nil
+	}
+	payload := line
+	if message.Payload != nil {
+		payload = message.Payload
+	}
+
+	var raw Raw
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil
+	}
+	if raw.EventName == ps2.Unknown {
+		return nil
+	}
+	return raw.Event()
+}
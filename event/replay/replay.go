@@ -0,0 +1,152 @@
+// Package replay plays recorded event logs to the same handlers used with a live wsc.Client,
+// so that state.Manager and other consumers can be tested offline with real event sequences.
+//
+//	client, err := replay.Open("websocket-1234.log.gz")
+//	if err != nil { ... }
+//	defer client.Close()
+//	client.Replay.Speed = 60 // an hour of events per minute
+//	manager.AttachHandlers(client)
+//	err = client.Run(ctx)
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/event/wsc"
+)
+
+// Client reads events from a recorded log and calls the handlers registered with [Client.AddHandler],
+// in the order they appear in the log.
+// Logs are read by [event.Replay], which accepts the logs written by cmd/eventclient and wsc.MessageLogger.
+type Client struct {
+	// Replay sets the playback speed and time window.
+	// The zero value plays every event as fast as it can be read.
+	Replay event.Replay
+
+	log      io.Reader
+	closer   io.Closer
+	handlers map[ps2.Event][]func(event.Typer)
+}
+
+// New returns a Client that plays log.
+// Gzipped logs are decompressed.
+func New(log io.Reader) (*Client, error) {
+	r, closer, err := decompress(log)
+	if err != nil {
+		return nil, fmt.Errorf("replay.New: %w", err)
+	}
+	return &Client{log: r, closer: closer, handlers: make(map[ps2.Event][]func(event.Typer))}, nil
+}
+
+// Open returns a Client that plays the log in the named file.
+// Close the client to close the file.
+func Open(name string) (*Client, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("replay.Open: %w", err)
+	}
+	c, err := New(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("replay.Open: %w", err)
+	}
+	c.closer = f
+	return c, nil
+}
+
+// decompress returns a reader for the decompressed log if it's gzipped.
+func decompress(log io.Reader) (io.Reader, io.Closer, error) {
+	buffered := bufio.NewReader(log)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return buffered, nil, nil
+	}
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gz, gz, nil
+}
+
+// Close closes the file opened by [Open].
+func (c *Client) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// AddHandler registers h to be called for every event of the matching type.
+// It accepts the same handler types as wsc.Client.AddHandler,
+// so code written for a live client can be attached unchanged;
+// handlers for service messages like wsc.Heartbeat are accepted but never called,
+// since logs only contain events.
+// AddHandler panics for any other type.
+func (c *Client) AddHandler(h any) {
+	switch v := h.(type) {
+	case func(event.PlayerLogin):
+		addHandler(c, ps2.PlayerLogin, v)
+	case func(event.PlayerLogout):
+		addHandler(c, ps2.PlayerLogout, v)
+	case func(event.GainExperience):
+		addHandler(c, ps2.GainExperience, v)
+	case func(event.VehicleDestroy):
+		addHandler(c, ps2.VehicleDestroy, v)
+	case func(event.Death):
+		addHandler(c, ps2.Death, v)
+	case func(event.AchievementEarned):
+		addHandler(c, ps2.AchievementEarned, v)
+	case func(event.BattleRankUp):
+		addHandler(c, ps2.BattleRankUp, v)
+	case func(event.ItemAdded):
+		addHandler(c, ps2.ItemAdded, v)
+	case func(event.MetagameEvent):
+		addHandler(c, ps2.Metagame, v)
+	case func(event.FacilityControl):
+		addHandler(c, ps2.FacilityControl, v)
+	case func(event.PlayerFacilityCapture):
+		addHandler(c, ps2.PlayerFacilityCapture, v)
+	case func(event.PlayerFacilityDefend):
+		addHandler(c, ps2.PlayerFacilityDefend, v)
+	case func(event.SkillAdded):
+		addHandler(c, ps2.SkillAdded, v)
+	case func(event.ContinentLock):
+		addHandler(c, ps2.ContinentLock, v)
+	case func(event.FishScan):
+		addHandler(c, ps2.FishScan, v)
+	case func(wsc.Heartbeat), func(wsc.ServiceStateChanged), func(wsc.ConnectionStateChanged),
+		func(wsc.WorldPopulation), func(wsc.ServiceMessage), func(wsc.ClockSkew):
+	default:
+		panic(fmt.Sprintf("AddHandler: invalid type '%T'", h))
+	}
+}
+
+func addHandler[E event.Typer](c *Client, t ps2.Event, h func(E)) {
+	c.handlers[t] = append(c.handlers[t], func(e event.Typer) { h(e.(E)) })
+}
+
+// Run plays the log until it's exhausted, the end of the Replay window is passed, or ctx is cancelled.
+// Handlers are called on the goroutine that called Run.
+// A log can only be played once.
+func (c *Client) Run(ctx context.Context) error {
+	err := c.Replay.Play(ctx, c.log, func(e event.Typer) {
+		for _, h := range c.handlers[e.Type()] {
+			h(e)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("replay.Client.Run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package replay_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/event/replay"
+	"github.com/Travis-Britz/ps2/event/wsc"
+)
+
+const testLog = `{"service":"event","type":"heartbeat","online":{}}
+{"payload":{"character_id":"5428010618015189713","event_name":"PlayerLogin","timestamp":"1700000000","world_id":"17"},"service":"event","type":"serviceMessage"}
+{"payload":{"attacker_character_id":"5428010618015189713","character_id":"5428010618020694593","event_name":"Death","timestamp":"1700000005","world_id":"17","zone_id":"2"},"service":"event","type":"serviceMessage"}
+{"payload":{"character_id":"5428010618015189713","event_name":"PlayerLogout","timestamp":"1700000010","world_id":"17"},"service":"event","type":"serviceMessage"}
+`
+
+func TestClient(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(testLog))
+	gz.Close()
+
+	tt := map[string][]byte{
+		"plain":   []byte(testLog),
+		"gzipped": gzipped.Bytes(),
+	}
+	for name, log := range tt {
+		t.Run(name, func(t *testing.T) {
+			client, err := replay.New(bytes.NewReader(log))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			client.AddHandler(func(e event.PlayerLogin) { got = append(got, "login "+e.CharacterID.String()) })
+			client.AddHandler(func(e event.Death) { got = append(got, "death "+e.CharacterID.String()) })
+			client.AddHandler(func(e event.PlayerLogout) { got = append(got, "logout "+e.CharacterID.String()) })
+			client.AddHandler(func(wsc.Heartbeat) { t.Error("heartbeat handler was called") })
+			if err := client.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			want := "login 5428010618015189713,death 5428010618020694593,logout 5428010618015189713"
+			if strings.Join(got, ",") != want {
+				t.Errorf("got %v; want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestClientInvalidHandler(t *testing.T) {
+	client, _ := replay.New(strings.NewReader(""))
+	defer func() {
+		if recover() == nil {
+			t.Error("AddHandler didn't panic for an invalid handler type")
+		}
+	}()
+	client.AddHandler(func(ps2.CharacterID) {})
+}
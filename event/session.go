@@ -0,0 +1,187 @@
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// SessionEnded is a derived event summarizing a character's play session from PlayerLogin to logout.
+// It is created by a [SessionTracker] and never sent by census.
+type SessionEnded struct {
+	CharacterID ps2.CharacterID
+	WorldID     ps2.WorldID
+
+	// Start is the time of the PlayerLogin, and End is the time of the logout.
+	// For inferred logouts End is the last time the character was seen.
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+
+	// Inferred is true when the session was ended by a [LogoutInferred] instead of a PlayerLogout.
+	Inferred bool
+
+	Kills            int // Kills doesn't include suicides or teamkills
+	Deaths           int // Deaths includes suicides
+	VehicleKills     int // VehicleKills are vehicles destroyed that were owned by other characters
+	ExperienceTicks  int
+	Experience       float64
+	FacilityCaptures int
+	FacilityDefends  int
+
+	// Timestamp is when the session was ended.
+	Timestamp time.Time
+}
+
+func (e SessionEnded) Time() time.Time { return e.Timestamp }
+
+// SessionTracker collects stats between a PlayerLogin and the following logout for tracked characters,
+// and emits a [SessionEnded] summary when the session ends.
+// Sessions only start with a PlayerLogin,
+// so characters that were already online when tracking started have no session until they log in again.
+//
+//	tracker := event.NewSessionTracker(me)
+//	tracker.AttachHandlers(client)
+//	tracker.AttachInferrer(inferrer) // optional; also end sessions on inferred logouts
+//	tracker.OnSessionEnded(func(e event.SessionEnded) { ... })
+type SessionTracker struct {
+	mu         sync.Mutex
+	characters map[ps2.CharacterID]bool
+	sessions   map[ps2.CharacterID]*SessionEnded
+	handlers   []func(SessionEnded)
+}
+
+// NewSessionTracker creates a SessionTracker for characters.
+func NewSessionTracker(characters ...ps2.CharacterID) *SessionTracker {
+	s := &SessionTracker{
+		characters: make(map[ps2.CharacterID]bool),
+		sessions:   make(map[ps2.CharacterID]*SessionEnded),
+	}
+	for _, id := range characters {
+		s.characters[id] = true
+	}
+	return s
+}
+
+// Track starts tracking sessions for id from its next PlayerLogin.
+func (s *SessionTracker) Track(id ps2.CharacterID) {
+	s.mu.Lock()
+	s.characters[id] = true
+	s.mu.Unlock()
+}
+
+// Untrack stops tracking id and drops its current session without emitting it.
+func (s *SessionTracker) Untrack(id ps2.CharacterID) {
+	s.mu.Lock()
+	delete(s.characters, id)
+	delete(s.sessions, id)
+	s.mu.Unlock()
+}
+
+// OnSessionEnded registers f to be called for every ended session.
+func (s *SessionTracker) OnSessionEnded(f func(SessionEnded)) {
+	s.mu.Lock()
+	s.handlers = append(s.handlers, f)
+	s.mu.Unlock()
+}
+
+// AttachHandlers registers handlers for the events that make up a session with client,
+// such as a *wsc.Client.
+// The client must be subscribed to these events for the tracked characters.
+func (s *SessionTracker) AttachHandlers(client interface{ AddHandler(any) }) {
+	client.AddHandler(func(e PlayerLogin) { s.Observe(e) })
+	client.AddHandler(func(e PlayerLogout) { s.Observe(e) })
+	client.AddHandler(func(e Death) { s.Observe(e) })
+	client.AddHandler(func(e VehicleDestroy) { s.Observe(e) })
+	client.AddHandler(func(e GainExperience) { s.Observe(e) })
+	client.AddHandler(func(e PlayerFacilityCapture) { s.Observe(e) })
+	client.AddHandler(func(e PlayerFacilityDefend) { s.Observe(e) })
+}
+
+// AttachInferrer ends sessions for logouts inferred by l,
+// for when census drops the PlayerLogout.
+func (s *SessionTracker) AttachInferrer(l *LogoutInferrer) {
+	l.OnLogoutInferred(s.ObserveInferred)
+}
+
+// Observe adds e to the sessions of the characters involved.
+// A PlayerLogin starts a new session and a PlayerLogout ends one.
+// Events for characters without a session are ignored.
+func (s *SessionTracker) Observe(e Typer) {
+	s.mu.Lock()
+	var ended *SessionEnded
+	switch e := e.(type) {
+	case PlayerLogin:
+		if s.characters[e.CharacterID] {
+			s.sessions[e.CharacterID] = &SessionEnded{
+				CharacterID: e.CharacterID,
+				WorldID:     e.WorldID,
+				Start:       e.Timestamp,
+			}
+		}
+	case PlayerLogout:
+		ended = s.end(e.CharacterID, e.Timestamp, e.Timestamp, false)
+	case Death:
+		if session := s.sessions[e.CharacterID]; session != nil {
+			session.Deaths++
+		}
+		if session := s.sessions[e.AttackerCharacterID]; session != nil && !e.IsSuicide() && !e.IsTeamkill() {
+			session.Kills++
+		}
+	case VehicleDestroy:
+		if session := s.sessions[e.AttackerCharacterID]; session != nil && e.AttackerCharacterID != e.CharacterID {
+			session.VehicleKills++
+		}
+	case GainExperience:
+		if session := s.sessions[e.CharacterID]; session != nil {
+			session.ExperienceTicks++
+			session.Experience += e.Amount
+		}
+	case PlayerFacilityCapture:
+		if session := s.sessions[e.CharacterID]; session != nil {
+			session.FacilityCaptures++
+		}
+	case PlayerFacilityDefend:
+		if session := s.sessions[e.CharacterID]; session != nil {
+			session.FacilityDefends++
+		}
+	}
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	if ended != nil {
+		for _, h := range handlers {
+			h(*ended)
+		}
+	}
+}
+
+// ObserveInferred ends the session of the character in e.
+func (s *SessionTracker) ObserveInferred(e LogoutInferred) {
+	s.mu.Lock()
+	ended := s.end(e.CharacterID, e.LastSeen, e.Timestamp, true)
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	if ended != nil {
+		for _, h := range handlers {
+			h(*ended)
+		}
+	}
+}
+
+// end removes the session of id, or returns nil if it has none.
+// s.mu must be held.
+func (s *SessionTracker) end(id ps2.CharacterID, at, timestamp time.Time, inferred bool) *SessionEnded {
+	session := s.sessions[id]
+	if session == nil {
+		return nil
+	}
+	delete(s.sessions, id)
+	session.End = at
+	session.Duration = max(at.Sub(session.Start), 0)
+	session.Inferred = inferred
+	session.Timestamp = timestamp
+	return session
+}
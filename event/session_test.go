@@ -0,0 +1,32 @@
+package event_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestSessionTracker(t *testing.T) {
+	const me, enemy, friend ps2.CharacterID = 1, 2, 3
+	tracker := event.NewSessionTracker(me)
+	var ended []event.SessionEnded
+	tracker.OnSessionEnded(func(e event.SessionEnded) { ended = append(ended, e) })
+
+	start := time.Unix(1709037000, 0)
+	tracker.Observe(event.PlayerLogin{CharacterID: me, WorldID: ps2.Emerald, Timestamp: start})
+	tracker.Observe(event.Death{CharacterID: enemy, AttackerCharacterID: me, TeamID: ps2.TR, AttackerTeamID: ps2.VS, Timestamp: start})
+	// teamkills and suicides aren't kills
+	tracker.Observe(event.Death{CharacterID: friend, AttackerCharacterID: me, TeamID: ps2.VS, AttackerTeamID: ps2.VS, Timestamp: start})
+	tracker.Observe(event.Death{CharacterID: me, AttackerCharacterID: me, TeamID: ps2.VS, AttackerTeamID: ps2.VS, Timestamp: start})
+	tracker.Observe(event.GainExperience{CharacterID: me, Amount: 100, Timestamp: start})
+	tracker.Observe(event.PlayerLogout{CharacterID: me, Timestamp: start.Add(time.Hour)})
+
+	if len(ended) != 1 {
+		t.Fatalf("got %d sessions; want 1", len(ended))
+	}
+	if s := ended[0]; s.Kills != 1 || s.Deaths != 1 || s.ExperienceTicks != 1 || s.Experience != 100 || s.Duration != time.Hour {
+		t.Errorf("got %+v; want 1 kill and 1 death in an hour", s)
+	}
+}
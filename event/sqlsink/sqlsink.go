@@ -0,0 +1,341 @@
+// Package sqlsink archives typed events into a SQL database.
+//
+// Events are stored in a single table with the columns that are common to most queries
+// (type, world, zone, character, and time) and the full event as JSON.
+// Every row is keyed by the event's [event.UniqueKey],
+// so duplicated events from the push service and events written again after a restart are ignored.
+//
+// The package only depends on database/sql;
+// register a driver for Postgres or SQLite in the calling program:
+//
+//	db, _ := sql.Open("pgx", dsn)
+//	sink := sqlsink.New(db, sqlsink.Postgres, sqlsink.Options{})
+//	if err := sink.CreateTable(ctx); err != nil { ... }
+//	sink.AttachHandlers(client)
+//	go sink.Run(ctx)
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/internal/sqldialect"
+)
+
+// Dialect selects the SQL syntax for a database.
+// It's the same type as sqlstore.Dialect.
+type Dialect = sqldialect.Dialect
+
+const (
+	Postgres = sqldialect.Postgres
+	SQLite   = sqldialect.SQLite
+)
+
+// columns are the columns written for every event, in insert order.
+var columns = []string{"event_key", "event_type", "world_id", "zone_id", "character_id", "timestamp", "payload"}
+
+// Schema returns the statements that create table and its indexes for d.
+// Timestamps are stored as unix seconds, which is the resolution of the event stream.
+func Schema(d Dialect, table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	event_key    TEXT PRIMARY KEY,
+	event_type   TEXT NOT NULL,
+	world_id     INTEGER NOT NULL,
+	zone_id      BIGINT NOT NULL,
+	character_id BIGINT NOT NULL,
+	timestamp    BIGINT NOT NULL,
+	payload      %[2]s NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[1]s_world_time ON %[1]s (world_id, timestamp);
+CREATE INDEX IF NOT EXISTS %[1]s_character_time ON %[1]s (character_id, timestamp);`, table, d.JSONType())
+}
+
+// Options configures a [Sink].
+// Zero values use the defaults.
+type Options struct {
+	// Table is the table name.
+	// The default is "ps2_events".
+	Table string
+
+	// BatchSize is the number of events inserted per transaction.
+	// The default is 500.
+	BatchSize int
+
+	// FlushInterval is the longest an event waits before a partial batch is written.
+	// The default is one second.
+	FlushInterval time.Duration
+
+	// Queue is the number of events buffered before [Sink.Write] blocks.
+	// The default is 10000.
+	Queue int
+}
+
+// Sink writes events to a database in batches.
+type Sink struct {
+	db      *sql.DB
+	dialect Dialect
+	opts    Options
+	queue   chan row
+
+	mu      sync.Mutex
+	pending []row // pending is a batch that failed to insert and will be retried
+	onError func(error)
+}
+
+type row struct {
+	key       string
+	typ       string
+	world     ps2.WorldID
+	zone      ps2.ZoneInstanceID
+	character ps2.CharacterID
+	timestamp int64
+	payload   []byte
+}
+
+// New creates a Sink for db.
+func New(db *sql.DB, dialect Dialect, opts Options) *Sink {
+	if opts.Table == "" {
+		opts.Table = "ps2_events"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Queue <= 0 {
+		opts.Queue = 10000
+	}
+	return &Sink{
+		db:      db,
+		dialect: dialect,
+		opts:    opts,
+		queue:   make(chan row, opts.Queue),
+		onError: func(error) {},
+	}
+}
+
+// CreateTable creates the events table if it doesn't exist.
+func (s *Sink) CreateTable(ctx context.Context) error {
+	for _, stmt := range strings.Split(Schema(s.dialect, s.opts.Table), ";\n") {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("sqlsink.CreateTable: %w", err)
+		}
+	}
+	return nil
+}
+
+// OnError sets a function to be called when a batch fails to insert
+// or when an event passed to a handler from [Sink.AttachHandlers] can't be queued.
+// Failed batches are retried until they succeed.
+func (s *Sink) OnError(f func(error)) {
+	s.onError = f
+}
+
+// Write queues e to be inserted.
+// When the queue is full Write blocks until there is room or ctx is done,
+// which slows the event client down rather than dropping events while the database catches up.
+func (s *Sink) Write(ctx context.Context, e event.Typer) error {
+	r, err := newRow(e)
+	if err != nil {
+		return fmt.Errorf("sqlsink.Write: %w", err)
+	}
+	select {
+	case s.queue <- r:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newRow(e event.Typer) (row, error) {
+	keyer, ok := e.(event.UniqueKeyer)
+	if !ok {
+		return row{}, fmt.Errorf("%T has no unique key", e)
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return row{}, err
+	}
+	var common struct {
+		WorldID     ps2.WorldID
+		ZoneID      ps2.ZoneInstanceID
+		CharacterID ps2.CharacterID
+		Timestamp   time.Time
+	}
+	if err := json.Unmarshal(payload, &common); err != nil {
+		return row{}, err
+	}
+	key := keyer.Key()
+	return row{
+		key:       hex.EncodeToString(key[:]),
+		typ:       e.Type().String(),
+		world:     common.WorldID,
+		zone:      common.ZoneID,
+		character: common.CharacterID,
+		timestamp: common.Timestamp.Unix(),
+		payload:   payload,
+	}, nil
+}
+
+// AttachHandlers registers handlers for every event type with client, such as a *wsc.Client.
+// Handlers block while the queue is full.
+func (s *Sink) AttachHandlers(client interface{ AddHandler(any) }) {
+	write := func(e event.Typer) {
+		if err := s.Write(context.Background(), e); err != nil {
+			s.onError(err)
+		}
+	}
+	client.AddHandler(func(e event.PlayerLogin) { write(e) })
+	client.AddHandler(func(e event.PlayerLogout) { write(e) })
+	client.AddHandler(func(e event.GainExperience) { write(e) })
+	client.AddHandler(func(e event.VehicleDestroy) { write(e) })
+	client.AddHandler(func(e event.Death) { write(e) })
+	client.AddHandler(func(e event.AchievementEarned) { write(e) })
+	client.AddHandler(func(e event.BattleRankUp) { write(e) })
+	client.AddHandler(func(e event.ItemAdded) { write(e) })
+	client.AddHandler(func(e event.MetagameEvent) { write(e) })
+	client.AddHandler(func(e event.FacilityControl) { write(e) })
+	client.AddHandler(func(e event.PlayerFacilityCapture) { write(e) })
+	client.AddHandler(func(e event.PlayerFacilityDefend) { write(e) })
+	client.AddHandler(func(e event.SkillAdded) { write(e) })
+	client.AddHandler(func(e event.ContinentLock) { write(e) })
+	client.AddHandler(func(e event.FishScan) { write(e) })
+}
+
+// Run inserts queued events until ctx is cancelled,
+// then writes whatever is left in the queue before returning.
+// A batch that fails is retried with backoff and holds up later batches,
+// so the queue fills and [Sink.Write] blocks while the database is unavailable.
+func (s *Sink) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	backoff := time.Duration(0)
+	for {
+		full := s.fill(ctx)
+		if full || ctx.Err() != nil {
+			// write immediately
+		} else {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			return s.drain()
+		}
+		if err := s.flush(ctx); err != nil {
+			s.onError(err)
+			backoff = min(max(2*backoff, time.Second), time.Minute)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return s.drain()
+			}
+			continue
+		}
+		backoff = 0
+	}
+}
+
+// fill moves queued events into the pending batch until it is full or ctx is done.
+// It reports whether the batch is full.
+func (s *Sink) fill(ctx context.Context) bool {
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		s.mu.Unlock()
+		if n >= s.opts.BatchSize {
+			return true
+		}
+		select {
+		case r := <-s.queue:
+			s.mu.Lock()
+			s.pending = append(s.pending, r)
+			s.mu.Unlock()
+		default:
+			return false
+		}
+	}
+}
+
+// drain writes the pending batch and everything still queued.
+// It gives up after the first failed batch.
+func (s *Sink) drain() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for {
+		s.fill(ctx)
+		s.mu.Lock()
+		n := len(s.pending)
+		s.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if err := s.flush(ctx); err != nil {
+			return fmt.Errorf("sqlsink.Sink.Run: %d events were not written: %w", n+len(s.queue), err)
+		}
+	}
+}
+
+// flush inserts the pending batch in one transaction.
+// The batch is kept for the next attempt if anything fails.
+func (s *Sink) flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlsink: begin: %w", err)
+	}
+	perStatement := s.dialect.MaxParams() / len(columns)
+	for start := 0; start < len(batch); start += perStatement {
+		rows := batch[start:min(start+perStatement, len(batch))]
+		query, args := s.insert(rows)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return errors.Join(fmt.Errorf("sqlsink: insert: %w", err), tx.Rollback())
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlsink: commit: %w", err)
+	}
+	s.mu.Lock()
+	s.pending = s.pending[len(batch):]
+	s.mu.Unlock()
+	return nil
+}
+
+// insert builds a multi-row insert that skips events that were already written.
+func (s *Sink) insert(rows []row) (string, []any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", s.opts.Table, strings.Join(columns, ", "))
+	args := make([]any, 0, len(rows)*len(columns))
+	for i, r := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(s.dialect.Placeholder(len(args) + j + 1))
+		}
+		b.WriteByte(')')
+		args = append(args, r.key, r.typ, int64(r.world), int64(r.zone), int64(r.character), r.timestamp, string(r.payload))
+	}
+	b.WriteString(" ON CONFLICT (event_key) DO NOTHING")
+	return b.String(), args
+}
@@ -0,0 +1,217 @@
+package sqlsink
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/internal/sqltest"
+)
+
+func open(t *testing.T, dialect Dialect, opts Options) (*Sink, *sqltest.DB) {
+	t.Helper()
+	fake := &sqltest.DB{}
+	db := fake.Open()
+	t.Cleanup(func() { db.Close() })
+	return New(db, dialect, opts), fake
+}
+
+func death(character ps2.CharacterID, at time.Time) event.Death {
+	return event.Death{
+		AttackerCharacterID: 5428010618015189713,
+		CharacterID:         character,
+		Timestamp:           at,
+		WorldID:             ps2.Emerald,
+		ZoneID:              2,
+	}
+}
+
+func TestSchema(t *testing.T) {
+	for _, test := range []struct {
+		dialect Dialect
+		payload string
+	}{
+		{Postgres, "payload      JSONB NOT NULL"},
+		{SQLite, "payload      TEXT NOT NULL"},
+	} {
+		schema := Schema(test.dialect, "archive")
+		if !strings.Contains(schema, "CREATE TABLE IF NOT EXISTS archive (") || !strings.Contains(schema, test.payload) {
+			t.Errorf("got schema\n%s\nwant table archive with %q", schema, test.payload)
+		}
+
+		s, fake := open(t, test.dialect, Options{Table: "archive"})
+		if err := s.CreateTable(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		// drivers don't all accept several statements at once
+		stmts := fake.Statements()
+		if len(stmts) != 3 {
+			t.Fatalf("got %d statements; want the table and two indexes created separately", len(stmts))
+		}
+		for _, stmt := range stmts[1:] {
+			if !strings.HasPrefix(stmt.Query, "CREATE INDEX IF NOT EXISTS archive_") {
+				t.Errorf("got %q; want an index on archive", stmt.Query)
+			}
+		}
+	}
+}
+
+func TestNewRow(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	r, err := newRow(death(1, at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.typ != "Death" || r.world != ps2.Emerald || r.zone != 2 || r.character != 1 || r.timestamp != at.Unix() {
+		t.Errorf("got %+v", r)
+	}
+	if len(r.key) != 2*len(event.UniqueKey{}) {
+		t.Errorf("got key %q; want the hex encoded unique key", r.key)
+	}
+	if !strings.Contains(string(r.payload), `"AttackerCharacterID":5428010618015189713`) {
+		t.Errorf("got payload %s; want the full event", r.payload)
+	}
+	// the same event always has the same key
+	again, _ := newRow(death(1, at))
+	if again.key != r.key {
+		t.Errorf("got keys %q and %q for the same event", r.key, again.key)
+	}
+	if other, _ := newRow(death(2, at)); other.key == r.key {
+		t.Errorf("got key %q for two different events", r.key)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	rows := []row{
+		{key: "a", typ: "Death", world: ps2.Emerald, zone: 2, character: 1, timestamp: 10, payload: []byte("{}")},
+		{key: "b", typ: "Death", world: ps2.Emerald, zone: 2, character: 2, timestamp: 11, payload: []byte("{}")},
+	}
+	for _, test := range []struct {
+		dialect Dialect
+		want    string
+	}{
+		{Postgres, "INSERT INTO ps2_events (event_key, event_type, world_id, zone_id, character_id, timestamp, payload) VALUES " +
+			"($1, $2, $3, $4, $5, $6, $7), ($8, $9, $10, $11, $12, $13, $14) ON CONFLICT (event_key) DO NOTHING"},
+		{SQLite, "INSERT INTO ps2_events (event_key, event_type, world_id, zone_id, character_id, timestamp, payload) VALUES " +
+			"(?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (event_key) DO NOTHING"},
+	} {
+		s, _ := open(t, test.dialect, Options{})
+		query, args := s.insert(rows)
+		if query != test.want {
+			t.Errorf("got\n%s\nwant\n%s", query, test.want)
+		}
+		if len(args) != 2*len(columns) {
+			t.Fatalf("got %d args; want %d", len(args), 2*len(columns))
+		}
+		if args[7] != "b" || args[11] != int64(2) || args[13] != "{}" {
+			t.Errorf("got args %v; want the second row to start at 8", args)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	s, fake := open(t, SQLite, Options{})
+	// SQLite allows 999 parameters, so 200 rows take two statements in one transaction
+	for i := range 200 {
+		r, err := newRow(death(ps2.CharacterID(i+1), time.Unix(1700000000, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.pending = append(s.pending, r)
+	}
+	if err := s.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	stmts := fake.Statements()
+	if len(stmts) != 2 {
+		t.Fatalf("got %d statements; want 2", len(stmts))
+	}
+	if n := len(stmts[0].Args); n > SQLite.MaxParams() {
+		t.Errorf("got %d parameters in one statement; want at most %d", n, SQLite.MaxParams())
+	}
+	if n := len(stmts[0].Args) + len(stmts[1].Args); n != 200*len(columns) {
+		t.Errorf("got %d parameters; want %d", n, 200*len(columns))
+	}
+	if commits, _ := fake.Transactions(); commits != 1 {
+		t.Errorf("got %d transactions; want 1", commits)
+	}
+	if len(s.pending) != 0 {
+		t.Errorf("got %d rows pending after a flush; want 0", len(s.pending))
+	}
+}
+
+func TestFlushFailure(t *testing.T) {
+	s, fake := open(t, Postgres, Options{})
+	fail := errors.New("connection reset")
+	fake.Exec = func(string, []any) error { return fail }
+	r, err := newRow(death(1, time.Unix(1700000000, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.pending = append(s.pending, r)
+
+	if err := s.flush(context.Background()); !errors.Is(err, fail) {
+		t.Fatalf("got error %v; want %v", err, fail)
+	}
+	if _, rollbacks := fake.Transactions(); rollbacks != 1 {
+		t.Errorf("got %d rollbacks; want 1", rollbacks)
+	}
+	// the batch is kept to be retried
+	if len(s.pending) != 1 {
+		t.Errorf("got %d rows pending; want 1", len(s.pending))
+	}
+}
+
+func TestRun(t *testing.T) {
+	s, fake := open(t, Postgres, Options{BatchSize: 2, FlushInterval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	at := time.Unix(1700000000, 0)
+	for i := range 5 {
+		if err := s.Write(ctx, death(ps2.CharacterID(i+1), at)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the same event written twice is left for the database to ignore
+	if err := s.Write(ctx, death(1, at)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(ctx, unkeyed{}); err == nil {
+		t.Error("expected an error for an event without a unique key")
+	}
+
+	// full batches are written without waiting for the flush interval
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fake.Statements()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(fake.Statements()); n < 2 {
+		t.Fatalf("got %d batches written before the flush interval; want the full batches", n)
+	}
+
+	// the rest are written when Run stops
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	rows := 0
+	for _, stmt := range fake.Statements() {
+		if !strings.HasSuffix(stmt.Query, "ON CONFLICT (event_key) DO NOTHING") {
+			t.Errorf("got %q; want duplicates ignored", stmt.Query)
+		}
+		rows += len(stmt.Args) / len(columns)
+	}
+	if rows != 6 {
+		t.Errorf("got %d rows written; want 6", rows)
+	}
+}
+
+type unkeyed struct{}
+
+func (unkeyed) Type() ps2.Event { return ps2.Death }
@@ -11,6 +11,7 @@ import (
 	"log/slog"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Travis-Britz/ps2"
@@ -29,12 +30,22 @@ func New(serviceID string, env ps2.Environment) *Client {
 
 type Client struct {
 	conn                          *websocket.Conn
+	writeMu                       sync.Mutex // writeMu serializes writes to conn
+	connected                     atomic.Bool
+	subMu                         sync.Mutex
+	subscriptions                 []commander // subscriptions are sent every time the client connects
+	connectHooks                  []func()    // connectHooks are called after subscriptions are replayed, for components like RosterSync
 	messageLogger                 messageLogger
+	tees                          []*Tee
 	serviceID                     string
 	env                           ps2.Environment
 	serviceURL                    string
 	err                           chan error
 	connectHandler                func()
+	dispatch                      Dispatch
+	skew                          Skew
+	lag                           atomic.Int64 // lag is the StreamLag estimate as a time.Duration
+	lagObserved                   atomic.Bool
 	playerLoginHandlers           []func(event.PlayerLogin)
 	playerLogoutHandlers          []func(event.PlayerLogout)
 	gainExperienceHandlers        []func(event.GainExperience)
@@ -50,6 +61,19 @@ type Client struct {
 	skillAddedHandlers            []func(event.SkillAdded)
 	continentLockHandlers         []func(event.ContinentLock)
 	fishScanHandlers              []func(event.FishScan)
+	heartbeatHandlers             []func(Heartbeat)
+	serviceStateChangedHandlers   []func(ServiceStateChanged)
+	connectionStateHandlers       []func(ConnectionStateChanged)
+	worldPopulationHandlers       []func(WorldPopulation)
+	serviceMessageHandlers        []func(ServiceMessage)
+	clockSkewHandlers             []func(ClockSkew)
+	rawHandlers                   []func(json.RawMessage)
+	subscriptionHandlers          []func(SubscriptionConfirmed)
+	confirmed                     atomic.Pointer[SubscriptionConfirmed] // confirmed is the last subscription reply of the current connection
+	disconnectHandlers            []func(Disconnected)
+	reconnectHandlers             []func(Reconnected)
+	handlerPanicHandlers          []func(HandlerPanic)
+	handlerCtx                    atomic.Pointer[context.Context] // handlerCtx is the context of the current Run, for ContextHandler
 }
 
 // SetMessageLogger sets a logger to track all sent and received websocket messages.
@@ -83,7 +107,7 @@ func (c *Client) Run(ctx context.Context) error {
 		// Proxy:            http.ProxyFromEnvironment,
 		HandshakeTimeout: 10 * time.Second,
 	}
-	slog.Debug("dialing event service", "url", url)
+	slog.Debug("dialing event service", "url", redactURL(url))
 	conn, _, err := dialer.DialContext(ctx, url, nil)
 	// conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
 	if err != nil {
@@ -91,10 +115,23 @@ func (c *Client) Run(ctx context.Context) error {
 	}
 	defer conn.Close()
 	c.conn = conn
+	c.handlerCtx.Store(&ctx)
+	c.err = make(chan error, 1)
+	c.confirmed.Store(nil)
+	c.connected.Store(true)
+	defer c.connected.Store(false)
+	c.subMu.Lock()
+	subscriptions := append([]commander(nil), c.subscriptions...)
+	c.subMu.Unlock()
+	for _, cs := range subscriptions {
+		c.Send(cs)
+	}
+	for _, f := range c.connectHooks {
+		f()
+	}
 	if c.connectHandler != nil {
 		c.connectHandler()
 	}
-	c.err = make(chan error, 1)
 	messages := make(chan rawMessage, 100)
 	go c.handle(ctx, messages)
 	go c.read(ctx, messages)
@@ -120,13 +157,21 @@ func (c *Client) Run(ctx context.Context) error {
 //	sub.AllEvents()
 //	client.Send(sub)
 func (c *Client) Send(cs commander) {
+	if v, ok := cs.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			slog.Warn("sending subscription that will miss events", "error", err)
+		}
+	}
 	b, err := json.Marshal(cs.command())
 	if err != nil {
 		slog.Error("error marshaling command to JSON", "error", err, "command", cs)
 		return
 	}
 	c.messageLogger.Sent(b)
-	if err := c.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+	c.writeMu.Lock()
+	err = c.conn.WriteMessage(websocket.TextMessage, b)
+	c.writeMu.Unlock()
+	if err != nil {
 		c.exit(fmt.Errorf("write error: %w", err))
 		return
 	}
@@ -145,20 +190,62 @@ func (c *Client) read(ctx context.Context, messages chan<- rawMessage) {
 			break
 		}
 		messageLogger.Received(message)
+		for _, t := range c.tees {
+			t.send(message)
+		}
+		if !json.Valid(message) {
+			slog.Error("decoding JSON failed", "raw", string(message))
+			continue
+		}
 		err = json.Unmarshal(message, &m)
 		if err != nil {
-			slog.Error("decoding JSON failed", "error", err, "raw", string(message))
-			continue
+			// messages like help responses have no type; they're passed to raw handlers as-is
+			slog.Debug("unrecognized message", "error", err, "raw", string(message))
+			m = rawMessage{unrecognized: true}
 		}
+		m.data = message
+		m.received = time.Now()
 		messages <- m
 	}
 }
 
+// Subscribe sends subscriptions now if the client is connected,
+// and again every time the client connects,
+// so that they're replayed when [WithRetry] reconnects.
+// Subscriptions are sent in the order they were added, before the connect handler is called.
+func (c *Client) Subscribe(cs ...commander) {
+	c.subMu.Lock()
+	c.subscriptions = append(c.subscriptions, cs...)
+	connected := c.connected.Load()
+	c.subMu.Unlock()
+	if connected {
+		for _, s := range cs {
+			c.Send(s)
+		}
+	}
+}
+
+// ClearSubscriptions removes every subscription added with [Client.Subscribe],
+// and clears the subscriptions of the current connection.
+func (c *Client) ClearSubscriptions() {
+	c.subMu.Lock()
+	c.subscriptions = nil
+	connected := c.connected.Load()
+	c.subMu.Unlock()
+	if connected {
+		c.Send(ClearAll)
+	}
+}
+
 // SetConnectHandler sets a function h to be called upon connect success.
 func (c *Client) SetConnectHandler(h func()) {
 	c.connectHandler = h
 }
 
+// AddHandler registers h to be called for every received message of the matching type.
+// h must be a func taking one of the event types, such as func(event.Death),
+// or one of the service message types from this package, such as func(wsc.Heartbeat).
+// AddHandler panics for any other type.
 func (c *Client) AddHandler(h any) {
 	switch v := h.(type) {
 	case func(event.PlayerLogin):
@@ -191,79 +278,119 @@ func (c *Client) AddHandler(h any) {
 		c.continentLockHandlers = append(c.continentLockHandlers, v)
 	case func(event.FishScan):
 		c.fishScanHandlers = append(c.fishScanHandlers, v)
+	case func(Heartbeat):
+		c.heartbeatHandlers = append(c.heartbeatHandlers, v)
+	case func(ServiceStateChanged):
+		c.serviceStateChangedHandlers = append(c.serviceStateChangedHandlers, v)
+	case func(ConnectionStateChanged):
+		c.connectionStateHandlers = append(c.connectionStateHandlers, v)
+	case func(WorldPopulation):
+		c.worldPopulationHandlers = append(c.worldPopulationHandlers, v)
+	case func(ServiceMessage):
+		c.serviceMessageHandlers = append(c.serviceMessageHandlers, v)
+	case func(ClockSkew):
+		c.clockSkewHandlers = append(c.clockSkewHandlers, v)
 	default:
 		panic(fmt.Sprintf("AddHandler: invalid type '%T'", h))
 	}
 }
 
+// AddRawHandler registers h to be called with every received message that isn't a recognized event or service message,
+// such as help responses and messages added to the service after this package.
+// The json MUST NOT be modified.
+func (c *Client) AddRawHandler(h func(json.RawMessage)) {
+	c.rawHandlers = append(c.rawHandlers, h)
+}
+
+// OnSubscriptionConfirmed registers f to be called with every reply to a subscribe command.
+// Replies list everything the connection is subscribed to,
+// so [SubscriptionConfirmed.Includes] can check whether a subscription was applied.
+func (c *Client) OnSubscriptionConfirmed(f func(SubscriptionConfirmed)) {
+	c.subscriptionHandlers = append(c.subscriptionHandlers, f)
+}
+
+// Subscriptions returns the last reply to a subscribe command received on the current connection.
+// It returns false when no reply has been received since connecting,
+// which a short while after subscribing usually means the service ID isn't being served.
+func (c *Client) Subscriptions() (SubscriptionConfirmed, bool) {
+	s := c.confirmed.Load()
+	if s == nil {
+		return SubscriptionConfirmed{}, false
+	}
+	return *s, true
+}
+
 func (c *Client) handle(ctx context.Context, messages <-chan rawMessage) {
+	if c.dispatch.Workers > 1 {
+		c.handleParallel(messages)
+		return
+	}
 	// dedup := make(deduplicator, 0, 10000)
 	for m := range messages {
-		e := m.message()
+		e, skew := c.checkSkew(m.message(), m.received)
+		if skew != nil {
+			c.callHandlers(*skew)
+		}
 		// if ee, ok := e.(uniqueTimestampedEvent); ok {
 		// 	if !dedup.InsertFresh(ee) {
 		// 		slog.Debug("duplicate event dropped", "event", e)
 		// 		continue
 		// 	}
 		// }
-		switch v := e.(type) {
-		case event.PlayerLogin:
-			for _, h := range c.playerLoginHandlers {
-				h(v)
-			}
-		case event.PlayerLogout:
-			for _, h := range c.playerLogoutHandlers {
-				h(v)
-			}
-		case event.GainExperience:
-			for _, h := range c.gainExperienceHandlers {
-				h(v)
-			}
-		case event.VehicleDestroy:
-			for _, h := range c.vehicleDestroyHandlers {
-				h(v)
-			}
-		case event.Death:
-			for _, h := range c.deathHandlers {
-				h(v)
-			}
-		case event.AchievementEarned:
-			for _, h := range c.achievementEarnedHandlers {
-				h(v)
-			}
-		case event.BattleRankUp:
-			for _, h := range c.battleRankUpHandlers {
-				h(v)
-			}
-		case event.ItemAdded:
-			for _, h := range c.itemAddedHandlers {
-				h(v)
-			}
-		case event.MetagameEvent:
-			for _, h := range c.metagameEventHandlers {
-				h(v)
-			}
-		case event.FacilityControl:
-			for _, h := range c.facilityControlHandlers {
-				h(v)
-			}
-		case event.PlayerFacilityCapture:
-			for _, h := range c.playerFacilityCaptureHandlers {
-				h(v)
-			}
-		case event.PlayerFacilityDefend:
-			for _, h := range c.playerFacilityDefendHandlers {
-				h(v)
-			}
-		case event.SkillAdded:
-			for _, h := range c.skillAddedHandlers {
-				h(v)
-			}
-		case event.ContinentLock:
-			for _, h := range c.continentLockHandlers {
-				h(v)
-			}
-		}
+		c.callHandlers(e)
+	}
+}
+
+// callHandlers calls every handler registered for the type of e.
+func (c *Client) callHandlers(e any) {
+	switch v := e.(type) {
+	case event.PlayerLogin:
+		callEach(c, c.playerLoginHandlers, v)
+	case event.PlayerLogout:
+		callEach(c, c.playerLogoutHandlers, v)
+	case event.GainExperience:
+		callEach(c, c.gainExperienceHandlers, v)
+	case event.VehicleDestroy:
+		callEach(c, c.vehicleDestroyHandlers, v)
+	case event.Death:
+		callEach(c, c.deathHandlers, v)
+	case event.AchievementEarned:
+		callEach(c, c.achievementEarnedHandlers, v)
+	case event.BattleRankUp:
+		callEach(c, c.battleRankUpHandlers, v)
+	case event.ItemAdded:
+		callEach(c, c.itemAddedHandlers, v)
+	case event.MetagameEvent:
+		callEach(c, c.metagameEventHandlers, v)
+	case event.FacilityControl:
+		callEach(c, c.facilityControlHandlers, v)
+	case event.PlayerFacilityCapture:
+		callEach(c, c.playerFacilityCaptureHandlers, v)
+	case event.PlayerFacilityDefend:
+		callEach(c, c.playerFacilityDefendHandlers, v)
+	case event.SkillAdded:
+		callEach(c, c.skillAddedHandlers, v)
+	case event.ContinentLock:
+		callEach(c, c.continentLockHandlers, v)
+	case event.FishScan:
+		callEach(c, c.fishScanHandlers, v)
+	case Heartbeat:
+		callEach(c, c.heartbeatHandlers, v)
+	case ServiceStateChanged:
+		callEach(c, c.serviceStateChangedHandlers, v)
+	case ConnectionStateChanged:
+		callEach(c, c.connectionStateHandlers, v)
+	case WorldPopulation:
+		callEach(c, c.worldPopulationHandlers, v)
+	case ServiceMessage:
+		callEach(c, c.serviceMessageHandlers, v)
+	case ClockSkew:
+		callEach(c, c.clockSkewHandlers, v)
+	case SubscriptionConfirmed:
+		c.confirmed.Store(&v)
+		callEach(c, c.subscriptionHandlers, v)
+	case json.RawMessage:
+		callEach(c, c.rawHandlers, v)
 	}
 }
 
@@ -274,6 +401,20 @@ func (c *Client) url() string {
 	return fmt.Sprintf("wss://push.planetside2.com/streaming?environment=%s&service-id=s:%s", c.env, url.QueryEscape(c.serviceID))
 }
 
+// redactURL replaces the service ID in an event streaming url so that it can be logged.
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	q := parsed.Query()
+	if q.Has("service-id") {
+		q.Set("service-id", "s:REDACTED")
+		parsed.RawQuery = q.Encode()
+	}
+	return parsed.String()
+}
+
 func (c *Client) debug(fmt string, v ...any) {
 	log.Printf(fmt, v...)
 }
@@ -0,0 +1,497 @@
+package wsc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/event/wsc"
+	"github.com/Travis-Britz/ps2/event/wsc/wsctest"
+)
+
+func login(characterID string) wsctest.Step {
+	return wsctest.Payload(map[string]string{
+		"event_name":   "PlayerLogin",
+		"character_id": characterID,
+		"timestamp":    "1709037290",
+		"world_id":     "1",
+	})
+}
+
+func TestClientScript(t *testing.T) {
+	srv := wsctest.NewServer(
+		login("5428010618015189713"),
+		wsctest.Message(`{"payload":{"event_name":"PlayerLogin",`),
+		wsctest.Message(`not json`),
+		login("5428010618015189714"),
+		wsctest.Disconnect(),
+	)
+	defer srv.Close()
+
+	logins := make(chan event.PlayerLogin, 10)
+	connected := make(chan bool, 1)
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	client.AddHandler(func(e event.PlayerLogin) { logins <- e })
+	client.AddHandler(func(e wsc.ConnectionStateChanged) { connected <- e.Connected })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Run(ctx); err == nil {
+		t.Fatalf("expected an error after the server disconnected")
+	}
+
+	if !<-connected {
+		t.Errorf("expected a connectionStateChanged message")
+	}
+	// handlers run on their own goroutine and may still be draining
+	for _, want := range []ps2.CharacterID{5428010618015189713, 5428010618015189714} {
+		select {
+		case e := <-logins:
+			if e.CharacterID != want {
+				t.Errorf("expected login for %d; got %d", want, e.CharacterID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected login for %d", want)
+		}
+	}
+	select {
+	case e := <-logins:
+		t.Errorf("unexpected login: %v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClientSubscribe(t *testing.T) {
+	srv := wsctest.NewServer()
+	defer srv.Close()
+
+	subscribed := make(chan struct{})
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	client.SetConnectHandler(func() {
+		sub := wsc.Subscribe{}
+		sub.AllWorlds()
+		sub.AllCharacters()
+		sub.AllEvents()
+		client.Send(sub)
+	})
+	client.AddHandler(func(wsc.ConnectionStateChanged) {})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		for len(srv.Received()) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(subscribed)
+		cancel()
+	}()
+	if err := client.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	select {
+	case <-subscribed:
+	default:
+		t.Fatalf("server did not receive a subscription")
+	}
+	if srv.Connections() != 1 {
+		t.Errorf("expected 1 connection; got %d", srv.Connections())
+	}
+}
+
+func TestClientTee(t *testing.T) {
+	srv := wsctest.NewServer(
+		login("5428010618015189713"),
+		login("5428010618015189714"),
+		login("5428010618015189715"),
+		wsctest.Disconnect(),
+	)
+	defer srv.Close()
+
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	all := client.Tee(10, wsc.DropNewest)
+	newest := client.Tee(1, wsc.DropOldest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client.Run(ctx)
+
+	// connectionStateChanged plus three logins
+	if len(all.C) != 4 || all.Dropped() != 0 {
+		t.Errorf("expected 4 buffered messages and none dropped; got %d and %d", len(all.C), all.Dropped())
+	}
+	if newest.Dropped() != 3 {
+		t.Errorf("expected 3 dropped messages; got %d", newest.Dropped())
+	}
+	if b := <-newest.C; !bytes.Contains(b, []byte("5428010618015189715")) {
+		t.Errorf("expected the last login to be kept; got %s", b)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	pc := wsctest.NewServer(login("5428010618015189713"))
+	defer pc.Close()
+	ps4 := wsctest.NewServer(login("5428010618015189714"))
+	defer ps4.Close()
+
+	m := wsc.NewMulti("example", ps2.PC, ps2.PS4US)
+	m.Client(ps2.PC).SetURL(pc.URL)
+	m.Client(ps2.PS4US).SetURL(ps4.URL)
+	m.Subscribe(ps2.PS4US, (&wsc.Subscribe{}).All())
+
+	type tagged struct {
+		env ps2.Environment
+		id  ps2.CharacterID
+	}
+	logins := make(chan tagged, 10)
+	wsc.HandleEnv(m, func(env ps2.Environment, e event.PlayerLogin) { logins <- tagged{env, e.CharacterID} })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go m.Run(ctx)
+
+	got := map[ps2.Environment]ps2.CharacterID{}
+	for len(got) < 2 {
+		select {
+		case l := <-logins:
+			got[l.env] = l.id
+		case <-ctx.Done():
+			t.Fatalf("expected a login from each environment; got %v", got)
+		}
+	}
+	if got[ps2.PC] != 5428010618015189713 || got[ps2.PS4US] != 5428010618015189714 {
+		t.Errorf("logins were tagged with the wrong environment: %v", got)
+	}
+	for len(ps4.Received()) == 0 && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(pc.Received()) != 0 || len(ps4.Received()) != 1 {
+		t.Errorf("expected one subscription sent to PS4US only; got %d and %d", len(pc.Received()), len(ps4.Received()))
+	}
+}
+
+func TestClientDispatchByWorld(t *testing.T) {
+	var steps []wsctest.Step
+	for i := range 50 {
+		for _, world := range []string{"1", "10", "17"} {
+			steps = append(steps, wsctest.Payload(map[string]string{
+				"event_name":   "PlayerLogin",
+				"character_id": strconv.Itoa(i + 1),
+				"timestamp":    "1709037290",
+				"world_id":     world,
+			}))
+		}
+	}
+	steps = append(steps, wsctest.Disconnect())
+	srv := wsctest.NewServer(steps...)
+	defer srv.Close()
+
+	var mu sync.Mutex
+	seen := make(map[ps2.WorldID][]ps2.CharacterID)
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	client.SetDispatch(wsc.Dispatch{Workers: 4, Key: wsc.ByWorld})
+	client.AddHandler(func(e event.PlayerLogin) {
+		mu.Lock()
+		seen[e.WorldID] = append(seen[e.WorldID], e.CharacterID)
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client.Run(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(seen[1]) + len(seen[10]) + len(seen[17])
+		mu.Unlock()
+		if n == 150 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, world := range []ps2.WorldID{1, 10, 17} {
+		if len(seen[world]) != 50 {
+			t.Fatalf("world %d: expected 50 logins; got %d", world, len(seen[world]))
+		}
+		for i, id := range seen[world] {
+			if id != ps2.CharacterID(i+1) {
+				t.Fatalf("world %d: logins out of order at %d: %v", world, i, seen[world])
+			}
+		}
+	}
+}
+
+func TestWithRetryResubscribes(t *testing.T) {
+	srv := wsctest.NewServer(
+		wsctest.Wait(100*time.Millisecond),
+		wsctest.Disconnect(),
+		login("5428010618015189713"),
+	)
+	defer srv.Close()
+
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	client.Subscribe((&wsc.Subscribe{}).All())
+	disconnects := make(chan wsc.Disconnected, 1)
+	reconnects := make(chan wsc.Reconnected, 1)
+	logins := make(chan event.PlayerLogin, 1)
+	client.OnDisconnect(func(d wsc.Disconnected) { disconnects <- d })
+	client.OnReconnect(func(r wsc.Reconnected) { reconnects <- r })
+	client.AddHandler(func(e event.PlayerLogin) { logins <- e })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go wsc.WithRetry(client, ctx)
+
+	select {
+	case d := <-disconnects:
+		if d.Err == nil || d.Attempt != 1 || d.RetryIn < 500*time.Millisecond || d.RetryIn > time.Second {
+			t.Errorf("unexpected disconnect: %+v", d)
+		}
+	case <-ctx.Done():
+		t.Fatalf("expected a disconnect")
+	}
+	select {
+	case r := <-reconnects:
+		if r.Attempts != 1 || r.Downtime <= 0 {
+			t.Errorf("unexpected reconnect: %+v", r)
+		}
+	case <-ctx.Done():
+		t.Fatalf("expected a reconnect")
+	}
+	select {
+	case <-logins:
+	case <-ctx.Done():
+		t.Fatalf("expected a login after reconnecting")
+	}
+
+	if srv.Connections() != 2 {
+		t.Errorf("expected 2 connections; got %d", srv.Connections())
+	}
+	// the server records messages on its own goroutine
+	for len(srv.Received()) < 2 && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := len(srv.Received()); n != 2 {
+		t.Errorf("expected the subscription to be sent on both connections; got %d messages", n)
+	}
+}
+
+func TestClientRecoverAndContext(t *testing.T) {
+	srv := wsctest.NewServer(
+		login("5428010618015189713"),
+		login("5428010618015189714"),
+	)
+	defer srv.Close()
+
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	client.SetDispatch(wsc.Dispatch{Recover: true})
+	panics := make(chan wsc.HandlerPanic, 10)
+	client.OnHandlerPanic(func(p wsc.HandlerPanic) { panics <- p })
+	client.AddHandler(func(e event.PlayerLogin) {
+		if e.CharacterID == 5428010618015189713 {
+			panic("bad handler")
+		}
+	})
+	contexts := make(chan context.Context, 10)
+	client.AddHandler(wsc.ContextHandler(client, func(ctx context.Context, e event.PlayerLogin) { contexts <- ctx }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- client.Run(ctx) }()
+
+	var handlerCtx context.Context
+	for range 2 {
+		select {
+		case handlerCtx = <-contexts:
+		case <-time.After(time.Second):
+			t.Fatal("the handler after the panicking handler wasn't called for both logins")
+		}
+	}
+	select {
+	case p := <-panics:
+		if p.Value != "bad handler" || p.Message.(event.PlayerLogin).CharacterID != 5428010618015189713 || len(p.Stack) == 0 {
+			t.Errorf("unexpected panic report: %v for %v", p.Value, p.Message)
+		}
+	default:
+		t.Error("the panic wasn't reported")
+	}
+
+	if handlerCtx.Err() != nil {
+		t.Fatal("handler context was cancelled while the client was running")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if handlerCtx.Err() == nil {
+		t.Error("handler context wasn't cancelled when the client stopped")
+	}
+}
+
+func TestMultiReconnectsIndependently(t *testing.T) {
+	pc := wsctest.NewServer(
+		wsctest.Wait(100*time.Millisecond),
+		wsctest.Disconnect(),
+	)
+	defer pc.Close()
+	ps4 := wsctest.NewServer(wsctest.Wait(5 * time.Second))
+	defer ps4.Close()
+
+	m := wsc.NewMulti("example", ps2.PC, ps2.PS4US)
+	m.Client(ps2.PC).SetURL(pc.URL)
+	m.Client(ps2.PS4US).SetURL(ps4.URL)
+	disconnects := make(chan ps2.Environment, 10)
+	reconnects := make(chan ps2.Environment, 10)
+	m.OnDisconnect(func(env ps2.Environment, d wsc.Disconnected) { disconnects <- env })
+	m.OnReconnect(func(env ps2.Environment, r wsc.Reconnected) { reconnects <- env })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go m.Run(ctx)
+
+	select {
+	case env := <-disconnects:
+		if env != ps2.PC {
+			t.Errorf("got a disconnect from %v; want PC", env)
+		}
+		if !m.Connected(ps2.PS4US) {
+			t.Errorf("PS4US was disconnected along with PC")
+		}
+	case <-ctx.Done():
+		t.Fatalf("expected a disconnect")
+	}
+	select {
+	case env := <-reconnects:
+		if env != ps2.PC {
+			t.Errorf("got a reconnect from %v; want PC", env)
+		}
+	case <-ctx.Done():
+		t.Fatalf("expected a reconnect")
+	}
+	if ps4.Connections() != 1 {
+		t.Errorf("got %d PS4US connections; want 1", ps4.Connections())
+	}
+}
+
+func TestClientRawHandler(t *testing.T) {
+	srv := wsctest.NewServer(
+		wsctest.Message(`{"service":"event","action":"help","help":{"subscribe":{}}}`),
+		wsctest.Message(`{"detail":"EventServerEndpoint_Connery_1","online":"false","service":"event","type":"serviceStateChanged"}`),
+		wsctest.Message(`{"online":{"EventServerEndpoint_Connery_1":"false","EventServerEndpoint_Emerald_17":"true"},"service":"event","type":"heartbeat"}`),
+		wsctest.Message(`not json`),
+		login("5428010618015189713"),
+		wsctest.Wait(100*time.Millisecond),
+		wsctest.Disconnect(),
+	)
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var raw []string
+	var states []wsc.ServiceStateChanged
+	var heartbeats []wsc.Heartbeat
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	client.AddRawHandler(func(m json.RawMessage) {
+		mu.Lock()
+		raw = append(raw, string(m))
+		mu.Unlock()
+	})
+	client.AddHandler(func(e wsc.ServiceStateChanged) {
+		mu.Lock()
+		states = append(states, e)
+		mu.Unlock()
+	})
+	client.AddHandler(func(e wsc.Heartbeat) {
+		mu.Lock()
+		heartbeats = append(heartbeats, e)
+		mu.Unlock()
+	})
+	sub := wsc.Subscribe{}
+	sub.AddWorld(ps2.Emerald).AllEvents()
+	client.Subscribe(sub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var help bool
+	for _, m := range raw {
+		help = help || strings.Contains(m, `"action":"help"`)
+		if strings.Contains(m, `"subscription"`) {
+			t.Errorf("expected the subscription confirmation to be recognized; got it as a raw message")
+		}
+	}
+	if !help {
+		t.Errorf("expected the help response as a raw message; got %q", raw)
+	}
+	if len(raw) != 1 {
+		t.Errorf("got %d raw messages; want 1: %q", len(raw), raw)
+	}
+	if len(states) != 1 || states[0].WorldID != ps2.Osprey || states[0].Online {
+		t.Errorf("got %+v; want Osprey offline", states)
+	}
+	if len(heartbeats) != 1 {
+		t.Fatalf("got %d heartbeats; want 1", len(heartbeats))
+	}
+	if w := heartbeats[0].Worlds(); len(w) != 2 || w[ps2.Osprey] || !w[ps2.Emerald] {
+		t.Errorf("got %v; want Osprey offline and Emerald online", w)
+	}
+}
+
+func TestClientSubscriptionConfirmed(t *testing.T) {
+	srv := wsctest.NewServer(
+		wsctest.Wait(100*time.Millisecond),
+		wsctest.Disconnect(),
+	)
+	defer srv.Close()
+
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	confirmed := make(chan wsc.SubscriptionConfirmed, 1)
+	client.OnSubscriptionConfirmed(func(s wsc.SubscriptionConfirmed) { confirmed <- s })
+	sub := wsc.Subscribe{}
+	sub.AddWorld(ps2.Emerald).AddEvent(ps2.FacilityControl)
+	client.Subscribe(sub)
+	if _, ok := client.Subscriptions(); ok {
+		t.Errorf("expected no confirmed subscriptions before connecting")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go client.Run(ctx)
+
+	select {
+	case s := <-confirmed:
+		if !s.Includes(sub) {
+			t.Errorf("got %+v; want it to include %+v", s, sub)
+		}
+		other := wsc.Subscribe{}
+		other.AddWorld(ps2.Osprey).AddEvent(ps2.FacilityControl)
+		if s.Includes(other) {
+			t.Errorf("got %+v; want it not to include Osprey", s)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected a subscription confirmation")
+	}
+	if s, ok := client.Subscriptions(); !ok || len(s.Worlds) != 1 || s.Worlds[0] != ps2.Emerald {
+		t.Errorf("got %+v, %t; want the confirmed subscription to Emerald", s, ok)
+	}
+}
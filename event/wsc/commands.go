@@ -38,6 +38,48 @@ func (s *Subscribe) AllEvents() *Subscribe {
 	return s
 }
 
+// AddEvent adds events to the subscription.
+func (s *Subscribe) AddEvent(e ...ps2.Event) *Subscribe {
+	s.Events = append(s.Events, e...)
+	return s
+}
+
+// CharacterEvents adds every event that is about a character.
+// See [ps2.IsCharacterEvent].
+func (s *Subscribe) CharacterEvents() *Subscribe {
+	for _, e := range ps2.AllEvents() {
+		if ps2.IsCharacterEvent(e) {
+			s.Events = append(s.Events, e)
+		}
+	}
+	return s
+}
+
+// WorldEvents adds every event that can be received by subscribing to worlds.
+// See [ps2.IsWorldEvent].
+func (s *Subscribe) WorldEvents() *Subscribe {
+	for _, e := range ps2.AllEvents() {
+		if ps2.IsWorldEvent(e) {
+			s.Events = append(s.Events, e)
+		}
+	}
+	return s
+}
+
+// Validate returns an error for subscriptions that won't receive some of the requested events,
+// such as world events without any worlds.
+func (s Subscribe) Validate() error {
+	for _, e := range s.Events {
+		if e == ps2.Unknown || e.String() == "" {
+			return fmt.Errorf("wsc.Subscribe: invalid event %d", e)
+		}
+		if ps2.RequiresWorldSubscription(e) && s.Worlds == nil {
+			return fmt.Errorf("wsc.Subscribe: %s events are only sent to subscriptions with worlds", e)
+		}
+	}
+	return nil
+}
+
 func (s *Subscribe) All() *Subscribe {
 	s.AllWorlds()
 	s.AllEvents()
@@ -86,6 +128,33 @@ func (s Subscribe) command() command {
 	return c
 }
 
+// ClearSubscribe removes events, worlds, or characters from the subscriptions of the current connection.
+// Nil fields are left out of the command.
+//
+//	client.Send(wsc.ClearSubscribe{Characters: []ps2.CharacterID{id}})
+type ClearSubscribe struct {
+	Events     []ps2.Event
+	Worlds     []ps2.WorldID
+	Characters []ps2.CharacterID
+}
+
+func (s ClearSubscribe) command() command {
+	c := command{
+		Action:  clearSubscribe,
+		Service: eventService,
+	}
+	for _, e := range s.Events {
+		c.EventNames = append(c.EventNames, e.EventName())
+	}
+	for _, w := range s.Worlds {
+		c.Worlds = append(c.Worlds, w.StringID())
+	}
+	for _, ch := range s.Characters {
+		c.Characters = append(c.Characters, ch.String())
+	}
+	return c
+}
+
 type command struct {
 	Action                         action      `json:"action"`
 	Service                        service     `json:"service"`
@@ -0,0 +1,242 @@
+package wsc
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// DispatchKey chooses which events must be handled in the order they were received.
+type DispatchKey uint8
+
+const (
+	// ByWorld handles events from the same world in order.
+	// This is the right choice for anything tracking territory,
+	// since facility control and continent lock events depend on the order they happened in.
+	ByWorld DispatchKey = iota
+
+	// ByCharacter handles events for the same character in order.
+	// Death and VehicleDestroy are ordered by the victim.
+	// Events without a character, like FacilityControl, fall back to being ordered by world.
+	ByCharacter
+)
+
+// Dispatch controls how a [Client] calls handlers.
+//
+// By default every handler is called from a single goroutine in the order messages were received,
+// which means a slow handler holds up every other event.
+// With more than one worker, events are spread across serial queues by Key:
+// events with the same key are still handled in order,
+// while events with different keys may be handled in parallel.
+// Handlers must be safe for concurrent use when Workers is more than 1.
+//
+// Messages that don't belong to a world, such as [Heartbeat], always use the first queue.
+type Dispatch struct {
+	// Workers is the number of serial queues.
+	// 0 or 1 handles every message on one goroutine.
+	Workers int
+
+	Key DispatchKey
+
+	// Buffer is the number of messages each queue holds before reading from the websocket is blocked.
+	// The default is 100.
+	Buffer int
+
+	// Recover recovers panics in handlers instead of letting them crash the program.
+	// The panic is logged and passed to handlers registered with [Client.OnHandlerPanic],
+	// and the remaining handlers for the message are still called.
+	Recover bool
+}
+
+// HandlerPanic is passed to handlers registered with [Client.OnHandlerPanic]
+// when a handler panics while [Dispatch.Recover] is set.
+type HandlerPanic struct {
+	// Message is the event or service message the handler was called with.
+	Message any
+
+	// Value is the value the handler panicked with.
+	Value any
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// OnHandlerPanic registers f to be called when a handler panics while [Dispatch.Recover] is set.
+// f is called on the goroutine of the handler that panicked.
+// Panics in f are logged but not reported again.
+func (c *Client) OnHandlerPanic(f func(HandlerPanic)) {
+	c.handlerPanicHandlers = append(c.handlerPanicHandlers, f)
+}
+
+// ContextHandler adapts h to a handler for [Client.AddHandler]
+// that's called with the context of the running client.
+// The context is cancelled when [Client.Run] returns,
+// so handlers doing slow work like database writes can give up when the client stops
+// instead of holding up shutdown:
+//
+//	client.AddHandler(wsc.ContextHandler(client, func(ctx context.Context, e event.Death) {
+//		db.ExecContext(ctx, "...", e.CharacterID)
+//	}))
+//
+// Handlers called before the client has run get context.Background.
+func ContextHandler[E any](c *Client, h func(context.Context, E)) func(E) {
+	return func(e E) {
+		ctx := context.Background()
+		if p := c.handlerCtx.Load(); p != nil {
+			ctx = *p
+		}
+		h(ctx, e)
+	}
+}
+
+// callEach calls every handler with e,
+// recovering panics if the client is set to.
+func callEach[E any](c *Client, handlers []func(E), e E) {
+	if !c.dispatch.Recover {
+		for _, h := range handlers {
+			h(e)
+		}
+		return
+	}
+	for _, h := range handlers {
+		c.callRecover(func() { h(e) }, e)
+	}
+}
+
+// callRecover calls f and reports a panic as a [HandlerPanic] for message.
+func (c *Client) callRecover(f func(), message any) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		p := HandlerPanic{Message: message, Value: v, Stack: debug.Stack()}
+		slog.Error("wsc handler panicked", "panic", v, "message", message, "stack", string(p.Stack))
+		for _, h := range c.handlerPanicHandlers {
+			func() {
+				defer func() {
+					if v := recover(); v != nil {
+						slog.Error("wsc panic handler panicked", "panic", v)
+					}
+				}()
+				h(p)
+			}()
+		}
+	}()
+	f()
+}
+
+// SetDispatch sets how handlers are called.
+// It must be called before [Client.Run].
+func (c *Client) SetDispatch(d Dispatch) {
+	c.dispatch = d
+}
+
+func (c *Client) handleParallel(messages <-chan rawMessage) {
+	buffer := c.dispatch.Buffer
+	if buffer <= 0 {
+		buffer = 100
+	}
+	queues := make([]chan any, c.dispatch.Workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan any, buffer)
+		wg.Add(1)
+		go func(q <-chan any) {
+			defer wg.Done()
+			for e := range q {
+				c.callHandlers(e)
+			}
+		}(queues[i])
+	}
+	for m := range messages {
+		e, skew := c.checkSkew(m.message(), m.received)
+		q := queues[dispatchKey(e, c.dispatch.Key)%uint64(len(queues))]
+		if skew != nil {
+			q <- *skew
+		}
+		q <- e
+	}
+	for _, q := range queues {
+		close(q)
+	}
+	wg.Wait()
+}
+
+// dispatchKey returns the ordering key of e.
+// Messages without a key return 0.
+func dispatchKey(e any, by DispatchKey) uint64 {
+	if by == ByCharacter {
+		var character int64
+		switch v := e.(type) {
+		case event.PlayerLogin:
+			character = int64(v.CharacterID)
+		case event.PlayerLogout:
+			character = int64(v.CharacterID)
+		case event.GainExperience:
+			character = int64(v.CharacterID)
+		case event.VehicleDestroy:
+			character = int64(v.CharacterID)
+		case event.Death:
+			character = int64(v.CharacterID)
+		case event.AchievementEarned:
+			character = int64(v.CharacterID)
+		case event.BattleRankUp:
+			character = int64(v.CharacterID)
+		case event.ItemAdded:
+			character = int64(v.CharacterID)
+		case event.PlayerFacilityCapture:
+			character = int64(v.CharacterID)
+		case event.PlayerFacilityDefend:
+			character = int64(v.CharacterID)
+		case event.SkillAdded:
+			character = int64(v.CharacterID)
+		case event.FishScan:
+			character = int64(v.CharacterID)
+		}
+		if character != 0 {
+			return uint64(character)
+		}
+	}
+
+	switch v := e.(type) {
+	case event.PlayerLogin:
+		return uint64(v.WorldID)
+	case event.PlayerLogout:
+		return uint64(v.WorldID)
+	case event.GainExperience:
+		return uint64(v.WorldID)
+	case event.VehicleDestroy:
+		return uint64(v.WorldID)
+	case event.Death:
+		return uint64(v.WorldID)
+	case event.AchievementEarned:
+		return uint64(v.WorldID)
+	case event.BattleRankUp:
+		return uint64(v.WorldID)
+	case event.ItemAdded:
+		return uint64(v.WorldID)
+	case event.MetagameEvent:
+		return uint64(v.WorldID)
+	case event.FacilityControl:
+		return uint64(v.WorldID)
+	case event.PlayerFacilityCapture:
+		return uint64(v.WorldID)
+	case event.PlayerFacilityDefend:
+		return uint64(v.WorldID)
+	case event.SkillAdded:
+		return uint64(v.WorldID)
+	case event.ContinentLock:
+		return uint64(v.WorldID)
+	case event.FishScan:
+		return uint64(v.WorldID)
+	case ServiceStateChanged:
+		return uint64(v.WorldID)
+	case WorldPopulation:
+		return uint64(v.WorldID)
+	}
+	return 0
+}
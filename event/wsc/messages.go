@@ -2,18 +2,27 @@ package wsc
 
 import (
 	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
 
+	"github.com/Travis-Britz/ps2"
 	"github.com/Travis-Britz/ps2/event"
 )
 
 type rawMessage struct {
-	Service service     `json:"service"`
-	Type    messageType `json:"type"`
-	heartbeatMessage
-	serviceStateChangedMessage
-	connectionStateChangedMessage
-	subscriptionMessage
-	eventServiceMessage
+	Service                       service     `json:"service"`
+	Type                          messageType `json:"type"`
+	heartbeatMessage              `json:"-"`
+	serviceStateChangedMessage    `json:"-"`
+	connectionStateChangedMessage `json:"-"`
+	subscriptionMessage           `json:"-"`
+	eventServiceMessage           `json:"-"`
+
+	received     time.Time // received is when the message was read from the websocket
+	data         []byte    // data is the message as it was read from the websocket
+	unrecognized bool      // unrecognized is set for messages that couldn't be decoded
+	subscription bool      // subscription is set for the subscription echo sent after subscribe commands
 }
 
 func (m *rawMessage) UnmarshalJSON(data []byte) error {
@@ -24,7 +33,8 @@ func (m *rawMessage) UnmarshalJSON(data []byte) error {
 	}
 
 	if tmp["service"] == nil && tmp["type"] == nil && tmp["subscription"] != nil {
-		return json.Unmarshal(tmp["subscription"], &m.subscriptionMessage)
+		m.subscription = true
+		return json.Unmarshal(data, &m.subscriptionMessage)
 	}
 
 	if err := json.Unmarshal(tmp["service"], &m.Service); err != nil {
@@ -36,7 +46,7 @@ func (m *rawMessage) UnmarshalJSON(data []byte) error {
 
 	switch {
 	case m.Service == eventService && m.Type == serviceMessage:
-		return json.Unmarshal(data, &m.eventServiceMessage)
+		return json.Unmarshal(tmp["payload"], &m.eventServiceMessage)
 	case m.Service == eventService && m.Type == heartbeat:
 		return json.Unmarshal(data, &m.heartbeatMessage)
 	case m.Service == eventService && m.Type == serviceStateChanged:
@@ -48,26 +58,48 @@ func (m *rawMessage) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// message returns the typed message of m,
+// or the raw json for messages that aren't recognized,
+// such as help responses.
 func (m rawMessage) message() any {
 	switch {
+	case m.unrecognized:
+		return json.RawMessage(m.data)
+	case m.subscription:
+		return m.subscriptionMessage.confirmed(m.received)
 	case m.Service == eventService && m.Type == serviceMessage:
-		return m.eventServiceMessage.Payload.Event()
+		if e := m.eventServiceMessage.message(); e != nil {
+			return e
+		}
 	case m.Service == eventService && m.Type == heartbeat:
-		return m.heartbeatMessage
+		return m.heartbeatMessage.Heartbeat()
 	case m.Service == eventService && m.Type == serviceStateChanged:
-		return m.serviceStateChangedMessage
+		return ServiceStateChanged{
+			Endpoint: endpoints[m.serviceStateChangedMessage.Detail],
+			WorldID:  ps2.WorldID(m.serviceStateChangedMessage.Detail),
+			Online:   bool(m.serviceStateChangedMessage.Online),
+		}
 	case m.Service == push && m.Type == connectionStateChanged:
-		return m.connectionStateChangedMessage
-	case !m.subscriptionMessage.IsEmpty():
-		return m.subscriptionMessage
+		return ConnectionStateChanged{Connected: bool(m.connectionStateChangedMessage.Connected)}
 	}
-	return nil
+	return json.RawMessage(m.data)
 }
 
 type heartbeatMessage struct {
 	Online map[string]stringBool `json:"online"`
 }
 
+func (m heartbeatMessage) Heartbeat() Heartbeat {
+	h := Heartbeat{
+		Online:    make(map[string]bool, len(m.Online)),
+		Timestamp: time.Now().UTC(),
+	}
+	for endpoint, online := range m.Online {
+		h.Online[endpoint] = bool(online)
+	}
+	return h
+}
+
 type serviceStateChangedMessage struct {
 	Detail endpoint   `json:"detail"`
 	Online stringBool `json:"online"`
@@ -80,6 +112,7 @@ type connectionStateChangedMessage struct {
 type subscriptionMessage struct {
 	Subscription struct {
 		Characters                     []string `json:"characters"`
+		CharacterCount                 int      `json:"characterCount"`
 		EventNames                     []string `json:"eventNames"`
 		LogicalAndCharactersWithWorlds bool     `json:"logicalAndCharactersWithWorlds"`
 		Worlds                         []string `json:"worlds"`
@@ -90,6 +123,224 @@ func (s subscriptionMessage) IsEmpty() bool {
 	return s.Subscription.Characters == nil && s.Subscription.EventNames == nil && s.Subscription.Worlds == nil && s.Subscription.LogicalAndCharactersWithWorlds == false
 }
 
+func (s subscriptionMessage) confirmed(received time.Time) SubscriptionConfirmed {
+	sub := s.Subscription
+	c := SubscriptionConfirmed{
+		EventNames:                     sub.EventNames,
+		CharacterCount:                 sub.CharacterCount,
+		LogicalAndCharactersWithWorlds: sub.LogicalAndCharactersWithWorlds,
+		Timestamp:                      received,
+	}
+	for _, ch := range sub.Characters {
+		if ch == "all" {
+			c.AllCharacters = true
+		} else if sub.CharacterCount == 0 {
+			c.CharacterCount++
+		}
+	}
+	for _, w := range sub.Worlds {
+		if w == "all" {
+			c.AllWorlds = true
+			continue
+		}
+		if id, err := strconv.Atoi(w); err == nil {
+			c.Worlds = append(c.Worlds, ps2.WorldID(id))
+		}
+	}
+	return c
+}
+
+// eventServiceMessage holds the payload of a serviceMessage.
+// Most payloads are game events,
+// but some services (like NSS) also push their own payloads using the same message type.
 type eventServiceMessage struct {
-	Payload event.Raw `json:"payload"`
+	event      event.Typer
+	population *WorldPopulation
+	unknown    json.RawMessage
+}
+
+func (s *eventServiceMessage) UnmarshalJSON(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	var name ps2.Event
+	if keys["event_name"] != nil && name.UnmarshalJSON(keys["event_name"]) == nil {
+		var raw event.Raw
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		s.event = raw.Event()
+		return nil
+	}
+
+	if keys["event_name"] == nil && keys["world_id"] != nil &&
+		(keys["vs_population"] != nil || keys["nc_population"] != nil || keys["tr_population"] != nil) {
+		var pop worldPopulationPayload
+		if err := json.Unmarshal(data, &pop); err != nil {
+			return err
+		}
+		p := pop.WorldPopulation()
+		s.population = &p
+		return nil
+	}
+
+	s.unknown = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+func (s eventServiceMessage) message() any {
+	switch {
+	case s.event != nil:
+		return s.event
+	case s.population != nil:
+		return *s.population
+	case s.unknown != nil:
+		return ServiceMessage{Payload: s.unknown}
+	}
+	return nil
+}
+
+// Heartbeat is sent periodically by the push service with the online status of each event server endpoint.
+type Heartbeat struct {
+	// Online is keyed by endpoint name, e.g. "EventServerEndpoint_Connery_1".
+	Online map[string]bool
+
+	// Timestamp is the time the heartbeat was received.
+	// Heartbeats don't include a timestamp of their own.
+	Timestamp time.Time
+}
+
+// Worlds returns the online status of each world in the heartbeat,
+// keyed by the same WorldID as [ServiceStateChanged].
+// Endpoints that aren't known to this package are left out.
+func (h Heartbeat) Worlds() map[ps2.WorldID]bool {
+	worlds := make(map[ps2.WorldID]bool, len(h.Online))
+	for name, online := range h.Online {
+		for e, endpointName := range endpoints {
+			if name == endpointName {
+				worlds[ps2.WorldID(e)] = online
+			}
+		}
+	}
+	return worlds
+}
+
+// ServiceStateChanged is sent when an event server endpoint goes online or offline.
+type ServiceStateChanged struct {
+	Endpoint string
+
+	// WorldID is the world the endpoint serves.
+	// The Genudine endpoint serves every PS4US world.
+	WorldID ps2.WorldID
+	Online  bool
+}
+
+// SubscriptionConfirmed is the reply to every subscribe command,
+// describing everything the connection is subscribed to once the command was applied.
+// Census doesn't reply with an error for parts of a subscription it doesn't understand,
+// such as a misspelled event name or a world ID it doesn't know;
+// they're left out of the reply instead.
+type SubscriptionConfirmed struct {
+	// EventNames are the subscribed event names,
+	// such as "Death" or "GainExperience_experience_id_4".
+	EventNames []string
+
+	Worlds    []ps2.WorldID
+	AllWorlds bool
+
+	// CharacterCount is the number of subscribed characters.
+	// Census doesn't list them.
+	CharacterCount int
+	AllCharacters  bool
+
+	LogicalAndCharactersWithWorlds bool
+
+	// Timestamp is the time the reply was received.
+	Timestamp time.Time
+}
+
+// Includes reports whether every event and world of sub is part of the confirmed subscription,
+// and whether at least as many characters are subscribed.
+// A subscription that isn't included after it was sent was at least partly rejected.
+func (s SubscriptionConfirmed) Includes(sub Subscribe) bool {
+	cmd := sub.command()
+	for _, name := range cmd.EventNames {
+		if !slices.Contains(s.EventNames, name) && !slices.Contains(s.EventNames, "all") {
+			return false
+		}
+	}
+	for _, w := range cmd.Worlds {
+		if w == "all" {
+			if !s.AllWorlds {
+				return false
+			}
+			continue
+		}
+		if !s.AllWorlds && !slices.ContainsFunc(s.Worlds, func(id ps2.WorldID) bool { return id.StringID() == w }) {
+			return false
+		}
+	}
+	switch {
+	case slices.Equal(cmd.Characters, []string{"all"}):
+		return s.AllCharacters
+	case s.AllCharacters:
+		return true
+	default:
+		return s.CharacterCount >= len(cmd.Characters)
+	}
+}
+
+// ConnectionStateChanged is sent by the push service when the websocket connection changes state,
+// which in practice is only once after connecting.
+type ConnectionStateChanged struct {
+	Connected bool
+}
+
+// WorldPopulation is a population summary pushed by services that wrap the event stream, such as NSS.
+// Census does not send these.
+type WorldPopulation struct {
+	WorldID   ps2.WorldID
+	ZoneID    ps2.ZoneInstanceID // ZoneID is 0 when the population is for an entire world
+	VS        int
+	NC        int
+	TR        int
+	NSO       int
+	Timestamp time.Time
+}
+
+// Total returns the sum of every faction's population.
+func (p WorldPopulation) Total() int { return p.VS + p.NC + p.TR + p.NSO }
+
+type worldPopulationPayload struct {
+	WorldID   ps2.WorldID        `json:"world_id,string"`
+	ZoneID    ps2.ZoneInstanceID `json:"zone_id,string"`
+	VS        int                `json:"vs_population,string"`
+	NC        int                `json:"nc_population,string"`
+	TR        int                `json:"tr_population,string"`
+	NSO       int                `json:"ns_population,string"`
+	Timestamp int64              `json:"timestamp,string"`
+}
+
+func (p worldPopulationPayload) WorldPopulation() WorldPopulation {
+	pop := WorldPopulation{
+		WorldID:   p.WorldID,
+		ZoneID:    p.ZoneID,
+		VS:        p.VS,
+		NC:        p.NC,
+		TR:        p.TR,
+		NSO:       p.NSO,
+		Timestamp: time.Unix(p.Timestamp, 0).UTC(),
+	}
+	if p.Timestamp == 0 {
+		pop.Timestamp = time.Now().UTC()
+	}
+	return pop
+}
+
+// ServiceMessage is a serviceMessage payload that is neither a game event nor a known service payload.
+// Payload is the raw json and MUST NOT be modified.
+type ServiceMessage struct {
+	Payload json.RawMessage
 }
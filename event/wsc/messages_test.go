@@ -0,0 +1,76 @@
+package wsc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// decodeMessage decodes a websocket message the same way the client does.
+func decodeMessage(t *testing.T, data string) any {
+	t.Helper()
+	m := rawMessage{data: []byte(data)}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return m.message()
+}
+
+func TestEventServiceMessage(t *testing.T) {
+	// captured from the census push service
+	death := decodeMessage(t, `{"payload":{"attacker_character_id":"5428010618015189713","attacker_fire_mode_id":"7390","attacker_loadout_id":"15","attacker_team_id":"2","attacker_vehicle_id":"0","attacker_weapon_id":"7214","character_id":"5428713425545165425","character_loadout_id":"8","event_name":"Death","is_critical":"0","is_headshot":"1","team_id":"3","timestamp":"1709037290","vehicle_id":"0","world_id":"17","zone_id":"2"},"service":"event","type":"serviceMessage"}`)
+	if d, ok := death.(event.Death); !ok || d.AttackerCharacterID != 5428010618015189713 || d.CharacterID != 5428713425545165425 || !d.IsHeadshot || d.WorldID != ps2.Emerald || d.ZoneID != 2 || d.Timestamp.Unix() != 1709037290 {
+		t.Errorf("got %#v; want the death", death)
+	}
+
+	exp := decodeMessage(t, `{"payload":{"amount":"100","character_id":"5428010618015189713","event_name":"GainExperience","experience_id":"4","loadout_id":"15","other_id":"5428713425545165425","team_id":"2","timestamp":"1709037291","world_id":"17","zone_id":"2"},"service":"event","type":"serviceMessage"}`)
+	if e, ok := exp.(event.GainExperience); !ok || e.Amount != 100 || e.ExperienceID != 4 || e.OtherID != 5428713425545165425 {
+		t.Errorf("got %#v; want the experience", exp)
+	}
+
+	control := decodeMessage(t, `{"payload":{"duration_held":"1845","event_name":"FacilityControl","facility_id":"7500","new_faction_id":"1","old_faction_id":"3","outfit_id":"37509488620604883","timestamp":"1709037292","world_id":"17","zone_id":"2"},"service":"event","type":"serviceMessage"}`)
+	if c, ok := control.(event.FacilityControl); !ok || c.FacilityID != 7500 || c.NewFactionID != ps2.VS || c.DurationHeld != 1845*time.Second || c.OutfitID != 37509488620604883 {
+		t.Errorf("got %#v; want the facility capture", control)
+	}
+}
+
+func TestEventServiceMessagePopulation(t *testing.T) {
+	// pushed by NSS, which wraps the census stream
+	pop := decodeMessage(t, `{"payload":{"world_id":"17","zone_id":"2","vs_population":"120","nc_population":"98","tr_population":"143","ns_population":"7","timestamp":"1709037290"},"service":"event","type":"serviceMessage"}`)
+	want := WorldPopulation{WorldID: ps2.Emerald, ZoneID: 2, VS: 120, NC: 98, TR: 143, NSO: 7, Timestamp: time.Unix(1709037290, 0).UTC()}
+	if pop != want {
+		t.Errorf("got %#v; want %#v", pop, want)
+	}
+
+	// a world total without a zone or timestamp is stamped when it's received
+	before := time.Now().UTC()
+	world := decodeMessage(t, `{"payload":{"world_id":"1","vs_population":"10"},"service":"event","type":"serviceMessage"}`)
+	if p, ok := world.(WorldPopulation); !ok || p.WorldID != ps2.Osprey || p.ZoneID != 0 || p.Total() != 10 || p.Timestamp.Before(before) {
+		t.Errorf("got %#v; want the world population", world)
+	}
+}
+
+func TestEventServiceMessageUnknown(t *testing.T) {
+	for _, payload := range []string{
+		// an event this package doesn't know about
+		`{"event_name":"PlayerEmote","character_id":"5428010618015189713","timestamp":"1709037290","world_id":"17"}`,
+		// a population without a world can't be attributed
+		`{"vs_population":"120"}`,
+		`{"status":"ok"}`,
+	} {
+		msg := decodeMessage(t, `{"payload":`+payload+`,"service":"event","type":"serviceMessage"}`)
+		sm, ok := msg.(ServiceMessage)
+		if !ok || string(sm.Payload) != payload {
+			t.Errorf("got %#v; want the raw payload %s", msg, payload)
+		}
+	}
+
+	// payloads that aren't objects are a decoding error
+	var m rawMessage
+	if err := json.Unmarshal([]byte(`{"payload":"text","service":"event","type":"serviceMessage"}`), &m); err == nil {
+		t.Error("expected an error for a payload that isn't an object")
+	}
+}
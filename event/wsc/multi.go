@@ -0,0 +1,124 @@
+package wsc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Multi runs one [Client] per environment behind a single set of handlers.
+//
+//	m := wsc.NewMulti("example", ps2.PC, ps2.PS4US, ps2.PS4EU)
+//	m.Subscribe(ps2.PC, (&wsc.Subscribe{}).All())
+//	m.Subscribe(ps2.PS4US, (&wsc.Subscribe{Events: []ps2.Event{ps2.FacilityControl}}).AllWorlds())
+//	m.AddHandler(func(e event.FacilityControl) { ... })
+//	wsc.HandleEnv(m, func(env ps2.Environment, e event.Death) { ... })
+//	m.OnDisconnect(func(env ps2.Environment, d wsc.Disconnected) { ... })
+//	err := m.Run(ctx)
+//
+// Each environment has its own connection and reconnects on its own schedule,
+// so an outage of one environment doesn't interrupt the others.
+type Multi struct {
+	envs    []ps2.Environment
+	clients map[ps2.Environment]*Client
+}
+
+// NewMulti creates a client for each of envs.
+func NewMulti(serviceID string, envs ...ps2.Environment) *Multi {
+	m := &Multi{
+		clients: make(map[ps2.Environment]*Client, len(envs)),
+	}
+	for _, env := range envs {
+		if _, exists := m.clients[env]; exists {
+			continue
+		}
+		c := New(serviceID, env)
+		m.envs = append(m.envs, env)
+		m.clients[env] = c
+	}
+	return m
+}
+
+// Client returns the client for env,
+// or nil if env wasn't given to [NewMulti].
+// It can be used for settings like [Client.SetURL] or [Client.SetMessageLogger].
+func (m *Multi) Client(env ps2.Environment) *Client {
+	return m.clients[env]
+}
+
+// Subscribe adds subscriptions for env that will be sent every time its client connects.
+// Subscribe panics if env wasn't given to [NewMulti].
+func (m *Multi) Subscribe(env ps2.Environment, cs ...commander) {
+	if m.clients[env] == nil {
+		panic(fmt.Sprintf("wsc.Multi.Subscribe: environment %v is not running", env))
+	}
+	m.clients[env].Subscribe(cs...)
+}
+
+// AddHandler registers h with the client for every environment.
+// See [Client.AddHandler] for the accepted types.
+// Use [HandleEnv] to know which environment an event came from.
+func (m *Multi) AddHandler(h any) {
+	for _, env := range m.envs {
+		m.clients[env].AddHandler(h)
+	}
+}
+
+// HandleEnv registers h with the client for every environment,
+// tagging each call with the environment the message was received from.
+// T must be one of the types accepted by [Client.AddHandler], such as event.Death or wsc.Heartbeat.
+func HandleEnv[T any](m *Multi, h func(ps2.Environment, T)) {
+	for _, env := range m.envs {
+		m.clients[env].AddHandler(func(e T) { h(env, e) })
+	}
+}
+
+// OnDisconnect registers f to be called when the connection of any environment is lost or fails,
+// with the environment that disconnected.
+// See [Client.OnDisconnect].
+func (m *Multi) OnDisconnect(f func(ps2.Environment, Disconnected)) {
+	for _, env := range m.envs {
+		m.clients[env].OnDisconnect(func(d Disconnected) { f(env, d) })
+	}
+}
+
+// OnReconnect registers f to be called when an environment connects again after a disconnect,
+// with the environment that reconnected.
+// See [Client.OnReconnect].
+func (m *Multi) OnReconnect(f func(ps2.Environment, Reconnected)) {
+	for _, env := range m.envs {
+		m.clients[env].OnReconnect(func(r Reconnected) { f(env, r) })
+	}
+}
+
+// OnSubscriptionConfirmed registers f to be called with every reply to a subscribe command,
+// with the environment of the connection that replied.
+// See [Client.OnSubscriptionConfirmed].
+func (m *Multi) OnSubscriptionConfirmed(f func(ps2.Environment, SubscriptionConfirmed)) {
+	for _, env := range m.envs {
+		m.clients[env].OnSubscriptionConfirmed(func(s SubscriptionConfirmed) { f(env, s) })
+	}
+}
+
+// Connected reports whether the client for env currently has a connection.
+// It's false for environments that weren't given to [NewMulti].
+func (m *Multi) Connected(env ps2.Environment) bool {
+	c := m.clients[env]
+	return c != nil && c.connected.Load()
+}
+
+// Run runs every client with [WithRetry] until ctx is cancelled.
+func (m *Multi) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, env := range m.envs {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			WithRetry(c, ctx)
+		}(m.clients[env])
+	}
+	wg.Wait()
+	return nil
+}
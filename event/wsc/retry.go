@@ -3,45 +3,112 @@ package wsc
 import (
 	"context"
 	"log/slog"
+	"math/rand/v2"
 	"time"
 )
 
+// Disconnected is passed to handlers registered with [Client.OnDisconnect]
+// every time a connection run by [WithRetry] fails or ends with an error.
+type Disconnected struct {
+	Err error
+
+	// Attempt counts the failed connections since the client was last connected, starting at 1.
+	Attempt int
+
+	// RetryIn is how long WithRetry waits before connecting again.
+	RetryIn time.Duration
+}
+
+// Reconnected is passed to handlers registered with [Client.OnReconnect]
+// when [WithRetry] connects again after a disconnect.
+// Subscriptions added with [Client.Subscribe] have already been replayed.
+type Reconnected struct {
+	// Attempts is the number of connections tried since the disconnect, including this one.
+	Attempts int
+
+	// Downtime is the time since the first disconnect.
+	// Events sent during the downtime were missed.
+	Downtime time.Duration
+}
+
+// OnDisconnect registers f to be called when a connection run by [WithRetry] is lost or fails.
+// f is called from the goroutine running WithRetry and delays reconnecting until it returns.
+func (c *Client) OnDisconnect(f func(Disconnected)) {
+	c.disconnectHandlers = append(c.disconnectHandlers, f)
+}
+
+// OnReconnect registers f to be called when [WithRetry] connects again after a disconnect.
+// f is called before the connect handler.
+func (c *Client) OnReconnect(f func(Reconnected)) {
+	c.reconnectHandlers = append(c.reconnectHandlers, f)
+}
+
 // WithRetry will run a Client,
 // with retries on error,
 // until ctx is cancelled.
 //
-// Connection retries will follow an exponential backoff,
+// Connection retries will follow an exponential backoff with jitter,
 // with up to 1hr between retries.
 // Successful connections will reset the retry delay.
+// Subscriptions added with [Client.Subscribe] are sent again on every reconnect,
+// and [Client.OnDisconnect] and [Client.OnReconnect] handlers are told about each outage.
 func WithRetry(c *Client, ctx context.Context) error {
 	var delay time.Duration
+	var disconnected time.Time
+	attempts := 0
 	h := c.connectHandler
+	defer func() { c.connectHandler = h }()
 	c.connectHandler = func() {
+		if !disconnected.IsZero() {
+			r := Reconnected{Attempts: attempts, Downtime: time.Since(disconnected)}
+			for _, f := range c.reconnectHandlers {
+				f(r)
+			}
+		}
+		disconnected = time.Time{}
+		attempts = 0
+		delay = 0
 		if h != nil {
 			h()
 		}
-		delay = 0
 	}
 
+	var wait time.Duration
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-time.After(delay):
+		case <-time.After(wait):
 			err := c.Run(ctx)
 			select {
 			case <-ctx.Done():
 				return nil
 			default:
 				if err != nil {
+					if disconnected.IsZero() {
+						disconnected = time.Now()
+					}
+					attempts++
 					delay = delay*2 + time.Second
 					if delay > time.Hour {
 						delay = time.Hour
 					}
-					slog.Info("planetside websocket service disconnected", "error", err, "retry_delay", delay.String())
+					wait = jitter(delay)
+					slog.Info("planetside websocket service disconnected", "error", err, "retry_delay", wait.String())
+					d := Disconnected{Err: err, Attempt: attempts, RetryIn: wait}
+					for _, f := range c.disconnectHandlers {
+						f(d)
+					}
+				} else {
+					wait = 0
 				}
 			}
-
 		}
 	}
 }
+
+// jitter returns a random duration between half of d and d,
+// so that clients disconnected at the same time don't all reconnect at the same time.
+func jitter(d time.Duration) time.Duration {
+	return d/2 + rand.N(d/2+1)
+}
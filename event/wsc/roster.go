@@ -0,0 +1,152 @@
+package wsc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// RosterProvider returns the characters a [RosterSync] should be subscribed to,
+// such as the members of an outfit.
+type RosterProvider interface {
+	Roster(ctx context.Context) ([]ps2.CharacterID, error)
+}
+
+// RosterFunc is a function that implements [RosterProvider].
+type RosterFunc func(ctx context.Context) ([]ps2.CharacterID, error)
+
+func (f RosterFunc) Roster(ctx context.Context) ([]ps2.CharacterID, error) { return f(ctx) }
+
+// RosterSync keeps the character subscriptions of a [Client] in sync with a roster,
+// subscribing to characters that join and clearing the subscriptions of characters that leave.
+// The whole roster is subscribed again every time the client connects.
+//
+//	members := wsc.RosterFunc(func(ctx context.Context) ([]ps2.CharacterID, error) {
+//		// e.g. query census for outfit_member rows
+//	})
+//	roster := wsc.NewRosterSync(client, members, 10*time.Minute)
+//	go roster.Run(ctx)
+//	wsc.WithRetry(client, ctx)
+type RosterSync struct {
+	client   *Client
+	provider RosterProvider
+	interval time.Duration
+	events   []ps2.Event
+
+	mu      sync.Mutex
+	current map[ps2.CharacterID]bool
+	updated time.Time
+}
+
+// NewRosterSync creates a RosterSync that checks provider every interval.
+// Characters are subscribed to events,
+// or to every character event when no events are given.
+// It must be created before the client is run.
+func NewRosterSync(client *Client, provider RosterProvider, interval time.Duration, events ...ps2.Event) *RosterSync {
+	if len(events) == 0 {
+		events = (&Subscribe{}).CharacterEvents().Events
+	}
+	s := &RosterSync{
+		client:   client,
+		provider: provider,
+		interval: interval,
+		events:   events,
+		current:  make(map[ps2.CharacterID]bool),
+	}
+	client.connectHooks = append(client.connectHooks, s.resubscribe)
+	return s
+}
+
+// Run refreshes the roster right away and then every interval until ctx is cancelled.
+// Refresh errors are logged and the previous roster is kept until the next refresh.
+func (s *RosterSync) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			slog.Info("roster refresh failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh gets the roster from the provider and updates the subscriptions of the current connection.
+// Changes made while the client is disconnected are sent when it connects.
+func (s *RosterSync) Refresh(ctx context.Context) error {
+	roster, err := s.provider.Roster(ctx)
+	if err != nil {
+		return fmt.Errorf("wsc.RosterSync.Refresh: %w", err)
+	}
+	next := make(map[ps2.CharacterID]bool, len(roster))
+	var added, removed []ps2.CharacterID
+	for _, id := range roster {
+		if id == 0 || next[id] {
+			continue
+		}
+		next[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range next {
+		if !s.current[id] {
+			added = append(added, id)
+		}
+	}
+	for id := range s.current {
+		if !next[id] {
+			removed = append(removed, id)
+		}
+	}
+	s.current = next
+	s.updated = time.Now()
+	if !s.client.connected.Load() {
+		return nil
+	}
+	if len(removed) > 0 {
+		slices.Sort(removed)
+		s.client.Send(ClearSubscribe{Characters: removed})
+	}
+	if len(added) > 0 {
+		slices.Sort(added)
+		s.client.Send(Subscribe{Events: s.events, Characters: added})
+	}
+	return nil
+}
+
+// Characters returns the characters of the last successful refresh, sorted by ID,
+// and the time of that refresh.
+func (s *RosterSync) Characters() ([]ps2.CharacterID, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]ps2.CharacterID, 0, len(s.current))
+	for id := range s.current {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, s.updated
+}
+
+// resubscribe subscribes the whole roster on a new connection.
+func (s *RosterSync) resubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.current) == 0 {
+		return
+	}
+	ids := make([]ps2.CharacterID, 0, len(s.current))
+	for id := range s.current {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	s.client.Send(Subscribe{Events: s.events, Characters: ids})
+}
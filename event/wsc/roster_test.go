@@ -0,0 +1,78 @@
+package wsc_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event/wsc"
+	"github.com/Travis-Britz/ps2/event/wsc/wsctest"
+)
+
+func TestRosterSync(t *testing.T) {
+	srv := wsctest.NewServer()
+	defer srv.Close()
+
+	var mu sync.Mutex
+	roster := []ps2.CharacterID{1, 2}
+	provider := wsc.RosterFunc(func(context.Context) ([]ps2.CharacterID, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return roster, nil
+	})
+	client := wsc.New("example", ps2.PC)
+	client.SetURL(srv.URL)
+	rs := wsc.NewRosterSync(client, provider, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// refreshed before connecting, so the roster is only sent by the connect hook
+	if err := rs.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	go client.Run(ctx)
+	waitReceived := func(n int) {
+		for len(srv.Received()) < n && ctx.Err() == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitReceived(1)
+
+	mu.Lock()
+	roster = []ps2.CharacterID{2, 3, 3}
+	mu.Unlock()
+	if err := rs.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	waitReceived(3)
+
+	type message struct {
+		Action     string   `json:"action"`
+		Characters []string `json:"characters"`
+	}
+	want := []message{
+		{"subscribe", []string{"1", "2"}},
+		{"clearSubscribe", []string{"1"}},
+		{"subscribe", []string{"3"}},
+	}
+	received := srv.Received()
+	if len(received) != len(want) {
+		t.Fatalf("expected %d messages; got %d", len(want), len(received))
+	}
+	for i, b := range received {
+		var got message
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if got.Action != want[i].Action || !slices.Equal(got.Characters, want[i].Characters) {
+			t.Errorf("message %d: got %+v; want %+v", i, got, want[i])
+		}
+	}
+	if ids, _ := rs.Characters(); !slices.Equal(ids, []ps2.CharacterID{2, 3}) {
+		t.Errorf("got characters %v; want [2 3]", ids)
+	}
+}
@@ -0,0 +1,151 @@
+package wsc
+
+import (
+	"time"
+
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// Skew controls how a [Client] treats event timestamps that disagree with the local clock.
+//
+// Census timestamps occasionally arrive a little in the future,
+// and during catch-up bursts after an outage they can lag far behind.
+// Handlers registered for [ClockSkew] are told about both,
+// and [Client.StreamLag] estimates the usual delay so that ordering and deduplication windows can adapt to it.
+type Skew struct {
+	// Tolerance is how far ahead of the receive time a timestamp may be before it is skewed.
+	// Timestamps only have one second resolution, so the default is 2 seconds.
+	Tolerance time.Duration
+
+	// Clamp replaces timestamps that are skewed into the future with the time the event was received.
+	Clamp bool
+
+	// MaxLag is how far behind the receive time a timestamp may be before it is reported as skewed.
+	// 0 never reports lagging events.
+	MaxLag time.Duration
+}
+
+const (
+	defaultSkewTolerance = 2 * time.Second
+
+	// lagSmoothing is the weight of each new event in the stream lag estimate.
+	lagSmoothing = 0.05
+)
+
+// ClockSkew is sent to handlers when an event's timestamp is outside the bounds of the client's [Skew] settings.
+// It is handled just before the event itself, on the same goroutine.
+type ClockSkew struct {
+	// Event is the skewed event, with its timestamp clamped if [Skew.Clamp] is set.
+	Event event.Typer
+
+	// SkewedBy is the event's original timestamp minus the time it was received.
+	// It is positive for timestamps in the future and negative for lagging events.
+	SkewedBy time.Duration
+
+	Received time.Time
+	Clamped  bool
+}
+
+// SetSkew sets how skewed timestamps are handled.
+// It must be called before [Client.Run].
+func (c *Client) SetSkew(s Skew) {
+	c.skew = s
+}
+
+// StreamLag returns a moving estimate of the delay between an event's timestamp and when it was received.
+// The estimate includes up to one second of rounding from the timestamp resolution,
+// and it is negative when the server clock is ahead of the local clock.
+func (c *Client) StreamLag() time.Duration {
+	return time.Duration(c.lag.Load())
+}
+
+// checkSkew updates the lag estimate with e and applies the skew settings.
+// skew is nil unless e should be reported to ClockSkew handlers.
+func (c *Client) checkSkew(e any, received time.Time) (adjusted any, skew *ClockSkew) {
+	ts, ok := e.(event.Timestamper)
+	if !ok || ts.Time().IsZero() || received.IsZero() {
+		return e, nil
+	}
+	offset := ts.Time().Sub(received)
+	c.observeLag(-offset)
+
+	tolerance := c.skew.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultSkewTolerance
+	}
+	future := offset > tolerance
+	lagging := c.skew.MaxLag > 0 && -offset > c.skew.MaxLag
+	if !future && !lagging {
+		return e, nil
+	}
+	skew = &ClockSkew{SkewedBy: offset, Received: received}
+	if future && c.skew.Clamp {
+		e = withTimestamp(e, received.UTC())
+		skew.Clamped = true
+	}
+	skew.Event, _ = e.(event.Typer)
+	return e, skew
+}
+
+// observeLag adds lag to the moving estimate.
+// It's only called from the goroutine handling messages.
+func (c *Client) observeLag(lag time.Duration) {
+	next := int64(lag)
+	if c.lagObserved.Swap(true) {
+		old := c.lag.Load()
+		next = old + int64(lagSmoothing*float64(next-old))
+	}
+	c.lag.Store(next)
+}
+
+// withTimestamp returns e with its timestamp replaced by t.
+func withTimestamp(e any, t time.Time) any {
+	switch v := e.(type) {
+	case event.PlayerLogin:
+		v.Timestamp = t
+		return v
+	case event.PlayerLogout:
+		v.Timestamp = t
+		return v
+	case event.GainExperience:
+		v.Timestamp = t
+		return v
+	case event.VehicleDestroy:
+		v.Timestamp = t
+		return v
+	case event.Death:
+		v.Timestamp = t
+		return v
+	case event.AchievementEarned:
+		v.Timestamp = t
+		return v
+	case event.BattleRankUp:
+		v.Timestamp = t
+		return v
+	case event.ItemAdded:
+		v.Timestamp = t
+		return v
+	case event.MetagameEvent:
+		v.Timestamp = t
+		return v
+	case event.FacilityControl:
+		v.Timestamp = t
+		return v
+	case event.PlayerFacilityCapture:
+		v.Timestamp = t
+		return v
+	case event.PlayerFacilityDefend:
+		v.Timestamp = t
+		return v
+	case event.SkillAdded:
+		v.Timestamp = t
+		return v
+	case event.ContinentLock:
+		v.Timestamp = t
+		return v
+	case event.FishScan:
+		v.Timestamp = t
+		return v
+	}
+	return e
+}
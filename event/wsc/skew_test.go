@@ -0,0 +1,119 @@
+package wsc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestClockSkewDefaultTolerance(t *testing.T) {
+	c := New("example", ps2.PC)
+	received := time.Unix(1709037290, 0)
+
+	// timestamps have one second resolution, so up to 2 seconds ahead is normal
+	for _, ahead := range []time.Duration{0, time.Second, 2 * time.Second} {
+		e := event.Death{Timestamp: received.Add(ahead)}
+		if adjusted, skew := c.checkSkew(e, received); skew != nil || adjusted != e {
+			t.Errorf("%v ahead: got %+v; want no skew", ahead, skew)
+		}
+	}
+
+	e := event.Death{CharacterID: 1, Timestamp: received.Add(3 * time.Second)}
+	adjusted, skew := c.checkSkew(e, received)
+	if skew == nil {
+		t.Fatal("got no skew for a timestamp 3 seconds ahead")
+	}
+	if skew.SkewedBy != 3*time.Second || skew.Clamped || !skew.Received.Equal(received) || skew.Event != e || adjusted != e {
+		t.Errorf("got %+v; want the unclamped event skewed by 3s", skew)
+	}
+
+	// lagging events aren't reported without MaxLag
+	if _, skew := c.checkSkew(event.Death{Timestamp: received.Add(-time.Hour)}, received); skew != nil {
+		t.Errorf("got %+v for a lagging event without MaxLag", skew)
+	}
+}
+
+func TestClockSkewClamp(t *testing.T) {
+	c := New("example", ps2.PC)
+	c.SetSkew(Skew{Tolerance: 5 * time.Second, Clamp: true, MaxLag: time.Minute})
+	received := time.Unix(1709037290, 0)
+
+	if _, skew := c.checkSkew(event.FacilityControl{Timestamp: received.Add(4 * time.Second)}, received); skew != nil {
+		t.Errorf("got %+v within the tolerance", skew)
+	}
+
+	adjusted, skew := c.checkSkew(event.FacilityControl{FacilityID: 7500, Timestamp: received.Add(10 * time.Second)}, received)
+	fc, ok := adjusted.(event.FacilityControl)
+	if skew == nil || !skew.Clamped || !ok || fc.FacilityID != 7500 || !fc.Timestamp.Equal(received) || fc.Timestamp.Location() != time.UTC {
+		t.Errorf("got %+v, %+v; want the event clamped to the receive time", adjusted, skew)
+	}
+	if skew.SkewedBy != 10*time.Second || skew.Event != adjusted {
+		t.Errorf("got %+v; want the original skew with the clamped event", skew)
+	}
+
+	// lagging events are reported but never clamped
+	lagging := event.FacilityControl{Timestamp: received.Add(-2 * time.Minute)}
+	adjusted, skew = c.checkSkew(lagging, received)
+	if skew == nil || skew.SkewedBy != -2*time.Minute || skew.Clamped || adjusted != lagging {
+		t.Errorf("got %+v; want the lagging event reported as it was", skew)
+	}
+}
+
+func TestClockSkewIgnored(t *testing.T) {
+	c := New("example", ps2.PC)
+	received := time.Unix(1709037290, 0)
+	for _, e := range []any{
+		Heartbeat{Timestamp: received.Add(time.Hour)},
+		event.Death{},
+		ServiceMessage{},
+	} {
+		if _, skew := c.checkSkew(e, received); skew != nil {
+			t.Errorf("got %+v for %T", skew, e)
+		}
+	}
+	if _, skew := c.checkSkew(event.Death{Timestamp: received.Add(time.Hour)}, time.Time{}); skew != nil {
+		t.Errorf("got %+v without a receive time", skew)
+	}
+	if c.StreamLag() != 0 {
+		t.Errorf("got stream lag %v from messages without timestamps", c.StreamLag())
+	}
+}
+
+func TestStreamLag(t *testing.T) {
+	c := New("example", ps2.PC)
+	received := time.Unix(1709037290, 0)
+
+	// the first event sets the estimate, and later ones move it gradually
+	c.checkSkew(event.Death{Timestamp: received.Add(-10 * time.Second)}, received)
+	if lag := c.StreamLag(); lag != 10*time.Second {
+		t.Fatalf("got lag %v; want 10s", lag)
+	}
+	c.checkSkew(event.Death{Timestamp: received}, received)
+	if lag := c.StreamLag(); lag != 9500*time.Millisecond {
+		t.Errorf("got lag %v; want 9.5s", lag)
+	}
+	for range 200 {
+		c.checkSkew(event.Death{Timestamp: received.Add(time.Second)}, received)
+	}
+	// a server clock ahead of ours makes the lag negative
+	if lag := c.StreamLag(); lag > -900*time.Millisecond || lag < -time.Second {
+		t.Errorf("got lag %v; want about -1s", lag)
+	}
+}
+
+func TestWithTimestamp(t *testing.T) {
+	at := time.Unix(1709037290, 0).UTC()
+	for _, e := range []event.Typer{
+		event.PlayerLogin{}, event.PlayerLogout{}, event.GainExperience{}, event.VehicleDestroy{},
+		event.Death{}, event.AchievementEarned{}, event.BattleRankUp{}, event.ItemAdded{},
+		event.MetagameEvent{}, event.FacilityControl{}, event.PlayerFacilityCapture{}, event.PlayerFacilityDefend{},
+		event.SkillAdded{}, event.ContinentLock{}, event.FishScan{},
+	} {
+		ts, ok := withTimestamp(e, at).(event.Timestamper)
+		if !ok || !ts.Time().Equal(at) {
+			t.Errorf("%T: timestamp wasn't replaced", e)
+		}
+	}
+}
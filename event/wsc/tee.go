@@ -0,0 +1,85 @@
+package wsc
+
+import (
+	"sync/atomic"
+)
+
+// DropPolicy decides what a [Tee] does with a message when its buffer is full.
+type DropPolicy uint8
+
+const (
+	// DropNewest discards the incoming message when the buffer is full.
+	DropNewest DropPolicy = iota
+
+	// DropOldest discards the oldest buffered message to make room for the incoming one.
+	DropOldest
+
+	// Block waits for the consumer to make room in the buffer.
+	// A slow consumer will stall typed handlers and eventually the websocket connection.
+	Block
+)
+
+// Tee is a copy of the raw message stream received by a [Client].
+type Tee struct {
+	// C receives every message read from the websocket, before unmarshaling.
+	// The channel is never closed, and it continues to receive messages across reconnects.
+	// Given byte slices are shared and MUST NOT be modified in any way.
+	C <-chan []byte
+
+	c       chan []byte
+	policy  DropPolicy
+	dropped atomic.Uint64
+}
+
+// Dropped returns the number of messages discarded because the buffer was full.
+func (t *Tee) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+func (t *Tee) send(b []byte) {
+	switch t.policy {
+	case Block:
+		t.c <- b
+	case DropOldest:
+		for {
+			select {
+			case t.c <- b:
+				return
+			default:
+			}
+			select {
+			case <-t.c:
+				t.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case t.c <- b:
+		default:
+			t.dropped.Add(1)
+		}
+	}
+}
+
+// Tee returns a subscription to the raw JSON frames received by the client,
+// buffered up to size messages (at least 1).
+// This is useful for archiving the exact byte stream while still using typed handlers from the same connection.
+//
+// Tee must be called before [Client.Run].
+//
+//	tee := client.Tee(1000, wsc.DropOldest)
+//	go func() {
+//		for b := range tee.C {
+//			archive.Write(b)
+//		}
+//	}()
+func (c *Client) Tee(size int, policy DropPolicy) *Tee {
+	if size < 1 {
+		size = 1
+	}
+	ch := make(chan []byte, size)
+	t := &Tee{C: ch, c: ch, policy: policy}
+	c.tees = append(c.tees, t)
+	return t
+}
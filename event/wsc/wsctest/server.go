@@ -0,0 +1,230 @@
+// Package wsctest provides an in-process websocket server for testing code that consumes the Planetside 2 event streaming service.
+//
+// The server plays a scripted sequence of messages to connected clients,
+// which makes it possible to test handlers, reconnects, and deduplication without connecting to census.
+package wsctest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Step is one scripted action performed by a [Server].
+// Steps are created with [Message], [Payload], [Wait], and [Disconnect].
+type Step struct {
+	message    []byte
+	delay      time.Duration
+	disconnect bool
+}
+
+// Message returns a Step that sends raw to the client exactly as given.
+// raw does not need to be valid json,
+// which is useful for testing malformed messages.
+func Message(raw string) Step {
+	return Step{message: []byte(raw)}
+}
+
+// Payload returns a Step that sends a serviceMessage wrapping payload,
+// the same way census sends game events.
+//
+//	wsctest.Payload(map[string]string{
+//		"event_name":   "PlayerLogin",
+//		"character_id": "5428010618015189713",
+//		"timestamp":    "1709037290",
+//		"world_id":     "1",
+//	})
+func Payload(payload map[string]string) Step {
+	b, err := json.Marshal(struct {
+		Payload map[string]string `json:"payload"`
+		Service string            `json:"service"`
+		Type    string            `json:"type"`
+	}{payload, "event", "serviceMessage"})
+	if err != nil {
+		panic("wsctest.Payload: " + err.Error())
+	}
+	return Step{message: b}
+}
+
+// Wait returns a Step that pauses the script for d.
+func Wait(d time.Duration) Step {
+	return Step{delay: d}
+}
+
+// Disconnect returns a Step that closes the current connection.
+// The script resumes from the next step when the client reconnects.
+func Disconnect() Step {
+	return Step{disconnect: true}
+}
+
+// Server is a mock event streaming service.
+type Server struct {
+	// URL is the websocket url of the server, e.g. "ws://127.0.0.1:51234".
+	// Pass it to wsc.Client.SetURL.
+	URL string
+
+	srv         *httptest.Server
+	upgrader    websocket.Upgrader
+	mu          sync.Mutex
+	script      []Step
+	next        int
+	received    [][]byte
+	connections int
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewServer starts a server that plays script to connected clients.
+// The caller should call Close when finished.
+//
+// Every new connection is first sent a connectionStateChanged message,
+// followed by the remaining steps of the script.
+// Subscription commands sent by the client are answered with a subscription message the same way census answers them.
+func NewServer(script ...Step) *Server {
+	s := &Server{
+		script: script,
+		done:   make(chan struct{}),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = "ws" + strings.TrimPrefix(s.srv.URL, "http")
+	return s
+}
+
+// Close disconnects all clients and shuts down the server.
+// It may be called more than once.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.srv.CloseClientConnections()
+		s.srv.Close()
+	})
+}
+
+// Received returns every message the server has received from clients, in order.
+func (s *Server) Received() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([][]byte, len(s.received))
+	copy(r, s.received)
+	return r
+}
+
+// Connections returns the number of connections the server has accepted.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Finished reports whether every step of the script has been played.
+func (s *Server) Finished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next >= len(s.script)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+
+	// gorilla connections support one concurrent writer,
+	// and both the script and subscription replies write to the connection.
+	var writeMu sync.Mutex
+	write := func(b []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteMessage(websocket.TextMessage, b)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.received = append(s.received, b)
+			s.mu.Unlock()
+			if reply := subscriptionReply(b); reply != nil {
+				write(reply)
+			}
+		}
+	}()
+
+	if err := write([]byte(`{"connected":"true","service":"push","type":"connectionStateChanged"}`)); err != nil {
+		return
+	}
+
+	for {
+		s.mu.Lock()
+		if s.next >= len(s.script) {
+			s.mu.Unlock()
+			break
+		}
+		step := s.script[s.next]
+		s.next++
+		s.mu.Unlock()
+
+		if step.disconnect {
+			return
+		}
+		if step.delay > 0 {
+			select {
+			case <-time.After(step.delay):
+			case <-closed:
+				return
+			case <-s.done:
+				return
+			}
+		}
+		if step.message != nil {
+			if err := write(step.message); err != nil {
+				return
+			}
+		}
+	}
+
+	// hold the connection open after the script finishes, like census would
+	select {
+	case <-closed:
+	case <-s.done:
+	}
+}
+
+// subscriptionReply returns the message census sends in response to a subscribe command,
+// or nil if b is not a subscribe command.
+func subscriptionReply(b []byte) []byte {
+	var cmd struct {
+		Action                         string   `json:"action"`
+		EventNames                     []string `json:"eventNames"`
+		Worlds                         []string `json:"worlds"`
+		Characters                     []string `json:"characters"`
+		LogicalAndCharactersWithWorlds bool     `json:"logicalAndCharactersWithWorlds"`
+	}
+	if err := json.Unmarshal(b, &cmd); err != nil || cmd.Action != "subscribe" {
+		return nil
+	}
+	reply, _ := json.Marshal(map[string]any{
+		"subscription": map[string]any{
+			"characters":                     cmd.Characters,
+			"eventNames":                     cmd.EventNames,
+			"logicalAndCharactersWithWorlds": cmd.LogicalAndCharactersWithWorlds,
+			"worlds":                         cmd.Worlds,
+		},
+	})
+	return reply
+}
@@ -0,0 +1,107 @@
+// Package poll runs the polling loops shared by the ps2alerts and state packages:
+// a request repeated at an interval with random jitter,
+// and retried with exponential backoff while it fails.
+package poll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Options is the timing of a polling loop.
+// The exported polling options of other packages are converted to it.
+type Options struct {
+	// Interval is the delay between successful polls.
+	// A negative interval polls only until the first success.
+	Interval time.Duration
+
+	// Jitter is the maximum random delay added to every poll.
+	// A negative jitter disables it.
+	Jitter time.Duration
+
+	// MinBackoff is the delay before the first retry, doubling after each failure up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// FirstDelay limits the jitter before the first poll.
+	// Zero polls immediately.
+	FirstDelay time.Duration
+}
+
+// WithDefaults fills in the zero values of o.
+// The default jitter is one fifth of the interval.
+func (o Options) WithDefaults(interval, maxBackoff time.Duration) Options {
+	if o.Interval == 0 {
+		o.Interval = interval
+	}
+	if o.Jitter == 0 {
+		o.Jitter = interval / 5
+		if o.Interval > 0 {
+			o.Jitter = o.Interval / 5
+		}
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = maxBackoff
+	}
+	o.MaxBackoff = max(o.MaxBackoff, o.MinBackoff)
+	return o
+}
+
+// jitter returns a random duration in [0, o.Jitter).
+func (o Options) jitter() time.Duration {
+	if o.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(o.Jitter)
+}
+
+// waitError is returned by a poll function to delay the next poll without backing off.
+type waitError struct {
+	until time.Time
+}
+
+func (e waitError) Error() string {
+	return fmt.Sprintf("polling paused until %v", e.until)
+}
+
+// Until returns an error that delays the next poll until t without counting as a failure,
+// such as while a circuit breaker is open.
+func Until(t time.Time) error {
+	return waitError{until: t}
+}
+
+// Run calls poll until ctx is done, or until the first success if o.Interval is negative.
+// When poll fails, failed is called with the error and the backoff before the next attempt.
+func Run(ctx context.Context, o Options, poll func(context.Context) error, failed func(err error, retry time.Duration)) {
+	delay := min(o.jitter(), o.FirstDelay)
+	var backoff time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		err := poll(ctx)
+		var wait waitError
+		switch {
+		case err == nil:
+			backoff = 0
+			if o.Interval < 0 {
+				return
+			}
+			delay = o.Interval + o.jitter()
+		case ctx.Err() != nil:
+			return
+		case errors.As(err, &wait):
+			delay = time.Until(wait.until) + o.jitter()
+		default:
+			backoff = min(max(backoff*2, o.MinBackoff), o.MaxBackoff)
+			failed(err, backoff)
+			delay = backoff + o.jitter()
+		}
+	}
+}
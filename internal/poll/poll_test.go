@@ -0,0 +1,51 @@
+package poll_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2/internal/poll"
+)
+
+func TestRun(t *testing.T) {
+	o := poll.Options{Interval: -1, Jitter: -1, MinBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+	calls := 0
+	var retries []time.Duration
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		poll.Run(context.Background(), o, func(context.Context) error {
+			calls++
+			switch calls {
+			case 1:
+				// a pause doesn't count toward the backoff
+				return poll.Until(time.Now())
+			case 2, 3, 4, 5:
+				return errors.New("unavailable")
+			}
+			return nil
+		}, func(err error, retry time.Duration) { retries = append(retries, retry) })
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the first success with a negative interval")
+	}
+	if calls != 6 || fmt.Sprint(retries) != "[1ms 2ms 4ms 4ms]" {
+		t.Errorf("got %d calls with retries %v; want 6 with the backoff doubling up to 4ms", calls, retries)
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	o := poll.Options{MinBackoff: time.Minute}.WithDefaults(5*time.Minute, 30*time.Second)
+	if o.Interval != 5*time.Minute || o.Jitter != time.Minute || o.MaxBackoff != time.Minute {
+		t.Errorf("got %+v; want the default interval with a fifth of it as jitter, and the backoff at least the minimum", o)
+	}
+	o = poll.Options{Interval: -1}.WithDefaults(5*time.Minute, 30*time.Second)
+	if o.Interval != -1 || o.Jitter != time.Minute {
+		t.Errorf("got %+v; want a negative interval kept with jitter from the default", o)
+	}
+}
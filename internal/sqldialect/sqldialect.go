@@ -0,0 +1,38 @@
+// Package sqldialect holds the differences in SQL syntax between the databases supported by
+// the sqlsink and sqlstore packages.
+package sqldialect
+
+import "fmt"
+
+// Dialect selects the SQL syntax for a database.
+type Dialect uint8
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+// Placeholder returns the bind parameter for the nth (1-based) value.
+func (d Dialect) Placeholder(n int) string {
+	if d == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// MaxParams is the number of bind parameters allowed in one statement.
+// SQLite before 3.32 allows 999.
+func (d Dialect) MaxParams() int {
+	if d == SQLite {
+		return 999
+	}
+	return 65535
+}
+
+// JSONType is the column type used for JSON payloads.
+func (d Dialect) JSONType() string {
+	if d == SQLite {
+		return "TEXT"
+	}
+	return "JSONB"
+}
@@ -0,0 +1,192 @@
+// Package sqltest is a database/sql driver for testing code that writes its own SQL.
+// It doesn't understand SQL: statements are recorded as they are executed,
+// and queries are answered by a function supplied by the test.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Statement is a statement executed by the database.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// Rows is the result of a query.
+type Rows struct {
+	Columns []string
+	Values  [][]any
+}
+
+// DB is a fake database.
+// Statements executed inside a transaction are only recorded when it's committed.
+type DB struct {
+	// Exec is called for every statement as it's executed, when set.
+	// An error fails the statement.
+	Exec func(query string, args []any) error
+	// Query answers queries. A nil Query answers every query with no rows.
+	Query func(query string, args []any) (Rows, error)
+
+	mu         sync.Mutex
+	statements []Statement
+	commits    int
+	rollbacks  int
+}
+
+// Open returns a *sql.DB that uses db.
+func (db *DB) Open() *sql.DB {
+	return sql.OpenDB(connector{db})
+}
+
+// Statements returns the statements recorded so far, in order.
+func (db *DB) Statements() []Statement {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]Statement(nil), db.statements...)
+}
+
+// Reset forgets the recorded statements.
+func (db *DB) Reset() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.statements = nil
+}
+
+// Transactions returns the number of transactions committed and rolled back.
+func (db *DB) Transactions() (commits, rollbacks int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.commits, db.rollbacks
+}
+
+type connector struct{ db *DB }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{db: c.db}, nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("sqltest: use DB.Open")
+}
+
+type conn struct {
+	db *DB
+	tx []Statement // tx holds the statements of the open transaction
+	// inTx is set while a transaction is open
+	inTx bool
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return stmt{c, query}, nil }
+func (c *conn) Close() error                              { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	if c.inTx {
+		return nil, errors.New("sqltest: transaction already open")
+	}
+	c.inTx = true
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.statements = append(c.db.statements, c.tx...)
+	c.db.commits++
+	c.tx, c.inTx = nil, false
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.rollbacks++
+	c.tx, c.inTx = nil, false
+	return nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	args := values(named)
+	if c.db.Exec != nil {
+		if err := c.db.Exec(query, args); err != nil {
+			return nil, err
+		}
+	}
+	s := Statement{Query: query, Args: args}
+	if c.inTx {
+		c.tx = append(c.tx, s)
+	} else {
+		c.db.mu.Lock()
+		c.db.statements = append(c.db.statements, s)
+		c.db.mu.Unlock()
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	var r Rows
+	if c.db.Query != nil {
+		var err error
+		if r, err = c.db.Query(query, values(named)); err != nil {
+			return nil, err
+		}
+	}
+	return &rows{Rows: r}, nil
+}
+
+func values(named []driver.NamedValue) []any {
+	args := make([]any, len(named))
+	for i, v := range named {
+		args[i] = v.Value
+	}
+	return args
+}
+
+type stmt struct {
+	c     *conn
+	query string
+}
+
+func (s stmt) Close() error  { return nil }
+func (s stmt) NumInput() int { return -1 }
+
+func (s stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	n := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		n[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return n
+}
+
+type rows struct {
+	Rows
+	next int
+}
+
+func (r *rows) Columns() []string { return r.Rows.Columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.Values) {
+		return io.EOF
+	}
+	for i, v := range r.Values[r.next] {
+		dest[i] = v
+	}
+	r.next++
+	return nil
+}
@@ -0,0 +1,86 @@
+package ps2
+
+import (
+	"cmp"
+	"slices"
+)
+
+// The lookup tables are generated from the census map_region collection with:
+//
+//	go run ./cmd/staticdata -lookups > lookups_gen.go
+//
+// They cover the regions of every continent with territory,
+// so events that only carry a zone or facility can be placed without a census request.
+// Facilities added by a game update are missing until the tables are regenerated.
+
+// regionEntry is a row of the region lookup table, which is sorted by RegionID.
+type regionEntry struct {
+	RegionID    RegionID
+	FacilityID  FacilityID // FacilityID is 0 for regions without a facility
+	ContinentID ContinentID
+}
+
+// facilityEntry is a row of the facility lookup table, which is sorted by FacilityID.
+type facilityEntry struct {
+	FacilityID     FacilityID
+	RegionID       RegionID
+	FacilityTypeID FacilityTypeID
+}
+
+func lookupRegion(r RegionID) (regionEntry, bool) {
+	i, found := slices.BinarySearchFunc(regionTable[:], r, func(e regionEntry, r RegionID) int {
+		return cmp.Compare(e.RegionID, r)
+	})
+	if !found {
+		return regionEntry{}, false
+	}
+	return regionTable[i], true
+}
+
+// RegionContinent returns the continent of region,
+// or false if region isn't in the lookup tables.
+func RegionContinent(region RegionID) (ContinentID, bool) {
+	e, ok := lookupRegion(region)
+	return e.ContinentID, ok
+}
+
+// RegionFacility returns the facility located in region,
+// or false if region isn't in the lookup tables or has no facility.
+func RegionFacility(region RegionID) (FacilityID, bool) {
+	e, ok := lookupRegion(region)
+	return e.FacilityID, ok && e.FacilityID != 0
+}
+
+func lookupFacility(f FacilityID) (facilityEntry, bool) {
+	i, found := slices.BinarySearchFunc(facilityTable[:], f, func(e facilityEntry, f FacilityID) int {
+		return cmp.Compare(e.FacilityID, f)
+	})
+	if !found {
+		return facilityEntry{}, false
+	}
+	return facilityTable[i], true
+}
+
+// FacilityRegion returns the region that facility is located in,
+// or false if facility isn't in the lookup tables.
+func FacilityRegion(facility FacilityID) (RegionID, bool) {
+	e, ok := lookupFacility(facility)
+	return e.RegionID, ok
+}
+
+// FacilityType returns the type of facility, such as a small outpost or tech plant,
+// or false if facility isn't in the lookup tables.
+func FacilityType(facility FacilityID) (FacilityTypeID, bool) {
+	e, ok := lookupFacility(facility)
+	return e.FacilityTypeID, ok
+}
+
+// FacilityContinent returns the continent of facility,
+// or false if facility isn't in the lookup tables.
+func FacilityContinent(facility FacilityID) (ContinentID, bool) {
+	region, ok := FacilityRegion(facility)
+	if !ok {
+		return 0, false
+	}
+	return RegionContinent(region)
+}
@@ -0,0 +1,117 @@
+package ps2alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// defaultHistoryPageSize is the page size of history queries that don't set one.
+const defaultHistoryPageSize = 100
+
+// HistoryOptions filters the alerts returned by [GetHistory].
+//
+// Zero values don't filter.
+type HistoryOptions struct {
+	World   ps2.WorldID
+	Zone    ps2.ContinentID
+	Bracket Bracket
+
+	// From and To limit results to alerts that started in the range.
+	From time.Time
+	To   time.Time
+
+	// PageSize is the number of alerts per page.
+	// The default is 100.
+	PageSize int
+
+	// Page is the page to return, starting from 0.
+	Page int
+}
+
+// Next returns the options for the page after o.
+func (o HistoryOptions) Next() HistoryOptions {
+	o.Page++
+	return o
+}
+
+func (o HistoryOptions) query() url.Values {
+	q := url.Values{}
+	if o.World != 0 {
+		q.Set("world", o.World.StringID())
+	}
+	if o.Zone != 0 {
+		q.Set("zone", strconv.Itoa(int(o.Zone)))
+	}
+	if o.Bracket != 0 {
+		q.Set("bracket", strconv.Itoa(int(o.Bracket)))
+	}
+	if !o.From.IsZero() {
+		q.Set("timeStartedFrom", o.From.UTC().Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		q.Set("timeStartedTo", o.To.UTC().Format(time.RFC3339))
+	}
+	pageSize := o.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	q.Set("pageSize", strconv.Itoa(pageSize))
+	q.Set("page", strconv.Itoa(max(o.Page, 0)))
+	q.Set("sortBy", "timeStarted")
+	q.Set("order", "desc")
+	return q
+}
+
+// GetHistory returns one page of alerts matching o from the /instances endpoint,
+// newest first.
+// Pages are fetched by calling it again with [HistoryOptions.Next]
+// until it returns fewer alerts than the page size:
+//
+//	o := ps2alerts.HistoryOptions{World: ps2.Emerald, From: time.Now().AddDate(0, 0, -7), PageSize: 100}
+//	for {
+//		alerts, err := ps2alerts.GetHistory(ctx, o)
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//		if len(alerts) < o.PageSize {
+//			break
+//		}
+//		o = o.Next()
+//	}
+func GetHistory(ctx context.Context, o HistoryOptions) ([]Alert, error) {
+	u := "https://api.ps2alerts.com/instances?" + o.query().Encode()
+	slog.Info("ps2alerts query", "url", u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ps2alerts.GetHistory: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ps2alerts.GetHistory: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ps2alerts.GetHistory: returned http %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ps2alerts.GetHistory: %w", err)
+	}
+	var alerts []Alert
+	if err := json.Unmarshal(body, &alerts); err != nil {
+		return nil, fmt.Errorf("ps2alerts.GetHistory: %w", err)
+	}
+	return alerts, nil
+}
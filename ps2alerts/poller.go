@@ -0,0 +1,163 @@
+package ps2alerts
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/internal/poll"
+)
+
+// PollOptions controls how often a [Poller] requests active alerts.
+//
+// Zero values use the defaults.
+type PollOptions struct {
+	// Interval is the delay between successful polls.
+	// The default is 1 minute.
+	// A negative interval polls only until the first success.
+	Interval time.Duration
+
+	// Jitter is the maximum random delay added to every poll.
+	// The default is one fifth of Interval.
+	// A negative jitter disables it.
+	Jitter time.Duration
+
+	// MaxBackoff is the longest delay between retries while polls are failing.
+	// Retries start at 10 seconds and double after each failure.
+	// The default is 10 minutes.
+	MaxBackoff time.Duration
+}
+
+const (
+	defaultPollInterval = time.Minute
+	defaultPollBackoff  = 10 * time.Minute
+	minPollBackoff      = 10 * time.Second
+)
+
+// schedule returns the polling loop timing of o with the defaults filled in.
+func (o PollOptions) schedule() poll.Options {
+	return poll.Options{
+		Interval:   o.Interval,
+		Jitter:     o.Jitter,
+		MaxBackoff: o.MaxBackoff,
+		MinBackoff: minPollBackoff,
+	}.WithDefaults(defaultPollInterval, defaultPollBackoff)
+}
+
+// Poller requests the active alerts from ps2alerts until its context is cancelled,
+// so that alert scores can be followed while alerts are running.
+//
+//	p := ps2alerts.NewPoller(ps2alerts.PollOptions{Interval: 30 * time.Second})
+//	p.OnAlert(func(a ps2alerts.Alert) { fmt.Println(a.InstanceID, a.Result) })
+//	go p.Run(ctx)
+//
+// Every active alert is delivered after every successful poll.
+// When an alert is no longer active it's requested once more,
+// so that the final result is delivered as well;
+// that request is repeated in the next few polls if it fails.
+//
+// Handlers and channels must be added before [Poller.Run].
+type Poller struct {
+	options  poll.Options
+	handlers []func(Alert)
+	channels []chan Alert
+
+	// active holds the alerts that were active in the last poll,
+	// and the alerts whose final state is still being requested with the number of failed requests
+	active map[ps2.MetagameEventInstanceID]int
+}
+
+// NewPoller creates a Poller.
+func NewPoller(o PollOptions) *Poller {
+	return &Poller{
+		options: o.schedule(),
+		active:  make(map[ps2.MetagameEventInstanceID]int),
+	}
+}
+
+// OnAlert registers f to be called with every alert received.
+// Handlers are called on the goroutine running [Poller.Run],
+// so polling waits for them to return.
+func (p *Poller) OnAlert(f func(Alert)) {
+	p.handlers = append(p.handlers, f)
+}
+
+// Chan returns a channel that receives every alert.
+// Polling waits for each alert to be received,
+// and the channel is closed when [Poller.Run] returns.
+func (p *Poller) Chan(buffer int) <-chan Alert {
+	ch := make(chan Alert, max(buffer, 0))
+	p.channels = append(p.channels, ch)
+	return ch
+}
+
+// Run polls ps2alerts until ctx is cancelled.
+// Failed polls are retried with exponential backoff.
+func (p *Poller) Run(ctx context.Context) {
+	defer func() {
+		for _, ch := range p.channels {
+			close(ch)
+		}
+	}()
+	poll.Run(ctx, p.options, p.poll, func(err error, retry time.Duration) {
+		slog.Warn("ps2alerts poll failed", "error", err, "retry", retry)
+	})
+}
+
+// maxFinalRetries is how many polls in a row may fail to get the final state of an alert before it's given up on.
+const maxFinalRetries = 5
+
+// poll requests the active alerts and delivers them,
+// followed by the final state of alerts that are no longer active.
+// Alerts whose final state couldn't be requested are tried again in the next poll.
+func (p *Poller) poll(ctx context.Context) error {
+	alerts, err := GetActiveContext(ctx)
+	if err != nil {
+		return err
+	}
+	active := make(map[ps2.MetagameEventInstanceID]int, len(alerts))
+	for _, a := range alerts {
+		active[a.InstanceID] = 0
+		if !p.deliver(ctx, a) {
+			return ctx.Err()
+		}
+	}
+	for id, failures := range p.active {
+		if _, ok := active[id]; ok {
+			continue
+		}
+		a, err := GetInstanceContext(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("failed getting ended ps2alerts instance", "instance", id, "error", err, "failures", failures+1)
+			if failures+1 < maxFinalRetries {
+				active[id] = failures + 1
+			}
+			continue
+		}
+		if !p.deliver(ctx, a) {
+			return ctx.Err()
+		}
+	}
+	p.active = active
+	return nil
+}
+
+// deliver sends a to every handler and channel.
+// It returns false if ctx was cancelled while waiting on a channel.
+func (p *Poller) deliver(ctx context.Context, a Alert) bool {
+	for _, h := range p.handlers {
+		h(a)
+	}
+	for _, ch := range p.channels {
+		select {
+		case ch <- a:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package psmap
+
+import (
+	"math"
+)
+
+// coord is a plain x,y coordinate implementing [Point].
+type coord struct {
+	x, y float64
+}
+
+func (c coord) Point() (float64, float64) { return c.x, c.y }
+
+// hexCenter returns the center of a hex tile using the same coordinates as [Outline].
+func hexCenter(h Hex, size float64) coord {
+	width := math.Sqrt(3) * size
+	height := 2 * size
+	return coord{
+		x: width * (float64(h.X) + float64(h.Y)*0.5),
+		y: float64(-1*h.Y)*height*0.75 - height/2,
+	}
+}
+
+// ComputeCentroid returns the center of mass of a region made of hexes.
+// width is the hex width as returned by census, the same as for [Outline].
+// The result uses the same coordinates as [Outline] and [Region.Point].
+//
+// The centroid of an oddly shaped region (like a bridge or a crescent) may fall outside of the region.
+// Use [LabelAnchor] to find a point that is always inside.
+func ComputeCentroid(hexes []Hex, width int) Point {
+	if len(hexes) == 0 {
+		return coord{}
+	}
+	size := widthToSize(width)
+	var sumX, sumY float64
+	for _, h := range hexes {
+		c := hexCenter(h, size)
+		sumX += c.x
+		sumY += c.y
+	}
+	// every hex has the same area, so the average of the centers is the centroid
+	n := float64(len(hexes))
+	return coord{sumX / n, sumY / n}
+}
+
+// LabelAnchor returns a good point for placing a label inside region.
+// It is the center of the hex furthest from the region's edge (the pole of inaccessibility),
+// with ties going to the hex nearest the centroid.
+// Unlike the centroid, the anchor is always inside the region.
+//
+// width is the hex width as returned by census.
+func LabelAnchor(region Region, width int) Point {
+	if len(region.Hexes) == 0 {
+		return coord{}
+	}
+	type tile struct{ X, Y int }
+	neighbors := [6]tile{{-1, 0}, {1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}}
+
+	inRegion := make(map[tile]bool, len(region.Hexes))
+	for _, h := range region.Hexes {
+		inRegion[tile{h.X, h.Y}] = true
+	}
+
+	// breadth-first search inward from the edge tiles,
+	// so that distance is the number of steps to leave the region
+	distance := make(map[tile]int, len(inRegion))
+	queue := make([]tile, 0, len(inRegion))
+	for t := range inRegion {
+		for _, n := range neighbors {
+			if !inRegion[tile{t.X + n.X, t.Y + n.Y}] {
+				distance[t] = 1
+				queue = append(queue, t)
+				break
+			}
+		}
+	}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, n := range neighbors {
+			next := tile{t.X + n.X, t.Y + n.Y}
+			if !inRegion[next] {
+				continue
+			}
+			if _, seen := distance[next]; seen {
+				continue
+			}
+			distance[next] = distance[t] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	size := widthToSize(width)
+	cx, cy := ComputeCentroid(region.Hexes, width).Point()
+	var best coord
+	bestDistance := -1
+	bestOffset := math.Inf(1)
+	for _, h := range region.Hexes {
+		d := distance[tile{h.X, h.Y}]
+		c := hexCenter(h, size)
+		offset := math.Hypot(c.x-cx, c.y-cy)
+		if d > bestDistance || (d == bestDistance && offset < bestOffset) {
+			best, bestDistance, bestOffset = c, d, offset
+		}
+	}
+	return best
+}
+
+// Anchor returns the facility coordinates of r,
+// or the [LabelAnchor] of r when census is missing facility coordinates.
+// width is the hex width as returned by census.
+func (r Region) Anchor(width int) Point {
+	if r.FacilityX != 0 || r.FacilityY != 0 {
+		return coord{r.FacilityX, r.FacilityY}
+	}
+	return LabelAnchor(r, width)
+}
@@ -0,0 +1,52 @@
+package psmap_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestLabelAnchor(t *testing.T) {
+	const width = 50
+	center := func(h psmap.Hex) (float64, float64) {
+		return psmap.ComputeCentroid([]psmap.Hex{h}, width).Point()
+	}
+	near := func(ax, ay, bx, by float64) bool {
+		return math.Abs(ax-bx) < 0.001 && math.Abs(ay-by) < 0.001
+	}
+
+	tt := map[string]struct {
+		Hexes []psmap.Hex
+		Want  psmap.Hex
+	}{
+		"bridge": {
+			Hexes: []psmap.Hex{{X: 0}, {X: 1}, {X: 2}, {X: 3}, {X: 4}},
+			Want:  psmap.Hex{X: 2},
+		},
+		"blob": {
+			// a hex surrounded by its six neighbors, plus a tail to pull the centroid off center
+			Hexes: []psmap.Hex{{X: 0, Y: 0}, {X: -1, Y: 0}, {X: 1, Y: 0}, {X: -1, Y: 1}, {X: 0, Y: -1}, {X: 0, Y: 1}, {X: 1, Y: -1}, {X: 2, Y: 0}, {X: 3, Y: 0}},
+			Want:  psmap.Hex{X: 0, Y: 0},
+		},
+		"crescent": {
+			// the centroid of a C shape falls in the gap
+			Hexes: []psmap.Hex{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: -1, Y: 1}, {X: -1, Y: 2}, {X: -1, Y: 3}, {X: -1, Y: 4}, {X: 0, Y: 4}, {X: 1, Y: 4}},
+			Want:  psmap.Hex{X: -1, Y: 2},
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			gotX, gotY := psmap.LabelAnchor(psmap.Region{Hexes: tc.Hexes}, width).Point()
+			wantX, wantY := center(tc.Want)
+			if !near(gotX, gotY, wantX, wantY) {
+				t.Errorf("expected anchor at hex %v (%.1f,%.1f); got (%.1f,%.1f)", tc.Want, wantX, wantY, gotX, gotY)
+			}
+		})
+	}
+
+	r := psmap.Region{FacilityX: 10, FacilityY: -20, Hexes: []psmap.Hex{{X: 0}}}
+	if x, y := r.Anchor(width).Point(); x != 10 || y != -20 {
+		t.Errorf("expected facility coordinates to be used as the anchor; got (%v,%v)", x, y)
+	}
+}
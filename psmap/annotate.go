@@ -0,0 +1,221 @@
+package psmap
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+// Annotations are the objectives of alerts that aren't decided by territory,
+// drawn on top of a map by [DrawAnnotations].
+type Annotations struct {
+	// Anomalies are the objectives of an Aerial Anomalies alert.
+	Anomalies []Anomaly
+
+	// KillScore is the score of a Sudden Death alert.
+	// A nil score draws no banner.
+	KillScore KillScore
+
+	// ShadeDisabled shades regions owned by faction 0,
+	// which is how the haunted bastion event disables regions.
+	ShadeDisabled bool
+
+	// Warpgates draws an arrow at each warpgate in the color of its owner.
+	Warpgates bool
+
+	// Alert is drawn as a status panel across the bottom of the map.
+	// A nil Alert draws no panel.
+	// If the alert has no Territory, it's filled in from mapstate.
+	Alert *AlertStatus
+}
+
+// Anomaly is an Aerial Anomalies objective.
+type Anomaly struct {
+	Loc Loc
+
+	// FactionID is the faction currently holding the anomaly, or ps2.None.
+	FactionID ps2.FactionID
+}
+
+// KillScore is the number of kills for each faction during a Sudden Death alert.
+type KillScore map[ps2.FactionID]int
+
+// DisabledColor is the fill color for regions shaded by [DrawDisabled].
+var DisabledColor = color.RGBA{0x10, 0x10, 0x10, 0xa0}
+
+// DrawAnnotations draws a onto img.
+// It is meant to be called after [Draw] with the same img, data, and mapstate.
+// The same image requirements as Draw apply.
+func DrawAnnotations(img draw.Image, data Map, mapstate owner, a Annotations) error {
+	var summary Summary
+	if a.ShadeDisabled || (a.Alert != nil && a.Alert.Territory == nil) {
+		var err error
+		summary, err = Summarize(data, mapstate)
+		if err != nil {
+			return fmt.Errorf("psmap.DrawAnnotations: summary failed: %w", err)
+		}
+	}
+	if a.ShadeDisabled {
+		if err := DrawDisabled(img, data, summary); err != nil {
+			return err
+		}
+	}
+	if a.Warpgates {
+		if err := DrawWarpgates(img, data, mapstate); err != nil {
+			return err
+		}
+	}
+	if len(a.Anomalies) > 0 {
+		if err := DrawAnomalies(img, data, a.Anomalies); err != nil {
+			return err
+		}
+	}
+	if a.KillScore != nil {
+		if err := DrawKillScore(img, a.KillScore); err != nil {
+			return err
+		}
+	}
+	if a.Alert != nil {
+		status := *a.Alert
+		if status.Territory == nil {
+			status.Territory = summary.Territory
+		}
+		if err := DrawAlertStatus(img, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrawDisabled shades the regions listed in summary.Disabled with [DisabledColor].
+func DrawDisabled(img draw.Image, data Map, summary Summary) error {
+	if err := checkCanvas(img); err != nil {
+		return fmt.Errorf("psmap.DrawDisabled: %w", err)
+	}
+	transform, scale := canvasTransform(img, data)
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.SetStrokeColor(color.White)
+	gc.SetLineWidth(4 * scale)
+	gc.SetFillColor(DisabledColor)
+	for _, region := range data.Regions {
+		if !summary.Disabled[region.RegionID] {
+			continue
+		}
+		gc.BeginPath()
+		for i, point := range Outline(region.Hexes, data.HexSize) {
+			if i == 0 {
+				gc.MoveTo(transform(point))
+			} else {
+				gc.LineTo(transform(point))
+			}
+		}
+		gc.Close()
+		gc.FillStroke()
+	}
+	return nil
+}
+
+// DrawAnomalies draws a marker at each anomaly,
+// filled with the color of the faction holding it.
+// Markers are about the size of a facility region so that they stay visible on small thumbnails.
+func DrawAnomalies(img draw.Image, data Map, anomalies []Anomaly) error {
+	if err := checkCanvas(img); err != nil {
+		return fmt.Errorf("psmap.DrawAnomalies: %w", err)
+	}
+	transform, scale := canvasTransform(img, data)
+	radius := max(float64(2*data.HexSize)*scale, 3)
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.SetStrokeColor(color.White)
+	gc.SetLineWidth(max(6*scale, 1))
+	for _, a := range anomalies {
+		fc := color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+		if a.FactionID != ps2.None && int(a.FactionID) < len(FactionDrawColors) {
+			fc = FactionDrawColors[a.FactionID]
+		}
+		gc.SetFillColor(withOpacity(fc, 0.8))
+		x, y := transform(a.Loc)
+		gc.BeginPath()
+		draw2dkit.Circle(gc, x, y, radius)
+		gc.FillStroke()
+	}
+	return nil
+}
+
+// DrawKillScore draws a banner across the top of img,
+// split between factions by their share of the kills.
+// Nothing is drawn until a faction has scored.
+func DrawKillScore(img draw.Image, score KillScore) error {
+	if err := checkCanvas(img); err != nil {
+		return fmt.Errorf("psmap.DrawKillScore: %w", err)
+	}
+	total := 0
+	for _, kills := range score {
+		total += max(kills, 0)
+	}
+	if total == 0 {
+		return nil
+	}
+	b := img.Bounds()
+	height := max(b.Dy()/24, 4)
+	x := float64(b.Min.X)
+	for _, faction := range []ps2.FactionID{ps2.VS, ps2.NC, ps2.TR, ps2.NSO} {
+		kills := max(score[faction], 0)
+		if kills == 0 {
+			continue
+		}
+		width := float64(b.Dx()) * float64(kills) / float64(total)
+		segment := image.Rect(int(math.Round(x)), b.Min.Y, int(math.Round(x+width)), b.Min.Y+height)
+		draw.Draw(img, segment, image.NewUniform(FactionDrawColors[faction]), image.Point{}, draw.Over)
+		x += width
+	}
+	return nil
+}
+
+// checkCanvas returns an error for images that the draw functions can't handle.
+func checkCanvas(img draw.Image) error {
+	if img.Bounds().Dx() != img.Bounds().Dy() {
+		return fmt.Errorf("image bounds must be square; given: %v", img.Bounds())
+	}
+	if img.Bounds().Empty() {
+		return errors.New("image cannot be empty")
+	}
+	if (img.Bounds().Min != image.Point{}) {
+		// The draw2dimg package behaves in unexpected ways when img does not start at 0,0.
+		return errors.New("image bounds must start at 0,0")
+	}
+	return nil
+}
+
+// canvasTransform returns a function that converts census coordinates to img coordinates,
+// along with the ratio of the image size to the full continent size.
+func canvasTransform(img draw.Image, data Map) (transform func(Point) (float64, float64), scale float64) {
+	scale = float64(img.Bounds().Dx()) / float64(data.Size)
+	transform = func(p Point) (x float64, y float64) {
+		x, y = p.Point()
+		x += float64(data.Size / 2)
+		y += float64(data.Size / 2)
+		return x * scale, y * scale
+	}
+	return transform, scale
+}
+
+// withOpacity returns c with the alpha set to opacity (0-1) and the color channels premultiplied to match.
+func withOpacity(c color.RGBA, opacity float64) color.RGBA {
+	if c.A == 0 {
+		return c
+	}
+	a := uint8(255 * opacity)
+	return color.RGBA{
+		R: uint8(uint16(c.R) * uint16(a) / uint16(c.A)),
+		G: uint8(uint16(c.G) * uint16(a) / uint16(c.A)),
+		B: uint8(uint16(c.B) * uint16(a) / uint16(c.A)),
+		A: a,
+	}
+}
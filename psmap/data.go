@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"slices"
-	"strings"
-	"time"
 
 	"github.com/Travis-Britz/ps2"
 	"github.com/Travis-Britz/ps2/census"
@@ -17,7 +15,7 @@ import (
 // Currently only one problematic region exists,
 // but this way users can add new regions (if planetside ever gets any) without waiting for a package update from me.
 var IgnoredRegions = []ps2.RegionID{
-	18347, // Oshur Vast Expanse - this region is a line of hex tiles that circles the entire map and has no gameplay relevance. This is the only region in the game with empty tiles in the center, which also breaks my method for outlining regions.
+	ps2.OshurVastExpanse, // this region is a line of hex tiles that circles the entire map and has no gameplay relevance. This is the only region in the game with empty tiles in the center, which also breaks my method for outlining regions.
 }
 
 // LoadData loads map zones, regions, facilities, and hexes from census.
@@ -29,64 +27,69 @@ var IgnoredRegions = []ps2.RegionID{
 
 // todo: add context
 func GetAllMapData(ctx context.Context, env ps2.Environment) (data []Map, err error) {
-	res := censusMapResult{}
-	err = census.GetEnv(
+	// the joined hexes of every zone make this one of the largest census responses,
+	// so zones are converted as they're decoded instead of holding the whole response
+	_, err = census.Stream(
 		ctx,
+		nil,
 		env,
 		"zone?c:join=map_region^list:1^inject_at:regions^hide:zone_id(map_hex^list:1^inject_at:hexes^hide:zone_id'map_region_id)"+
 			"&c:join=facility_link^list:1^inject_at:links^hide:zone_id'description"+
 			"&c:lang=en"+
 			"&c:limit=5000",
-		&res,
+		func(zone MapResult) error {
+			data = append(data, mapFromResult(zone))
+			return nil
+		},
 	)
 	if err != nil {
-		return data, err
+		return nil, err
 	}
-	for _, zone := range res.ZoneList {
-		zoneData := Map{
-			ZoneID:  zone.ZoneID,
-			HexSize: zone.HexSize,
+	return data, nil
+}
+
+// mapFromResult converts a zone from census with its joined regions, hexes, and links.
+func mapFromResult(zone MapResult) Map {
+	zoneData := Map{
+		ZoneID:  zone.ZoneID,
+		HexSize: zone.HexSize,
+	}
+	if cont, err := zone.ZoneID.ContinentID(); err == nil {
+		if size, err := Size(cont); err == nil {
+			zoneData.Size = size
 		}
-		if cont, err := zone.ZoneID.ContinentID(); err == nil {
-			if size, err := Size(cont); err == nil {
-				zoneData.Size = size
-			}
+	}
+	for _, region := range zone.MapRegions {
+		if slices.Contains(IgnoredRegions, region.MapRegionID) {
+			continue
 		}
-		for _, region := range zone.MapRegions {
-			if slices.Contains(IgnoredRegions, region.MapRegionID) {
-				continue
-			}
-			mapregion := Region{
-				RegionID:       region.MapRegionID,
-				Name:           region.Name,
-				FacilityID:     region.FacilityID,
-				FacilityTypeID: region.Type,
-				FacilityX:      region.LocationZ,
-				FacilityY:      region.LocationX,
-			}
-
-			hexes := make([]Hex, 0, len(region.Hexes))
-			for _, h := range region.Hexes {
-				hexes = append(hexes, Hex{
-					X:    h.X,
-					Y:    h.Y,
-					Type: h.HexType,
-				})
-			}
-			mapregion.Hexes = hexes
-			zoneData.Regions = append(zoneData.Regions, mapregion)
+		mapregion := Region{
+			RegionID:       region.MapRegionID,
+			Name:           region.Name,
+			FacilityID:     region.FacilityID,
+			FacilityTypeID: region.Type,
+			FacilityX:      region.LocationZ,
+			FacilityY:      region.LocationX,
 		}
-		for _, link := range zone.FacilityLinks {
-			zoneData.Links = append(zoneData.Links, Link{
-				A: link.FacilityIDA,
-				B: link.FacilityIDB,
+
+		hexes := make([]Hex, 0, len(region.Hexes))
+		for _, h := range region.Hexes {
+			hexes = append(hexes, Hex{
+				X:    h.X,
+				Y:    h.Y,
+				Type: h.HexType,
 			})
 		}
-
-		data = append(data, zoneData)
+		mapregion.Hexes = hexes
+		zoneData.Regions = append(zoneData.Regions, mapregion)
 	}
-
-	return data, nil
+	for _, link := range zone.FacilityLinks {
+		zoneData.Links = append(zoneData.Links, Link{
+			A: link.FacilityIDA,
+			B: link.FacilityIDB,
+		})
+	}
+	return zoneData
 }
 
 func GetMapData(cont ps2.ContinentID) (data Map, err error) {
@@ -138,9 +141,7 @@ func getMapData(zoneid ps2.ZoneID) (data Map, err error) {
 	data.HexSize = zone.HexSize
 	for _, region := range zone.MapRegions {
 
-		// skip oshur vast expanse
-		// todo: define a constant or variable of blacklisted regions
-		if region.MapRegionID == 18347 {
+		if slices.Contains(IgnoredRegions, region.MapRegionID) {
 			continue
 		}
 		mapregion := Region{
@@ -174,42 +175,24 @@ func getMapData(zoneid ps2.ZoneID) (data Map, err error) {
 }
 
 func getMapState(ctx context.Context, world ps2.WorldID, zone ...ps2.ZoneInstanceID) (state []State, err error) {
-
-	zoneids := []string{}
-	for _, z := range zone {
-		zoneids = append(zoneids, z.StringID())
-	}
-	query := "map?world_id=" + world.StringID() + "&zone_ids=" + strings.Join(zoneids, ",")
-	var response struct {
-		MapList []struct {
-			ZoneID  ps2.ZoneInstanceID `json:"ZoneId,string"`
-			Regions struct {
-				IsList stringNumericBool `json:"IsList"`
-				Row    []struct {
-					RowData struct {
-						RegionID  ps2.RegionID  `json:"RegionId,string"`
-						FactionID ps2.FactionID `json:"FactionId,string"`
-					} `json:"RowData"`
-				} `json:"Row"`
-			} `json:"Regions"`
-		} `json:"map_list"`
-		Returned int `json:"returned"`
-	}
-	if err = census.GetEnv(ctx, ps2.GetEnvironment(world), query, &response); err != nil {
+	// census.GetMap shares its cache with other callers in the process, like the state manager
+	zm, err := census.GetMap(ctx, nil, world, zone...)
+	if err != nil {
 		return nil, fmt.Errorf("psmap: get state: %w", err)
 	}
-	if len(response.MapList) < 1 {
+	if len(zm) < 1 {
 		return nil, fmt.Errorf("no results")
 	}
 
-	for _, zonestate := range response.MapList {
+	for _, zonestate := range zm {
 		zone := State{
-			ZoneID:    zonestate.ZoneID,
+			ZoneID:    zonestate.ZoneInstanceID,
+			WorldID:   world,
 			Territory: map[ps2.RegionID]ps2.FactionID{},
-			Timestamp: time.Now().UTC(),
+			Timestamp: zonestate.Timestamp.UTC(),
 		}
-		for _, rd := range zonestate.Regions.Row {
-			zone.Territory[rd.RowData.RegionID] = rd.RowData.FactionID
+		for _, rd := range zonestate.Regions {
+			zone.Territory[rd.RegionID] = rd.FactionID
 		}
 		state = append(state, zone)
 	}
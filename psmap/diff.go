@@ -0,0 +1,80 @@
+package psmap
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// RegionChange is a region that changed owner between two states of a zone.
+type RegionChange struct {
+	RegionID ps2.RegionID `json:"region_id"`
+
+	// FacilityID is 0 for regions without a facility,
+	// and for changes from [Diff], which doesn't have the map data to look it up.
+	FacilityID ps2.FacilityID `json:"facility_id,omitempty"`
+
+	OldFaction ps2.FactionID `json:"old_faction_id"`
+	NewFaction ps2.FactionID `json:"new_faction_id"`
+}
+
+// Diff returns the regions whose owner differs between old and new,
+// sorted by region ID.
+// A region missing from one of the states is treated as owned by faction 0.
+//
+// Diffing periodic map polls gives a capture feed for applications that don't use the event stream,
+// although a region that flipped and flipped back between polls won't appear.
+// Use [DiffMap] to include facility IDs.
+func Diff(old, new State) ([]RegionChange, error) {
+	if err := checkSameZone(old, new); err != nil {
+		return nil, fmt.Errorf("psmap.Diff: %w", err)
+	}
+	var changes []RegionChange
+	for region, faction := range new.Territory {
+		if previous := old.Owner(region); previous != faction {
+			changes = append(changes, RegionChange{RegionID: region, OldFaction: previous, NewFaction: faction})
+		}
+	}
+	for region, faction := range old.Territory {
+		if _, found := new.Territory[region]; !found && faction != none {
+			changes = append(changes, RegionChange{RegionID: region, OldFaction: faction})
+		}
+	}
+	slices.SortFunc(changes, func(a, b RegionChange) int { return cmp.Compare(a.RegionID, b.RegionID) })
+	return changes, nil
+}
+
+// DiffMap is the same as [Diff],
+// but only includes the regions of data and fills in their facility IDs.
+func DiffMap(data Map, old, new State) ([]RegionChange, error) {
+	if err := checkSameZone(old, new); err != nil {
+		return nil, fmt.Errorf("psmap.DiffMap: %w", err)
+	}
+	var changes []RegionChange
+	for _, r := range data.Regions {
+		if previous, current := old.Owner(r.RegionID), new.Owner(r.RegionID); previous != current {
+			changes = append(changes, RegionChange{
+				RegionID:   r.RegionID,
+				FacilityID: r.FacilityID,
+				OldFaction: previous,
+				NewFaction: current,
+			})
+		}
+	}
+	slices.SortFunc(changes, func(a, b RegionChange) int { return cmp.Compare(a.RegionID, b.RegionID) })
+	return changes, nil
+}
+
+// checkSameZone returns an error if old and new aren't states of the same zone.
+// A WorldID of 0 matches any world.
+func checkSameZone(old, new State) error {
+	if old.ZoneID != new.ZoneID {
+		return fmt.Errorf("states are for different zones: %d and %d", old.ZoneID, new.ZoneID)
+	}
+	if old.WorldID != 0 && new.WorldID != 0 && old.WorldID != new.WorldID {
+		return fmt.Errorf("states are for different worlds: %d and %d", old.WorldID, new.WorldID)
+	}
+	return nil
+}
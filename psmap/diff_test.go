@@ -0,0 +1,67 @@
+package psmap_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestDiff(t *testing.T) {
+	old := psmap.State{
+		ZoneID:  ps2.ZoneInstanceID(ps2.Indar),
+		WorldID: ps2.Emerald,
+		Territory: map[ps2.RegionID]ps2.FactionID{
+			1: ps2.VS,
+			2: ps2.NC,
+			3: ps2.TR,
+			4: ps2.TR,
+		},
+	}
+	new := psmap.State{
+		ZoneID:  ps2.ZoneInstanceID(ps2.Indar),
+		WorldID: ps2.Emerald,
+		Territory: map[ps2.RegionID]ps2.FactionID{
+			1: ps2.VS,
+			2: ps2.TR,
+			3: ps2.TR,
+			5: ps2.NC,
+		},
+	}
+
+	got, err := psmap.Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []psmap.RegionChange{
+		{RegionID: 2, OldFaction: ps2.NC, NewFaction: ps2.TR},
+		{RegionID: 4, OldFaction: ps2.TR, NewFaction: ps2.None},
+		{RegionID: 5, OldFaction: ps2.None, NewFaction: ps2.NC},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Diff:\ngot  %v\nwant %v", got, want)
+	}
+
+	data := psmap.Map{Regions: []psmap.Region{
+		{RegionID: 1, FacilityID: 100},
+		{RegionID: 2, FacilityID: 200},
+		{RegionID: 5},
+	}}
+	got, err = psmap.DiffMap(data, old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []psmap.RegionChange{
+		{RegionID: 2, FacilityID: 200, OldFaction: ps2.NC, NewFaction: ps2.TR},
+		{RegionID: 5, OldFaction: ps2.None, NewFaction: ps2.NC},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DiffMap:\ngot  %v\nwant %v", got, want)
+	}
+
+	new.ZoneID = ps2.ZoneInstanceID(ps2.Amerish)
+	if _, err := psmap.Diff(old, new); err == nil {
+		t.Errorf("expected an error for states of different zones")
+	}
+}
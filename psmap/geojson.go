@@ -0,0 +1,145 @@
+package psmap
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// GeoJSONOptions enables optional features of [ToGeoJSON].
+type GeoJSONOptions struct {
+	// Normalized divides coordinates by the map size so that both axes go from 0 to 1,
+	// which lets the same data be drawn over terrain images of any resolution.
+	// Coordinates are LOD0 terrain pixels otherwise.
+	Normalized bool
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection (RFC 7946).
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature.
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id,omitempty"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry.
+// Coordinates hold a [2]float64 for a Point,
+// a [][2]float64 for a LineString,
+// or a [][][2]float64 for a Polygon.
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// ToGeoJSON converts the regions, facilities, and lattice links of data to a GeoJSON FeatureCollection,
+// so that web map libraries can draw them without converting census data themselves.
+//
+// Every feature has a "kind" property to tell the layers apart:
+//   - "region" features are the Polygon outlines of regions, with the id "region-{RegionID}"
+//     and the properties "region_id", "name", "facility_id", and "facility_type_id"
+//   - "facility" features are the Points of facilities, with the id "facility-{FacilityID}"
+//     and the same properties as their region
+//   - "link" features are lattice LineStrings between two facilities, with the id "link-{FacilityA}-{FacilityB}"
+//     and the properties "facility_a" and "facility_b"
+//
+// Coordinates have 0,0 at the upper left of the map with y increasing downward, the same as [DrawSVG].
+// This is flipped from the y axis of Leaflet's CRS.Simple, which needs a transformation of (1, 0, 1, 0) to match.
+// Facilities missing their coordinates are left out, along with their links.
+func ToGeoJSON(data Map, opts GeoJSONOptions) (FeatureCollection, error) {
+	if data.Size <= 0 {
+		return FeatureCollection{}, fmt.Errorf("psmap.ToGeoJSON: map size must be positive; given: %d", data.Size)
+	}
+	transform := func(p Point) [2]float64 {
+		x, y := p.Point()
+		x += float64(data.Size / 2)
+		y += float64(data.Size / 2)
+		if opts.Normalized {
+			return [2]float64{roundTo(x/float64(data.Size), 1e6), roundTo(y/float64(data.Size), 1e6)}
+		}
+		return [2]float64{roundTo(x, 10), roundTo(y, 10)}
+	}
+
+	fc := FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	points := make(map[ps2.FacilityID][2]float64)
+	for _, region := range data.Regions {
+		properties := func(kind string) map[string]any {
+			return map[string]any{
+				"kind":             kind,
+				"region_id":        region.RegionID,
+				"name":             region.Name,
+				"facility_id":      region.FacilityID,
+				"facility_type_id": region.FacilityTypeID,
+			}
+		}
+		if outline := Outline(region.Hexes, data.HexSize); len(outline) > 0 {
+			ring := make([][2]float64, 0, len(outline)+1)
+			for _, p := range outline {
+				ring = append(ring, transform(p))
+			}
+			// RFC 7946 rings are closed and exterior rings are counterclockwise
+			ring = append(ring, ring[0])
+			if ringArea(ring) < 0 {
+				slices.Reverse(ring)
+			}
+			fc.Features = append(fc.Features, Feature{
+				Type:       "Feature",
+				ID:         fmt.Sprintf("region-%d", region.RegionID),
+				Geometry:   Geometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+				Properties: properties("region"),
+			})
+		}
+		// facilities at exactly 0,0 are missing their coordinates
+		if region.FacilityID == 0 || (region.FacilityX == 0 && region.FacilityY == 0) {
+			continue
+		}
+		point := transform(region)
+		points[region.FacilityID] = point
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			ID:         fmt.Sprintf("facility-%d", region.FacilityID),
+			Geometry:   Geometry{Type: "Point", Coordinates: point},
+			Properties: properties("facility"),
+		})
+	}
+	for _, link := range data.Links {
+		a, foundA := points[link.A]
+		b, foundB := points[link.B]
+		if !foundA || !foundB {
+			continue
+		}
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			ID:       fmt.Sprintf("link-%d-%d", link.A, link.B),
+			Geometry: Geometry{Type: "LineString", Coordinates: [][2]float64{a, b}},
+			Properties: map[string]any{
+				"kind":       "link",
+				"facility_a": link.A,
+				"facility_b": link.B,
+			},
+		})
+	}
+	return fc, nil
+}
+
+// ringArea returns the signed area of a closed ring,
+// which is positive when the ring is counterclockwise.
+func ringArea(ring [][2]float64) float64 {
+	var sum float64
+	for i := 0; i+1 < len(ring); i++ {
+		sum += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return sum / 2
+}
+
+// roundTo rounds f to the nearest 1/scale, which keeps encoded coordinates short.
+func roundTo(f, scale float64) float64 {
+	return math.Round(f*scale) / scale
+}
@@ -0,0 +1,65 @@
+package psmap_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestToGeoJSON(t *testing.T) {
+	data := psmap.Map{
+		Size:    1024,
+		HexSize: 50,
+		Regions: []psmap.Region{
+			{RegionID: 1, Name: "Gate", FacilityID: 1, FacilityTypeID: ps2.Warpgate, FacilityX: -256, FacilityY: 0, Hexes: []psmap.Hex{{X: -4, Y: 0}}},
+			{RegionID: 10, FacilityID: 10, FacilityX: 10, FacilityY: 10, Hexes: []psmap.Hex{{X: 0, Y: 0}, {X: 1, Y: 0}}},
+			{RegionID: 11, FacilityID: 11, Hexes: []psmap.Hex{{X: 0, Y: -2}}}, // missing coordinates
+		},
+		Links: []psmap.Link{{A: 1, B: 10}, {A: 10, B: 11}},
+	}
+	fc, err := psmap.ToGeoJSON(data, psmap.GeoJSONOptions{Normalized: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Type     string `json:"type"`
+		Features []struct {
+			ID       string `json:"id"`
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	for _, f := range decoded.Features {
+		kinds[f.Properties["kind"].(string)]++
+		switch f.ID {
+		case "facility-1":
+			var p [2]float64
+			json.Unmarshal(f.Geometry.Coordinates, &p)
+			if p != [2]float64{0.25, 0.5} {
+				t.Errorf("got %v for the warpgate; want normalized coordinates [0.25 0.5]", p)
+			}
+		case "region-10":
+			var rings [][][2]float64
+			json.Unmarshal(f.Geometry.Coordinates, &rings)
+			if len(rings) != 1 || rings[0][0] != rings[0][len(rings[0])-1] {
+				t.Errorf("got %v for region 10; want one closed ring", rings)
+			}
+		}
+	}
+	if decoded.Type != "FeatureCollection" || kinds["region"] != 3 || kinds["facility"] != 2 || kinds["link"] != 1 {
+		t.Errorf("got %s with %v; want 3 regions, 2 facilities, and 1 link", decoded.Type, kinds)
+	}
+}
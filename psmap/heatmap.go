@@ -0,0 +1,253 @@
+package psmap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// HeatmapMode is how [DrawHeatmap] bins points.
+type HeatmapMode uint8
+
+const (
+	// HeatmapBlur spreads every point over a gaussian-blurred raster.
+	HeatmapBlur HeatmapMode = iota
+
+	// HeatmapHex fills each map hex by the number of points inside it.
+	// Points outside of every region are ignored.
+	HeatmapHex
+)
+
+// HeatmapOptions configures [DrawHeatmap].
+// The zero value draws a blurred heat map with the defaults.
+type HeatmapOptions struct {
+	Mode HeatmapMode
+
+	// Radius is the standard deviation of the blur in map units (meters) for HeatmapBlur.
+	// The default is 30.
+	Radius float64
+
+	// Gradient is the colors from the least to the most dense areas, evenly spaced.
+	// The default is DefaultHeatmapGradient.
+	Gradient []color.RGBA
+
+	// Opacity is the opacity (0-1) of the most dense areas.
+	// Less dense areas fade out towards transparent so that the terrain stays visible.
+	// The default is 0.75.
+	Opacity float64
+}
+
+// DefaultHeatmapGradient runs from blue for the least dense areas to red for the most dense.
+var DefaultHeatmapGradient = []color.RGBA{
+	{0x00, 0x00, 0xff, 0xff},
+	{0x00, 0xff, 0xff, 0xff},
+	{0x00, 0xff, 0x00, 0xff},
+	{0xff, 0xff, 0x00, 0xff},
+	{0xff, 0x00, 0x00, 0xff},
+}
+
+const (
+	defaultHeatmapRadius  = 30
+	defaultHeatmapOpacity = 0.75
+
+	// heatmapThreshold is the share of the peak density below which nothing is drawn.
+	heatmapThreshold = 0.02
+)
+
+// DrawHeatmap draws the density of points onto img,
+// such as the locations of deaths or experience events from a recorded event stream.
+// It's meant to be drawn over the continent terrain, either with or without the regions from [Draw].
+// Density is relative to the densest area, which is drawn in the last color of the gradient.
+// The same image requirements as Draw apply.
+func DrawHeatmap(img draw.Image, data Map, points []Loc, opts HeatmapOptions) error {
+	if err := checkCanvas(img); err != nil {
+		return fmt.Errorf("psmap.DrawHeatmap: %w", err)
+	}
+	if data.Size <= 0 {
+		return fmt.Errorf("psmap.DrawHeatmap: invalid map size %d", data.Size)
+	}
+	if opts.Radius <= 0 {
+		opts.Radius = defaultHeatmapRadius
+	}
+	if len(opts.Gradient) == 0 {
+		opts.Gradient = DefaultHeatmapGradient
+	}
+	if opts.Opacity <= 0 {
+		opts.Opacity = defaultHeatmapOpacity
+	}
+	opts.Opacity = min(opts.Opacity, 1)
+	if len(points) == 0 {
+		return nil
+	}
+
+	switch opts.Mode {
+	case HeatmapBlur:
+		drawHeatmapBlur(img, data, points, opts)
+	case HeatmapHex:
+		drawHeatmapHex(img, data, points, opts)
+	default:
+		return fmt.Errorf("psmap.DrawHeatmap: unknown mode %d", opts.Mode)
+	}
+	return nil
+}
+
+// drawHeatmapBlur bins points into a grid a few cells per blur radius wide,
+// blurs the grid, and draws it scaled up to img with bilinear interpolation.
+func drawHeatmapBlur(img draw.Image, data Map, points []Loc, opts HeatmapOptions) {
+	transform, scale := canvasTransform(img, data)
+	size := img.Bounds().Dx()
+	sigma := opts.Radius * scale // in pixels
+	cell := max(sigma/3, 1)
+	cells := int(math.Ceil(float64(size) / cell))
+
+	grid := make([]float64, cells*cells)
+	for _, p := range points {
+		x, y := transform(p)
+		col, row := int(x/cell), int(y/cell)
+		if col < 0 || row < 0 || col >= cells || row >= cells {
+			continue
+		}
+		grid[row*cells+col]++
+	}
+	grid = gaussianBlur(grid, cells, sigma/cell)
+
+	peak := 0.0
+	for _, v := range grid {
+		peak = max(peak, v)
+	}
+	if peak == 0 {
+		return
+	}
+
+	at := func(col, row int) float64 {
+		col = min(max(col, 0), cells-1)
+		row = min(max(row, 0), cells-1)
+		return grid[row*cells+col]
+	}
+	overlay := image.NewRGBA(img.Bounds())
+	for y := 0; y < size; y++ {
+		fy := (float64(y)+0.5)/cell - 0.5
+		row := int(math.Floor(fy))
+		ty := fy - float64(row)
+		for x := 0; x < size; x++ {
+			fx := (float64(x)+0.5)/cell - 0.5
+			col := int(math.Floor(fx))
+			tx := fx - float64(col)
+			v := (at(col, row)*(1-tx)+at(col+1, row)*tx)*(1-ty) +
+				(at(col, row+1)*(1-tx)+at(col+1, row+1)*tx)*ty
+			if c, ok := heatColor(v/peak, opts); ok {
+				overlay.SetRGBA(x, y, c)
+			}
+		}
+	}
+	draw.Draw(img, img.Bounds(), overlay, image.Point{}, draw.Over)
+}
+
+// gaussianBlur returns a copy of the square grid blurred with a gaussian kernel of standard deviation sigma,
+// as two passes of a one dimensional kernel.
+func gaussianBlur(grid []float64, width int, sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	pass := func(src []float64, step func(i, offset int) (int, bool)) []float64 {
+		dst := make([]float64, len(src))
+		for i, v := range src {
+			if v == 0 {
+				continue
+			}
+			for k, weight := range kernel {
+				if j, ok := step(i, k-radius); ok {
+					dst[j] += v * weight
+				}
+			}
+		}
+		return dst
+	}
+	horizontal := func(i, offset int) (int, bool) {
+		col := i%width + offset
+		return i + offset, col >= 0 && col < width
+	}
+	vertical := func(i, offset int) (int, bool) {
+		row := i/width + offset
+		return i + offset*width, row >= 0 && row < width
+	}
+	return pass(pass(grid, horizontal), vertical)
+}
+
+// drawHeatmapHex fills every region hex that contains a point.
+func drawHeatmapHex(img draw.Image, data Map, points []Loc, opts HeatmapOptions) {
+	inRegion := make(map[Hex]bool)
+	for _, region := range data.Regions {
+		for _, h := range region.Hexes {
+			inRegion[Hex{X: h.X, Y: h.Y}] = true
+		}
+	}
+	counts := make(map[Hex]int)
+	peak := 0
+	for _, p := range points {
+		x, y := p.Point()
+		h := hexAt(x, y, data.HexSize)
+		if !inRegion[h] {
+			continue
+		}
+		counts[h]++
+		peak = max(peak, counts[h])
+	}
+	if peak == 0 {
+		return
+	}
+
+	transform, _ := canvasTransform(img, data)
+	gc := draw2dimg.NewGraphicContext(img)
+	for h, n := range counts {
+		c, ok := heatColor(float64(n)/float64(peak), opts)
+		if !ok {
+			continue
+		}
+		gc.SetFillColor(c)
+		gc.BeginPath()
+		for i, corner := range hexCorners(h, data.HexSize) {
+			if i == 0 {
+				gc.MoveTo(transform(corner))
+			} else {
+				gc.LineTo(transform(corner))
+			}
+		}
+		gc.Close()
+		gc.Fill()
+	}
+}
+
+// heatColor returns the premultiplied color for density t (0-1) relative to the peak,
+// or false if t is too low to draw.
+func heatColor(t float64, opts HeatmapOptions) (color.RGBA, bool) {
+	if t < heatmapThreshold {
+		return color.RGBA{}, false
+	}
+	t = min(t, 1)
+	g := opts.Gradient
+	pos := t * float64(len(g)-1)
+	i := min(int(pos), len(g)-1)
+	c := g[i]
+	if i+1 < len(g) {
+		f := pos - float64(i)
+		lerp := func(a, b uint8) uint8 { return uint8(math.Round(float64(a)*(1-f) + float64(b)*f)) }
+		c = color.RGBA{lerp(c.R, g[i+1].R), lerp(c.G, g[i+1].G), lerp(c.B, g[i+1].B), 0xff}
+	}
+	c.A = 0xff
+	// fade out sparse areas so that single points don't hide the terrain
+	return withOpacity(c, opts.Opacity*math.Sqrt(t)), true
+}
@@ -0,0 +1,42 @@
+package psmap_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestDrawHeatmap(t *testing.T) {
+	data := psmap.Map{
+		Size:    1024,
+		HexSize: 50,
+		Regions: []psmap.Region{{RegionID: 1, Hexes: []psmap.Hex{{X: 0, Y: 0}, {X: 4, Y: 0}}}},
+	}
+	// census coordinates are Loc{Z: x, X: -y};
+	// these are the centers of the two hexes
+	hot := psmap.Loc{Z: 0, X: 29}
+	warm := psmap.Loc{Z: 200, X: 29}
+	points := []psmap.Loc{hot, hot, hot, hot, warm}
+	// points are drawn at (x+512)/4, (y+512)/4
+	hotPixel, warmPixel, coldPixel := image.Pt(128, 120), image.Pt(178, 120), image.Pt(50, 230)
+
+	for name, mode := range map[string]psmap.HeatmapMode{"blur": psmap.HeatmapBlur, "hex": psmap.HeatmapHex} {
+		t.Run(name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+			if err := psmap.DrawHeatmap(img, data, points, psmap.HeatmapOptions{Mode: mode}); err != nil {
+				t.Fatal(err)
+			}
+			hot, warm, cold := img.RGBAAt(hotPixel.X, hotPixel.Y), img.RGBAAt(warmPixel.X, warmPixel.Y), img.RGBAAt(coldPixel.X, coldPixel.Y)
+			if cold.A != 0 {
+				t.Errorf("got %v away from every point; want transparent", cold)
+			}
+			if hot.A == 0 || warm.A == 0 {
+				t.Fatalf("got %v at the hot point and %v at the warm point; want both drawn", hot, warm)
+			}
+			if hot.A <= warm.A || hot.R <= warm.R {
+				t.Errorf("got %v at the hot point and %v at the warm point; want the hot point more opaque and red", hot, warm)
+			}
+		})
+	}
+}
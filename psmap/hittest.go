@@ -0,0 +1,56 @@
+package psmap
+
+import (
+	"math"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// ImagePointToRegion returns the region drawn at pixel x,y of a square image that is imageSize pixels wide,
+// as rendered from data by [Draw].
+// It's the inverse of the drawing transform followed by a hex lookup,
+// so a frontend showing a generated map can resolve clicks without the region polygons.
+//
+// The result is false for pixels outside of the image or outside of every region.
+func ImagePointToRegion(data Map, imageSize int, x, y int) (ps2.RegionID, bool) {
+	if imageSize <= 0 || data.Size <= 0 || x < 0 || y < 0 || x >= imageSize || y >= imageSize {
+		return 0, false
+	}
+	// sample the center of the pixel,
+	// then undo the scale and the shift of 0,0 from the center to the top left
+	scale := float64(imageSize) / float64(data.Size)
+	px := (float64(x)+0.5)/scale - float64(data.Size/2)
+	py := (float64(y)+0.5)/scale - float64(data.Size/2)
+
+	hex := hexAt(px, py, data.HexSize)
+	for _, region := range data.Regions {
+		for _, h := range region.Hexes {
+			if h.X == hex.X && h.Y == hex.Y {
+				return region.RegionID, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// hexAt returns the tile containing the point x,y,
+// using the same coordinates as [Outline].
+// width is the hex width as returned by census.
+func hexAt(x, y float64, width int) Hex {
+	size := widthToSize(width)
+	// invert hexCenter to get fractional tile coordinates
+	fy := -(y + size) / (1.5 * size)
+	fx := x/(math.Sqrt(3)*size) - fy/2
+
+	// round in cube coordinates so that points near corners land in the nearest hex
+	fz := -fx - fy
+	rx, ry, rz := math.Round(fx), math.Round(fy), math.Round(fz)
+	dx, dy, dz := math.Abs(rx-fx), math.Abs(ry-fy), math.Abs(rz-fz)
+	switch {
+	case dx > dy && dx > dz:
+		rx = -ry - rz
+	case dy > dz:
+		ry = -rx - rz
+	}
+	return Hex{X: int(rx), Y: int(ry)}
+}
@@ -0,0 +1,60 @@
+package psmap_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestImagePointToRegion(t *testing.T) {
+	data := psmap.Map{
+		Size:    1024,
+		HexSize: 50,
+		Regions: []psmap.Region{
+			{RegionID: 1, Hexes: []psmap.Hex{{X: 0, Y: 0}, {X: 1, Y: 0}}},
+			{RegionID: 2, Hexes: []psmap.Hex{{X: -1, Y: 1}}},
+			{RegionID: 3, Hexes: []psmap.Hex{{X: 3, Y: -2}}},
+		},
+	}
+	// a full size image keeps one pixel per map unit so that points near the corners can be checked
+	imageSize := data.Size
+	outerRadius := 50 / math.Sqrt(3)
+	pixel := func(h psmap.Hex, dx, dy float64) (int, int) {
+		x, y := psmap.ComputeCentroid([]psmap.Hex{h}, data.HexSize).Point()
+		return int(math.Floor(x + dx + float64(data.Size/2))), int(math.Floor(y + dy + float64(data.Size/2)))
+	}
+
+	tt := map[string]struct {
+		Hex    psmap.Hex
+		Region int
+		Found  bool
+	}{
+		"first hex":      {psmap.Hex{X: 0, Y: 0}, 1, true},
+		"second hex":     {psmap.Hex{X: 1, Y: 0}, 1, true},
+		"other region":   {psmap.Hex{X: -1, Y: 1}, 2, true},
+		"diagonal tile":  {psmap.Hex{X: 3, Y: -2}, 3, true},
+		"outside region": {psmap.Hex{X: 4, Y: 4}, 0, false},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			// the center and points a little inside each corner should all land in the same hex
+			offsets := [][2]float64{{0, 0}}
+			for corner := 0; corner < 6; corner++ {
+				angle := (math.Pi / 180) * float64(60*corner+90)
+				offsets = append(offsets, [2]float64{0.8 * outerRadius * math.Cos(angle), -0.8 * outerRadius * math.Sin(angle)})
+			}
+			for _, o := range offsets {
+				x, y := pixel(tc.Hex, o[0], o[1])
+				region, found := psmap.ImagePointToRegion(data, imageSize, x, y)
+				if int(region) != tc.Region || found != tc.Found {
+					t.Errorf("pixel %d,%d: expected region %d (%v); got %d (%v)", x, y, tc.Region, tc.Found, region, found)
+				}
+			}
+		})
+	}
+
+	if _, found := psmap.ImagePointToRegion(data, imageSize, imageSize, 0); found {
+		t.Errorf("expected pixels outside of the image to miss")
+	}
+}
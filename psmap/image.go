@@ -6,6 +6,8 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
+	"slices"
 
 	"github.com/Travis-Britz/ps2"
 	"github.com/llgcode/draw2d/draw2dimg"
@@ -31,6 +33,11 @@ var FactionDrawColors = [5]color.RGBA{
 // This function expects 0,0 to be the upper left corner of img and will shift census coordinates appropriately.
 // Map data should be given using the Census coordinates.
 func Draw(img draw.Image, data Map, mapstate owner) error {
+	return DrawWithOptions(img, data, mapstate, DrawOptions{})
+}
+
+// DrawWithOptions is like [Draw] but with the optional drawing features in opts.
+func DrawWithOptions(img draw.Image, data Map, mapstate owner, opts DrawOptions) error {
 	if img.Bounds().Dx() != img.Bounds().Dy() {
 		return fmt.Errorf("psmap.Draw: image bounds must be square; given: %v", img.Bounds())
 	}
@@ -107,7 +114,30 @@ func Draw(img draw.Image, data Map, mapstate owner) error {
 			}
 		}
 		gc.Close()
+		if opts.RestrictedHexes {
+			// fully restricted hexes are cut out of the fill as holes in the region path
+			for _, hex := range region.Hexes {
+				if hex.Type != ps2.FullyRestrictedHex {
+					continue
+				}
+				for i, corner := range hexCorners(hex, data.HexSize) {
+					if i == 0 {
+						gc.MoveTo(transform(corner))
+					} else {
+						gc.LineTo(transform(corner))
+					}
+				}
+				gc.Close()
+			}
+		}
 		gc.FillStroke()
+		if opts.RestrictedHexes {
+			for _, hex := range region.Hexes {
+				if hex.Type == ps2.FactionRestrictedHex {
+					hatchHex(img, hex, data.HexSize, transform, scale)
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -250,6 +280,43 @@ func LocBounds(terrainBounds image.Rectangle, data Map, loc Loc) (image.Rectangl
 	return rect, nil
 }
 
+// CropAroundFacility returns square bounds inside terrainBounds centered on a facility,
+// suitable for cropping a zoomed minimap of a single base.
+// radius is the distance in meters from the facility to each edge of the crop;
+// a full size continent map is one pixel per meter.
+//
+// The crop is shifted to stay inside terrainBounds when the facility is near the edge of the map,
+// so the facility is only centered when there is room.
+// Regions that are missing facility coordinates in census are centered on their [LabelAnchor].
+func CropAroundFacility(terrainBounds image.Rectangle, data Map, facility ps2.FacilityID, radius float64) (image.Rectangle, error) {
+	if terrainBounds.Empty() {
+		return image.Rectangle{}, errors.New("cannot use empty terrain bounds")
+	}
+	if terrainBounds.Dx() != terrainBounds.Dy() {
+		return image.Rectangle{}, errors.New("terrain bounds must be square")
+	}
+	if radius <= 0 {
+		return image.Rectangle{}, fmt.Errorf("radius must be positive; given: %v", radius)
+	}
+	i := slices.IndexFunc(data.Regions, func(r Region) bool { return r.FacilityID == facility })
+	if i < 0 {
+		return image.Rectangle{}, fmt.Errorf("facility %d not found in zone %d", facility, data.ZoneID)
+	}
+
+	scale := float64(terrainBounds.Dx()) / float64(data.Size)
+	x, y := data.Regions[i].Anchor(data.HexSize).Point()
+	x = (x + float64(data.Size/2)) * scale
+	y = (y + float64(data.Size/2)) * scale
+
+	side := min(int(math.Round(2*radius*scale)), terrainBounds.Dx())
+	side = max(side, 1)
+	minX := int(math.Round(x)) - side/2 + terrainBounds.Min.X
+	minY := int(math.Round(y)) - side/2 + terrainBounds.Min.Y
+	minX = min(max(minX, terrainBounds.Min.X), terrainBounds.Max.X-side)
+	minY = min(max(minY, terrainBounds.Min.Y), terrainBounds.Max.Y-side)
+	return image.Rect(minX, minY, minX+side, minY+side), nil
+}
+
 // GenerateMask return an [image.Image] for use as a mask in [draw.DrawMask].
 // mask draw.Image, data Map, hexes []Hex, scale float64, offset image.Point
 
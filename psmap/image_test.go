@@ -2,6 +2,7 @@ package psmap_test
 
 import (
 	"image"
+	"math"
 	"testing"
 
 	"github.com/Travis-Britz/ps2"
@@ -89,3 +90,62 @@ func getHexes(data psmap.Map, region ps2.RegionID) []psmap.Hex {
 	}
 	return nil
 }
+
+func TestCropAroundFacility(t *testing.T) {
+	data := psmap.Map{
+		ZoneID:  2,
+		HexSize: 200,
+		Size:    8192,
+		Regions: []psmap.Region{
+			{RegionID: 1, FacilityID: 100, FacilityX: 800, FacilityY: -400},
+			{RegionID: 2, FacilityID: 200, FacilityX: 4000, FacilityY: 4000},
+			// census is missing the coordinates of some facilities
+			{RegionID: 3, FacilityID: 300, Hexes: []psmap.Hex{{X: 0, Y: 0}}},
+		},
+	}
+	terrain := image.Rect(0, 0, 1024, 1024)
+	ax, ay := psmap.LabelAnchor(data.Regions[2], data.HexSize).Point()
+	anchor := image.Pt(int(math.Round((ax+4096)/8)), int(math.Round((ay+4096)/8)))
+
+	tt := map[string]struct {
+		Terrain  image.Rectangle
+		Facility ps2.FacilityID
+		Radius   float64
+		Want     image.Rectangle
+	}{
+		// (800+4096)/8 = 612 and (-400+4096)/8 = 462, with 400m on each side scaled to 50px
+		"centered":        {terrain, 100, 400, image.Rect(562, 412, 662, 512)},
+		"shifted at edge": {terrain, 200, 400, image.Rect(924, 924, 1024, 1024)},
+		"offset terrain":  {terrain.Add(image.Pt(100, 200)), 100, 400, image.Rect(662, 612, 762, 712)},
+		"whole map":       {terrain, 100, 10000, terrain},
+		"label anchor":    {terrain, 300, 80, image.Rectangle{anchor, anchor}.Inset(-10)},
+	}
+	for name, tc := range tt {
+		got, err := psmap.CropAroundFacility(tc.Terrain, data, tc.Facility, tc.Radius)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got != tc.Want {
+			t.Errorf("%s: got %v; want %v", name, got, tc.Want)
+		}
+		if !got.In(tc.Terrain) || got.Dx() != got.Dy() {
+			t.Errorf("%s: got %v; want a square inside %v", name, got, tc.Terrain)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		Terrain  image.Rectangle
+		Facility ps2.FacilityID
+		Radius   float64
+	}{
+		"empty terrain":      {image.Rectangle{}, 100, 400},
+		"non-square terrain": {image.Rect(0, 0, 1024, 512), 100, 400},
+		"zero radius":        {terrain, 100, 0},
+		"unknown facility":   {terrain, 999, 400},
+	} {
+		if _, err := psmap.CropAroundFacility(tc.Terrain, data, tc.Facility, tc.Radius); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
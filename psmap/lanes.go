@@ -0,0 +1,98 @@
+package psmap
+
+import (
+	"sort"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Lane is the part of the lattice that is closer to one warpgate than to any other.
+type Lane struct {
+	Warpgate ps2.RegionID  `json:"warpgate"`
+	Owner    ps2.FactionID `json:"owner"` // Owner is the faction that owns the warpgate
+
+	// Regions are the facility regions in the lane, ordered by the number of lattice links from the warpgate.
+	// The warpgate itself is not included.
+	Regions []ps2.RegionID `json:"regions"`
+
+	// Held is the number of Regions owned by the warpgate owner.
+	// A lane is collapsing as Held drops toward 0.
+	Held int `json:"held"`
+}
+
+// Lanes assigns every facility region to the lane of the warpgate it is closest to,
+// counting lattice links without regard to ownership.
+//
+// Regions that are the same distance from more than one warpgate belong to no lane and are returned in contested
+// (on most continents this is the center of the map).
+// Regions that can't be reached from any warpgate are omitted from both.
+// Lanes are returned in the same order as the warpgates appear in data.
+func Lanes(data Map, regions owner) (lanes []Lane, contested []ps2.RegionID, err error) {
+	lattice, warpgates, err := buildLattice(data, regions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type assignment struct {
+		lane     int // index into lanes, or -1 when contested
+		distance int
+	}
+	assigned := make(map[ps2.FacilityID]assignment, len(lattice))
+	lanes = make([]Lane, len(warpgates))
+	level := make([]*facilityRegion, 0, len(warpgates))
+	for i, wg := range warpgates {
+		lanes[i] = Lane{Warpgate: wg.RegionID, Owner: wg.Owner}
+		assigned[wg.FacilityID] = assignment{lane: i}
+		level = append(level, wg)
+	}
+
+	// breadth-first search from every warpgate at once, one level of links at a time,
+	// so that each facility is claimed by whichever warpgates reach it first.
+	for distance := 1; len(level) > 0; distance++ {
+		var next []*facilityRegion
+		for _, r := range level {
+			from := assigned[r.FacilityID].lane
+			for _, n := range r.Links {
+				a, seen := assigned[n.FacilityID]
+				if !seen {
+					assigned[n.FacilityID] = assignment{lane: from, distance: distance}
+					next = append(next, n)
+					continue
+				}
+				// reached by a different lane (or a contested region) at the same distance
+				if a.distance == distance && a.lane != from {
+					assigned[n.FacilityID] = assignment{lane: -1, distance: distance}
+				}
+			}
+		}
+		level = next
+	}
+
+	// order regions by distance, then by ID so that results are stable
+	ordered := make([]*facilityRegion, 0, len(assigned))
+	for id, a := range assigned {
+		if a.distance > 0 {
+			ordered = append(ordered, lattice[id])
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		di, dj := assigned[ordered[i].FacilityID].distance, assigned[ordered[j].FacilityID].distance
+		if di != dj {
+			return di < dj
+		}
+		return ordered[i].RegionID < ordered[j].RegionID
+	})
+	for _, r := range ordered {
+		a := assigned[r.FacilityID]
+		if a.lane < 0 {
+			contested = append(contested, r.RegionID)
+			continue
+		}
+		lane := &lanes[a.lane]
+		lane.Regions = append(lane.Regions, r.RegionID)
+		if r.Owner == lane.Owner {
+			lane.Held++
+		}
+	}
+	return lanes, contested, nil
+}
@@ -0,0 +1,44 @@
+package psmap_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestLanes(t *testing.T) {
+	// two warpgates joined by a chain of facilities, with a spur off the first:
+	//
+	//	wg1 - 10 - 11 - 12 - wg2
+	//	      |
+	//	      13
+	region := func(id int, typ ps2.FacilityTypeID) psmap.Region {
+		return psmap.Region{RegionID: ps2.RegionID(id), FacilityID: ps2.FacilityID(id), FacilityTypeID: typ}
+	}
+	link := func(a, b int) psmap.Link { return psmap.Link{A: ps2.FacilityID(a), B: ps2.FacilityID(b)} }
+	data := psmap.Map{
+		Regions: []psmap.Region{region(1, ps2.Warpgate), region(2, ps2.Warpgate), region(10, 0), region(11, 0), region(12, 0), region(13, 0)},
+		Links:   []psmap.Link{link(1, 10), link(10, 11), link(11, 12), link(12, 2), link(10, 13)},
+	}
+	state := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{
+		1: ps2.VS, 10: ps2.NC, 11: ps2.NC, 12: ps2.NC, 13: ps2.VS,
+		2: ps2.NC,
+	}}
+
+	lanes, contested, err := psmap.Lanes(data, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []psmap.Lane{
+		{Warpgate: 1, Owner: ps2.VS, Regions: []ps2.RegionID{10, 13}, Held: 1},
+		{Warpgate: 2, Owner: ps2.NC, Regions: []ps2.RegionID{12}, Held: 1},
+	}
+	if !reflect.DeepEqual(lanes, want) {
+		t.Errorf("expected lanes %+v; got %+v", want, lanes)
+	}
+	if !reflect.DeepEqual(contested, []ps2.RegionID{11}) {
+		t.Errorf("expected region 11 to be contested; got %v", contested)
+	}
+}
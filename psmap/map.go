@@ -2,8 +2,10 @@
 package psmap
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/Travis-Britz/ps2"
@@ -123,8 +125,10 @@ func (l Loc) Point() (float64, float64) {
 }
 
 // State describes the territory ownership of a continent.
+// See [SchemaVersion] for the json encoding.
 type State struct {
 	ZoneID    ps2.ZoneInstanceID
+	WorldID   ps2.WorldID // WorldID is 0 when unknown
 	Timestamp time.Time
 	Territory map[ps2.RegionID]ps2.FactionID
 }
@@ -154,63 +158,25 @@ type facilityRegion struct {
 // on Nexus (outfit wars) there may only be two teams.
 func Summarize(data Map, regions owner) (summary Summary, err error) {
 	summary = Summary{
-		Territory:     map[ps2.FactionID]float32{},
-		FacilityCount: map[ps2.FactionID]int{},
-		CutoffCount:   map[ps2.FactionID]int{},
-		Cutoff:        map[ps2.RegionID]bool{},
+		Territory:      map[ps2.FactionID]float32{},
+		FacilityCount:  map[ps2.FactionID]int{},
+		CutoffCount:    map[ps2.FactionID]int{},
+		Cutoff:         map[ps2.RegionID]bool{},
+		Disabled:       map[ps2.RegionID]bool{},
+		WarpgateOwners: map[ps2.RegionID]ps2.FactionID{},
 	}
-	lattice := make(map[ps2.FacilityID]*facilityRegion) // lattice is the graph of facility connections
-	regionIdx := make(map[ps2.RegionID]ps2.FacilityID)  // regionIdx maps RegionIDs to FacilityIDs
-	warpgates := make([]*facilityRegion, 0, 3)
-
-	// start by building a graph of connected facilities
-	for _, reg := range data.Regions {
-		// the census /map endpoint gives facility ownership by region id,
-		// but not every region has a facility.
-		// regions without a facility will typically be owned by faction 0 (None)
-		if reg.FacilityID == 0 {
-			continue
-		}
-
-		r := &facilityRegion{
-			RegionID:   reg.RegionID,
-			FacilityID: reg.FacilityID,
-			Owner:      regions.Owner(reg.RegionID),
-			// Cutoff:     true, // every region starts as cut off, then as we visit each region in the graph we mark it as available
-		}
-		lattice[reg.FacilityID] = r
+	lattice, warpgates, err := buildLattice(data, regions)
+	if err != nil {
+		return summary, err
+	}
+	for _, r := range lattice {
 		summary.CutoffCount[r.Owner]++
 		if r.Owner != none {
-			summary.Cutoff[reg.RegionID] = true
-		}
-
-		regionIdx[reg.RegionID] = reg.FacilityID
-		if reg.FacilityTypeID == ps2.Warpgate {
-			warpgates = append(warpgates, r)
+			summary.Cutoff[r.RegionID] = true
+		} else {
+			summary.Disabled[r.RegionID] = true
 		}
 	}
-	// go through the list of lattice connections and build up the list of neighbor facilities for each facility
-	for _, link := range data.Links {
-
-		// check for referenced facilities that don't exist.
-		// we can't always trust census to be consistent,
-		// and we don't need any nil pointers to dereference.
-		fA, ok := lattice[link.A]
-		if !ok {
-			return summary, fmt.Errorf("a facility link referenced a facility missing from the supplied map data; link: %v, facility: %v", link, link.A)
-		}
-		fB, ok := lattice[link.B]
-		if !ok {
-			return summary, fmt.Errorf("a facility link referenced a facility missing from the supplied map data; link: %v, facility: %v", link, link.B)
-		}
-
-		// the lattice links may or may not contain a link both ways, but the links are bidirectional.
-		// it doesn't matter if we have duplicate neighbors added;
-		// our graph traversal will skip visited facilities.
-		fA.Links = append(fA.Links, fB)
-		fB.Links = append(fB.Links, fA)
-
-	}
 
 	frontier := &stack.Stack[*facilityRegion]{}
 	visited := map[ps2.FacilityID]bool{}
@@ -240,6 +206,7 @@ func Summarize(data Map, regions owner) (summary Summary, err error) {
 	totalTerritories := float32(len(lattice) - len(warpgates))
 	for _, warpgate := range warpgates {
 		factionCount[warpgate.Owner] = struct{}{}
+		summary.WarpgateOwners[warpgate.RegionID] = warpgate.Owner
 		owned := float32(summary.FacilityCount[warpgate.Owner])
 		summary.Territory[warpgate.Owner] = 100 * owned / totalTerritories
 	}
@@ -248,6 +215,7 @@ func Summarize(data Map, regions owner) (summary Summary, err error) {
 	// if all warpgates are owned by one faction then a continent is locked
 	case len(factionCount) == 1:
 		summary.Status = Locked
+		summary.OwningFaction = warpgates[0].Owner
 
 		// if any facilities are owned by faction 0 then the continent is probably in an unstable state.
 		// however, the haunted bastion event can disable regions.
@@ -261,6 +229,110 @@ func Summarize(data Map, regions owner) (summary Summary, err error) {
 	return summary, nil
 }
 
+// RegionSummary is a [Summary] along with the regions behind its counts.
+// Every list is sorted by region ID.
+type RegionSummary struct {
+	Summary
+
+	// Connected is the regions owned by a faction that are connected to one of its warpgates,
+	// matching FacilityCount.
+	Connected map[ps2.FactionID][]ps2.RegionID
+
+	// CutoffRegions is the regions owned by a faction that are cut off from its warpgates,
+	// matching CutoffCount.
+	// Disabled regions are listed here for faction 0.
+	CutoffRegions map[ps2.FactionID][]ps2.RegionID
+
+	// Warpgates is the warpgate regions owned by each faction.
+	Warpgates map[ps2.FactionID][]ps2.RegionID
+}
+
+// SummarizeRegions is the same as [Summarize],
+// but also lists the regions owned by each faction.
+// Only regions with a facility are included, as with Summarize.
+func SummarizeRegions(data Map, regions owner) (RegionSummary, error) {
+	summary, err := Summarize(data, regions)
+	if err != nil {
+		return RegionSummary{}, err
+	}
+	rs := RegionSummary{
+		Summary:       summary,
+		Connected:     map[ps2.FactionID][]ps2.RegionID{},
+		CutoffRegions: map[ps2.FactionID][]ps2.RegionID{},
+		Warpgates:     map[ps2.FactionID][]ps2.RegionID{},
+	}
+	for _, r := range data.Regions {
+		if r.FacilityID == 0 {
+			continue
+		}
+		faction := regions.Owner(r.RegionID)
+		switch {
+		case r.FacilityTypeID == ps2.Warpgate:
+			rs.Warpgates[faction] = append(rs.Warpgates[faction], r.RegionID)
+		case faction == none || summary.Cutoff[r.RegionID]:
+			rs.CutoffRegions[faction] = append(rs.CutoffRegions[faction], r.RegionID)
+		default:
+			rs.Connected[faction] = append(rs.Connected[faction], r.RegionID)
+		}
+	}
+	for _, lists := range []map[ps2.FactionID][]ps2.RegionID{rs.Connected, rs.CutoffRegions, rs.Warpgates} {
+		for _, list := range lists {
+			slices.Sort(list)
+		}
+	}
+	return rs, nil
+}
+
+// buildLattice builds a graph of connected facilities,
+// returning every facility keyed by ID and the warpgates.
+func buildLattice(data Map, regions owner) (lattice map[ps2.FacilityID]*facilityRegion, warpgates []*facilityRegion, err error) {
+	lattice = make(map[ps2.FacilityID]*facilityRegion) // lattice is the graph of facility connections
+	warpgates = make([]*facilityRegion, 0, 3)
+
+	for _, reg := range data.Regions {
+		// the census /map endpoint gives facility ownership by region id,
+		// but not every region has a facility.
+		// regions without a facility will typically be owned by faction 0 (None)
+		if reg.FacilityID == 0 {
+			continue
+		}
+
+		r := &facilityRegion{
+			RegionID:   reg.RegionID,
+			FacilityID: reg.FacilityID,
+			Owner:      regions.Owner(reg.RegionID),
+			// Cutoff:     true, // every region starts as cut off, then as we visit each region in the graph we mark it as available
+		}
+		lattice[reg.FacilityID] = r
+		if reg.FacilityTypeID == ps2.Warpgate {
+			warpgates = append(warpgates, r)
+		}
+	}
+	// go through the list of lattice connections and build up the list of neighbor facilities for each facility
+	for _, link := range data.Links {
+
+		// check for referenced facilities that don't exist.
+		// we can't always trust census to be consistent,
+		// and we don't need any nil pointers to dereference.
+		fA, ok := lattice[link.A]
+		if !ok {
+			return nil, nil, fmt.Errorf("a facility link referenced a facility missing from the supplied map data; link: %v, facility: %v", link, link.A)
+		}
+		fB, ok := lattice[link.B]
+		if !ok {
+			return nil, nil, fmt.Errorf("a facility link referenced a facility missing from the supplied map data; link: %v, facility: %v", link, link.B)
+		}
+
+		// the lattice links may or may not contain a link both ways, but the links are bidirectional.
+		// it doesn't matter if we have duplicate neighbors added;
+		// our graph traversal will skip visited facilities.
+		fA.Links = append(fA.Links, fB)
+		fB.Links = append(fB.Links, fA)
+
+	}
+	return lattice, warpgates, nil
+}
+
 // Summary describes territory control, continent status, etc. for a continent.
 type Summary struct {
 	// FacilityCount is the number of owned facilities for a faction, excluding warpgates and cut off regions
@@ -274,10 +346,20 @@ type Summary struct {
 
 	Cutoff map[ps2.RegionID]bool
 
+	// Disabled is the set of facility regions owned by faction 0,
+	// such as regions disabled by the haunted bastion event or every region of an unstable continent.
+	Disabled map[ps2.RegionID]bool
+
 	// Status is the locked/unlocked status of the continent.
 	Status Status
 
-	//todo: owning faction for locked continents?
+	// OwningFaction is the faction that owns every warpgate of a locked continent,
+	// and 0 when the continent isn't locked.
+	OwningFaction ps2.FactionID
+
+	// WarpgateOwners is the owner of each warpgate region.
+	// Warpgates are found by their facility type in the map data.
+	WarpgateOwners map[ps2.RegionID]ps2.FactionID
 }
 
 type Status uint8
@@ -299,6 +381,20 @@ func (s Status) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("%q", s.String())), nil
 }
 
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("psmap.Status.UnmarshalJSON: %w", err)
+	}
+	for _, status := range []Status{Locked, Unlocked, Unstable} {
+		if name == status.String() {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("psmap.Status.UnmarshalJSON: unknown status %q", name)
+}
+
 const (
 	none = ps2.None
 	nc   = ps2.NC
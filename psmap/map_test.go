@@ -202,3 +202,83 @@ func ExampleLoc_Bearing() {
 	// 315
 
 }
+
+func TestSummarizeRegions(t *testing.T) {
+	//	wg1 - 10 - 11 - 12 - wg2
+	//	      |
+	//	      13 - 14
+	region := func(id int, typ ps2.FacilityTypeID) psmap.Region {
+		return psmap.Region{RegionID: ps2.RegionID(id), FacilityID: ps2.FacilityID(id), FacilityTypeID: typ}
+	}
+	link := func(a, b int) psmap.Link { return psmap.Link{A: ps2.FacilityID(a), B: ps2.FacilityID(b)} }
+	data := psmap.Map{
+		Regions: []psmap.Region{region(1, ps2.Warpgate), region(2, ps2.Warpgate), region(10, 0), region(11, 0), region(12, 0), region(13, 0), region(14, 0), {RegionID: 99}},
+		Links:   []psmap.Link{link(1, 10), link(10, 11), link(11, 12), link(12, 2), link(10, 13), link(13, 14)},
+	}
+	state := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{
+		1: VS, 10: VS, 11: NC, 12: NC, 13: NC, 14: None,
+		2: NC,
+	}}
+
+	rs, err := psmap.SummarizeRegions(data, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(name string, got map[ps2.FactionID][]ps2.RegionID, want map[ps2.FactionID][]ps2.RegionID) {
+		t.Helper()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: expected %v; got %v", name, want, got)
+		}
+	}
+	check("Connected", rs.Connected, map[ps2.FactionID][]ps2.RegionID{VS: {10}, NC: {11, 12}})
+	check("CutoffRegions", rs.CutoffRegions, map[ps2.FactionID][]ps2.RegionID{None: {14}, NC: {13}})
+	check("Warpgates", rs.Warpgates, map[ps2.FactionID][]ps2.RegionID{VS: {1}, NC: {2}})
+	for faction, regions := range rs.Connected {
+		if rs.FacilityCount[faction] != len(regions) {
+			t.Errorf("faction %v: FacilityCount is %d but %d regions are connected", faction, rs.FacilityCount[faction], len(regions))
+		}
+	}
+	for faction, regions := range rs.CutoffRegions {
+		if rs.CutoffCount[faction] != len(regions) {
+			t.Errorf("faction %v: CutoffCount is %d but %d regions are cut off", faction, rs.CutoffCount[faction], len(regions))
+		}
+	}
+}
+
+func TestSummarizeOwningFaction(t *testing.T) {
+	//	wg1 - 10 - wg2
+	//	       |
+	//	      wg3
+	region := func(id int, typ ps2.FacilityTypeID) psmap.Region {
+		return psmap.Region{RegionID: ps2.RegionID(id), FacilityID: ps2.FacilityID(id), FacilityTypeID: typ}
+	}
+	link := func(a, b int) psmap.Link { return psmap.Link{A: ps2.FacilityID(a), B: ps2.FacilityID(b)} }
+	data := psmap.Map{
+		Regions: []psmap.Region{region(1, ps2.Warpgate), region(2, ps2.Warpgate), region(3, ps2.Warpgate), region(10, 0)},
+		Links:   []psmap.Link{link(1, 10), link(10, 2), link(10, 3)},
+	}
+
+	locked := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{1: TR, 2: TR, 3: TR, 10: TR}}
+	summary, err := psmap.Summarize(data, locked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Status != psmap.Locked || summary.OwningFaction != TR {
+		t.Errorf("expected locked by %v; got %v by %v", TR, summary.Status, summary.OwningFaction)
+	}
+	if want := fmt.Sprint(map[ps2.RegionID]ps2.FactionID{1: TR, 2: TR, 3: TR}); fmt.Sprint(summary.WarpgateOwners) != want {
+		t.Errorf("WarpgateOwners: expected %v; got %v", want, summary.WarpgateOwners)
+	}
+
+	unlocked := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{1: VS, 2: NC, 3: TR, 10: NC}}
+	summary, err = psmap.Summarize(data, unlocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Status != psmap.Unlocked || summary.OwningFaction != None {
+		t.Errorf("expected unlocked with no owner; got %v by %v", summary.Status, summary.OwningFaction)
+	}
+	if want := fmt.Sprint(map[ps2.RegionID]ps2.FactionID{1: VS, 2: NC, 3: TR}); fmt.Sprint(summary.WarpgateOwners) != want {
+		t.Errorf("WarpgateOwners: expected %v; got %v", want, summary.WarpgateOwners)
+	}
+}
@@ -0,0 +1,77 @@
+package psmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// DrawOptions enables optional features of [DrawWithOptions].
+// The zero value draws the same image as [Draw].
+type DrawOptions struct {
+	// RestrictedHexes draws hex tiles by their ps2.MapHexType the way the in-game map does.
+	// Fully restricted tiles are left out of the region fill,
+	// and faction restricted tiles (the warpgate tiles that only the owning faction can enter)
+	// are shaded with diagonal stripes of [RestrictedHatchColor].
+	RestrictedHexes bool
+}
+
+// RestrictedHatchColor is the stripe color for faction restricted hexes.
+var RestrictedHatchColor = color.RGBA{0xff, 0xff, 0xff, 0x50}
+
+// hexCorners returns the six corners of hex in the same coordinates as [Outline].
+func hexCorners(hex Hex, width int) [6]Point {
+	size := widthToSize(width)
+	var corners [6]Point
+	for i := range corners {
+		corners[i] = point{Hex: hex, corner: i, size: size}
+	}
+	return corners
+}
+
+// hatchHex draws diagonal stripes over hex.
+// draw2d can't clip to a path, so the stripes are blended pixel by pixel inside the transformed hexagon.
+func hatchHex(img draw.Image, hex Hex, width int, transform func(Point) (float64, float64), scale float64) {
+	var poly [6][2]float64
+	bounds := image.Rectangle{Min: image.Pt(math.MaxInt, math.MaxInt), Max: image.Pt(math.MinInt, math.MinInt)}
+	for i, corner := range hexCorners(hex, width) {
+		x, y := transform(corner)
+		poly[i] = [2]float64{x, y}
+		bounds.Min.X = min(bounds.Min.X, int(math.Floor(x)))
+		bounds.Min.Y = min(bounds.Min.Y, int(math.Floor(y)))
+		bounds.Max.X = max(bounds.Max.X, int(math.Ceil(x)))
+		bounds.Max.Y = max(bounds.Max.Y, int(math.Ceil(y)))
+	}
+	bounds = bounds.Intersect(img.Bounds())
+
+	// stripes are a fixed share of the hex size so that the pattern looks the same at any image size,
+	// but never thinner than a pixel
+	period := max(int(float64(width)*scale/3), 3)
+	stripe := max(period/3, 1)
+	src := image.NewUniform(RestrictedHatchColor)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if (x+y)%period >= stripe {
+				continue
+			}
+			if !insidePolygon(poly[:], float64(x)+0.5, float64(y)+0.5) {
+				continue
+			}
+			draw.Draw(img, image.Rect(x, y, x+1, y+1), src, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// insidePolygon reports whether x,y is inside poly using the even-odd rule.
+func insidePolygon(poly [][2]float64, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		xi, yi := poly[i][0], poly[i][1]
+		xj, yj := poly[j][0], poly[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,106 @@
+package psmap_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestDrawRestrictedHexes(t *testing.T) {
+	open := psmap.Hex{X: 0, Y: 0}
+	blocked := psmap.Hex{X: 1, Y: 0, Type: ps2.FullyRestrictedHex}
+	gate := psmap.Hex{X: 2, Y: 0, Type: ps2.FactionRestrictedHex}
+	data := psmap.Map{
+		HexSize: 200,
+		Size:    2048,
+		Regions: []psmap.Region{{RegionID: 1, Hexes: []psmap.Hex{open, blocked, gate}}},
+	}
+	state := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{1: ps2.TR}}
+
+	// center returns the pixel at the center of hex in a 512x512 image
+	const imageSize = 512
+	scale := float64(imageSize) / float64(data.Size)
+	center := func(hex psmap.Hex) image.Point {
+		x, y := psmap.LabelAnchor(psmap.Region{Hexes: []psmap.Hex{hex}}, data.HexSize).Point()
+		return image.Pt(int((x+float64(data.Size/2))*scale), int((y+float64(data.Size/2))*scale))
+	}
+
+	plain := image.NewRGBA(image.Rect(0, 0, imageSize, imageSize))
+	if err := psmap.Draw(plain, data, state); err != nil {
+		t.Fatal(err)
+	}
+	restricted := image.NewRGBA(image.Rect(0, 0, imageSize, imageSize))
+	if err := psmap.DrawWithOptions(restricted, data, state, psmap.DrawOptions{RestrictedHexes: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	filled := plain.RGBAAt(center(open).X, center(open).Y)
+	if filled.A == 0 {
+		t.Fatal("the region wasn't filled")
+	}
+	for _, hex := range []psmap.Hex{blocked, gate} {
+		if c := center(hex); plain.RGBAAt(c.X, c.Y) != filled {
+			t.Errorf("got %v at the center of %+v without options; want every hex filled like %v", plain.RGBAAt(c.X, c.Y), hex, filled)
+		}
+	}
+
+	if c := center(open); restricted.RGBAAt(c.X, c.Y) != filled {
+		t.Errorf("got %v at the center of an unrestricted hex; want %v", restricted.RGBAAt(c.X, c.Y), filled)
+	}
+	if c := center(blocked); restricted.RGBAAt(c.X, c.Y) != (color.RGBA{}) {
+		t.Errorf("got %v at the center of a fully restricted hex; want it cut out of the fill", restricted.RGBAAt(c.X, c.Y))
+	}
+
+	// the stripes only cover the faction restricted hex,
+	// and only part of it
+	inner := 200 * scale / 2 // the inner radius of a hex in pixels
+	gateCenter := center(gate)
+	striped, unchanged := 0, 0
+	for y := range imageSize {
+		for x := range imageSize {
+			d := math.Hypot(float64(x-gateCenter.X), float64(y-gateCenter.Y))
+			differs := restricted.RGBAAt(x, y) != plain.RGBAAt(x, y)
+			switch {
+			case d < inner-2 && differs:
+				striped++
+			case d < inner-2:
+				unchanged++
+			case differs && d > inner*1.3 && math.Hypot(float64(x-center(blocked).X), float64(y-center(blocked).Y)) > inner*1.3:
+				t.Fatalf("got pixel %d,%d changed outside the restricted hexes", x, y)
+			}
+		}
+	}
+	if striped == 0 || unchanged == 0 {
+		t.Errorf("got %d striped and %d unchanged pixels inside the faction restricted hex; want stripes with gaps", striped, unchanged)
+	}
+}
+
+func TestDrawWithOptionsZero(t *testing.T) {
+	data := psmap.Map{
+		HexSize: 200,
+		Size:    2048,
+		Regions: []psmap.Region{{RegionID: 1, Hexes: []psmap.Hex{{X: 0, Y: 0}, {X: 1, Y: 0, Type: ps2.FullyRestrictedHex}}}},
+	}
+	state := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{1: ps2.VS}}
+	plain := image.NewRGBA(image.Rect(0, 0, 128, 128))
+	withZero := image.NewRGBA(image.Rect(0, 0, 128, 128))
+	if err := psmap.Draw(plain, data, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := psmap.DrawWithOptions(withZero, data, state, psmap.DrawOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range plain.Pix {
+		if plain.Pix[i] != withZero.Pix[i] {
+			t.Fatal("got a different image from the zero DrawOptions than from Draw")
+		}
+	}
+
+	if err := psmap.DrawWithOptions(image.NewRGBA(image.Rect(0, 0, 128, 64)), data, state, psmap.DrawOptions{RestrictedHexes: true}); err == nil {
+		t.Error("expected an error for an image that isn't square")
+	}
+}
@@ -0,0 +1,103 @@
+package psmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// SchemaVersion is the version of the json encoding for [Map] and [State].
+// It will be incremented for any change that isn't backwards compatible.
+const SchemaVersion = 1
+
+// stateJSON is the json schema for State:
+//
+//	{
+//	    "version": 1,
+//	    "zone_id": 2,
+//	    "world_id": 1,
+//	    "captured_at": "2024-10-15T12:00:00Z",
+//	    "regions": [
+//	        {"region_id": 2201, "faction_id": 1},
+//	        {"region_id": 2202, "faction_id": 3}
+//	    ]
+//	}
+//
+// Regions are sorted by region_id so that the same state always produces the same output.
+type stateJSON struct {
+	Version    int                `json:"version"`
+	ZoneID     ps2.ZoneInstanceID `json:"zone_id"`
+	WorldID    ps2.WorldID        `json:"world_id,omitempty"`
+	CapturedAt time.Time          `json:"captured_at"`
+	Regions    []regionOwner      `json:"regions"`
+}
+
+type regionOwner struct {
+	RegionID  ps2.RegionID  `json:"region_id"`
+	FactionID ps2.FactionID `json:"faction_id"`
+}
+
+func (s State) MarshalJSON() ([]byte, error) {
+	v := stateJSON{
+		Version:    SchemaVersion,
+		ZoneID:     s.ZoneID,
+		WorldID:    s.WorldID,
+		CapturedAt: s.Timestamp,
+		Regions:    make([]regionOwner, 0, len(s.Territory)),
+	}
+	for r, f := range s.Territory {
+		v.Regions = append(v.Regions, regionOwner{r, f})
+	}
+	slices.SortFunc(v.Regions, func(a, b regionOwner) int { return int(a.RegionID) - int(b.RegionID) })
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes any schema version up to [SchemaVersion],
+// as well as the unversioned format written by older releases.
+func (s *State) UnmarshalJSON(data []byte) error {
+	var v stateJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("psmap.State.UnmarshalJSON: %w", err)
+	}
+	if v.Version == 0 {
+		return s.unmarshalLegacy(data)
+	}
+	if v.Version > SchemaVersion {
+		return fmt.Errorf("psmap.State.UnmarshalJSON: schema version %d is newer than the supported version %d", v.Version, SchemaVersion)
+	}
+	*s = State{
+		ZoneID:    v.ZoneID,
+		WorldID:   v.WorldID,
+		Timestamp: v.CapturedAt,
+		Territory: make(map[ps2.RegionID]ps2.FactionID, len(v.Regions)),
+	}
+	for _, r := range v.Regions {
+		s.Territory[r.RegionID] = r.FactionID
+	}
+	return nil
+}
+
+// unmarshalLegacy decodes the unversioned format written by older releases,
+// which was the default encoding of State.
+func (s *State) unmarshalLegacy(data []byte) error {
+	var legacy struct {
+		ZoneID    ps2.ZoneInstanceID
+		Timestamp time.Time
+		Territory map[ps2.RegionID]ps2.FactionID
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("psmap.State.UnmarshalJSON: %w", err)
+	}
+	if legacy.Territory == nil {
+		legacy.Territory = make(map[ps2.RegionID]ps2.FactionID)
+	}
+	*s = State{
+		ZoneID:    legacy.ZoneID,
+		Timestamp: legacy.Timestamp,
+		Territory: legacy.Territory,
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package psmap_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestStateJSON(t *testing.T) {
+	state := psmap.State{
+		ZoneID:    ps2.ZoneInstanceID(ps2.Indar),
+		WorldID:   ps2.Osprey,
+		Timestamp: time.Date(2024, 10, 15, 12, 0, 0, 0, time.UTC),
+		Territory: map[ps2.RegionID]ps2.FactionID{2202: ps2.TR, 2201: ps2.VS},
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":1,"zone_id":2,"world_id":1,"captured_at":"2024-10-15T12:00:00Z","regions":[{"region_id":2201,"faction_id":1},{"region_id":2202,"faction_id":3}]}`
+	if string(b) != want {
+		t.Errorf("expected %s; got %s", want, b)
+	}
+
+	var got psmap.State
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("expected round trip to give %+v; got %+v", state, got)
+	}
+
+	legacy := `{"ZoneID":2,"Timestamp":"2024-10-15T12:00:00Z","Territory":{"2201":1,"2202":3}}`
+	got = psmap.State{}
+	if err := json.Unmarshal([]byte(legacy), &got); err != nil {
+		t.Fatal(err)
+	}
+	state.WorldID = 0
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("expected legacy format to give %+v; got %+v", state, got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"version":99,"regions":[]}`), &got); err == nil {
+		t.Errorf("expected an error for an unsupported schema version")
+	}
+}
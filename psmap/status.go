@@ -0,0 +1,206 @@
+package psmap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// AlertStatus is the alert information drawn by [DrawAlertStatus].
+type AlertStatus struct {
+	// Name is the name of the alert, like "Indar Superiority".
+	// Names are drawn with a fixed-size ASCII font,
+	// so other characters don't render and long names are cut short.
+	Name string
+
+	// Remaining is the time left until the alert ends.
+	// No timer is drawn when it is 0 or less.
+	Remaining time.Duration
+
+	// Territory is the percentage of territory owned by each faction, as in [Summary].
+	// A nil Territory draws no territory bar.
+	Territory map[ps2.FactionID]float32
+}
+
+// StatusPanelColor is the background of the panel drawn by [DrawAlertStatus].
+var StatusPanelColor = color.RGBA{0x00, 0x00, 0x00, 0xb0}
+
+// textFace is the font used for text on maps.
+// It only covers ASCII, which is enough for numbers and English alert names.
+var textFace = basicfont.Face7x13
+
+// DrawAlertStatus draws a panel across the bottom of img with the name of the alert,
+// the time remaining, and a bar split between factions by their share of the territory,
+// labeled with their percentages.
+// Text is drawn at one font pixel per image pixel for every 512 pixels of image width,
+// so it's only legible on images of at least 256 pixels.
+func DrawAlertStatus(img draw.Image, s AlertStatus) error {
+	if err := checkCanvas(img); err != nil {
+		return fmt.Errorf("psmap.DrawAlertStatus: %w", err)
+	}
+	hasText := s.Name != "" || s.Remaining > 0
+	if !hasText && s.Territory == nil {
+		return nil
+	}
+	b := img.Bounds()
+	k := max(b.Dx()/512, 1)
+	lineHeight := textFace.Height * k
+	pad := 4 * k
+	height := pad
+	if hasText {
+		height += lineHeight + pad
+	}
+	if s.Territory != nil {
+		height += lineHeight + 2*k + pad
+	}
+	panel := image.Rect(b.Min.X, b.Max.Y-height, b.Max.X, b.Max.Y)
+	draw.Draw(img, panel, image.NewUniform(StatusPanelColor), image.Point{}, draw.Over)
+
+	y := panel.Min.Y + pad
+	if hasText {
+		var timer string
+		if s.Remaining > 0 {
+			timer = formatRemaining(s.Remaining)
+			drawText(img, image.Pt(panel.Max.X-pad-textWidth(timer)*k, y), timer, color.White, k)
+		}
+		// the name gets whatever space the timer doesn't use
+		room := (panel.Dx() - 2*pad) / (textFace.Advance * k)
+		if timer != "" {
+			room -= len(timer) + 1
+		}
+		drawText(img, image.Pt(panel.Min.X+pad, y), truncate(s.Name, room), color.White, k)
+		y += lineHeight + pad
+	}
+	if s.Territory != nil {
+		bar := image.Rect(panel.Min.X+pad, y, panel.Max.X-pad, y+lineHeight+2*k)
+		drawTerritoryBar(img, bar, s.Territory, k)
+	}
+	return nil
+}
+
+// drawTerritoryBar fills bar with a segment for each faction sized by its share of territory,
+// with the percentage drawn inside segments that are wide enough to hold it.
+func drawTerritoryBar(img draw.Image, bar image.Rectangle, territory map[ps2.FactionID]float32, k int) {
+	factions := []ps2.FactionID{ps2.VS, ps2.NC, ps2.TR}
+	var total float64
+	for _, faction := range factions {
+		total += max(float64(territory[faction]), 0)
+	}
+	if total == 0 {
+		return
+	}
+	x := float64(bar.Min.X)
+	for _, faction := range factions {
+		percent := max(float64(territory[faction]), 0)
+		if percent == 0 {
+			continue
+		}
+		width := float64(bar.Dx()) * percent / total
+		segment := image.Rect(int(math.Round(x)), bar.Min.Y, int(math.Round(x+width)), bar.Max.Y)
+		draw.Draw(img, segment, image.NewUniform(FactionDrawColors[faction]), image.Point{}, draw.Over)
+		x += width
+
+		// percentages are floored to match the in-game numbers
+		label := fmt.Sprintf("%d%%", int(percent))
+		if w := textWidth(label) * k; w+2*k <= segment.Dx() {
+			at := image.Pt(segment.Min.X+(segment.Dx()-w)/2, segment.Min.Y+k)
+			drawText(img, at, label, color.White, k)
+		}
+	}
+}
+
+// DrawWarpgates draws an arrow at each warpgate of data pointing into the continent,
+// filled with the color of the faction that owns it.
+// Warpgates without facility coordinates are skipped.
+func DrawWarpgates(img draw.Image, data Map, mapstate owner) error {
+	if err := checkCanvas(img); err != nil {
+		return fmt.Errorf("psmap.DrawWarpgates: %w", err)
+	}
+	transform, scale := canvasTransform(img, data)
+	length := max(float64(5*data.HexSize)*scale, 8)
+	cx, cy := float64(img.Bounds().Dx())/2, float64(img.Bounds().Dy())/2
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.SetStrokeColor(color.White)
+	gc.SetLineWidth(max(6*scale, 1))
+	for _, region := range data.Regions {
+		if region.FacilityTypeID != ps2.Warpgate || (region.FacilityX == 0 && region.FacilityY == 0) {
+			continue
+		}
+		x, y := transform(region)
+		dx, dy := cx-x, cy-y
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			continue
+		}
+		// unit vector toward the center, and its perpendicular
+		dx, dy = dx/d, dy/d
+		px, py := -dy, dx
+
+		fc := color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+		if f := mapstate.Owner(region.RegionID); f != ps2.None && int(f) < len(FactionDrawColors) {
+			fc = FactionDrawColors[f]
+		}
+		gc.SetFillColor(fc)
+		gc.BeginPath()
+		gc.MoveTo(x+dx*length, y+dy*length)
+		gc.LineTo(x+px*length/2, y+py*length/2)
+		gc.LineTo(x+dx*length/4, y+dy*length/4)
+		gc.LineTo(x-px*length/2, y-py*length/2)
+		gc.Close()
+		gc.FillStroke()
+	}
+	return nil
+}
+
+// drawText draws s with the top left corner at pt,
+// scaled up so that each font pixel covers k x k image pixels.
+func drawText(img draw.Image, pt image.Point, s string, c color.Color, k int) {
+	if s == "" {
+		return
+	}
+	text := image.NewRGBA(image.Rect(0, 0, textWidth(s), textFace.Height))
+	d := font.Drawer{
+		Dst:  text,
+		Src:  image.NewUniform(c),
+		Face: textFace,
+		Dot:  fixed.P(0, textFace.Ascent),
+	}
+	d.DrawString(s)
+	dst := image.Rect(pt.X, pt.Y, pt.X+text.Bounds().Dx()*k, pt.Y+text.Bounds().Dy()*k)
+	draw.NearestNeighbor.Scale(img, dst, text, text.Bounds(), draw.Over, nil)
+}
+
+// textWidth returns the width of s in font pixels.
+func textWidth(s string) int {
+	return font.MeasureString(textFace, s).Ceil()
+}
+
+// truncate shortens s to n characters, ending with "..." when anything was cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return ""
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// formatRemaining formats d like "1h 05m left", or "12m left" when less than an hour remains.
+func formatRemaining(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm left", max(int(d.Minutes()), 1))
+	}
+	return fmt.Sprintf("%dh %02dm left", int(d.Hours()), int(d.Minutes())%60)
+}
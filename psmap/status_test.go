@@ -0,0 +1,50 @@
+package psmap_test
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestDrawAlertAnnotations(t *testing.T) {
+	data := psmap.Map{
+		Size:    1024,
+		HexSize: 50,
+		Regions: []psmap.Region{
+			{RegionID: 1, FacilityTypeID: ps2.Warpgate, FacilityX: -300, Hexes: []psmap.Hex{{X: -6, Y: 0}}},
+			{RegionID: 2, Hexes: []psmap.Hex{{X: 0, Y: 0}}},
+		},
+	}
+	mapstate := psmap.State{ZoneID: ps2.ZoneInstanceID(ps2.Indar), Territory: map[ps2.RegionID]ps2.FactionID{1: ps2.TR, 2: ps2.VS}}
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	err := psmap.DrawAnnotations(img, data, mapstate, psmap.Annotations{
+		Warpgates: true,
+		Alert: &psmap.AlertStatus{
+			Name:      "Indar Superiority",
+			Remaining: 75 * time.Minute,
+			Territory: map[ps2.FactionID]float32{ps2.VS: 50, ps2.TR: 50},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the warpgate is drawn at (53, 128) and its arrow points right, toward the center
+	if got, want := img.RGBAAt(80, 128), psmap.FactionDrawColors[ps2.TR]; got != want {
+		t.Errorf("got %v inside the warpgate arrow; want %v", got, want)
+	}
+	if got := img.RGBAAt(40, 128); got.A != 0 {
+		t.Errorf("got %v behind the warpgate; want transparent", got)
+	}
+	// the territory bar is the last thing above the bottom padding
+	left, right := img.RGBAAt(8, 250), img.RGBAAt(247, 250)
+	if left.B <= left.R || right.R <= right.B {
+		t.Errorf("got %v and %v at the ends of the territory bar; want VS and TR colors", left, right)
+	}
+	if got := img.RGBAAt(128, 10); got.A != 0 {
+		t.Errorf("got %v at the top of the map; want no panel", got)
+	}
+}
@@ -1,84 +1,164 @@
 package psmap
 
 import (
-	"errors"
+	"bufio"
+	"fmt"
+	"html"
 	"io"
-	"text/template"
+	"strconv"
+	"strings"
 
 	"github.com/Travis-Britz/ps2"
 	"github.com/Travis-Britz/ps2/census"
 )
 
-const svgTemplate = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 8192 8192">
-<style>
-	.NC {fill: #004b80ff;}
-	.TR {fill: #9e0b0fff;}
-	.VS {fill: #440e62ff;}
-	.None {fill: #00000009;}
-	.cutoff {filter: brightness(0.6);}
-	polygon:hover {
-		filter: brightness(1.5);
-	}
-	polygon {
-		transition: 0.4s;
-		stroke-width: 3px;
-		stroke: white;
-	}
-</style>
-{{if (ne .TerrainImageURL "")}}
-	<image x="0" y="0" width="8192" height="8192" href="{{ .TerrainImageURL }}"/>
-{{end}}
-{{range .Regions }}
-	<g id="region{{ .ID }}" class="">
-		<polygon points="{{range .Outline}}{{.X}},{{.Y}} {{end}}" class="{{.Faction}}{{if .Cutoff}} cutoff{{end}}" style="fill:lime;stroke:purple;stroke-width:3"/>
-	</g>
-{{end}}
-</svg>`
-
-var svgTmpl = template.Must(template.New("mapsvg").Parse(svgTemplate))
-
-func Svg(zone ps2.ZoneID, state State) io.WriterTo {
-	svg := &svgZone{}
-
-	return svg
-}
+// DefaultSVGStyle is the stylesheet used by [DrawSVG] when [SVGOptions.Style] is empty.
+// It matches the colors of [Draw].
+//
+// Every element has classes that can be targeted by a custom stylesheet:
+// regions are paths with the class "region",
+// the owning faction ("None", "VS", "NC", "TR", or "NSO"),
+// and "cutoff" when the region is cut off from its warpgate;
+// lattice links are lines with the class "link" and either the faction owning both ends or "contested".
+const DefaultSVGStyle = `
+.region { stroke: #ffffff; stroke-width: 4; fill-rule: evenodd; }
+.region.None { fill: none; }
+.region.VS { fill: rgba(68, 14, 98, 0.4); }
+.region.NC { fill: rgba(0, 75, 128, 0.4); }
+.region.TR { fill: rgba(158, 11, 15, 0.4); }
+.region.NSO { fill: rgba(128, 128, 128, 0.4); }
+.region.cutoff { filter: brightness(0.5); }
+.link { stroke: #ffffff; stroke-width: 6; }
+.link.contested { stroke: #ffcc00; stroke-dasharray: 24 12; }
+`
 
-func (svg svgZone) WriteTo(w io.Writer) (int64, error) {
-	return 0, errors.New("not implemented")
-	// counter := &counter{w: w}
-	// err := svgTmpl.Execute(counter, svg)
-	// return int64(counter.n), err
-}
+// SVGOptions enables optional features of [DrawSVG].
+type SVGOptions struct {
+	// TerrainImageURL is linked as a background image covering the whole map when it isn't empty.
+	TerrainImageURL string
 
-type counter struct {
-	n int
-	w io.Writer
-}
+	// Width sets the width and height attributes of the svg element.
+	// When it's 0 the image scales to fit its container.
+	Width int
 
-func (c *counter) Write(p []byte) (n int, err error) {
-	n, err = c.w.Write(p)
-	c.n += n
-	return
+	// Style replaces [DefaultSVGStyle].
+	Style string
+
+	// RestrictedHexes cuts fully restricted hex tiles out of the region paths like [DrawOptions.RestrictedHexes].
+	RestrictedHexes bool
 }
 
-type svgMapregion struct {
-	ID           ps2.RegionID
-	Name         string
-	FacilityID   ps2.FacilityID
-	FacilityType ps2.FacilityTypeID
-	FacilityX    float64
-	FacilityY    float64
-	Faction      ps2.FactionID
-	Cutoff       bool
-	Outline      []struct {
-		X int64
-		Y int64
+// DrawSVG writes the map regions and lattice links to w as an SVG image,
+// so that web frontends can style and animate regions client-side.
+//
+// The viewBox is the full continent size with 0,0 at the upper left, the same as the LOD0 terrain image.
+// Regions have the id "region-{RegionID}" and links have the id "link-{FacilityA}-{FacilityB}",
+// and both have data attributes with their IDs.
+// See [DefaultSVGStyle] for the classes.
+func DrawSVG(w io.Writer, data Map, mapstate owner, opts SVGOptions) error {
+	if data.Size <= 0 {
+		return fmt.Errorf("psmap.DrawSVG: map size must be positive; given: %d", data.Size)
+	}
+	summary, err := Summarize(data, mapstate)
+	if err != nil {
+		return fmt.Errorf("psmap.DrawSVG: summary failed: %w", err)
+	}
+	style := opts.Style
+	if style == "" {
+		style = DefaultSVGStyle
 	}
+	transform := func(p Point) (string, string) {
+		x, y := p.Point()
+		return formatSVGNumber(x + float64(data.Size/2)), formatSVGNumber(y + float64(data.Size/2))
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d"`, data.Size, data.Size)
+	if opts.Width > 0 {
+		fmt.Fprintf(b, ` width="%d" height="%d"`, opts.Width, opts.Width)
+	}
+	b.WriteString(">\n<style><![CDATA[")
+	b.WriteString(style)
+	b.WriteString("]]></style>\n")
+	if opts.TerrainImageURL != "" {
+		fmt.Fprintf(b, "<image x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" href=\"%s\"/>\n", data.Size, data.Size, html.EscapeString(opts.TerrainImageURL))
+	}
+
+	b.WriteString("<g class=\"regions\">\n")
+	for _, region := range data.Regions {
+		outline := Outline(region.Hexes, data.HexSize)
+		if len(outline) == 0 {
+			continue
+		}
+		var d strings.Builder
+		path := func(points []Point) {
+			for i, p := range points {
+				x, y := transform(p)
+				if i == 0 {
+					d.WriteString("M")
+				} else {
+					d.WriteString(" L")
+				}
+				d.WriteString(x + " " + y)
+			}
+			d.WriteString(" Z")
+		}
+		path(outline)
+		if opts.RestrictedHexes {
+			for _, hex := range region.Hexes {
+				if hex.Type == ps2.FullyRestrictedHex {
+					corners := hexCorners(hex, data.HexSize)
+					d.WriteString(" ")
+					path(corners[:])
+				}
+			}
+		}
+		class := "region " + mapstate.Owner(region.RegionID).String()
+		if summary.Cutoff[region.RegionID] {
+			class += " cutoff"
+		}
+		fmt.Fprintf(b, "<path id=\"region-%d\" class=\"%s\" data-region=\"%d\"", region.RegionID, class, region.RegionID)
+		if region.FacilityID != 0 {
+			fmt.Fprintf(b, " data-facility=\"%d\"", region.FacilityID)
+		}
+		fmt.Fprintf(b, " d=\"%s\"><title>%s</title></path>\n", d.String(), html.EscapeString(region.Name))
+	}
+	b.WriteString("</g>\n")
+
+	facilities := make(map[ps2.FacilityID]Region, len(data.Regions))
+	for _, region := range data.Regions {
+		if region.FacilityID != 0 {
+			facilities[region.FacilityID] = region
+		}
+	}
+	b.WriteString("<g class=\"lattice\">\n")
+	for _, link := range data.Links {
+		a, foundA := facilities[link.A]
+		z, foundZ := facilities[link.B]
+		// facilities at exactly 0,0 are missing their coordinates
+		if !foundA || !foundZ || (a.FacilityX == 0 && a.FacilityY == 0) || (z.FacilityX == 0 && z.FacilityY == 0) {
+			continue
+		}
+		class := "link contested"
+		if owner := mapstate.Owner(a.RegionID); owner == mapstate.Owner(z.RegionID) {
+			class = "link " + owner.String()
+		}
+		x1, y1 := transform(a)
+		x2, y2 := transform(z)
+		fmt.Fprintf(b, "<line id=\"link-%d-%d\" class=\"%s\" data-facility-a=\"%d\" data-facility-b=\"%d\" x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\"/>\n",
+			link.A, link.B, class, link.A, link.B, x1, y1, x2, y2)
+	}
+	b.WriteString("</g>\n</svg>\n")
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("psmap.DrawSVG: %w", err)
+	}
+	return nil
 }
-type svgZone struct {
-	Regions         []svgMapregion
-	Links           []Link
-	TerrainImageURL string
+
+// formatSVGNumber formats a coordinate with one decimal place,
+// which is finer than a pixel of the LOD0 terrain and keeps paths short.
+func formatSVGNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', 1, 64)
 }
 
 // https://census.daybreakgames.com/get/ps2:v2/zone?zone_id=2&c:join=map_region^list:1^inject_at:regions^hide:zone_id(map_hex^list:1^inject_at:hexes^hide:zone_id'map_region_id)&c:lang=en
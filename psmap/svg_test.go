@@ -0,0 +1,87 @@
+package psmap_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestDrawSVG(t *testing.T) {
+	//	wg1 - 10 - wg2
+	//	      |
+	//	      11
+	region := func(id int, typ ps2.FacilityTypeID, x, y float64, hexes ...psmap.Hex) psmap.Region {
+		return psmap.Region{RegionID: ps2.RegionID(id), Name: "<region>", FacilityID: ps2.FacilityID(id), FacilityTypeID: typ, FacilityX: x, FacilityY: y, Hexes: hexes}
+	}
+	link := func(a, b int) psmap.Link { return psmap.Link{A: ps2.FacilityID(a), B: ps2.FacilityID(b)} }
+	data := psmap.Map{
+		Size:    1024,
+		HexSize: 50,
+		Regions: []psmap.Region{
+			region(1, ps2.Warpgate, -200, 0, psmap.Hex{X: -4, Y: 0}),
+			region(2, ps2.Warpgate, 200, 0, psmap.Hex{X: 4, Y: 0}),
+			region(10, 0, 10, 10, psmap.Hex{X: 0, Y: 0}, psmap.Hex{X: 1, Y: 0}),
+			region(11, 0, 0, 0, psmap.Hex{X: 0, Y: -2}), // missing coordinates
+		},
+		Links: []psmap.Link{link(1, 10), link(10, 2), link(10, 11)},
+	}
+	state := psmap.State{Territory: map[ps2.RegionID]ps2.FactionID{1: VS, 2: NC, 10: VS, 11: NC}}
+
+	var buf bytes.Buffer
+	if err := psmap.DrawSVG(&buf, data, state, psmap.SVGOptions{Width: 512}); err != nil {
+		t.Fatal(err)
+	}
+
+	var svg struct {
+		Width  int `xml:"width,attr"`
+		Groups []struct {
+			Class string `xml:"class,attr"`
+			Paths []struct {
+				ID    string `xml:"id,attr"`
+				Class string `xml:"class,attr"`
+				D     string `xml:"d,attr"`
+				Title string `xml:"title"`
+			} `xml:"path"`
+			Lines []struct {
+				ID    string `xml:"id,attr"`
+				Class string `xml:"class,attr"`
+			} `xml:"line"`
+		} `xml:"g"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &svg); err != nil {
+		t.Fatalf("invalid svg: %v\n%s", err, buf.String())
+	}
+	if svg.Width != 512 || len(svg.Groups) != 2 {
+		t.Fatalf("unexpected svg structure:\n%s", buf.String())
+	}
+
+	classes := map[string]string{}
+	for _, p := range svg.Groups[0].Paths {
+		classes[p.ID] = p.Class
+		if p.D == "" || p.Title != "<region>" {
+			t.Errorf("%s: got d %q and title %q", p.ID, p.D, p.Title)
+		}
+	}
+	for _, l := range svg.Groups[1].Lines {
+		classes[l.ID] = l.Class
+	}
+	want := map[string]string{
+		"region-1":  "region VS",
+		"region-2":  "region NC",
+		"region-10": "region VS",
+		"region-11": "region NC cutoff",
+		"link-1-10": "link VS",
+		"link-10-2": "link contested",
+	}
+	if len(classes) != len(want) {
+		t.Errorf("got elements %v; want %v", classes, want)
+	}
+	for id, class := range want {
+		if classes[id] != class {
+			t.Errorf("%s: got class %q; want %q", id, classes[id], class)
+		}
+	}
+}
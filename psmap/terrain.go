@@ -0,0 +1,240 @@
+package psmap
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Terrain is a map terrain image that can be read in pieces,
+// so that cropping a region doesn't require the whole terrain image in memory.
+// The LOD0 terrain of a continent is 8192x8192, which uses at least 256MB once decoded.
+type Terrain interface {
+	// Bounds returns the bounds of the full terrain image,
+	// for use with functions like [Bounds] and [LocBounds].
+	Bounds() image.Rectangle
+
+	// ReadRect returns the part of the terrain inside r.
+	// The returned image has the same bounds as r,
+	// and areas of r outside of the terrain are transparent.
+	ReadRect(r image.Rectangle) (image.Image, error)
+}
+
+// ImageTerrain is a Terrain for an image that's already in memory,
+// such as a small embedded terrain image.
+func ImageTerrain(img image.Image) Terrain {
+	return imageTerrain{img}
+}
+
+type imageTerrain struct {
+	image.Image
+}
+
+func (t imageTerrain) ReadRect(r image.Rectangle) (image.Image, error) {
+	img := image.NewRGBA(r)
+	draw.Draw(img, r, t.Image, r.Min, draw.Src)
+	return img, nil
+}
+
+// tileIndexFile describes the tiles in a tile directory.
+// It's written last so that directories interrupted while being written are never opened.
+const tileIndexFile = "tiles.json"
+
+type tileIndex struct {
+	Bounds   image.Rectangle `json:"bounds"`
+	TileSize int             `json:"tile_size"`
+}
+
+// WriteTiles decodes a terrain image from src,
+// such as one of the LOD PNG files,
+// and writes it to dir as square PNG tiles of tileSize for use with [OpenTiles].
+// This is the only step that needs the full image in memory,
+// so it only has to happen once for each terrain image.
+func WriteTiles(dir string, src io.Reader, tileSize int) error {
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return fmt.Errorf("psmap.WriteTiles: %w", err)
+	}
+	if err := WriteImageTiles(dir, img, tileSize); err != nil {
+		return fmt.Errorf("psmap.WriteTiles: %w", err)
+	}
+	return nil
+}
+
+// WriteImageTiles is the same as [WriteTiles] for an image that's already decoded.
+func WriteImageTiles(dir string, img image.Image, tileSize int) error {
+	if tileSize <= 0 {
+		return errors.New("psmap.WriteImageTiles: tile size must be positive")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("psmap.WriteImageTiles: %w", err)
+	}
+	// a previous index would describe tiles that are about to be replaced
+	if err := os.Remove(filepath.Join(dir, tileIndexFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("psmap.WriteImageTiles: %w", err)
+	}
+
+	bounds := img.Bounds()
+	tile := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+	for col := 0; col*tileSize < bounds.Dx(); col++ {
+		for row := 0; row*tileSize < bounds.Dy(); row++ {
+			r := tileRect(bounds, tileSize, image.Pt(col, row))
+			tile.Rect = r
+			draw.Draw(tile, r, img, r.Min, draw.Src)
+			if err := writePNG(filepath.Join(dir, tileName(image.Pt(col, row))), tile); err != nil {
+				return fmt.Errorf("psmap.WriteImageTiles: %w", err)
+			}
+		}
+	}
+
+	index, err := json.Marshal(tileIndex{Bounds: bounds, TileSize: tileSize})
+	if err != nil {
+		return fmt.Errorf("psmap.WriteImageTiles: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, tileIndexFile), index, 0640); err != nil {
+		return fmt.Errorf("psmap.WriteImageTiles: %w", err)
+	}
+	return nil
+}
+
+func writePNG(name string, img image.Image) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// TileCache is a Terrain read from a directory of tiles written by [WriteTiles].
+// Tiles are decoded when a read first needs them,
+// and the most recently used tiles are kept in memory.
+// It's safe for concurrent use.
+type TileCache struct {
+	dir      string
+	bounds   image.Rectangle
+	tileSize int
+	maxTiles int
+
+	mu    sync.Mutex
+	tiles map[image.Point]*list.Element
+	order *list.List // order holds *cachedTile, most recently used first
+}
+
+type cachedTile struct {
+	at  image.Point
+	img image.Image
+}
+
+// OpenTiles opens a tile directory written by [WriteTiles].
+// At most maxTiles decoded tiles are kept in memory;
+// a value less than 1 keeps 16.
+func OpenTiles(dir string, maxTiles int) (*TileCache, error) {
+	b, err := os.ReadFile(filepath.Join(dir, tileIndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("psmap.OpenTiles: %w", err)
+	}
+	var index tileIndex
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("psmap.OpenTiles: %w", err)
+	}
+	if index.TileSize <= 0 || index.Bounds.Empty() {
+		return nil, fmt.Errorf("psmap.OpenTiles: invalid tile index in %s", dir)
+	}
+	if maxTiles < 1 {
+		maxTiles = 16
+	}
+	return &TileCache{
+		dir:      dir,
+		bounds:   index.Bounds,
+		tileSize: index.TileSize,
+		maxTiles: maxTiles,
+		tiles:    make(map[image.Point]*list.Element),
+		order:    list.New(),
+	}, nil
+}
+
+// Bounds returns the bounds of the full terrain image.
+func (c *TileCache) Bounds() image.Rectangle {
+	return c.bounds
+}
+
+// ReadRect returns the part of the terrain inside r,
+// decoding only the tiles that overlap it.
+func (c *TileCache) ReadRect(r image.Rectangle) (image.Image, error) {
+	img := image.NewRGBA(r)
+	area := r.Intersect(c.bounds)
+	if area.Empty() {
+		return img, nil
+	}
+	origin := c.bounds.Min
+	first := area.Min.Sub(origin).Div(c.tileSize)
+	last := area.Max.Sub(origin).Sub(image.Pt(1, 1)).Div(c.tileSize)
+	for col := first.X; col <= last.X; col++ {
+		for row := first.Y; row <= last.Y; row++ {
+			tile, err := c.tile(image.Pt(col, row))
+			if err != nil {
+				return nil, fmt.Errorf("psmap.TileCache.ReadRect: %w", err)
+			}
+			// decoded tiles start at 0,0
+			at := tileRect(c.bounds, c.tileSize, image.Pt(col, row))
+			dst := at.Intersect(area)
+			draw.Draw(img, dst, tile, dst.Min.Sub(at.Min), draw.Src)
+		}
+	}
+	return img, nil
+}
+
+// tile returns the decoded tile at column and row at,
+// with its bounds starting at 0,0.
+func (c *TileCache) tile(at image.Point) (image.Image, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.tiles[at]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*cachedTile).img, nil
+	}
+
+	f, err := os.Open(filepath.Join(c.dir, tileName(at)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", f.Name(), err)
+	}
+	if want := tileRect(c.bounds, c.tileSize, at).Size(); img.Bounds().Size() != want {
+		return nil, fmt.Errorf("tile %s has size %v; expected %v", f.Name(), img.Bounds().Size(), want)
+	}
+
+	c.tiles[at] = c.order.PushFront(&cachedTile{at: at, img: img})
+	for c.order.Len() > c.maxTiles {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.tiles, oldest.Value.(*cachedTile).at)
+	}
+	return img, nil
+}
+
+// tileRect returns the bounds of the tile at column and row at.
+// Tiles on the right and bottom edges are smaller when the terrain isn't a multiple of tileSize.
+func tileRect(bounds image.Rectangle, tileSize int, at image.Point) image.Rectangle {
+	min := bounds.Min.Add(at.Mul(tileSize))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(tileSize, tileSize))}.Intersect(bounds)
+}
+
+func tileName(at image.Point) string {
+	return fmt.Sprintf("%d_%d.png", at.X, at.Y)
+}
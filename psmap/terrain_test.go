@@ -0,0 +1,59 @@
+package psmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+func TestTileCache(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 70))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 70; y++ {
+			src.Set(x, y, color.RGBA{uint8(x), uint8(y), uint8(x ^ y), 0xff})
+		}
+	}
+	dir := t.TempDir()
+	if err := psmap.WriteImageTiles(dir, src, 32); err != nil {
+		t.Fatal(err)
+	}
+	tiles, err := psmap.OpenTiles(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiles.Bounds() != src.Bounds() {
+		t.Fatalf("got bounds %v; want %v", tiles.Bounds(), src.Bounds())
+	}
+
+	tt := map[string]image.Rectangle{
+		"inside one tile":    image.Rect(1, 1, 20, 20),
+		"across tiles":       image.Rect(10, 20, 90, 50),
+		"edge tiles":         image.Rect(60, 60, 100, 70),
+		"whole terrain":      src.Bounds(),
+		"partly outside":     image.Rect(-10, -10, 40, 40),
+		"completely outside": image.Rect(200, 200, 210, 210),
+	}
+	for name, r := range tt {
+		t.Run(name, func(t *testing.T) {
+			for _, terrain := range []psmap.Terrain{tiles, psmap.ImageTerrain(src)} {
+				img, err := terrain.ReadRect(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if img.Bounds() != r {
+					t.Fatalf("%T: got bounds %v; want %v", terrain, img.Bounds(), r)
+				}
+				for x := r.Min.X; x < r.Max.X; x++ {
+					for y := r.Min.Y; y < r.Max.Y; y++ {
+						want := color.RGBAModel.Convert(src.At(x, y))
+						if got := color.RGBAModel.Convert(img.At(x, y)); got != want {
+							t.Fatalf("%T: pixel %d,%d is %v; want %v", terrain, x, y, got, want)
+						}
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package ps2
+
+// Well-known map regions.
+//
+// The warpgate declarations can be checked against census with:
+//
+//	go run ./cmd/staticdata -warpgates
+const (
+	IndarNorthernWarpgate   RegionID = 2201
+	IndarWesternWarpgate    RegionID = 2202
+	IndarEasternWarpgate    RegionID = 2203
+	HossinWesternWarpgate   RegionID = 4230
+	HossinEasternWarpgate   RegionID = 4240
+	HossinSouthernWarpgate  RegionID = 4250
+	AmerishWesternWarpgate  RegionID = 6001
+	AmerishEasternWarpgate  RegionID = 6002
+	AmerishSouthernWarpgate RegionID = 6003
+	EsamirNorthernWarpgate  RegionID = 18029
+	EsamirSouthernWarpgate  RegionID = 18030
+	EsamirEasternWarpgate   RegionID = 18062
+	OshurNortheastWarpgate  RegionID = 18303
+	OshurNorthwestWarpgate  RegionID = 18304
+	OshurSouthernWarpgate   RegionID = 18305
+
+	// OshurVastExpanse is a line of hex tiles that circles the entire continent of Oshur
+	// and has no gameplay relevance.
+	// It's the only region with empty tiles in the middle.
+	OshurVastExpanse RegionID = 18347
+)
+
+// Compass is the direction of a map feature from the center of its continent.
+type Compass uint8
+
+const (
+	CompassNone Compass = iota
+	CompassNorth
+	CompassNortheast
+	CompassEast
+	CompassSoutheast
+	CompassSouth
+	CompassSouthwest
+	CompassWest
+	CompassNorthwest
+)
+
+var compassNames = [...]string{"", "north", "northeast", "east", "southeast", "south", "southwest", "west", "northwest"}
+var compassArrows = [...]string{"", "⬆", "↗", "➡", "↘", "⬇", "↙", "⬅", "↖"}
+
+func (c Compass) String() string {
+	if int(c) >= len(compassNames) {
+		return ""
+	}
+	return compassNames[c]
+}
+
+// Arrow returns an arrow symbol pointing in direction c, like "⬆" for north.
+func (c Compass) Arrow() string {
+	if int(c) >= len(compassArrows) {
+		return ""
+	}
+	return compassArrows[c]
+}
+
+// WarpgateRegion describes the warpgate region of a continent.
+type WarpgateRegion struct {
+	RegionID    RegionID
+	ContinentID ContinentID
+	Position    Compass
+	Name        string // Name is the English facility name, like "Indar Northern Warpgate".
+}
+
+// WarpgateRegions lists the warpgates of each continent with warpgates,
+// in region order.
+var WarpgateRegions = map[ContinentID][]WarpgateRegion{
+	Indar: {
+		{IndarNorthernWarpgate, Indar, CompassNorth, "Indar Northern Warpgate"},
+		{IndarWesternWarpgate, Indar, CompassWest, "Indar Western Warpgate"},
+		{IndarEasternWarpgate, Indar, CompassEast, "Indar Eastern Warpgate"},
+	},
+	Hossin: {
+		{HossinWesternWarpgate, Hossin, CompassWest, "Hossin Western Warpgate"},
+		{HossinEasternWarpgate, Hossin, CompassEast, "Hossin Eastern Warpgate"},
+		{HossinSouthernWarpgate, Hossin, CompassSouth, "Hossin Southern Warpgate"},
+	},
+	Amerish: {
+		{AmerishWesternWarpgate, Amerish, CompassWest, "Amerish Western Warpgate"},
+		{AmerishEasternWarpgate, Amerish, CompassEast, "Amerish Eastern Warpgate"},
+		{AmerishSouthernWarpgate, Amerish, CompassSouth, "Amerish Southern Warpgate"},
+	},
+	Esamir: {
+		{EsamirNorthernWarpgate, Esamir, CompassNorth, "Esamir Northern Warpgate"},
+		{EsamirSouthernWarpgate, Esamir, CompassSouth, "Esamir Southern Warpgate"},
+		{EsamirEasternWarpgate, Esamir, CompassEast, "Esamir Eastern Warpgate"},
+	},
+	Oshur: {
+		{OshurNortheastWarpgate, Oshur, CompassNortheast, "Oshur Northeast Warpgate"},
+		{OshurNorthwestWarpgate, Oshur, CompassNorthwest, "Oshur Northwest Warpgate"},
+		{OshurSouthernWarpgate, Oshur, CompassSouth, "Oshur Southern Warpgate"},
+	},
+}
+
+// LookupWarpgate returns the warpgate for region,
+// or false if region isn't a known warpgate.
+func LookupWarpgate(region RegionID) (WarpgateRegion, bool) {
+	for _, gates := range WarpgateRegions {
+		for _, w := range gates {
+			if w.RegionID == region {
+				return w, true
+			}
+		}
+	}
+	return WarpgateRegion{}, false
+}
+
+// IsWarpgateRegion reports whether region is a known warpgate.
+func IsWarpgateRegion(region RegionID) bool {
+	_, ok := LookupWarpgate(region)
+	return ok
+}
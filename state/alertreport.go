@@ -0,0 +1,260 @@
+package state
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+const (
+	// alertReportOutfits is the number of outfits listed in an AlertReport.
+	alertReportOutfits = 10
+
+	// alertTimelineLimit caps the territory snapshots kept for an alert.
+	// A busy 90 minute alert has a few hundred captures.
+	alertTimelineLimit = 1000
+)
+
+// AlertReport summarizes an alert from everything the Manager observed while it ran.
+// It is meant for archiving or posting after the alert ends.
+type AlertReport struct {
+	ID              ps2.MetagameEventInstanceID `json:"id"`
+	WorldID         ps2.WorldID                 `json:"world_id"`
+	ZoneID          ps2.ZoneInstanceID          `json:"zone_id"`
+	MetagameEventID ps2.MetagameEventID         `json:"metagame_event_id"`
+	Name            string                      `json:"name"`
+	EventURL        string                      `json:"event_url"`
+	Started         time.Time                   `json:"started"`
+	Ended           *time.Time                  `json:"ended"` // Ended is nil for a report of a running alert
+	Duration        ps2.Seconds                 `json:"duration"`
+	Victor          ps2.FactionID               `json:"victor"`
+	FinalScore      score                       `json:"final_score"`
+
+	// Outfits are the outfits with the most facility captures, most first.
+	Outfits []OutfitCaptures `json:"outfits"`
+
+	// Timeline is the score after every change, oldest first.
+	Timeline []ScoreSnapshot `json:"timeline"`
+
+	// Population is the range of players on each team in the zone,
+	// sampled every time populations are counted.
+	Population PopulationBrackets `json:"population"`
+
+	// Partial is true when tracking started after the alert did,
+	// such as after a restart, so the report is missing the start of the alert.
+	Partial bool `json:"partial"`
+}
+
+// OutfitCaptures is the number of facilities an outfit captured during an alert.
+type OutfitCaptures struct {
+	OutfitID ps2.OutfitID `json:"outfit_id"`
+	Captures int          `json:"captures"`
+}
+
+// ScoreSnapshot is an alert score at a point in time.
+type ScoreSnapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	Score     score     `json:"score"`
+}
+
+// PopulationBrackets are population ranges per team.
+type PopulationBrackets struct {
+	VS      PopulationBracket `json:"vs"`
+	NC      PopulationBracket `json:"nc"`
+	TR      PopulationBracket `json:"tr"`
+	Samples int               `json:"samples"`
+}
+
+// PopulationBracket is the range of a team's population over the samples of a report.
+type PopulationBracket struct {
+	Min     int     `json:"min"`
+	Max     int     `json:"max"`
+	Average float64 `json:"average"`
+}
+
+// alertTracker collects the data for an AlertReport while an alert runs.
+type alertTracker struct {
+	captures map[ps2.OutfitID]int
+	timeline []ScoreSnapshot
+	samples  []zonepop
+	partial  bool
+	report   *AlertReport // report is set when the alert ends
+}
+
+func (t *alertTracker) snapshot(at time.Time, s score) {
+	if n := len(t.timeline); n > 0 && t.timeline[n-1].Score == s {
+		return
+	}
+	if len(t.timeline) >= alertTimelineLimit {
+		return
+	}
+	t.timeline = append(t.timeline, ScoreSnapshot{Timestamp: at, Score: s})
+}
+
+func (t *alertTracker) build(event EventState) AlertReport {
+	r := AlertReport{
+		ID:              event.ID,
+		WorldID:         event.ID.WorldID,
+		ZoneID:          event.MapID,
+		MetagameEventID: event.MetagameEventID,
+		Name:            event.EventName,
+		EventURL:        event.EventURL,
+		Started:         event.Started,
+		Ended:           event.Ended,
+		Victor:          event.Victor,
+		FinalScore:      event.Score,
+		Timeline:        slices.Clone(t.timeline),
+		Partial:         t.partial,
+	}
+	end := time.Now()
+	if event.Ended != nil {
+		end = *event.Ended
+	}
+	r.Duration = ps2.Seconds(end.Sub(event.Started))
+
+	for outfit, n := range t.captures {
+		r.Outfits = append(r.Outfits, OutfitCaptures{OutfitID: outfit, Captures: n})
+	}
+	slices.SortFunc(r.Outfits, func(a, b OutfitCaptures) int {
+		if c := cmp.Compare(b.Captures, a.Captures); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.OutfitID, b.OutfitID)
+	})
+	if len(r.Outfits) > alertReportOutfits {
+		r.Outfits = r.Outfits[:alertReportOutfits]
+	}
+
+	r.Population.Samples = len(t.samples)
+	r.Population.VS = bracket(t.samples, func(p zonepop) int { return p.VS })
+	r.Population.NC = bracket(t.samples, func(p zonepop) int { return p.NC })
+	r.Population.TR = bracket(t.samples, func(p zonepop) int { return p.TR })
+	return r
+}
+
+func bracket(samples []zonepop, count func(zonepop) int) (b PopulationBracket) {
+	if len(samples) == 0 {
+		return b
+	}
+	b.Min = count(samples[0])
+	total := 0
+	for _, p := range samples {
+		n := count(p)
+		b.Min = min(b.Min, n)
+		b.Max = max(b.Max, n)
+		total += n
+	}
+	b.Average = float64(total) / float64(len(samples))
+	return b
+}
+
+// OnAlertReport adds a function that will be called with the report of every alert when it ends.
+// Alerts that are replaced or removed without an end event are reported when they're removed.
+func (manager *Manager) OnAlertReport(f func(AlertReport)) {
+	manager.alertReportHandlers = append(manager.alertReportHandlers, f)
+}
+
+// AlertReport returns the report for an alert.
+// Reports of running alerts are built from the data so far.
+// Reports are kept until the alert is removed from the state, a few minutes after it ends,
+// and are updated if ps2alerts or a continent lock reports the final score or victor after that.
+func (manager *Manager) AlertReport(ctx context.Context, id ps2.MetagameEventInstanceID) (AlertReport, error) {
+	r, err := askContext(ctx, manager, func(manager *Manager) *AlertReport {
+		t := manager.alertTrackers[id]
+		if t == nil {
+			return nil
+		}
+		if t.report != nil {
+			r := *t.report
+			return &r
+		}
+		event := manager.alerts[id]
+		if event == nil {
+			return nil
+		}
+		r := t.build(event.Clone())
+		return &r
+	})
+	if err != nil {
+		return AlertReport{}, fmt.Errorf("manager.AlertReport: %w", err)
+	}
+	if r == nil {
+		return AlertReport{}, fmt.Errorf("manager.AlertReport: alert %s not found", id)
+	}
+	return *r, nil
+}
+
+// trackAlert starts collecting report data for event.
+func trackAlert(manager *Manager, event *EventState, partial bool) {
+	if _, ok := manager.alertTrackers[event.ID]; ok {
+		return
+	}
+	t := &alertTracker{
+		captures: make(map[ps2.OutfitID]int),
+		partial:  partial,
+	}
+	t.snapshot(event.Timestamp, event.Score)
+	manager.alertTrackers[event.ID] = t
+}
+
+// recordAlertCapture records a facility capture during a running alert.
+func recordAlertCapture(manager *Manager, event *EventState, outfit ps2.OutfitID, at time.Time) {
+	t := manager.alertTrackers[event.ID]
+	if t == nil {
+		return
+	}
+	if outfit != 0 {
+		t.captures[outfit]++
+	}
+	t.snapshot(at, event.Score)
+}
+
+// sampleAlertPopulations records the population of every zone with a running alert.
+func sampleAlertPopulations(manager *Manager) {
+	for id, event := range manager.alerts {
+		t := manager.alertTrackers[id]
+		if t == nil || event.Ended != nil {
+			continue
+		}
+		zone := manager.state.getZoneptr(uniqueZone{id.WorldID, event.MapID})
+		if zone == nil {
+			continue
+		}
+		t.samples = append(t.samples, zone.Population)
+	}
+}
+
+// finishAlert builds the final report for event and emits it.
+// The report is only emitted once, but calling finishAlert again
+// rebuilds the stored report if the final score, victor, or end arrived after the alert ended.
+func finishAlert(manager *Manager, event *EventState) {
+	t := manager.alertTrackers[event.ID]
+	if t == nil {
+		return
+	}
+	if r := t.report; r != nil && r.FinalScore == event.Score && r.Victor == event.Victor && sameTime(r.Ended, event.Ended) {
+		return
+	}
+	emit := t.report == nil
+	t.snapshot(event.Timestamp, event.Score)
+	r := t.build(event.Clone())
+	t.report = &r
+	if !emit {
+		return
+	}
+	for _, f := range manager.alertReportHandlers {
+		f(r)
+	}
+}
+
+// sameTime reports whether a and b are both nil or the same instant.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
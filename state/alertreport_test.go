@@ -0,0 +1,193 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/ps2alerts"
+)
+
+// answerQueries answers queries against m until the test ends, without running the pollers in Run.
+func answerQueries(t *testing.T, m *Manager) {
+	close(m.running)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case q := <-m.queryQueue:
+				q.Ask(m)
+			}
+		}
+	}()
+}
+
+func TestAlertReport(t *testing.T) {
+	zone := ps2.ZoneInstanceID(ps2.Indar)
+	start := time.Now().Add(-30 * time.Second)
+	started := event.MetagameEvent{
+		InstanceID:         7,
+		MetagameEventID:    ps2.IndarSuddenDeath,
+		MetagameEventState: ps2.Started,
+		Timestamp:          start,
+		WorldID:            ps2.Emerald,
+		ZoneID:             zone,
+	}
+	id := ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 7}
+
+	m := New(testStore{}, nil)
+	var reports []AlertReport
+	m.OnAlertReport(func(r AlertReport) { reports = append(reports, r) })
+	answerQueries(t, m)
+	ctx := context.Background()
+	handlePushEvent(ctx, m, started)
+
+	alert := m.alerts[id]
+	if alert == nil {
+		t.Fatal("the alert wasn't tracked")
+	}
+	recordAlertCapture(m, alert, 100, start.Add(time.Minute))
+	recordAlertCapture(m, alert, 200, start.Add(2*time.Minute))
+	recordAlertCapture(m, alert, 200, start.Add(3*time.Minute))
+	// captures by players without an outfit only change the timeline
+	recordAlertCapture(m, alert, 0, start.Add(4*time.Minute))
+	for _, pop := range []popCounter{{VS: 10, NC: 20, TR: 30}, {VS: 30, NC: 20, TR: 10}} {
+		m.state.setZonePop(uniqueZone{ps2.Emerald, zone}, pop)
+		sampleAlertPopulations(m)
+	}
+
+	r, err := m.AlertReport(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Ended != nil || r.Partial || r.ZoneID != zone || r.MetagameEventID != ps2.IndarSuddenDeath {
+		t.Errorf("got %+v; want a running report of the alert", r)
+	}
+	if len(r.Outfits) != 2 || r.Outfits[0] != (OutfitCaptures{200, 2}) || r.Outfits[1] != (OutfitCaptures{100, 1}) {
+		t.Errorf("got outfits %+v; want 200 with 2 captures then 100 with 1", r.Outfits)
+	}
+	if want := (PopulationBracket{Min: 10, Max: 30, Average: 20}); r.Population.VS != want || r.Population.Samples != 2 {
+		t.Errorf("got VS population %+v from %d samples; want %+v from 2", r.Population.VS, r.Population.Samples, want)
+	}
+	if r.Duration.Duration() < 30*time.Second {
+		t.Errorf("got duration %v for a running alert; want at least 30s", r.Duration)
+	}
+	if len(reports) != 0 {
+		t.Errorf("got %d reports emitted for a running alert; want 0", len(reports))
+	}
+
+	ended := started
+	ended.MetagameEventState = ps2.Ended
+	ended.Timestamp = start.Add(20 * time.Minute)
+	ended.FactionVS, ended.FactionNC, ended.FactionTR = 40, 12, 30
+	handlePushEvent(ctx, m, ended)
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports emitted when the alert ended; want 1", len(reports))
+	}
+	final, err := m.AlertReport(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Ended == nil || final.Duration.Duration() != 20*time.Minute || final.Victor != VS {
+		t.Errorf("got %+v; want a 20 minute alert won by VS", final)
+	}
+	if last := final.Timeline[len(final.Timeline)-1]; last.Score != (score{VS: 40, NC: 12, TR: 30}) {
+		t.Errorf("got final timeline score %+v", last.Score)
+	}
+
+	if _, err := m.AlertReport(ctx, ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 8}); err == nil {
+		t.Error("expected an error for an unknown alert")
+	}
+}
+
+func TestAlertReportContext(t *testing.T) {
+	// queries fail immediately before Run
+	m := New(testStore{}, nil)
+	id := ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 7}
+	if _, err := m.AlertReport(context.Background(), id); !errors.Is(err, errGoneHome) {
+		t.Errorf("got error %v before Run; want %v", err, errGoneHome)
+	}
+
+	// nothing answers the queries of a manager that's busy
+	close(m.running)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.AlertReport(ctx, id); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	close(m.unavailable)
+	if _, err := m.AlertReport(context.Background(), id); !errors.Is(err, errGoneHome) {
+		t.Errorf("got error %v; want %v", err, errGoneHome)
+	}
+}
+
+func TestAlertReportFinalResult(t *testing.T) {
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Indar)}
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	end := start.Add(45 * time.Minute)
+	id := ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 7}
+
+	m := New(testStore{}, nil)
+	var reports []AlertReport
+	m.OnAlertReport(func(r AlertReport) { reports = append(reports, r) })
+
+	// ps2alerts reports the result without census ever ending the alert
+	result := ps2alerts.Alert{World: ps2.Emerald, InstanceID: id, Zone: zone.ZoneInstanceID, TimeStarted: start, TimeEnded: &end}
+	result.Result.Vs, result.Result.Nc, result.Result.Tr = 30, 50, 20
+	handlePS2AlertsResponse(m, result)
+	if len(reports) != 1 || reports[0].Ended == nil || reports[0].FinalScore != (score{VS: 30, NC: 50, TR: 20}) {
+		t.Fatalf("got reports %+v; want the final result reported once", reports)
+	}
+
+	// the lock sets the victor afterwards, which updates the stored report without emitting another
+	handleLock(m, event.ContinentLock{WorldID: ps2.Emerald, ZoneID: zone.ZoneInstanceID, TriggeringFaction: ps2.NC})
+	if len(reports) != 1 {
+		t.Errorf("got %d reports; want the update stored without emitting again", len(reports))
+	}
+	if r := m.alertTrackers[id].report; r == nil || r.Victor != ps2.NC {
+		t.Errorf("got stored report %+v; want NC as the victor", r)
+	}
+	handlePS2AlertsResponse(m, result)
+	if len(reports) != 1 {
+		t.Errorf("got %d reports after a repeated response; want 1", len(reports))
+	}
+}
+
+func TestAlertReportRemoved(t *testing.T) {
+	zone := ps2.ZoneInstanceID(ps2.Indar)
+	started := event.MetagameEvent{
+		InstanceID:         7,
+		MetagameEventID:    ps2.IndarSuddenDeath,
+		MetagameEventState: ps2.Started,
+		Timestamp:          time.Now(),
+		WorldID:            ps2.Emerald,
+		ZoneID:             zone,
+	}
+
+	m := New(testStore{}, nil)
+	var reports []AlertReport
+	m.OnAlertReport(func(r AlertReport) { reports = append(reports, r) })
+
+	// an alert whose end was missed is reported when the next one replaces it
+	handlePushEvent(context.Background(), m, started)
+	next := started
+	next.InstanceID = 8
+	handlePushEvent(context.Background(), m, next)
+	if len(reports) != 1 || reports[0].ID.InstanceID != 7 {
+		t.Fatalf("got reports %+v; want the replaced alert", reports)
+	}
+
+	// and one that's never replaced is reported when it's removed as stale
+	m.alerts[next.EventInstanceID()].Started = time.Now().Add(-24 * time.Hour)
+	removeStaleEvents(m)
+	if len(reports) != 2 || reports[1].ID.InstanceID != 8 {
+		t.Errorf("got reports %+v; want the stale alert", reports)
+	}
+}
@@ -0,0 +1,171 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// bootstrapAlertWindow is how far back Bootstrap looks for alerts that are still running.
+// It is longer than the longest alert duration.
+const bootstrapAlertWindow = 3 * time.Hour
+
+// Bootstrap fills the Manager's state from census so that the first notifications are accurate
+// instead of waiting for the first map poll and for alerts to start:
+// territory and continent status for every tracked zone,
+// and any alerts that are currently running.
+//
+// Bootstrap must be called before [Manager.Run].
+// Handlers registered before calling Bootstrap receive the resulting notifications.
+// Population is not included because census has no way to count every online character;
+// it fills in from push events as usual, or from known characters with [Manager.SeedOnline].
+//
+// Errors are returned after as much state as possible has been loaded,
+// so a Manager is still usable after a failed Bootstrap.
+func (manager *Manager) Bootstrap(ctx context.Context) error {
+	if !manager.mu.TryLock() {
+		return errors.New("manager.Bootstrap: manager is already running")
+	}
+	defer manager.mu.Unlock()
+
+	var errs []error
+	worldZones := manager.state.listZones()
+	envWorlds := make(map[ps2.Environment][]ps2.WorldID)
+	for world, zones := range worldZones {
+		env := ps2.GetEnvironment(world)
+		envWorlds[env] = append(envWorlds[env], world)
+		if len(zones) == 0 {
+			continue
+		}
+		zm, err := census.GetMap(ctx, manager.census, world, zones...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("map for world %d: %w", world, err))
+			continue
+		}
+		for _, z := range zm {
+			handleMap(manager, z)
+		}
+	}
+
+	after := time.Now().Add(-bootstrapAlertWindow)
+	for env, worlds := range envWorlds {
+		events, err := census.GetMetagameEvents(ctx, manager.census, env, &after, worlds...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alerts for %s: %w", env, err))
+			continue
+		}
+		for _, e := range runningAlerts(events) {
+			handleMetagame(ctx, manager, e)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("manager.Bootstrap: %w", err)
+	}
+	return nil
+}
+
+// runningAlerts returns the start event of every alert in events (oldest first) that hasn't ended.
+func runningAlerts(events []event.MetagameEvent) []event.MetagameEvent {
+	latest := make(map[ps2.MetagameEventInstanceID]event.MetagameEvent)
+	var order []ps2.MetagameEventInstanceID
+	for _, e := range events {
+		id := e.EventInstanceID()
+		if _, seen := latest[id]; !seen {
+			order = append(order, id)
+		}
+		switch e.MetagameEventState {
+		case ps2.Started, ps2.Cancelled, ps2.Ended:
+			latest[id] = e
+		}
+	}
+	var running []event.MetagameEvent
+	for _, id := range order {
+		if e, ok := latest[id]; ok && e.MetagameEventState == ps2.Started {
+			running = append(running, e)
+		}
+	}
+	return running
+}
+
+// SeedOnline adds characters that census reports as online to the Manager's online players,
+// so that world population is accurate from the start instead of filling in as characters log in.
+// Statuses usually come from [census.GetOnlineStatus] or [census.GetOutfitOnline];
+// offline characters and characters the Manager already knows about are skipped.
+//
+// Seeded characters count toward the population of their world,
+// but not of any zone until an event places them in one.
+// Like other players, they're dropped after the stale timeout without an event (see [Manager.SetStaleTimeout]).
+// Factions come from the game data store,
+// falling back to census for characters the store doesn't know.
+//
+// SeedOnline must be called before [Manager.Run].
+// Errors are returned after every character has been added,
+// so characters whose faction couldn't be found are still counted.
+func (manager *Manager) SeedOnline(ctx context.Context, statuses ...census.OnlineStatus) error {
+	if !manager.mu.TryLock() {
+		return errors.New("manager.SeedOnline: manager is already running")
+	}
+	defer manager.mu.Unlock()
+
+	now := time.Now()
+	unknown := make(map[ps2.Environment][]ps2.CharacterID)
+	for _, s := range statuses {
+		if !s.Online() {
+			continue
+		}
+		if _, found := manager.players.players[s.CharacterID]; found {
+			continue
+		}
+		faction := manager.gameData.GetPlayerFaction(s.CharacterID)
+		if faction == 0 {
+			env := ps2.GetEnvironment(s.WorldID)
+			unknown[env] = append(unknown[env], s.CharacterID)
+		}
+		manager.players.players[s.CharacterID] = seededPlayer(s.WorldID, faction, now)
+		manager.logouts.Seen(s.CharacterID, s.WorldID, now)
+	}
+
+	var errs []error
+	for env, ids := range unknown {
+		characters, err := census.GetCharactersByID(ctx, manager.census, env, ids...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("factions for %s: %w", env, err))
+			continue
+		}
+		for _, c := range characters {
+			if c.FactionID == 0 {
+				continue
+			}
+			p := manager.players.players[c.CharacterID]
+			manager.players.players[c.CharacterID] = seededPlayer(p.world, c.FactionID, now)
+			manager.players.saver.SavePlayerFaction(c.CharacterID, c.FactionID)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("manager.SeedOnline: %w", err)
+	}
+	return nil
+}
+
+// seededPlayer returns the state of a player that was found by its online status instead of an event.
+// Players of the original factions are always on their own team;
+// an NSO player's team is unknown until an event shows it.
+func seededPlayer(world ps2.WorldID, faction ps2.FactionID, now time.Time) onlinePlayerState {
+	p := onlinePlayerState{
+		homeFaction: faction,
+		world:       world,
+		lastSeen:    now,
+		// factions from the store or census don't need to be saved again
+		saved: faction != 0,
+	}
+	if faction != NSO {
+		p.team = faction
+	}
+	return p
+}
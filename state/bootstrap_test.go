@@ -0,0 +1,46 @@
+package state
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+type characterServer struct{}
+
+func (characterServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"character_list":[{"character_id":"1","faction_id":"2"},{"character_id":"3","faction_id":"4"}],"returned":2}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestSeedOnline(t *testing.T) {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: characterServer{}})
+	m := New(testStore{}, client)
+
+	err := m.SeedOnline(context.Background(),
+		census.OnlineStatus{CharacterID: 1, WorldID: ps2.Emerald},
+		census.OnlineStatus{CharacterID: 2, WorldID: 0},
+		census.OnlineStatus{CharacterID: 3, WorldID: ps2.Emerald},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.players.players) != 2 {
+		t.Fatalf("got %d online players; want 2", len(m.players.players))
+	}
+	if p := m.players.players[1]; p.homeFaction != NC || p.team != NC || p.world != ps2.Emerald {
+		t.Errorf("got %+v for character 1; want NC on Emerald", p)
+	}
+	if p := m.players.players[3]; p.homeFaction != NSO || p.team != 0 {
+		t.Errorf("got %+v for character 3; want NSO with an unknown team", p)
+	}
+}
@@ -0,0 +1,220 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// StateStore saves and loads checkpoints of the Manager's state,
+// so that a restarted Manager knows about territory and running alerts
+// before the first map poll and without waiting for events.
+type StateStore interface {
+	Save(GlobalState) error
+
+	// Load returns the last saved state,
+	// or an error wrapping os.ErrNotExist if nothing has been saved.
+	Load() (GlobalState, error)
+}
+
+const defaultCheckpointInterval = time.Minute
+
+// SetStateStore saves a checkpoint to store every interval while the Manager is running,
+// and once more when it shuts down.
+// An interval less than 1 saves every minute.
+// Use [Manager.Restore] to load the checkpoint after a restart.
+// It must be called before [Manager.Run].
+func (manager *Manager) SetStateStore(store StateStore, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	manager.stateStore = store
+	manager.checkpointInterval = interval
+}
+
+// Restore loads the last checkpoint from the store set with [Manager.SetStateStore]:
+// territory, continent status, and alerts that hadn't ended.
+// Population isn't restored since it's counted from recent events.
+//
+// Restore must be called before [Manager.Run].
+// Call it before [Manager.Bootstrap] so that fresh census data replaces the checkpoint.
+// Restored alerts are removed as usual once they're past their duration,
+// so alerts that ended while the Manager was stopped don't linger.
+func (manager *Manager) Restore() error {
+	if !manager.mu.TryLock() {
+		return errors.New("manager.Restore: manager is already running")
+	}
+	defer manager.mu.Unlock()
+	if manager.stateStore == nil {
+		return errors.New("manager.Restore: no state store is set")
+	}
+	saved, err := manager.stateStore.Load()
+	if err != nil {
+		return fmt.Errorf("manager.Restore: %w", err)
+	}
+	restoreState(manager, saved)
+	return nil
+}
+
+// restoreState copies saved into the zones tracked by the manager.
+// Zones that aren't tracked anymore are ignored.
+func restoreState(manager *Manager, saved GlobalState) {
+	for _, world := range saved.Worlds {
+		for _, z := range world.Zones {
+			z := z.Clone()
+			zone := manager.state.getZoneptr(uniqueZone{world.WorldID, z.MapID})
+			if zone == nil {
+				continue
+			}
+			zone.OwningFaction = z.OwningFaction
+			zone.ContinentState = z.ContinentState
+			zone.LastLock = z.LastLock
+			zone.LastUnlock = z.LastUnlock
+			zone.MapTimestamp = z.MapTimestamp
+			if z.Regions.Territory != nil {
+				zone.Regions.Territory = z.Regions.Territory
+				zone.Regions.Timestamp = z.Regions.Timestamp
+			}
+			if z.Cutoff != nil {
+				zone.Cutoff = z.Cutoff
+			}
+			if z.Event == nil || z.Event.Ended != nil {
+				continue
+			}
+			e := *z.Event
+			e.MapID = z.MapID
+			e.Provenance.Source = SourceCheckpoint
+			manager.alerts[e.ID] = &e
+			zone.Event = &e
+			trackAlert(manager, &e, true)
+		}
+	}
+}
+
+// checkpoint saves the manager's state on a new goroutine,
+// unless the previous checkpoint is still being saved.
+func checkpoint(manager *Manager) {
+	if manager.stateStore == nil || !manager.checkpointing.CompareAndSwap(false, true) {
+		return
+	}
+	state := manager.state.Clone()
+	go func() {
+		defer manager.checkpointing.Store(false)
+		if err := manager.stateStore.Save(state); err != nil {
+			manager.logf("saving state checkpoint: %v", err)
+		}
+	}()
+}
+
+// saveFinalCheckpoint saves state after any periodic checkpoint has finished, giving up when ctx is done.
+// A save that's given up on keeps running, so it may still finish after Shutdown returns.
+func saveFinalCheckpoint(ctx context.Context, manager *Manager, state GlobalState) error {
+	// wait for a periodic checkpoint so it can't replace this one
+	wait := time.NewTicker(10 * time.Millisecond)
+	defer wait.Stop()
+	for !manager.checkpointing.CompareAndSwap(false, true) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait.C:
+		}
+	}
+	saved := make(chan struct{})
+	go func() {
+		defer close(saved)
+		defer manager.checkpointing.Store(false)
+		if err := manager.stateStore.Save(state); err != nil {
+			manager.logf("saving state checkpoint: %v", err)
+		}
+	}()
+	select {
+	case <-saved:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// JSONFileStore is a StateStore that keeps the checkpoint in a single JSON file.
+type JSONFileStore struct {
+	Path string
+}
+
+// jsonCheckpoint is the file format of a JSONFileStore.
+// Territory isn't part of the JSON encoding of GlobalState, so it's saved separately.
+type jsonCheckpoint struct {
+	Saved     time.Time            `json:"saved"`
+	State     GlobalState          `json:"state"`
+	Territory []jsonZoneCheckpoint `json:"territory"`
+}
+
+type jsonZoneCheckpoint struct {
+	WorldID   ps2.WorldID                    `json:"world_id"`
+	MapID     ps2.ZoneInstanceID             `json:"census_map_id"`
+	Timestamp time.Time                      `json:"timestamp"`
+	Regions   map[ps2.RegionID]ps2.FactionID `json:"regions"`
+	Cutoff    map[ps2.RegionID]bool          `json:"cutoff,omitempty"`
+}
+
+// Save writes to a temporary file first so that a crash never leaves a partial checkpoint.
+func (s JSONFileStore) Save(state GlobalState) error {
+	c := jsonCheckpoint{Saved: time.Now(), State: state}
+	for _, world := range state.Worlds {
+		for _, zone := range world.Zones {
+			c.Territory = append(c.Territory, jsonZoneCheckpoint{
+				WorldID:   world.WorldID,
+				MapID:     zone.MapID,
+				Timestamp: zone.Regions.Timestamp,
+				Regions:   zone.Regions.Territory,
+				Cutoff:    zone.Cutoff,
+			})
+		}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("state.JSONFileStore.Save: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("state.JSONFileStore.Save: %w", err)
+	}
+	_, err = f.Write(b)
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), s.Path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("state.JSONFileStore.Save: %w", err)
+	}
+	return nil
+}
+
+func (s JSONFileStore) Load() (GlobalState, error) {
+	b, err := os.ReadFile(s.Path)
+	if err != nil {
+		return GlobalState{}, fmt.Errorf("state.JSONFileStore.Load: %w", err)
+	}
+	var c jsonCheckpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return GlobalState{}, fmt.Errorf("state.JSONFileStore.Load: %w", err)
+	}
+	for _, t := range c.Territory {
+		zone := c.State.getZoneptr(uniqueZone{t.WorldID, t.MapID})
+		if zone == nil {
+			continue
+		}
+		zone.Regions.ZoneID = t.MapID
+		zone.Regions.WorldID = t.WorldID
+		zone.Regions.Timestamp = t.Timestamp
+		zone.Regions.Territory = t.Regions
+		zone.Cutoff = t.Cutoff
+	}
+	return c.State, nil
+}
@@ -0,0 +1,95 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	store := JSONFileStore{Path: filepath.Join(t.TempDir(), "state.json")}
+	if _, err := store.Load(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("got error %v loading a missing checkpoint; want os.ErrNotExist", err)
+	}
+
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Indar)}
+	started := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	id := ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 1234}
+
+	before := New(testStore{}, nil)
+	z := before.state.getZoneptr(zone)
+	z.OwningFaction = ps2.VS
+	z.Regions.Territory[2201] = ps2.NC
+	z.Cutoff[2201] = true
+	event := &EventState{ID: id, MapID: zone.ZoneInstanceID, MetagameEventID: 147, Started: started, EventDuration: 5400}
+	event.Provenance = before.provenance(SourceWebsocket, started)
+	before.alerts[id] = event
+	before.state.setEvent(zone, event)
+	if err := store.Save(before.state.Clone()); err != nil {
+		t.Fatal(err)
+	}
+
+	after := New(testStore{}, nil)
+	after.SetStateStore(store, 0)
+	if err := after.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	got := after.state.getZoneptr(zone)
+	if got.OwningFaction != ps2.VS || got.Regions.Territory[2201] != ps2.NC || !got.Cutoff[2201] {
+		t.Errorf("zone wasn't restored: got owner %v, territory %v, cutoff %v", got.OwningFaction, got.Regions.Territory, got.Cutoff)
+	}
+	restored := after.alerts[id]
+	if restored == nil || got.Event != restored {
+		t.Fatalf("alert %s wasn't restored to the zone", id)
+	}
+	if !restored.Started.Equal(started) || restored.MapID != zone.ZoneInstanceID {
+		t.Errorf("got alert started %v in %v; want %v in %v", restored.Started, restored.MapID, started, zone.ZoneInstanceID)
+	}
+	if restored.Provenance.Source != SourceCheckpoint {
+		t.Errorf("got provenance source %v; want %v", restored.Provenance.Source, SourceCheckpoint)
+	}
+	if after.alertTrackers[id] == nil {
+		t.Errorf("restored alert isn't tracked for reports")
+	}
+}
+
+// blockedStore is a StateStore whose saves don't finish until release is closed.
+type blockedStore struct {
+	release chan struct{}
+}
+
+func (s blockedStore) Save(GlobalState) error {
+	<-s.release
+	return nil
+}
+
+func (blockedStore) Load() (GlobalState, error) { return GlobalState{}, os.ErrNotExist }
+
+func TestShutdownCheckpointDeadline(t *testing.T) {
+	store := blockedStore{release: make(chan struct{})}
+	defer close(store.release)
+
+	for _, periodic := range []bool{true, false} {
+		m := New(testStore{}, nil)
+		m.SetStateStore(store, 0)
+		// a periodic checkpoint that's still saving holds the flag
+		m.checkpointing.Store(periodic)
+		emitted := false
+		m.OnShutdown(func(GlobalState) { emitted = true })
+
+		deadline, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := shutdown(deadline, context.Background(), m)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("periodic checkpoint %t: got error %v; want %v", periodic, err, context.DeadlineExceeded)
+		}
+		if !emitted {
+			t.Errorf("periodic checkpoint %t: the final state wasn't emitted", periodic)
+		}
+	}
+}
@@ -1,15 +1,21 @@
 package state
 
 import (
+	"time"
+
 	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
 	"github.com/Travis-Britz/ps2/psmap"
 )
 
 //todo: emit alert starts
 
 type WorldPopulation struct {
-	World worldpop
-	Zones map[ps2.ZoneID]zonepop
+	World worldpop               `json:"world"`
+	Zones map[ps2.ZoneID]zonepop `json:"zones"`
+
+	// Provenance is observed at the most recent event from a counted player.
+	Provenance Provenance `json:"provenance"`
 }
 
 type PopulationTotal map[ps2.WorldID]WorldPopulation
@@ -19,11 +25,16 @@ func (manager *Manager) OnPopulationTotal(f func(PopulationTotal)) {
 	manager.populationHandlers = append(manager.populationHandlers, f)
 }
 
-func emitPopulationSums(manager *Manager) {
+func emitPopulationSums(manager *Manager, lastSeen map[ps2.WorldID]time.Time) {
 	pt := manager.state.Population()
+	for id, wp := range pt {
+		wp.Provenance = manager.provenance(SourceWebsocket, lastSeen[id])
+		pt[id] = wp
+	}
 	for _, f := range manager.populationHandlers {
 		f(pt)
 	}
+	manager.populationSubs.publish(pt)
 }
 
 type TerritoryChange struct {
@@ -31,21 +42,63 @@ type TerritoryChange struct {
 	ZoneID  ps2.ZoneInstanceID
 	Regions map[ps2.RegionID]ps2.FactionID
 	Cutoff  map[ps2.RegionID]bool
+
+	// The fields below describe the capture that caused the change.
+	// They're only set for changes from a single FacilityControl event,
+	// and are zero for census map polls and continent unlocks.
+	FacilityID   ps2.FacilityID
+	FacilityName string
+	FacilityType ps2.FacilityTypeID
+	OutfitID     ps2.OutfitID // OutfitID is the outfit credited with the capture, or 0 for none.
+	DurationHeld time.Duration
+
+	// Provenance is a census map poll for full territory updates
+	// and a websocket FacilityControl for single captures.
+	Provenance Provenance
 }
 
 func (manager *Manager) OnTerritoryChange(f func(TerritoryChange)) {
 	manager.territoryChangeHandlers = append(manager.territoryChangeHandlers, f)
 }
-func emitTerritoryChange(manager *Manager, zone uniqueZone, territory map[ps2.RegionID]ps2.FactionID, cutoff map[ps2.RegionID]bool) {
+func emitTerritoryChange(manager *Manager, zone uniqueZone, territory map[ps2.RegionID]ps2.FactionID, cutoff map[ps2.RegionID]bool, source Source, observed time.Time) {
 	tc := TerritoryChange{
-		WorldID: zone.WorldID,
-		ZoneID:  zone.ZoneInstanceID,
-		Regions: territory,
-		Cutoff:  cutoff,
+		WorldID:    zone.WorldID,
+		ZoneID:     zone.ZoneInstanceID,
+		Regions:    territory,
+		Cutoff:     cutoff,
+		Provenance: manager.provenance(source, observed),
 	}
+	publishTerritoryChange(manager, tc)
+}
+
+// emitFacilityCapture emits the territory change of a single facility capture,
+// including the details of the facility and the capture.
+func emitFacilityCapture(manager *Manager, zone uniqueZone, region ps2.RegionID, cutoff map[ps2.RegionID]bool, e event.FacilityControl) {
+	facility := manager.gameData.GetFacility(e.FacilityID)
+	facilityType := facility.Type
+	if facilityType == 0 {
+		facilityType, _ = ps2.FacilityType(e.FacilityID)
+	}
+	tc := TerritoryChange{
+		WorldID:      zone.WorldID,
+		ZoneID:       zone.ZoneInstanceID,
+		Regions:      map[ps2.RegionID]ps2.FactionID{region: e.NewFactionID},
+		Cutoff:       cutoff,
+		FacilityID:   e.FacilityID,
+		FacilityName: facility.Name,
+		FacilityType: facilityType,
+		OutfitID:     e.OutfitID,
+		DurationHeld: e.DurationHeld,
+		Provenance:   manager.provenance(SourceWebsocket, e.Timestamp),
+	}
+	publishTerritoryChange(manager, tc)
+}
+
+func publishTerritoryChange(manager *Manager, tc TerritoryChange) {
 	for _, f := range manager.territoryChangeHandlers {
 		f(tc)
 	}
+	manager.territoryChangeSubs.publish(tc)
 }
 
 type ZoneStatusChange struct {
@@ -58,20 +111,62 @@ func (manager *Manager) OnZoneStatusChange(f func(ZoneStatusChange)) {
 	manager.zoneStatusChangeHandlers = append(manager.zoneStatusChangeHandlers, f)
 }
 func emitZoneStateChange(manager *Manager, id uniqueZone, status psmap.Status) {
+	change := ZoneStatusChange{
+		WorldID: id.WorldID,
+		ZoneID:  id.ZoneInstanceID,
+		Status:  status,
+	}
 	for _, f := range manager.zoneStatusChangeHandlers {
-		f(ZoneStatusChange{
-			WorldID: id.WorldID,
-			ZoneID:  id.ZoneInstanceID,
-			Status:  status,
-		})
+		f(change)
 	}
+	manager.zoneStatusChangeSubs.publish(change)
 }
 
 func (manager *Manager) OnEventUpdate(f func(EventState)) {
 	manager.eventUpdateHandlers = append(manager.eventUpdateHandlers, f)
 }
 func emitEventUpdate(manager *Manager, event EventState) {
+	event.Provenance.Stale = manager.mapPollFailing.Load()
 	for _, f := range manager.eventUpdateHandlers {
 		f(event)
 	}
+	manager.eventUpdateSubs.publish(event)
+}
+
+// OnBaseTrade adds a function that will be called every time a facility capture looks like base trading,
+// meaning the same two factions have repeatedly captured the facility from each other within a short window.
+// It will be called again for each further capture while the trading continues.
+func (manager *Manager) OnBaseTrade(f func(BaseTrade)) {
+	manager.baseTradeHandlers = append(manager.baseTradeHandlers, f)
+}
+func emitBaseTrade(manager *Manager, bt BaseTrade) {
+	for _, f := range manager.baseTradeHandlers {
+		f(bt)
+	}
+}
+
+// NSOTeamChange is emitted when an NSO character is seen fighting for a different team than before.
+type NSOTeamChange struct {
+	CharacterID ps2.CharacterID
+	WorldID     ps2.WorldID
+	ZoneID      ps2.ZoneInstanceID
+	From        ps2.FactionID
+	To          ps2.FactionID
+	Timestamp   time.Time
+}
+
+// OnNSOTeamChange adds a function that will be called every time an online NSO character switches teams.
+func (manager *Manager) OnNSOTeamChange(f func(NSOTeamChange)) {
+	manager.nsoTeamChangeHandlers = append(manager.nsoTeamChangeHandlers, f)
+}
+func emitNSOTeamChange(manager *Manager, change NSOTeamChange) {
+	for _, f := range manager.nsoTeamChangeHandlers {
+		f(change)
+	}
+}
+
+// OnShutdown adds a function that will be called with the final state when the Manager is stopped with [Manager.Shutdown].
+// This is the place to persist state before exiting.
+func (manager *Manager) OnShutdown(f func(GlobalState)) {
+	manager.shutdownHandlers = append(manager.shutdownHandlers, f)
 }
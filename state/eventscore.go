@@ -0,0 +1,160 @@
+package state
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/event/experience"
+)
+
+// eventScoring is how the Manager keeps score for an alert.
+type eventScoring uint8
+
+const (
+	// scoredElsewhere alerts get their score from territory and ps2alerts.
+	scoredElsewhere eventScoring = iota
+
+	// scoredByKills alerts are won by the faction with the most kills, like Sudden Death.
+	scoredByKills
+
+	// scoredByExperience alerts are won by the faction that earns the most points from the event objective,
+	// like Aerial Anomalies and Forgotten Carrier.
+	scoredByExperience
+)
+
+// scoringFor returns how the Manager keeps score for an alert of type id.
+func scoringFor(id ps2.MetagameEventID) eventScoring {
+	switch id {
+	case ps2.IndarSuddenDeath, ps2.HossinSuddenDeath, ps2.AmerishSuddenDeath, ps2.EsamirSuddenDeath, ps2.OshurSuddenDeath, ps2.OshurSuddenDeath2:
+		return scoredByKills
+	case ps2.IndarAerialAnomalies, ps2.HossinAerialAnomalies, ps2.AmerishAerialAnomalies, ps2.EsamirAerialAnomalies, ps2.OshurAerialAnomalies,
+		ps2.IndarForgottenCarrier, ps2.HossinForgottenCarrier, ps2.AmerishForgottenCarrier, ps2.EsamirForgottenCarrier, ps2.OshurForgottenCarrier:
+		return scoredByExperience
+	default:
+		return scoredElsewhere
+	}
+}
+
+// SetScoreExperience sets the experience IDs that score points for the earning faction during alerts
+// that are won by points instead of territory, like Aerial Anomalies and Forgotten Carrier.
+// Each GainExperience with one of the IDs adds its amount to the score of the player's team.
+//
+// By default the IDs are the objective experience of those alerts
+// ([experience.Catalog.EventObjectiveIDs]), loaded from the census experience collection when Run starts.
+// SetScoreExperience replaces the defaults, and calling it without IDs disables scoring by experience,
+// so that the score of those alerts is only known once they end, when census reports the final result.
+// Sudden Death alerts are always scored from kills.
+//
+// It must be called before [Manager.Run].
+func (manager *Manager) SetScoreExperience(ids ...ps2.ExperienceID) {
+	manager.scoreExperience = slices.Clone(ids)
+	manager.scoreExperienceSet = true
+}
+
+// loadScoreExperience sets the default score experience IDs from the census experience collection,
+// unless they were set with SetScoreExperience.
+func loadScoreExperience(ctx context.Context, manager *Manager) {
+	catalog, err := experience.Load(ctx, manager.census)
+	if err != nil {
+		if ctx.Err() == nil {
+			manager.logf("loading score experience: %v", err)
+		}
+		return
+	}
+	ids := catalog.EventObjectiveIDs()
+	askContext(ctx, manager, func(manager *Manager) struct{} {
+		manager.scoreExperience = ids
+		return struct{}{}
+	})
+}
+
+// runningScoredEvent returns the running alert of zone if the Manager keeps its score the way scoring does.
+func runningScoredEvent(manager *Manager, zone uniqueZone, scoring eventScoring) *EventState {
+	z := manager.state.getZoneptr(zone)
+	if z == nil || z.Event == nil || z.Event.Ended != nil {
+		return nil
+	}
+	if scoringFor(z.Event.MetagameEventID) != scoring {
+		return nil
+	}
+	return z.Event
+}
+
+// addScore adds points to the score of faction.
+func addScore(s *score, faction ps2.FactionID, points float64) bool {
+	switch faction {
+	case VS:
+		s.VS += points
+	case NC:
+		s.NC += points
+	case TR:
+		s.TR += points
+	default:
+		return false
+	}
+	return true
+}
+
+// scoreDeath counts a kill toward the running alert of the zone, if it's scored by kills.
+// Suicides and teamkills don't count.
+func scoreDeath(manager *Manager, e event.Death) {
+	if e.IsSuicide() || e.AttackerTeamID == e.TeamID {
+		return
+	}
+	event := runningScoredEvent(manager, uniqueZone{e.WorldID, e.ZoneID}, scoredByKills)
+	if event == nil {
+		return
+	}
+	if addScore(&event.Score, e.AttackerTeamID, 1) {
+		event.scoreChanged = true
+	}
+}
+
+// scoreExperience counts experience toward the running alert of the zone, if it's scored by experience.
+func scoreExperience(manager *Manager, e event.GainExperience) {
+	if !slices.Contains(manager.scoreExperience, e.ExperienceID) {
+		return
+	}
+	event := runningScoredEvent(manager, uniqueZone{e.WorldID, e.ZoneID}, scoredByExperience)
+	if event == nil {
+		return
+	}
+	if addScore(&event.Score, e.TeamID, e.Amount) {
+		event.scoreChanged = true
+	}
+}
+
+// scoreEnded sets the final score of an alert that isn't scored from territory
+// from the faction values census sends when the alert ends.
+// The update for the end of the alert includes the score,
+// so a pending score update is dropped.
+func scoreEnded(event *EventState, e event.MetagameEvent) {
+	event.scoreChanged = false
+	if scoringFor(event.MetagameEventID) == scoredElsewhere {
+		return
+	}
+	if e.FactionVS == 0 && e.FactionNC == 0 && e.FactionTR == 0 {
+		return
+	}
+	event.Score = score{VS: e.FactionVS, NC: e.FactionNC, TR: e.FactionTR}
+}
+
+// emitEventScores emits an update for every running alert whose score changed from kills or experience.
+// Scores change far too often to send an update for each kill.
+func emitEventScores(manager *Manager) {
+	for id, event := range manager.alerts {
+		if !event.scoreChanged {
+			continue
+		}
+		event.scoreChanged = false
+		event.Timestamp = time.Now()
+		event.Provenance = manager.provenance(SourceWebsocket, event.Timestamp)
+		if t := manager.alertTrackers[id]; t != nil {
+			t.snapshot(event.Timestamp, event.Score)
+		}
+		emitEventUpdate(manager, (*event).Clone())
+	}
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestEventScore(t *testing.T) {
+	zone := ps2.ZoneInstanceID(ps2.Indar)
+	start := time.Now().Add(-10 * time.Minute)
+	started := event.MetagameEvent{
+		InstanceID:         7,
+		MetagameEventID:    ps2.IndarSuddenDeath,
+		MetagameEventState: ps2.Started,
+		Timestamp:          start,
+		WorldID:            ps2.Emerald,
+		ZoneID:             zone,
+	}
+	kill := func(attacker, victim ps2.FactionID) event.Death {
+		return event.Death{AttackerCharacterID: 1, AttackerTeamID: attacker, CharacterID: 2, TeamID: victim, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: start}
+	}
+
+	m := New(testStore{}, nil)
+	var updates []EventState
+	m.OnEventUpdate(func(e EventState) { updates = append(updates, e) })
+	handlePushEvent(context.Background(), m, started)
+	for _, e := range []event.Death{kill(VS, TR), kill(VS, NC), kill(TR, VS), kill(NC, NC)} {
+		handlePushEvent(context.Background(), m, e)
+	}
+	emitEventScores(m)
+	if got, want := updates[len(updates)-1].Score, (score{VS: 2, TR: 1}); got != want {
+		t.Errorf("got running score %+v; want %+v", got, want)
+	}
+	n := len(updates)
+	emitEventScores(m)
+	if len(updates) != n {
+		t.Errorf("an unchanged score was sent again")
+	}
+
+	ended := started
+	ended.MetagameEventState = ps2.Ended
+	ended.Timestamp = start.Add(5 * time.Minute)
+	ended.FactionVS, ended.FactionNC, ended.FactionTR = 40, 12, 30
+	handlePushEvent(context.Background(), m, ended)
+	last := updates[len(updates)-1]
+	if want := (score{VS: 40, NC: 12, TR: 30}); last.Score != want || last.Victor != VS {
+		t.Errorf("got final score %+v and victor %v; want %+v and VS", last.Score, last.Victor, want)
+	}
+}
+
+type experienceServer struct{}
+
+func (experienceServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"experience_list":[{"experience_id":"1","description":"Kill Player","xp":"100","experience_award_type_id":"1"},{"experience_id":"1993","description":"Aerial Anomaly Capture","xp":"10","experience_award_type_id":"63"}],"returned":2}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestDefaultScoreExperience(t *testing.T) {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: experienceServer{}})
+
+	m := New(testStore{}, client)
+	answerQueries(t, m)
+	loadScoreExperience(context.Background(), m)
+	if got, want := m.scoreExperience, []ps2.ExperienceID{1993}; !slices.Equal(got, want) {
+		t.Errorf("got score experience %v; want the objective experience %v", got, want)
+	}
+}
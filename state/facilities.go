@@ -0,0 +1,169 @@
+package state
+
+import (
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+const (
+	// baseTradeWindow is how far back captures are considered when looking for base trading.
+	baseTradeWindow = 30 * time.Minute
+
+	// baseTradeCaptures is the number of captures between the same two factions within baseTradeWindow
+	// before a facility is considered to be traded.
+	// Three captures means each side took the base at least once from the other.
+	baseTradeCaptures = 3
+)
+
+// FacilityHold describes how long a faction has held a facility.
+type FacilityHold struct {
+	RegionID   ps2.RegionID   `json:"region_id"`
+	FacilityID ps2.FacilityID `json:"facility_id"` // FacilityID is 0 until a facility event has been seen for the region
+	FactionID  ps2.FactionID  `json:"faction_id"`
+
+	// Since is the time the current owner captured the facility.
+	// Since is the zero time when the capture time is unknown,
+	// like for regions that haven't changed hands since tracking started.
+	Since time.Time `json:"since"`
+
+	// Held is the duration the facility has been held as of the query,
+	// or 0 when Since is unknown.
+	Held ps2.Seconds `json:"held"`
+
+	// RecentCaptures is the number of captures within the base trading window.
+	RecentCaptures int  `json:"recent_captures"`
+	Trading        bool `json:"trading"`
+}
+
+// BaseTrade is emitted when a facility is rapidly captured back and forth between the same two factions.
+type BaseTrade struct {
+	WorldID    ps2.WorldID
+	ZoneID     ps2.ZoneInstanceID
+	RegionID   ps2.RegionID
+	FacilityID ps2.FacilityID
+	Factions   [2]ps2.FactionID
+	Captures   int           // Captures is the number of captures within Window
+	Window     time.Duration // Window is the time between the first and last counted capture
+	Timestamp  time.Time
+}
+
+type capture struct {
+	from, to  ps2.FactionID
+	timestamp time.Time
+}
+
+// regionHold tracks ownership history for a single map region.
+type regionHold struct {
+	facilityID ps2.FacilityID
+	faction    ps2.FactionID
+	since      time.Time
+	captures   []capture // captures within baseTradeWindow, oldest first
+}
+
+// trade reports whether every capture within the trading window was between the same two factions.
+func (h *regionHold) trade() (factions [2]ps2.FactionID, ok bool) {
+	if len(h.captures) < baseTradeCaptures {
+		return factions, false
+	}
+	first := h.captures[0]
+	factions = [2]ps2.FactionID{first.from, first.to}
+	for _, c := range h.captures[1:] {
+		if !(c.from == factions[0] && c.to == factions[1]) && !(c.from == factions[1] && c.to == factions[0]) {
+			return factions, false
+		}
+	}
+	return factions, true
+}
+
+// prune drops captures older than the trading window.
+func (h *regionHold) prune(now time.Time) {
+	cutoff := now.Add(-baseTradeWindow)
+	i := 0
+	for i < len(h.captures) && h.captures[i].timestamp.Before(cutoff) {
+		i++
+	}
+	h.captures = h.captures[i:]
+}
+
+func (h regionHold) report(region ps2.RegionID, now time.Time) FacilityHold {
+	fh := FacilityHold{
+		RegionID:   region,
+		FacilityID: h.facilityID,
+		FactionID:  h.faction,
+		Since:      h.since,
+	}
+	if !h.since.IsZero() {
+		fh.Held = ps2.Seconds(now.Sub(h.since))
+	}
+	h.prune(now)
+	fh.RecentCaptures = len(h.captures)
+	_, fh.Trading = h.trade()
+	return fh
+}
+
+// holdsFor returns the tracked holds for a zone, creating them if needed.
+func (manager *Manager) holdsFor(id uniqueZone) map[ps2.RegionID]*regionHold {
+	holds := manager.holds[id]
+	if holds == nil {
+		holds = make(map[ps2.RegionID]*regionHold)
+		manager.holds[id] = holds
+	}
+	return holds
+}
+
+// trackHold records a facility control event for region.
+// Defenses are recorded too, since their DurationHeld tells us when the owner captured the facility.
+func trackHold(manager *Manager, id uniqueZone, region ps2.RegionID, e event.FacilityControl) {
+	holds := manager.holdsFor(id)
+	h := holds[region]
+	if h == nil {
+		h = &regionHold{}
+		holds[region] = h
+	}
+	h.facilityID = e.FacilityID
+
+	if e.NewFactionID == e.OldFactionID {
+		h.faction = e.NewFactionID
+		if e.DurationHeld > 0 {
+			h.since = e.Timestamp.Add(-e.DurationHeld)
+		}
+		return
+	}
+
+	h.faction = e.NewFactionID
+	h.since = e.Timestamp
+	h.captures = append(h.captures, capture{from: e.OldFactionID, to: e.NewFactionID, timestamp: e.Timestamp})
+	h.prune(e.Timestamp)
+
+	if factions, ok := h.trade(); ok {
+		emitBaseTrade(manager, BaseTrade{
+			WorldID:    id.WorldID,
+			ZoneID:     id.ZoneInstanceID,
+			RegionID:   region,
+			FacilityID: e.FacilityID,
+			Factions:   factions,
+			Captures:   len(h.captures),
+			Window:     e.Timestamp.Sub(h.captures[0].timestamp),
+			Timestamp:  e.Timestamp,
+		})
+	}
+}
+
+// syncHolds updates holds from a full map snapshot.
+// Regions that changed owner without us seeing the event have an unknown capture time.
+func syncHolds(manager *Manager, id uniqueZone, territory map[ps2.RegionID]ps2.FactionID) {
+	holds := manager.holdsFor(id)
+	for region, faction := range territory {
+		h := holds[region]
+		if h == nil {
+			holds[region] = &regionHold{faction: faction}
+			continue
+		}
+		if h.faction != faction {
+			h.faction = faction
+			h.since = time.Time{}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestFacilityControlLookupTables(t *testing.T) {
+	// testStore doesn't know any facilities, so the region comes from the ps2 lookup tables
+	m := New(testStore{}, nil)
+	const facility ps2.FacilityID = 7500
+	region, ok := ps2.FacilityRegion(facility)
+	if !ok {
+		t.Fatalf("facility %d is missing from the lookup tables", facility)
+	}
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Indar)}
+	handlePushEvent(context.Background(), m, event.FacilityControl{
+		FacilityID:   facility,
+		OldFactionID: TR,
+		NewFactionID: VS,
+		WorldID:      zone.WorldID,
+		ZoneID:       zone.ZoneInstanceID,
+		Timestamp:    time.Now(),
+	})
+	if got := m.state.getZoneptr(zone).Regions.Territory[region]; got != VS {
+		t.Errorf("got region %d owned by %v; want VS", region, got)
+	}
+}
+
+func TestTerritoryChangeCapture(t *testing.T) {
+	m := New(testStore{}, nil)
+	const facility ps2.FacilityID = 7500
+	var got []TerritoryChange
+	m.OnTerritoryChange(func(tc TerritoryChange) { got = append(got, tc) })
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Indar)}
+	handlePushEvent(context.Background(), m, event.FacilityControl{
+		DurationHeld: 90 * time.Minute,
+		FacilityID:   facility,
+		OldFactionID: TR,
+		NewFactionID: VS,
+		OutfitID:     37509488620604883,
+		WorldID:      zone.WorldID,
+		ZoneID:       zone.ZoneInstanceID,
+		Timestamp:    time.Now(),
+	})
+	if len(got) == 0 {
+		t.Fatal("expected a territory change")
+	}
+	// a zone that was previously locked also emits the regions that didn't flip, before the capture
+	tc := got[len(got)-1]
+	wantType, _ := ps2.FacilityType(facility)
+	if tc.FacilityID != facility || tc.FacilityType != wantType || tc.OutfitID != 37509488620604883 || tc.DurationHeld != 90*time.Minute {
+		t.Errorf("got %+v; want the details of the capture of facility %d", tc, facility)
+	}
+}
+
+func facilityCapture(facility ps2.FacilityID, from, to ps2.FactionID, at time.Time) event.FacilityControl {
+	return event.FacilityControl{
+		FacilityID:   facility,
+		OldFactionID: from,
+		NewFactionID: to,
+		WorldID:      ps2.Emerald,
+		ZoneID:       ps2.ZoneInstanceID(ps2.Indar),
+		Timestamp:    at,
+	}
+}
+
+func TestBaseTrade(t *testing.T) {
+	m := New(testStore{}, nil)
+	const facility ps2.FacilityID = 7500
+	region, _ := ps2.FacilityRegion(facility)
+	var trades []BaseTrade
+	m.OnBaseTrade(func(bt BaseTrade) { trades = append(trades, bt) })
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour)
+
+	// a capture outside the window isn't counted with the others
+	handlePushEvent(ctx, m, facilityCapture(facility, NC, TR, start))
+	handlePushEvent(ctx, m, facilityCapture(facility, TR, VS, start.Add(40*time.Minute)))
+	handlePushEvent(ctx, m, facilityCapture(facility, VS, TR, start.Add(45*time.Minute)))
+	if len(trades) != 0 {
+		t.Fatalf("got %d trades after two captures in the window; want 0", len(trades))
+	}
+	handlePushEvent(ctx, m, facilityCapture(facility, TR, VS, start.Add(50*time.Minute)))
+	if len(trades) != 1 {
+		t.Fatalf("got %d trades after three captures; want 1", len(trades))
+	}
+	bt := trades[0]
+	if bt.RegionID != region || bt.FacilityID != facility || bt.Factions != [2]ps2.FactionID{TR, VS} || bt.Captures != 3 || bt.Window != 10*time.Minute {
+		t.Errorf("got %+v; want 3 captures between TR and VS over 10 minutes", bt)
+	}
+
+	// trading continues to be reported
+	handlePushEvent(ctx, m, facilityCapture(facility, VS, TR, start.Add(55*time.Minute)))
+	if len(trades) != 2 || trades[1].Captures != 4 {
+		t.Fatalf("got trades %+v; want a second trade with 4 captures", trades)
+	}
+
+	// a third faction taking the base ends the trade
+	handlePushEvent(ctx, m, facilityCapture(facility, TR, NC, start.Add(56*time.Minute)))
+	if len(trades) != 2 {
+		t.Errorf("got %d trades after NC captured the base; want 2", len(trades))
+	}
+}
+
+func TestFacilityHolds(t *testing.T) {
+	m := New(testStore{}, nil)
+	answerQueries(t, m)
+	ctx := context.Background()
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Indar)}
+	now := time.Now()
+	const captured, defended ps2.FacilityID = 7500, 7801
+	capturedRegion, _ := ps2.FacilityRegion(captured)
+	defendedRegion, ok := ps2.FacilityRegion(defended)
+	if !ok {
+		t.Fatalf("facility %d is missing from the lookup tables", defended)
+	}
+
+	handlePushEvent(ctx, m, facilityCapture(captured, TR, VS, now.Add(-10*time.Minute)))
+	// defenses tell us how long the owner has held the facility
+	defense := facilityCapture(defended, NC, NC, now.Add(-5*time.Minute))
+	defense.DurationHeld = time.Hour
+	handlePushEvent(ctx, m, defense)
+	// a map snapshot only has owners, so a changed owner has an unknown capture time
+	syncHolds(m, zone, map[ps2.RegionID]ps2.FactionID{capturedRegion: VS, defendedRegion: TR, 1: NC})
+
+	holds, err := m.FacilityHolds(context.Background(), zone.WorldID, zone.ZoneInstanceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byRegion := make(map[ps2.RegionID]FacilityHold)
+	for i, h := range holds {
+		if i > 0 && holds[i-1].RegionID >= h.RegionID {
+			t.Errorf("got holds out of region order: %+v", holds)
+		}
+		byRegion[h.RegionID] = h
+	}
+	if len(byRegion) != 3 {
+		t.Fatalf("got %d holds; want 3", len(byRegion))
+	}
+	if h := byRegion[capturedRegion]; h.FacilityID != captured || h.FactionID != VS || !h.Since.Equal(now.Add(-10*time.Minute)) || h.Held.Duration() < 10*time.Minute || h.RecentCaptures != 1 || h.Trading {
+		t.Errorf("got %+v for the captured facility; want VS holding it for 10 minutes", h)
+	}
+	if h := byRegion[defendedRegion]; h.FactionID != TR || !h.Since.IsZero() || h.Held != 0 {
+		t.Errorf("got %+v for the defended facility; want TR holding it since an unknown time", h)
+	}
+	if h := byRegion[1]; h.FactionID != NC || !h.Since.IsZero() || h.FacilityID != 0 {
+		t.Errorf("got %+v for a region only seen in a snapshot", h)
+	}
+
+	if _, err := m.FacilityHolds(context.Background(), ps2.Emerald, ps2.ZoneInstanceID(ps2.Hossin)); err == nil {
+		t.Error("expected an error for an untracked zone")
+	}
+}
+
+func TestFacilityHoldDefense(t *testing.T) {
+	m := New(testStore{}, nil)
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Indar)}
+	const facility ps2.FacilityID = 7500
+	region, _ := ps2.FacilityRegion(facility)
+	at := time.Now()
+	defense := facilityCapture(facility, NC, NC, at)
+	defense.DurationHeld = time.Hour
+	handlePushEvent(context.Background(), m, defense)
+
+	h := m.holds[zone][region]
+	if h == nil {
+		t.Fatal("the defense wasn't tracked")
+	}
+	if h.faction != NC || !h.since.Equal(at.Add(-time.Hour)) || len(h.captures) != 0 {
+		t.Errorf("got %+v; want NC holding since an hour before the defense, without a capture", h)
+	}
+}
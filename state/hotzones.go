@@ -0,0 +1,111 @@
+package state
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+const (
+	// hotZoneCaptureWeight is how many players a recent facility capture is worth when ranking zones.
+	// Captures matter more than raw population on quiet worlds where a few dozen players are still fighting over bases.
+	hotZoneCaptureWeight = 10
+
+	// hotZoneEventBonus multiplies the activity of zones with a running alert.
+	hotZoneEventBonus = 1.5
+
+	// hotZoneNotifyLimit is the number of zones sent to OnHotZones handlers.
+	hotZoneNotifyLimit = 10
+)
+
+// HotZone is a zone ranked by how active its fights are.
+type HotZone struct {
+	WorldID   ps2.WorldID        `json:"world_id"`
+	WorldName string             `json:"world_name"`
+	ZoneID    ps2.ZoneInstanceID `json:"zone_id"`
+	ZoneName  string             `json:"zone_name"`
+
+	Population zonepop `json:"population"`
+	Players    int     `json:"players"`
+
+	// RecentCaptures is the number of facility captures within the base trading window.
+	RecentCaptures int `json:"recent_captures"`
+
+	Event *EventState `json:"event"`
+
+	// Activity is the ranking score.
+	// It's only meaningful compared to other zones from the same call.
+	Activity float64 `json:"activity"`
+}
+
+// HotZones returns up to limit unlocked zones across all tracked worlds,
+// ranked from most to least active.
+// Activity combines zone population, recent facility captures, and running alerts.
+// Zones with no players and no captures are left out.
+// A limit of 0 or less returns every active zone.
+func (manager *Manager) HotZones(ctx context.Context, limit int) ([]HotZone, error) {
+	return askContext(ctx, manager, func(manager *Manager) []HotZone {
+		return hotZones(manager, time.Now(), limit)
+	})
+}
+
+// OnHotZones adds a function that will be called with the most active zones every time populations are counted.
+func (manager *Manager) OnHotZones(f func([]HotZone)) {
+	manager.hotZoneHandlers = append(manager.hotZoneHandlers, f)
+}
+
+func emitHotZones(manager *Manager) {
+	if len(manager.hotZoneHandlers) == 0 {
+		return
+	}
+	zones := hotZones(manager, time.Now(), hotZoneNotifyLimit)
+	for _, f := range manager.hotZoneHandlers {
+		f(slices.Clone(zones))
+	}
+}
+
+func hotZones(manager *Manager, now time.Time, limit int) []HotZone {
+	var zones []HotZone
+	for _, world := range manager.state.Worlds {
+		for _, zone := range world.Zones {
+			if zone.ContinentState == psmap.Locked {
+				continue
+			}
+			hz := HotZone{
+				WorldID:    world.WorldID,
+				WorldName:  world.Name,
+				ZoneID:     zone.MapID,
+				ZoneName:   zone.ZoneName,
+				Population: zone.Population,
+				Players:    zone.Population.VS + zone.Population.NC + zone.Population.TR,
+			}
+			for _, h := range manager.holds[uniqueZone{world.WorldID, zone.MapID}] {
+				hz.RecentCaptures += h.report(0, now).RecentCaptures
+			}
+			if hz.Players == 0 && hz.RecentCaptures == 0 {
+				continue
+			}
+			hz.Activity = float64(hz.Players + hotZoneCaptureWeight*hz.RecentCaptures)
+			if zone.Event != nil && zone.Event.Ended == nil {
+				e := zone.Event.Clone()
+				hz.Event = &e
+				hz.Activity *= hotZoneEventBonus
+			}
+			zones = append(zones, hz)
+		}
+	}
+	slices.SortStableFunc(zones, func(a, b HotZone) int {
+		if c := cmp.Compare(b.Activity, a.Activity); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Players, a.Players)
+	})
+	if limit > 0 && len(zones) > limit {
+		zones = zones[:limit]
+	}
+	return zones
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+// hotZoneManager returns a manager with Indar, Amerish, Hossin, and Esamir on Emerald.
+// Indar has 30 players and a running alert,
+// Amerish has 20 players and 2 recent captures,
+// Hossin is empty, and Esamir is locked.
+func hotZoneManager(t *testing.T, now time.Time) *Manager {
+	t.Helper()
+	m := New(testStore{}, nil)
+	emerald := census.World{WorldID: ps2.Emerald}
+	for _, cont := range []ps2.ContinentID{ps2.Amerish, ps2.Hossin, ps2.Esamir} {
+		m.state.trackZone(emerald, ps2.ZoneInstanceID(cont), census.Zone{ContinentID: cont, ZoneID: ps2.ZoneID(cont)})
+	}
+	zone := func(cont ps2.ContinentID) uniqueZone { return uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(cont)} }
+	for _, cont := range []ps2.ContinentID{ps2.Indar, ps2.Amerish, ps2.Hossin} {
+		m.state.getZoneptr(zone(cont)).ContinentState = psmap.Unlocked
+	}
+	m.state.setZonePop(zone(ps2.Indar), popCounter{VS: 10, NC: 10, TR: 10})
+	m.state.setZonePop(zone(ps2.Amerish), popCounter{VS: 5, NC: 5, TR: 10})
+	m.state.setZonePop(zone(ps2.Esamir), popCounter{VS: 50, NC: 50, TR: 50})
+
+	holds := m.holdsFor(zone(ps2.Amerish))
+	holds[6301] = &regionHold{captures: []capture{{from: TR, to: VS, timestamp: now.Add(-time.Minute)}}}
+	holds[6302] = &regionHold{captures: []capture{
+		// captures outside the base trading window don't count
+		{from: VS, to: TR, timestamp: now.Add(-time.Hour)},
+		{from: TR, to: NC, timestamp: now.Add(-2 * time.Minute)},
+	}}
+
+	handlePushEvent(context.Background(), m, event.MetagameEvent{
+		InstanceID:         7,
+		MetagameEventID:    ps2.IndarSuddenDeath,
+		MetagameEventState: ps2.Started,
+		Timestamp:          now,
+		WorldID:            ps2.Emerald,
+		ZoneID:             ps2.ZoneInstanceID(ps2.Indar),
+	})
+	return m
+}
+
+func TestHotZones(t *testing.T) {
+	m := hotZoneManager(t, time.Now())
+	answerQueries(t, m)
+
+	zones, err := m.HotZones(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 {
+		t.Fatalf("got %d zones; want Indar and Amerish", len(zones))
+	}
+	indar, amerish := zones[0], zones[1]
+	if indar.ZoneID != ps2.ZoneInstanceID(ps2.Indar) || indar.Players != 30 || indar.Event == nil || indar.Activity != 45 {
+		t.Errorf("got %+v; want Indar first with 30 players and an alert scoring 45", indar)
+	}
+	// 20 players and 2 captures worth 10 players each
+	if amerish.ZoneID != ps2.ZoneInstanceID(ps2.Amerish) || amerish.RecentCaptures != 2 || amerish.Event != nil || amerish.Activity != 40 {
+		t.Errorf("got %+v; want Amerish with 2 recent captures scoring 40", amerish)
+	}
+
+	zones, err = m.HotZones(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 1 || zones[0].ZoneID != ps2.ZoneInstanceID(ps2.Indar) {
+		t.Errorf("got %+v; want only Indar", zones)
+	}
+}
+
+func TestHotZonesContext(t *testing.T) {
+	m := New(testStore{}, nil)
+	close(m.running)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.HotZones(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestEmitHotZones(t *testing.T) {
+	m := hotZoneManager(t, time.Now())
+	// populations are counted every fifteen seconds,
+	// and handlers get their own copy of the ranking every time
+	var first, second [][]HotZone
+	m.OnHotZones(func(zones []HotZone) {
+		first = append(first, zones)
+		zones[0] = HotZone{}
+	})
+	m.OnHotZones(func(zones []HotZone) { second = append(second, zones) })
+	emitHotZones(m)
+	emitHotZones(m)
+
+	if len(first) != 2 || len(second) != 2 {
+		t.Fatalf("got %d and %d emits; want 2 each", len(first), len(second))
+	}
+	for _, zones := range second {
+		if len(zones) != 2 || zones[0].ZoneID != ps2.ZoneInstanceID(ps2.Indar) {
+			t.Errorf("got %+v; want Indar and Amerish unchanged by the other handler", zones)
+		}
+	}
+}
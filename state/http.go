@@ -0,0 +1,119 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+// Handler returns an HTTP handler that serves the Manager's state as JSON,
+// so that dashboards and other programs can read it without importing Go code.
+// Every response is a copy taken from the running Manager,
+// encoded with the same JSON fields as the types it returns:
+//
+//	GET /worlds                          every tracked world, as []WorldState
+//	GET /worlds/{world}                  a single world, as WorldState
+//	GET /worlds/{world}/zones            the zones of a world, as []ZoneState
+//	GET /worlds/{world}/zones/{zone}     a single zone, as ZoneState
+//	GET /worlds/{world}/zones/{zone}/outfits
+//	                                     outfit activity in a zone, as []OutfitActivity
+//	GET /alerts                          alerts that haven't ended, as []EventState
+//	GET /population                      population of every world, as PopulationTotal
+//
+// {world} and {zone} are numeric IDs; {zone} is the census map ID of the zone.
+// Unknown worlds and zones respond with 404 Not Found,
+// and requests made while the Manager isn't running respond with 503 Service Unavailable.
+//
+// The handler is meant to be mounted with [http.StripPrefix] under any path.
+// It doesn't authenticate requests.
+func (manager *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /worlds", func(w http.ResponseWriter, r *http.Request) {
+		s, err := manager.GlobalSnapshot(r.Context())
+		writeJSON(w, s.Worlds, err)
+	})
+	mux.HandleFunc("GET /worlds/{world}", func(w http.ResponseWriter, r *http.Request) {
+		world, ok := pathID[ps2.WorldID](w, r, "world")
+		if !ok {
+			return
+		}
+		ws, err := manager.WorldState(r.Context(), world)
+		writeJSON(w, ws, err)
+	})
+	mux.HandleFunc("GET /worlds/{world}/zones", func(w http.ResponseWriter, r *http.Request) {
+		world, ok := pathID[ps2.WorldID](w, r, "world")
+		if !ok {
+			return
+		}
+		ws, err := manager.WorldState(r.Context(), world)
+		writeJSON(w, ws.Zones, err)
+	})
+	mux.HandleFunc("GET /worlds/{world}/zones/{zone}", func(w http.ResponseWriter, r *http.Request) {
+		world, ok := pathID[ps2.WorldID](w, r, "world")
+		if !ok {
+			return
+		}
+		zone, ok := pathID[ps2.ZoneInstanceID](w, r, "zone")
+		if !ok {
+			return
+		}
+		zs, err := manager.ZoneState(r.Context(), world, zone)
+		writeJSON(w, zs, err)
+	})
+	mux.HandleFunc("GET /worlds/{world}/zones/{zone}/outfits", func(w http.ResponseWriter, r *http.Request) {
+		world, ok := pathID[ps2.WorldID](w, r, "world")
+		if !ok {
+			return
+		}
+		zone, ok := pathID[ps2.ZoneInstanceID](w, r, "zone")
+		if !ok {
+			return
+		}
+		if _, err := manager.ZoneState(r.Context(), world, zone); err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		outfits, err := manager.Outfits(r.Context(), world, zone)
+		writeJSON(w, outfits, err)
+	})
+	mux.HandleFunc("GET /alerts", func(w http.ResponseWriter, r *http.Request) {
+		alerts, err := manager.ActiveAlerts(r.Context())
+		writeJSON(w, alerts, err)
+	})
+	mux.HandleFunc("GET /population", func(w http.ResponseWriter, r *http.Request) {
+		s, err := manager.GlobalSnapshot(r.Context())
+		writeJSON(w, s.Population(), err)
+	})
+	return mux
+}
+
+// pathID parses the path value name of r as a numeric ID,
+// writing a 404 response when it isn't one.
+func pathID[T ~uint16 | ~uint32](w http.ResponseWriter, r *http.Request, name string) (T, bool) {
+	id, err := strconv.ParseUint(r.PathValue(name), 10, 64)
+	if err != nil || uint64(T(id)) != id {
+		http.NotFound(w, r)
+		return 0, false
+	}
+	return T(id), true
+}
+
+// writeJSON writes v as the JSON response,
+// or an error response for err.
+func writeJSON(w http.ResponseWriter, v any, err error) {
+	switch {
+	case errors.Is(err, errGoneHome), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		// the remaining query errors are for worlds and zones that aren't tracked
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,60 @@
+package state
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+)
+
+func TestHandler(t *testing.T) {
+	m := New(testStore{}, nil)
+	answerQueries(t, m)
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/worlds/17/zones/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zone ZoneState
+	err = json.NewDecoder(resp.Body).Decode(&zone)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || zone.MapID != ps2.ZoneInstanceID(ps2.Indar) {
+		t.Errorf("got status %d and zone %d; want 200 and Indar", resp.StatusCode, zone.MapID)
+	}
+
+	for _, path := range []string{"/worlds/1", "/worlds/17/zones/4", "/worlds/emerald", "/worlds/70000"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("got status %d for %s; want 404", resp.StatusCode, path)
+		}
+	}
+}
+
+func TestHandlerNotRunning(t *testing.T) {
+	m := New(testStore{}, nil)
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	// queries made before Run fail immediately instead of waiting for it
+	for _, path := range []string{"/worlds", "/worlds/17/zones/2", "/alerts"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("got status %d for %s before Run; want 503", resp.StatusCode, path)
+		}
+	}
+}
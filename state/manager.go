@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Travis-Britz/ps2"
@@ -36,18 +36,19 @@ type gameDataStore interface {
 	GetFacility(ps2.FacilityID) census.Facility
 	GetPlayerFaction(ps2.CharacterID) ps2.FactionID
 	SavePlayerFaction(ps2.CharacterID, ps2.FactionID)
-	GetFacilityRegion(ps2.FacilityID) ps2.RegionID
+	GetFacilityRegion(ps2.FacilityID) ps2.RegionID // GetFacilityRegion may return 0 to use the ps2 lookup tables
 	GetMap(id ps2.ContinentID) (psmap.Map, error)
 }
 
 func New(db gameDataStore, censusClient *census.Client) *Manager {
 	factionLookups := make(chan ps2.CharacterID, 10)
 	m := &Manager{
-		logf:         func(string, ...any) {},
-		gameData:     db,
-		census:       censusClient,
-		alerts:       make(map[ps2.MetagameEventInstanceID]*EventState),
-		alertUpdates: make(chan ps2alerts.Alert),
+		logf:          func(string, ...any) {},
+		gameData:      db,
+		census:        censusClient,
+		alerts:        make(map[ps2.MetagameEventInstanceID]*EventState),
+		alertTrackers: make(map[ps2.MetagameEventInstanceID]*alertTracker),
+		alertUpdates:  make(chan ps2alerts.Alert),
 		players: onlinePlayerStore{
 			players:        make(map[ps2.CharacterID]onlinePlayerState),
 			factionLookups: factionLookups,
@@ -55,10 +56,19 @@ func New(db gameDataStore, censusClient *census.Client) *Manager {
 		},
 		censusPushEvents:        make(chan event.Typer, 5000),
 		mapUpdates:              make(chan census.ZoneState, 10),
-		zoneLookups:             make(map[uniqueZone]time.Time),
+		zoneLookups:             make(map[uniqueZone]zoneLookup),
+		zoneLookupResults:       make(chan zoneLookupResult, 10),
+		zoneLookupTimeout:       defaultZoneLookupTimeout,
+		holds:                   make(map[uniqueZone]map[ps2.RegionID]*regionHold),
+		outfits:                 make(map[uniqueOutfit]*outfitStats),
 		characterFactionResults: make(chan factionResult, 10),
 		characterFactionLookups: factionLookups,
 		queryQueue:              make(chan query),
+		logouts:                 event.NewLogoutInferrer(event.DefaultLogoutWindow),
+		shutdownRequests:        make(chan shutdownRequest),
+		stopping:                make(chan struct{}),
+		running:                 make(chan struct{}),
+		unavailable:             make(chan struct{}),
 	}
 
 	// initialize state for all static zones on all worlds
@@ -79,20 +89,49 @@ type Manager struct {
 	gameData                 gameDataStore
 	census                   *census.Client
 	alerts                   map[ps2.MetagameEventInstanceID]*EventState
+	alertTrackers            map[ps2.MetagameEventInstanceID]*alertTracker
 	state                    GlobalState
 	players                  onlinePlayerStore
 	alertUpdates             chan ps2alerts.Alert
 	mapUpdates               chan census.ZoneState
+	mapPolling               MapPolling
+	alertPolling             ps2alerts.PollOptions
+	onlineReconciliation     time.Duration      // onlineReconciliation is how often quiet players are checked with census
+	scoreExperience          []ps2.ExperienceID // scoreExperience is the experience that scores points in alerts won by points
+	scoreExperienceSet       bool               // scoreExperienceSet is true when SetScoreExperience replaced the defaults
+	mapPollFailing           atomic.Bool        // mapPollFailing is set by pollMaps while polls are failing
+	stateStore               StateStore
+	logouts                  *event.LogoutInferrer // logouts infers the logouts census didn't send
+	checkpointInterval       time.Duration
+	checkpointing            atomic.Bool // checkpointing is set while a checkpoint is being saved
 	censusPushEvents         chan event.Typer
-	zoneLookups              map[uniqueZone]time.Time // zoneLookups is a cache of queried zone IDs
+	zoneLookups              map[uniqueZone]zoneLookup // zoneLookups is a cache of queried zone IDs
+	zoneLookupResults        chan zoneLookupResult
+	zoneLookupTimeout        time.Duration // zoneLookupTimeout limits the census request made by checkZone
+	holds                    map[uniqueZone]map[ps2.RegionID]*regionHold
+	outfits                  map[uniqueOutfit]*outfitStats
 	characterFactionResults  chan factionResult
 	characterFactionLookups  chan ps2.CharacterID
 	queryQueue               chan query    // queryQueue is a channel of external requests to access the Manager
-	unavailable              chan struct{} // unavailable is closed when the manager shuts down
+	running                  chan struct{} // running is closed when Run starts
+	unavailable              chan struct{} // unavailable is closed when Run returns
+	shutdownRequests         chan shutdownRequest
+	stopping                 chan struct{} // stopping is closed when Shutdown is called to stop accepting events
+	stopOnce                 sync.Once
 	populationHandlers       []func(PopulationTotal)
 	territoryChangeHandlers  []func(TerritoryChange)
 	zoneStatusChangeHandlers []func(ZoneStatusChange)
 	eventUpdateHandlers      []func(EventState)
+	baseTradeHandlers        []func(BaseTrade)
+	nsoTeamChangeHandlers    []func(NSOTeamChange)
+	hotZoneHandlers          []func([]HotZone)
+	alertReportHandlers      []func(AlertReport)
+	outfitUpdateHandlers     []func([]OutfitActivity)
+	shutdownHandlers         []func(GlobalState)
+	populationSubs           subscribers[PopulationTotal]
+	territoryChangeSubs      subscribers[TerritoryChange]
+	zoneStatusChangeSubs     subscribers[ZoneStatusChange]
+	eventUpdateSubs          subscribers[EventState]
 }
 
 // AttachHandlers attaches the required handlers to client.
@@ -105,20 +144,52 @@ func (manager *Manager) AttachHandlers(client eventClient) {
 	client.AddHandler(manager.handleVehicleDestroy)
 	client.AddHandler(manager.handleMetagame)
 	client.AddHandler(manager.handleGainExperience)
+	client.AddHandler(manager.handlePlayerFacilityCapture)
+	client.AddHandler(manager.handlePlayerFacilityDefend)
 }
 
 // Run starts the Manager,
 // blocking until ctx is cancelled.
+//
+// Use [Manager.Shutdown] to stop gracefully without losing queued events.
+// A Manager can't be run again after Run returns.
 func (manager *Manager) Run(ctx context.Context) {
 	manager.mu.Lock()
 	defer manager.mu.Unlock()
+	select {
+	case <-manager.unavailable:
+		return
+	default:
+	}
+	close(manager.running)
+	defer close(manager.unavailable)
+	defer manager.closeSubscriptions()
+	// Shutdown checks running after closing stopping,
+	// so a Shutdown that raced this startup is seen here
+	select {
+	case <-manager.stopping:
+		return
+	default:
+	}
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
 	everyFifteenSeconds := time.NewTicker(15 * time.Second)
 	defer everyFifteenSeconds.Stop()
-	manager.unavailable = make(chan struct{})
-	defer close(manager.unavailable)
+	var checkpoints <-chan time.Time
+	if manager.stateStore != nil {
+		ticker := time.NewTicker(manager.checkpointInterval)
+		defer ticker.Stop()
+		checkpoints = ticker.C
+	}
 
-	go getMapData(ctx, manager, manager.mapUpdates)
-	go updateActiveEventInstances(ctx, manager.alertUpdates)
+	go pollMaps(ctx, manager)
+	go pollAlerts(ctx, manager)
+	if manager.onlineReconciliation > 0 {
+		go reconcileOnline(ctx, manager)
+	}
+	if !manager.scoreExperienceSet && manager.census != nil {
+		go loadScoreExperience(ctx, manager)
+	}
 	go func() {
 		for {
 			select {
@@ -139,97 +210,178 @@ func (manager *Manager) Run(ctx context.Context) {
 			handlePS2AlertsResponse(manager, alertData)
 		case mapData := <-manager.mapUpdates:
 			handleMap(manager, mapData)
+		case result := <-manager.zoneLookupResults:
+			handleZoneLookup(manager, result)
 		case result := <-manager.characterFactionResults:
 			manager.players.factionUpdate(result.CharacterID, result.FactionID)
 		case e := <-manager.censusPushEvents:
-			switch event := e.(type) {
-			case event.ContinentLock:
-				handleLock(manager, event)
-			case event.PlayerLogout:
-				handleLogout(manager, event)
-			case event.PlayerLogin:
-				handleLogin(manager, event)
-			case event.MetagameEvent:
-				checkZone(ctx, manager, uniqueZone{event.WorldID, event.ZoneID})
-				// if the zone needs to be initialized,
-				// then this won't immediately track the alert.
-				// handleMetagame will spawn a goroutine to fill data from ps2alerts though, which could also fail.
-				// if the census api fails, the alert might not be initialized until one of the polls to /active on ps2alerts,
-				// assuming their site is functioning and it's a territory alert.
-				handleMetagame(ctx, manager, event)
-			case event.Death:
-				handleDeath(manager, event)
-			case event.VehicleDestroy:
-				handleVehicleDestroy(manager, event)
-			case event.GainExperience:
-				handleGainExperience(manager, event)
-			case event.FacilityControl:
-				checkZone(ctx, manager, uniqueZone{event.WorldID, event.ZoneID})
-				handleFacilityControl(manager, event) // when warpgates change, send to unlocks channel
-			}
+			handlePushEvent(ctx, manager, e)
 		case <-everyFifteenSeconds.C:
 			countPlayers(manager)
 			removeStaleEvents(manager)
+			emitEventScores(manager)
+			emitHotZones(manager)
+		case <-checkpoints:
+			checkpoint(manager)
 		case query := <-manager.queryQueue:
 			query.Ask(manager)
+		case req := <-manager.shutdownRequests:
+			req.done <- shutdown(req.ctx, ctx, manager)
+			return
 		}
 	}
 }
-func (m *Manager) handleFacilityControl(e event.FacilityControl) {
+
+func handlePushEvent(ctx context.Context, manager *Manager, e event.Typer) {
+	manager.logouts.Observe(e)
+	switch event := e.(type) {
+	case event.ContinentLock:
+		handleLock(manager, event)
+	case event.PlayerLogout:
+		handleLogout(manager, event)
+	case event.PlayerLogin:
+		handleLogin(manager, event)
+	case event.MetagameEvent:
+		checkZone(ctx, manager, uniqueZone{event.WorldID, event.ZoneID})
+		// if the zone needs to be initialized,
+		// then this won't immediately track the alert.
+		// handleMetagame will spawn a goroutine to fill data from ps2alerts though, which could also fail.
+		// if the census api fails, the alert might not be initialized until one of the polls to /active on ps2alerts,
+		// assuming their site is functioning and it's a territory alert.
+		handleMetagame(ctx, manager, event)
+	case event.Death:
+		handleDeath(manager, event)
+		recordOutfitDeath(manager, event)
+		scoreDeath(manager, event)
+	case event.VehicleDestroy:
+		handleVehicleDestroy(manager, event)
+		recordOutfitVehicleDestroy(manager, event)
+	case event.GainExperience:
+		handleGainExperience(manager, event)
+		scoreExperience(manager, event)
+	case event.FacilityControl:
+		checkZone(ctx, manager, uniqueZone{event.WorldID, event.ZoneID})
+		handleFacilityControl(manager, event) // when warpgates change, send to unlocks channel
+		recordOutfitFacilityControl(manager, event)
+	case event.PlayerFacilityCapture:
+		handlePlayerFacilityCapture(manager, event)
+	case event.PlayerFacilityDefend:
+		handlePlayerFacilityDefend(manager, event)
+	}
+}
+
+type shutdownRequest struct {
+	ctx  context.Context
+	done chan error // done must be buffered
+}
+
+// Shutdown gracefully stops a running Manager.
+//
+// Shutdown happens in this order:
+//  1. Incoming events from the event client are no longer accepted.
+//  2. Events, map updates, and lookup results that were already queued are processed until none are left or ctx is done,
+//     emitting notifications as usual.
+//  3. Each OnShutdown handler is called with the final state.
+//  4. Run returns, and queries against the Manager return errors.
+//
+// No notifications are emitted after Shutdown returns.
+// The returned error is ctx.Err() if the queue could not be drained
+// or the final checkpoint could not be saved before ctx was done,
+// in which case the remaining work is abandoned but the final state is still emitted.
+//
+// Shutdown returns an error immediately if Run hasn't started,
+// and Run returns immediately if it's called after Shutdown.
+func (manager *Manager) Shutdown(ctx context.Context) error {
+	manager.stopOnce.Do(func() { close(manager.stopping) })
+	if !manager.started() {
+		return errGoneHome
+	}
+	req := shutdownRequest{ctx: ctx, done: make(chan error, 1)}
 	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
-		return
+	case manager.shutdownRequests <- req:
+	case <-manager.unavailable:
+		return errGoneHome
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+	return <-req.done
 }
-func (m *Manager) handleGainExperience(e event.GainExperience) {
-	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
-		return
+
+// shutdown drains queued work and emits the final state.
+// deadline is the context given to Shutdown and ctx is the context of Run.
+func shutdown(deadline context.Context, ctx context.Context, manager *Manager) (err error) {
+drain:
+	for {
+		select {
+		case <-deadline.Done():
+			err = deadline.Err()
+			break drain
+		case e := <-manager.censusPushEvents:
+			handlePushEvent(ctx, manager, e)
+		case alertData := <-manager.alertUpdates:
+			handlePS2AlertsResponse(manager, alertData)
+		case mapData := <-manager.mapUpdates:
+			handleMap(manager, mapData)
+		case result := <-manager.zoneLookupResults:
+			handleZoneLookup(manager, result)
+		case result := <-manager.characterFactionResults:
+			manager.players.factionUpdate(result.CharacterID, result.FactionID)
+		default:
+			break drain
+		}
+	}
+	final := manager.state.Clone()
+	if manager.stateStore != nil && err == nil {
+		err = saveFinalCheckpoint(deadline, manager, final)
+	}
+	for _, f := range manager.shutdownHandlers {
+		f(final)
 	}
+	return err
 }
-func (m *Manager) handleMetagame(e event.MetagameEvent) {
+
+// enqueue sends e to the Manager's event queue unless the Manager is stopping.
+func (m *Manager) enqueue(e event.Typer) {
 	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
+	case <-m.stopping:
 		return
+	default:
 	}
-}
-func (m *Manager) handleVehicleDestroy(e event.VehicleDestroy) {
 	select {
 	case m.censusPushEvents <- e:
+	case <-m.stopping:
 	case <-m.unavailable:
-		return
 	}
 }
+func (m *Manager) handleFacilityControl(e event.FacilityControl) {
+	m.enqueue(e)
+}
+func (m *Manager) handleGainExperience(e event.GainExperience) {
+	m.enqueue(e)
+}
+func (m *Manager) handleMetagame(e event.MetagameEvent) {
+	m.enqueue(e)
+}
+func (m *Manager) handleVehicleDestroy(e event.VehicleDestroy) {
+	m.enqueue(e)
+}
 func (m *Manager) handleDeath(e event.Death) {
-	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
-		return
-	}
+	m.enqueue(e)
 }
 func (m *Manager) handleContinentLock(e event.ContinentLock) {
-	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
-		return
-	}
+	m.enqueue(e)
 }
 func (m *Manager) handleLogin(e event.PlayerLogin) {
-	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
-		return
-	}
+	m.enqueue(e)
 }
 func (m *Manager) handleLogout(e event.PlayerLogout) {
-	select {
-	case m.censusPushEvents <- e:
-	case <-m.unavailable:
-		return
-	}
+	m.enqueue(e)
+}
+func (m *Manager) handlePlayerFacilityCapture(e event.PlayerFacilityCapture) {
+	m.enqueue(e)
+}
+func (m *Manager) handlePlayerFacilityDefend(e event.PlayerFacilityDefend) {
+	m.enqueue(e)
 }
 
 type factionSaver interface {
@@ -243,9 +395,12 @@ type onlinePlayerStore struct {
 	saver          factionSaver
 }
 
-func (store *onlinePlayerStore) receivedEvent(id ps2.CharacterID, world ps2.WorldID, zone ps2.ZoneInstanceID, team ps2.FactionID, loadout ps2.LoadoutID, timestamp time.Time) {
+// receivedEvent updates the player store from an event mentioning a character.
+// When the character plays for NSO and their team changed,
+// the team they switched from is returned.
+func (store *onlinePlayerStore) receivedEvent(id ps2.CharacterID, world ps2.WorldID, zone ps2.ZoneInstanceID, team ps2.FactionID, loadout ps2.LoadoutID, timestamp time.Time) (previousTeam ps2.FactionID) {
 	if id == 0 {
-		return
+		return 0
 	}
 
 	if world == 0 {
@@ -258,12 +413,12 @@ func (store *onlinePlayerStore) receivedEvent(id ps2.CharacterID, world ps2.Worl
 		// 	"loadout", loadout,
 		// 	"team", team,
 		// )
-		return
+		return 0
 	}
 
 	p, found := store.players[id]
 	if timestamp.Before(p.lastSeen) {
-		return
+		return 0
 	}
 
 	if p.homeFaction == 0 && loadout != 0 {
@@ -271,6 +426,12 @@ func (store *onlinePlayerStore) receivedEvent(id ps2.CharacterID, world ps2.Worl
 	}
 
 	if team != 0 {
+		// NSO players are assigned to a team that can change between sessions or zones.
+		// Players from the other factions should never switch teams,
+		// so only NSO switches are reported.
+		if p.homeFaction == NSO && p.team != 0 && p.team != team {
+			previousTeam = p.team
+		}
 		p.team = team
 	}
 
@@ -286,6 +447,16 @@ func (store *onlinePlayerStore) receivedEvent(id ps2.CharacterID, world ps2.Worl
 	p.zone = zone
 	p.lastSeen = timestamp
 	store.players[id] = p
+	return previousTeam
+}
+
+// outfitUpdate sets the outfit of an online character.
+// An outfit of 0 means the character isn't in an outfit.
+func (store *onlinePlayerStore) outfitUpdate(id ps2.CharacterID, outfit ps2.OutfitID) {
+	if p, found := store.players[id]; found {
+		p.outfit = outfit
+		store.players[id] = p
+	}
 }
 
 func (store *onlinePlayerStore) factionUpdate(id ps2.CharacterID, faction ps2.FactionID) {
@@ -301,50 +472,13 @@ func (store *onlinePlayerStore) factionUpdate(id ps2.CharacterID, faction ps2.Fa
 type onlinePlayerState struct {
 	homeFaction ps2.FactionID // homeFaction is 0 until an event containing a ps2.ProfileID is seen, then saved
 	team        ps2.FactionID // team is the current faction as determined by incoming kill events
+	outfit      ps2.OutfitID  // outfit is 0 until a facility capture or defense mentioning this player is seen
 	world       ps2.WorldID
 	zone        ps2.ZoneInstanceID
 	lastSeen    time.Time // timestamp of last event mentioning this player
 	saved       bool      // track whether faction has been saved to database this session
 }
 
-// checkZone checks whether a zone should start being actively tracked.
-func checkZone(ctx context.Context, manager *Manager, zone uniqueZone) {
-	// we can short-circuit any zones checked recently
-	if t := manager.zoneLookups[zone]; time.Since(t) < time.Hour {
-		return
-	}
-	manager.zoneLookups[zone] = time.Now()
-
-	// we're not concerned with tracking non-playable zones like VR-Training
-	if !ps2.IsPlayableZone(zone.ZoneID()) {
-		return
-	}
-
-	// if the zone is being tracked we don't need to do anything
-	if manager.state.isTracking(zone) {
-		return
-	}
-
-	// if other checks passed, then send it to the census api.
-	// active zones will be sent back on the mapData channel and be intitialized for tracking in the consumer of that channel.
-	go func() {
-		ctx, stop := context.WithTimeout(ctx, 30*time.Second)
-		defer stop()
-		zm, err := census.GetMap(ctx, manager.census, zone.WorldID, zone.ZoneInstanceID)
-		if err != nil {
-			// slog.Error("zone map lookup failed", "error", err, "zone", zone)
-			return
-		}
-		for _, z := range zm {
-			select {
-			case manager.mapUpdates <- z:
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
-}
-
 func handleMap(manager *Manager, mapData census.ZoneState) {
 	id := uniqueZone{mapData.WorldID, mapData.ZoneInstanceID}
 	trackZone(manager, id)
@@ -354,9 +488,11 @@ func handleMap(manager *Manager, mapData census.ZoneState) {
 		return
 	}
 	for _, region := range mapData.Regions {
-		zone.Regions[region.RegionID] = region.FactionID
+		zone.Regions.Territory[region.RegionID] = region.FactionID
 	}
 	zone.MapTimestamp = time.Now()
+	zone.Regions.Timestamp = zone.MapTimestamp
+	syncHolds(manager, id, zone.Regions.Territory)
 	mapp, err := manager.gameData.GetMap(id.ZoneID())
 	if err != nil {
 		return
@@ -369,7 +505,7 @@ func handleMap(manager *Manager, mapData census.ZoneState) {
 	zone.ContinentState = summary.Status
 	zone.Cutoff = summary.Cutoff
 	if zone.ContinentState != psmap.Locked {
-		emitTerritoryChange(manager, id, zone.Regions, zone.Cutoff)
+		emitTerritoryChange(manager, id, zone.Regions.Territory, zone.Cutoff, SourceCensusPoll, mapData.Timestamp)
 	}
 }
 
@@ -394,12 +530,18 @@ func trackZone(manager *Manager, zone uniqueZone) {
 	manager.state.trackZone(w, zone.ZoneInstanceID, cont)
 }
 
+// facilityRegion returns the region of facility from the game data store,
+// falling back to the lookup tables of the ps2 package for stores that don't know it.
+func facilityRegion(manager *Manager, facility ps2.FacilityID) ps2.RegionID {
+	if region := manager.gameData.GetFacilityRegion(facility); region != 0 {
+		return region
+	}
+	region, _ := ps2.FacilityRegion(facility)
+	return region
+}
+
 // handleFacilityControl handles push events from the websocket connection.
 func handleFacilityControl(manager *Manager, e event.FacilityControl) {
-	// for now we don't care about facility defense events
-	if e.NewFactionID == e.OldFactionID {
-		return
-	}
 	zoneID := uniqueZone{WorldID: e.WorldID, ZoneInstanceID: e.ZoneID}
 	zone := manager.state.getZoneptr(zoneID)
 	if zone == nil {
@@ -407,11 +549,18 @@ func handleFacilityControl(manager *Manager, e event.FacilityControl) {
 		// facility updates come in for zones like the tutorial all the time
 		return
 	}
-	regionID := manager.gameData.GetFacilityRegion(e.FacilityID)
+	regionID := facilityRegion(manager, e.FacilityID)
 	if regionID == 0 {
 		return
 	}
-	zone.Regions[regionID] = e.NewFactionID
+	trackHold(manager, zoneID, regionID, e)
+
+	// other than hold durations we don't care about facility defense events
+	if e.NewFactionID == e.OldFactionID {
+		return
+	}
+	zone.Regions.Territory[regionID] = e.NewFactionID
+	zone.Regions.Timestamp = e.Timestamp
 	mapp, err := manager.gameData.GetMap(zoneID.ZoneID())
 	if err != nil {
 		return
@@ -430,7 +579,7 @@ func handleFacilityControl(manager *Manager, e event.FacilityControl) {
 			// this check will emit two events because it triggers during warpgate flips,
 			// but that shouldn't matter
 			unflipped := map[ps2.RegionID]ps2.FactionID{}
-			for r, f := range zone.Regions {
+			for r, f := range zone.Regions.Territory {
 				if f == e.OldFactionID {
 					unflipped[r] = f
 				}
@@ -440,6 +589,8 @@ func handleFacilityControl(manager *Manager, e event.FacilityControl) {
 				zoneID,
 				unflipped,
 				summary.Cutoff,
+				SourceWebsocket,
+				e.Timestamp,
 			)
 		}
 	}
@@ -448,12 +599,7 @@ func handleFacilityControl(manager *Manager, e event.FacilityControl) {
 	zone.Cutoff = summary.Cutoff
 	zone.MapTimestamp = e.Timestamp
 
-	emitTerritoryChange(
-		manager,
-		zoneID,
-		map[ps2.RegionID]ps2.FactionID{regionID: e.NewFactionID},
-		summary.Cutoff,
-	)
+	emitFacilityCapture(manager, zoneID, regionID, summary.Cutoff, e)
 
 	event := zone.Event
 	if event != nil {
@@ -461,58 +607,26 @@ func handleFacilityControl(manager *Manager, e event.FacilityControl) {
 			event.Score.VS = float64(summary.Territory[VS])
 			event.Score.NC = float64(summary.Territory[NC])
 			event.Score.TR = float64(summary.Territory[TR])
+			event.Provenance = manager.provenance(SourceWebsocket, e.Timestamp)
 			// emit territory percents
 			emitEventUpdate(manager, (*event).Clone())
 		}
+		if event.Ended == nil {
+			recordAlertCapture(manager, event, e.OutfitID, e.Timestamp)
+		}
 	}
 }
 
 func handleMetagame(_ context.Context, manager *Manager, e event.MetagameEvent) {
 	switch e.MetagameEventState {
 	case ps2.Started:
-		// if the zone has any existing events we need to remove them
-		// e.g. sudden death started immediately after a meltdown tie
-		for alertID, alertData := range manager.alerts {
-			if alertID.WorldID == e.WorldID && alertData.MapID == e.ZoneID {
-				// no need to set the zone's EventState to nil because we'll overwrite it in the next block
-				delete(manager.alerts, alertID)
-			}
-		}
-
-		eventData := manager.gameData.GetEvent(e.MetagameEventID)
-		event := newEvent(e.EventInstanceID(), e.ZoneID, eventData.MetagameEventID, e.Timestamp, manager.gameData)
-		manager.alerts[e.EventInstanceID()] = event
-		zid := uniqueZone{
-			WorldID:        e.WorldID,
-			ZoneInstanceID: e.ZoneID,
-		}
-		manager.state.setEvent(zid, event)
-		emitEventUpdate(manager, (*event).Clone())
+		handleAlertStarted(manager, e)
 	case ps2.Restarted:
 	case ps2.Cancelled, ps2.Ended:
 		// events can end much earlier than their duration in the case of server shutdown.
 		// there are messages ingame that the server will be shutting down and the alert timer will change ingame.
 		// there are no events emitted from the census push service.
-		event := manager.alerts[e.EventInstanceID()]
-		if event == nil {
-			return
-		}
-		event.Ended = &e.Timestamp
-		event.Timestamp = e.Timestamp
-		nc := event.Score.NC
-		vs := event.Score.VS
-		tr := event.Score.TR
-
-		if nc > vs && nc > tr {
-			event.Victor = NC
-		}
-		if vs > nc && vs > tr {
-			event.Victor = VS
-		}
-		if tr > nc && tr > vs {
-			event.Victor = TR
-		}
-		emitEventUpdate(manager, (*event).Clone())
+		handleAlertEnded(manager, e)
 	}
 }
 func handleLock(manager *Manager, e event.ContinentLock) {
@@ -531,11 +645,32 @@ func handleLock(manager *Manager, e event.ContinentLock) {
 	zone.OwningFaction = e.TriggeringFaction
 	if zone.Event != nil {
 		zone.Event.Victor = e.TriggeringFaction
+		if zone.Event.Ended != nil {
+			// the lock can arrive after the alert ended
+			finishAlert(manager, zone.Event)
+		}
 	}
 }
 
+// trackPlayer records an event mentioning a character and reports NSO team switches.
+func trackPlayer(m *Manager, id ps2.CharacterID, world ps2.WorldID, zone ps2.ZoneInstanceID, team ps2.FactionID, loadout ps2.LoadoutID, timestamp time.Time) {
+	previous := m.players.receivedEvent(id, world, zone, team, loadout, timestamp)
+	if previous == 0 {
+		return
+	}
+	emitNSOTeamChange(m, NSOTeamChange{
+		CharacterID: id,
+		WorldID:     world,
+		ZoneID:      zone,
+		From:        previous,
+		To:          team,
+		Timestamp:   timestamp,
+	})
+}
+
 func handleLogin(m *Manager, e event.PlayerLogin) {
-	m.players.receivedEvent(
+	trackPlayer(
+		m,
 		e.CharacterID,
 		e.WorldID,
 		0,
@@ -549,7 +684,8 @@ func handleLogout(m *Manager, e event.PlayerLogout) {
 	delete(m.players.players, e.CharacterID)
 }
 func handleGainExperience(m *Manager, e event.GainExperience) {
-	m.players.receivedEvent(
+	trackPlayer(
+		m,
 		e.CharacterID,
 		e.WorldID,
 		e.ZoneID,
@@ -559,7 +695,8 @@ func handleGainExperience(m *Manager, e event.GainExperience) {
 	)
 }
 func handleVehicleDestroy(m *Manager, e event.VehicleDestroy) {
-	m.players.receivedEvent(
+	trackPlayer(
+		m,
 		e.AttackerCharacterID,
 		e.WorldID,
 		e.ZoneID,
@@ -569,7 +706,8 @@ func handleVehicleDestroy(m *Manager, e event.VehicleDestroy) {
 	)
 }
 func handleDeath(m *Manager, e event.Death) {
-	m.players.receivedEvent(
+	trackPlayer(
+		m,
 		e.AttackerCharacterID,
 		e.WorldID,
 		e.ZoneID,
@@ -577,7 +715,8 @@ func handleDeath(m *Manager, e event.Death) {
 		e.AttackerLoadoutID,
 		e.Timestamp,
 	)
-	m.players.receivedEvent(
+	trackPlayer(
+		m,
 		e.CharacterID,
 		e.WorldID,
 		e.ZoneID,
@@ -592,133 +731,94 @@ type popCounter [5]int
 
 func countPlayers(m *Manager) {
 	worldCount := make(map[ps2.WorldID]popCounter)
+	teamCount := make(map[ps2.WorldID]popCounter)
+	nsoCount := make(map[ps2.WorldID]popCounter)
 	zoneCount := make(map[uniqueZone]popCounter)
+	lastSeen := make(map[ps2.WorldID]time.Time)
+	outfitCount := make(map[uniqueOutfit]int)
+
+	// if we haven't seen any events for a player within the stale timeout,
+	// then we will assume that there is some kind of error in receiving events like logouts
+	// and we'll exclude the player from the population counts.
+	for _, e := range m.logouts.Sweep(time.Now()) {
+		// if they were still online they'll just get added back to tracking the next time an event comes in
+		if p, found := m.players.players[e.CharacterID]; found && !p.lastSeen.After(e.LastSeen) {
+			delete(m.players.players, e.CharacterID)
+		}
+	}
 
-	for id, player := range m.players.players {
-
-		// if we haven't seen any events for a player in more than X hours,
-		// then we will assume that there is some kind of error in receiving events like logouts
-		// and we'll exclude the player from the population counts.
-		if time.Since(player.lastSeen) > 2*time.Hour {
-			// if they were still online they'll just get added back to tracking the next time an event comes in
-			delete(m.players.players, id)
-			continue
+	for _, player := range m.players.players {
+		if player.lastSeen.After(lastSeen[player.world]) {
+			lastSeen[player.world] = player.lastSeen
 		}
 		wcount := worldCount[player.world]
 		wcount[player.homeFaction]++
 		worldCount[player.world] = wcount
 
+		wcount = teamCount[player.world]
+		wcount[player.team]++
+		teamCount[player.world] = wcount
+		if player.homeFaction == NSO {
+			wcount = nsoCount[player.world]
+			wcount[player.team]++
+			nsoCount[player.world] = wcount
+		}
+
 		z := uniqueZone{player.world, player.zone}
 		wcount = zoneCount[z]
 		wcount[player.team]++
 		zoneCount[z] = wcount
+		if player.outfit != 0 && player.zone != 0 {
+			outfitCount[uniqueOutfit{z, player.outfit}]++
+		}
 	}
 
 	for _, ws := range m.state.Worlds {
 		wid := ws.WorldID
-		m.state.setWorldPop(wid, worldCount[ws.WorldID])
+		m.state.setWorldPop(wid, worldCount[wid], teamCount[wid], nsoCount[wid])
 
 		for _, zs := range ws.Zones {
 			id := uniqueZone{WorldID: wid, ZoneInstanceID: zs.MapID}
 			m.state.setZonePop(id, zoneCount[id])
 		}
 	}
-	emitPopulationSums(m)
+	sampleAlertPopulations(m)
+	emitPopulationSums(m, lastSeen)
+	countOutfits(m, outfitCount, time.Now())
+	emitOutfitUpdate(m)
 }
 func removeStaleEvents(m *Manager) {
 	for eventID, event := range m.alerts {
-		deletionTime := event.Started.Add(event.EventDuration + 10*time.Minute)
+		deletionTime := event.Started.Add(event.EventDuration.Duration() + 10*time.Minute)
 		if time.Now().After(deletionTime) {
 			zone := uniqueZone{WorldID: event.ID.WorldID, ZoneInstanceID: event.MapID}
+			finishAlert(m, event)
 			m.state.deleteEvent(zone)
 			delete(m.alerts, eventID)
+			delete(m.alertTrackers, eventID)
 		}
 	}
 }
 
-func handlePS2AlertsResponse(manager *Manager, ps2aInstance ps2alerts.Alert) {
-	id := ps2aInstance.InstanceID
-	event := manager.alerts[id]
-	if event == nil {
-		eventData := manager.gameData.GetEvent(ps2aInstance.CensusMetagameEventType)
-		event = newEvent(id, ps2aInstance.Zone, eventData.MetagameEventID, ps2aInstance.TimeStarted, manager.gameData)
-		manager.alerts[id] = event
-		zid := uniqueZone{
-			WorldID:        ps2aInstance.World,
-			ZoneInstanceID: ps2aInstance.Zone,
-		}
-		manager.state.setEvent(zid, event)
-	}
-
-	event.Score = score{
-		NC: float64(ps2aInstance.Result.Nc),
-		TR: float64(ps2aInstance.Result.Tr),
-		VS: float64(ps2aInstance.Result.Vs),
-	}
-
-	if ps2aInstance.Result.Victor != nil {
-		event.Victor = *ps2aInstance.Result.Victor
-	}
-	event.Ended = ps2aInstance.TimeEnded
-
-	emitEventUpdate(manager, (*event).Clone())
-}
-
-func getMapData(ctx context.Context, m *Manager, results chan<- census.ZoneState) {
-	worldZones := m.state.listZones()
-	for world, zones := range worldZones {
-		go func(w ps2.WorldID, zones []ps2.ZoneInstanceID) {
-			if len(zones) == 0 {
-				return
-			}
-			ctx, stop := context.WithTimeout(ctx, 30*time.Second)
-			defer stop()
-			zm, err := census.GetMap(ctx, m.census, w, zones...)
-			if err != nil {
-				// slog.Warn("failed getting map state from census", "error", err, "zones", zones, "world", w)
-				return
-			}
-			for _, z := range zm {
-				results <- z
-			}
-		}(world, zones)
-	}
-}
-
-// func updateInstance(ctx context.Context, i ps2alerts.InstanceID, ch chan<- ps2alerts.Instance) {
-// 	instance, err := ps2alerts.GetInstanceContext(ctx, i)
-// 	if err != nil {
-// 		slog.Error("failed getting ps2alerts event instance", "error", err)
-// 		return
-// 	}
-// 	select {
-// 	case ch <- instance:
-// 	case <-ctx.Done():
-// 		return
-// 	}
-// }
+// errGoneHome is returned when the manager isn't working anymore
+var errGoneHome = errors.New("manager is not running")
 
-func updateActiveEventInstances(ctx context.Context, ch chan<- ps2alerts.Alert) {
-	events, err := ps2alerts.GetActiveContext(ctx)
-	if err != nil {
-		log.Printf("updateActiveEventInstances: %v", err)
-		return
-	}
-	for _, i := range events {
-		select {
-		case ch <- i:
-		case <-ctx.Done():
-			return
-		}
+// started reports whether Run has been called.
+func (m *Manager) started() bool {
+	select {
+	case <-m.running:
+		return true
+	default:
+		return false
 	}
 }
 
-// errGoneHome is returned when the manager isn't working anymore
-var errGoneHome = errors.New("manager is not running")
-
 // query adds a query to the Manager's queue.
 // It returns errGoneHome if the manager isn't available.
 func (m *Manager) query(q query) error {
+	if !m.started() {
+		return errGoneHome
+	}
 	select {
 	case m.queryQueue <- q:
 		return nil
@@ -727,6 +827,32 @@ func (m *Manager) query(q query) error {
 	}
 }
 
+// askContext runs queryFn on the Manager's goroutine and returns the result,
+// giving up when ctx is done.
+func askContext[T any](ctx context.Context, manager *Manager, queryFn func(*Manager) T) (T, error) {
+	question := managerQuery[T]{
+		queryFn: queryFn,
+		result:  make(chan T, 1),
+	}
+	var zero T
+	if !manager.started() {
+		return zero, errGoneHome
+	}
+	select {
+	case manager.queryQueue <- question:
+	case <-manager.unavailable:
+		return zero, errGoneHome
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	select {
+	case result := <-question.result:
+		return result, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
 // managerQuery holds a queued function to perform against a Manager and a buffered channel for the result.
 type managerQuery[T any] struct {
 	queryFn func(*Manager) T
@@ -772,7 +898,7 @@ func newEvent(id ps2.MetagameEventInstanceID, zone ps2.ZoneInstanceID, eventID p
 		MetagameEventID:  eventData.MetagameEventID,
 		EventName:        eventData.Name.String(),
 		EventDescription: eventData.Description.String(),
-		EventDuration:    eventData.Duration,
+		EventDuration:    ps2.Seconds(eventData.Duration),
 		IsContinentLock:  ps2.IsContinentLock(eventData.MetagameEventID),
 		IsTerritory:      ps2.IsTerritoryAlert(eventData.MetagameEventID),
 		StartingFaction:  ps2.StartingFaction(eventData.MetagameEventID),
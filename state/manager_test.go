@@ -0,0 +1,35 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownNotRunning(t *testing.T) {
+	m := New(testStore{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, errGoneHome) {
+		t.Errorf("got error %v; want %v before Run", err, errGoneHome)
+	}
+	if ctx.Err() != nil {
+		t.Error("Shutdown waited for its context before Run")
+	}
+
+	// Run doesn't start a Manager that was already shut down
+	done := make(chan struct{})
+	go func() {
+		m.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("Run didn't return after Shutdown")
+	}
+	if _, err := m.HotZones(context.Background(), 0); !errors.Is(err, errGoneHome) {
+		t.Errorf("got error %v from a query after Run returned; want %v", err, errGoneHome)
+	}
+}
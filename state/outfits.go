@@ -0,0 +1,209 @@
+package state
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// outfitIdleTimeout is how long an outfit with no online members is kept after its last recorded activity.
+const outfitIdleTimeout = 2 * time.Hour
+
+// OutfitActivity is the activity of one outfit in a zone.
+//
+// Census death and vehicle events don't include outfits,
+// so a character is only counted for an outfit once a PlayerFacilityCapture or PlayerFacilityDefend reveals their outfit.
+// Kills and deaths from before then aren't attributed to any outfit.
+type OutfitActivity struct {
+	OutfitID ps2.OutfitID       `json:"outfit_id"`
+	WorldID  ps2.WorldID        `json:"world_id"`
+	ZoneID   ps2.ZoneInstanceID `json:"zone_id"`
+
+	// Online is the number of known members in the zone as of the last population count.
+	Online int `json:"online"`
+
+	Kills        int `json:"kills"`         // Kills doesn't include suicides or teamkills
+	Deaths       int `json:"deaths"`        // Deaths includes suicides
+	VehicleKills int `json:"vehicle_kills"` // VehicleKills are vehicles destroyed that were owned by other characters
+
+	// Captures and Defends are facility control events credited to the outfit.
+	Captures int `json:"captures"`
+	Defends  int `json:"defends"`
+
+	// LastActive is the last time the outfit had an event counted or members online.
+	LastActive time.Time `json:"last_active"`
+}
+
+// KD returns the ratio of kills to deaths.
+// An outfit with no deaths returns its kills.
+func (a OutfitActivity) KD() float64 {
+	if a.Deaths == 0 {
+		return float64(a.Kills)
+	}
+	return float64(a.Kills) / float64(a.Deaths)
+}
+
+// uniqueOutfit is an outfit within a zone.
+type uniqueOutfit struct {
+	uniqueZone
+	OutfitID ps2.OutfitID
+}
+
+// outfitStats is the activity of an outfit and whether it changed since handlers were last notified.
+type outfitStats struct {
+	OutfitActivity
+	changed bool
+}
+
+// Outfits returns the activity of outfits on world,
+// sorted by online members and then by outfit ID.
+// A zone of 0 returns outfits from every zone of the world.
+func (manager *Manager) Outfits(ctx context.Context, world ps2.WorldID, zone ps2.ZoneInstanceID) ([]OutfitActivity, error) {
+	return askContext(ctx, manager, func(manager *Manager) []OutfitActivity {
+		var outfits []OutfitActivity
+		for id, stats := range manager.outfits {
+			if id.WorldID != world || (zone != 0 && id.ZoneInstanceID != zone) {
+				continue
+			}
+			outfits = append(outfits, stats.OutfitActivity)
+		}
+		sortOutfits(outfits)
+		return outfits
+	})
+}
+
+// OnOutfitUpdate adds a function that will be called with the outfits whose activity changed
+// every time populations are counted.
+func (manager *Manager) OnOutfitUpdate(f func([]OutfitActivity)) {
+	manager.outfitUpdateHandlers = append(manager.outfitUpdateHandlers, f)
+}
+
+func emitOutfitUpdate(manager *Manager) {
+	var changed []OutfitActivity
+	for _, stats := range manager.outfits {
+		if stats.changed {
+			stats.changed = false
+			changed = append(changed, stats.OutfitActivity)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	sortOutfits(changed)
+	for _, f := range manager.outfitUpdateHandlers {
+		f(slices.Clone(changed))
+	}
+}
+
+func sortOutfits(outfits []OutfitActivity) {
+	slices.SortFunc(outfits, func(a, b OutfitActivity) int {
+		if c := cmp.Compare(b.Online, a.Online); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.OutfitID, b.OutfitID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ZoneID, b.ZoneID)
+	})
+}
+
+// outfitActivity returns the stats for outfit in zone, creating them if needed.
+func outfitActivity(manager *Manager, zone uniqueZone, outfit ps2.OutfitID) *outfitStats {
+	id := uniqueOutfit{zone, outfit}
+	stats := manager.outfits[id]
+	if stats == nil {
+		stats = &outfitStats{OutfitActivity: OutfitActivity{
+			OutfitID: outfit,
+			WorldID:  zone.WorldID,
+			ZoneID:   zone.ZoneInstanceID,
+		}}
+		manager.outfits[id] = stats
+	}
+	return stats
+}
+
+// recordOutfit applies update to the stats of the outfit of character, if the outfit is known.
+func recordOutfit(manager *Manager, character ps2.CharacterID, zone uniqueZone, timestamp time.Time, update func(*OutfitActivity)) {
+	outfit := manager.players.players[character].outfit
+	if outfit == 0 {
+		return
+	}
+	stats := outfitActivity(manager, zone, outfit)
+	update(&stats.OutfitActivity)
+	stats.changed = true
+	if timestamp.After(stats.LastActive) {
+		stats.LastActive = timestamp
+	}
+}
+
+func recordOutfitDeath(manager *Manager, e event.Death) {
+	zone := uniqueZone{e.WorldID, e.ZoneID}
+	recordOutfit(manager, e.CharacterID, zone, e.Timestamp, func(a *OutfitActivity) { a.Deaths++ })
+	if e.IsSuicide() || (e.AttackerTeamID != 0 && e.AttackerTeamID == e.TeamID) {
+		return
+	}
+	recordOutfit(manager, e.AttackerCharacterID, zone, e.Timestamp, func(a *OutfitActivity) { a.Kills++ })
+}
+
+func recordOutfitVehicleDestroy(manager *Manager, e event.VehicleDestroy) {
+	if e.AttackerCharacterID == e.CharacterID {
+		return
+	}
+	recordOutfit(manager, e.AttackerCharacterID, uniqueZone{e.WorldID, e.ZoneID}, e.Timestamp, func(a *OutfitActivity) { a.VehicleKills++ })
+}
+
+// recordOutfitFacilityControl credits the outfit in e with a capture or defense.
+// Only tracked zones are recorded.
+func recordOutfitFacilityControl(manager *Manager, e event.FacilityControl) {
+	zone := uniqueZone{e.WorldID, e.ZoneID}
+	if e.OutfitID == 0 || manager.state.getZoneptr(zone) == nil {
+		return
+	}
+	stats := outfitActivity(manager, zone, e.OutfitID)
+	if e.NewFactionID == e.OldFactionID {
+		stats.Defends++
+	} else {
+		stats.Captures++
+	}
+	stats.changed = true
+	if e.Timestamp.After(stats.LastActive) {
+		stats.LastActive = e.Timestamp
+	}
+}
+
+// handlePlayerFacilityCapture records the outfit of the capturing character.
+func handlePlayerFacilityCapture(m *Manager, e event.PlayerFacilityCapture) {
+	trackPlayer(m, e.CharacterID, e.WorldID, e.ZoneID, 0, 0, e.Timestamp)
+	m.players.outfitUpdate(e.CharacterID, e.OutfitID)
+}
+
+// handlePlayerFacilityDefend records the outfit of the defending character.
+func handlePlayerFacilityDefend(m *Manager, e event.PlayerFacilityDefend) {
+	trackPlayer(m, e.CharacterID, e.WorldID, e.ZoneID, 0, 0, e.Timestamp)
+	m.players.outfitUpdate(e.CharacterID, e.OutfitID)
+}
+
+// countOutfits sets the online members of every outfit from online,
+// and removes outfits that have no members online and have been idle for longer than outfitIdleTimeout.
+func countOutfits(manager *Manager, online map[uniqueOutfit]int, now time.Time) {
+	for id := range online {
+		outfitActivity(manager, id.uniqueZone, id.OutfitID)
+	}
+	for id, stats := range manager.outfits {
+		count := online[id]
+		if count > 0 {
+			stats.LastActive = now
+		} else if now.Sub(stats.LastActive) > outfitIdleTimeout {
+			delete(manager.outfits, id)
+			continue
+		}
+		if stats.Online != count {
+			stats.Online = count
+			stats.changed = true
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestOutfitActivity(t *testing.T) {
+	m := New(testStore{}, nil)
+	var updates [][]OutfitActivity
+	m.OnOutfitUpdate(func(a []OutfitActivity) { updates = append(updates, a) })
+
+	zone := ps2.ZoneInstanceID(ps2.Indar)
+	now := time.Now()
+	const (
+		outfit ps2.OutfitID    = 37512345
+		member ps2.CharacterID = 1
+		enemy  ps2.CharacterID = 2
+	)
+	steps := []event.Typer{
+		event.PlayerFacilityCapture{CharacterID: member, OutfitID: outfit, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.FacilityControl{OutfitID: outfit, OldFactionID: TR, NewFactionID: VS, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.FacilityControl{OutfitID: outfit, OldFactionID: VS, NewFactionID: VS, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.Death{AttackerCharacterID: member, AttackerTeamID: VS, CharacterID: enemy, TeamID: TR, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.Death{AttackerCharacterID: member, AttackerTeamID: VS, CharacterID: enemy, TeamID: TR, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.Death{AttackerCharacterID: enemy, AttackerTeamID: TR, CharacterID: member, TeamID: VS, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.Death{AttackerCharacterID: member, AttackerTeamID: VS, CharacterID: member, TeamID: VS, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+		event.VehicleDestroy{AttackerCharacterID: member, CharacterID: enemy, WorldID: ps2.Emerald, ZoneID: zone, Timestamp: now},
+	}
+	for _, e := range steps {
+		handlePushEvent(context.Background(), m, e)
+	}
+	countPlayers(m)
+
+	want := OutfitActivity{
+		OutfitID:     outfit,
+		WorldID:      ps2.Emerald,
+		ZoneID:       zone,
+		Online:       1,
+		Kills:        2,
+		Deaths:       2,
+		VehicleKills: 1,
+		Captures:     1,
+		Defends:      1,
+	}
+	if len(updates) != 1 || len(updates[0]) != 1 {
+		t.Fatalf("got updates %v; want one update with one outfit", updates)
+	}
+	got := updates[0][0]
+	got.LastActive = time.Time{}
+	if got != want {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+	if kd := got.KD(); kd != 1 {
+		t.Errorf("got KD %v; want 1", kd)
+	}
+
+	countPlayers(m)
+	if len(updates) != 1 {
+		t.Errorf("unchanged outfits were sent to handlers again")
+	}
+}
@@ -0,0 +1,153 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/internal/poll"
+	"github.com/Travis-Britz/ps2/ps2alerts"
+)
+
+// MapPolling controls how often the Manager requests full map state from census.
+// Territory is kept current by FacilityControl events between polls;
+// polling corrects for missed events and initializes zones on startup.
+//
+// Zero values use the defaults.
+type MapPolling struct {
+	// Interval is the delay between successful polls.
+	// The default is 5 minutes.
+	// A negative interval polls only until the first success.
+	Interval time.Duration
+
+	// Jitter is the maximum random delay added to every poll
+	// so that many deployments started together don't poll census in sync.
+	// The first poll is delayed by at most initialMapPollSpread so that startup isn't held up.
+	// The default is one fifth of Interval.
+	// A negative jitter disables it.
+	Jitter time.Duration
+
+	// MaxBackoff is the longest delay between retries while polls are failing.
+	// Retries start at 30 seconds and double after each failure.
+	// The default is 30 minutes.
+	MaxBackoff time.Duration
+}
+
+const (
+	defaultMapPollInterval = 5 * time.Minute
+	defaultMapPollBackoff  = 30 * time.Minute
+	minMapPollBackoff      = 30 * time.Second
+	initialMapPollSpread   = 10 * time.Second
+)
+
+// schedule returns the polling loop timing of p with the defaults filled in.
+func (p MapPolling) schedule() poll.Options {
+	return poll.Options{
+		Interval:   p.Interval,
+		Jitter:     p.Jitter,
+		MaxBackoff: p.MaxBackoff,
+		MinBackoff: minMapPollBackoff,
+		FirstDelay: initialMapPollSpread,
+	}.WithDefaults(defaultMapPollInterval, defaultMapPollBackoff)
+}
+
+// SetMapPolling configures census map polling.
+// It must be called before [Manager.Run].
+func (manager *Manager) SetMapPolling(p MapPolling) {
+	manager.mapPolling = p
+}
+
+// pollMaps requests map state for every tracked zone until ctx is cancelled.
+//
+// Failed polls are retried with exponential backoff.
+// While the census circuit breaker is open no requests are made at all,
+// since they would fail without being sent and only delay recovery.
+func pollMaps(ctx context.Context, m *Manager) {
+	poll.Run(ctx, m.mapPolling.schedule(), func(ctx context.Context) error {
+		if until, open := census.CircuitOpen(); open {
+			m.logf("census circuit breaker is open; delaying map poll until %v", until)
+			m.mapPollFailing.Store(true)
+			return poll.Until(until)
+		}
+		if err := getMapData(ctx, m); err != nil {
+			m.mapPollFailing.Store(true)
+			return err
+		}
+		m.mapPollFailing.Store(false)
+		return nil
+	}, func(err error, retry time.Duration) {
+		m.logf("map poll failed; retrying in %v: %v", retry, err)
+	})
+}
+
+// getMapData requests map state for every tracked zone, one request per world,
+// and sends the results to the manager.
+// The returned error joins the errors of every world that failed.
+func getMapData(ctx context.Context, m *Manager) error {
+	question := managerQuery[map[ps2.WorldID][]ps2.ZoneInstanceID]{
+		queryFn: func(manager *Manager) map[ps2.WorldID][]ps2.ZoneInstanceID {
+			return manager.state.listZones()
+		},
+		result: make(chan map[ps2.WorldID][]ps2.ZoneInstanceID, 1),
+	}
+	if err := m.query(question); err != nil {
+		return err
+	}
+	worldZones := <-question.result
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for world, zones := range worldZones {
+		if len(zones) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(w ps2.WorldID, zones []ps2.ZoneInstanceID) {
+			defer wg.Done()
+			ctx, stop := context.WithTimeout(ctx, 30*time.Second)
+			defer stop()
+			zm, err := census.GetMap(ctx, m.census, w, zones...)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("world %d: %w", w, err))
+				mu.Unlock()
+				return
+			}
+			for _, z := range zm {
+				select {
+				case m.mapUpdates <- z:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(world, zones)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// SetAlertPolling configures ps2alerts polling,
+// which keeps alert scores and population brackets current.
+// It must be called before [Manager.Run].
+func (manager *Manager) SetAlertPolling(o ps2alerts.PollOptions) {
+	manager.alertPolling = o
+}
+
+// pollAlerts sends alerts reported by ps2alerts to the manager until ctx is cancelled.
+func pollAlerts(ctx context.Context, m *Manager) {
+	poller := ps2alerts.NewPoller(m.alertPolling)
+	poller.OnAlert(func(a ps2alerts.Alert) {
+		select {
+		case m.alertUpdates <- a:
+		case <-ctx.Done():
+		}
+	})
+	poller.Run(ctx)
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// Census sometimes drops PlayerLogout events,
+// so players who logged out without one would be counted forever.
+// Players are dropped when an event.LogoutInferrer infers a logout after the stale timeout,
+// and optionally sooner by asking census whether quiet players are still online.
+
+// SetStaleTimeout sets how long a player can go without any events before they're no longer counted.
+// A timeout of 0 or less uses [event.DefaultLogoutWindow].
+// Shorter timeouts recover from missed logouts sooner,
+// but drop idle players who are still online until their next event.
+// It must be called before [Manager.Run].
+func (manager *Manager) SetStaleTimeout(d time.Duration) {
+	manager.logouts = event.NewLogoutInferrer(d)
+}
+
+// SetOnlineReconciliation looks up the census online status of players
+// who haven't been seen in an event for interval, once every interval,
+// and stops counting the ones census reports as offline.
+// This catches missed logouts much sooner than the stale timeout
+// at the cost of a census request per 100 quiet players.
+// An interval of 0 or less disables reconciliation, which is the default.
+// It must be called before [Manager.Run].
+func (manager *Manager) SetOnlineReconciliation(interval time.Duration) {
+	manager.onlineReconciliation = interval
+}
+
+// reconcileOnline reconciles online players with census every interval until ctx is cancelled.
+func reconcileOnline(ctx context.Context, m *Manager) {
+	ticker := time.NewTicker(m.onlineReconciliation)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, open := census.CircuitOpen(); open {
+			continue
+		}
+		if err := reconcilePlayers(ctx, m, time.Now().Add(-m.onlineReconciliation)); err != nil && ctx.Err() == nil {
+			m.logf("online reconciliation failed: %v", err)
+		}
+	}
+}
+
+// reconcilePlayers removes players who were last seen before cutoff and are offline according to census.
+// Players seen in an event while census was being asked are kept.
+func reconcilePlayers(ctx context.Context, m *Manager, cutoff time.Time) error {
+	quiet, err := askContext(ctx, m, func(m *Manager) map[ps2.Environment][]ps2.CharacterID {
+		quiet := make(map[ps2.Environment][]ps2.CharacterID)
+		for id, p := range m.players.players {
+			if p.lastSeen.Before(cutoff) {
+				env := ps2.GetEnvironment(p.world)
+				quiet[env] = append(quiet[env], id)
+			}
+		}
+		return quiet
+	})
+	if err != nil {
+		return err
+	}
+
+	var offline []ps2.CharacterID
+	for env, ids := range quiet {
+		statuses, err := census.GetOnlineStatus(ctx, m.census, env, ids...)
+		if err != nil {
+			return fmt.Errorf("state.reconcilePlayers: %w", err)
+		}
+		for _, s := range statuses {
+			if !s.Online() {
+				offline = append(offline, s.CharacterID)
+			}
+		}
+	}
+	if len(offline) == 0 {
+		return nil
+	}
+
+	removed, err := askContext(ctx, m, func(m *Manager) int {
+		var n int
+		for _, id := range offline {
+			if p, found := m.players.players[id]; found && p.lastSeen.Before(cutoff) {
+				delete(m.players.players, id)
+				n++
+			}
+		}
+		return n
+	})
+	if err != nil {
+		return err
+	}
+	m.logf("removed %d players that census reports offline", removed)
+	return nil
+}
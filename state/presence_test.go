@@ -0,0 +1,72 @@
+package state
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+type onlineStatusServer struct{}
+
+func (onlineStatusServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"characters_online_status_list":[{"character_id":"1","online_status":"0"},{"character_id":"2","online_status":"17"}],"returned":2}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestReconcilePlayers(t *testing.T) {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: onlineStatusServer{}})
+	m := New(testStore{}, client)
+	answerQueries(t, m)
+
+	now := time.Now()
+	m.players.players[1] = onlinePlayerState{world: ps2.Emerald, lastSeen: now.Add(-time.Hour)}
+	m.players.players[2] = onlinePlayerState{world: ps2.Emerald, lastSeen: now.Add(-time.Hour)}
+	m.players.players[3] = onlinePlayerState{world: ps2.Emerald, lastSeen: now}
+
+	if err := reconcilePlayers(context.Background(), m, now.Add(-10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := m.players.players[1]; found {
+		t.Errorf("offline character 1 is still counted")
+	}
+	if _, found := m.players.players[2]; !found {
+		t.Errorf("online character 2 was removed")
+	}
+	if _, found := m.players.players[3]; !found {
+		t.Errorf("recently seen character 3 was removed")
+	}
+}
+
+func TestStaleTimeout(t *testing.T) {
+	m := New(testStore{}, nil)
+	m.SetStaleTimeout(30 * time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+	handlePushEvent(ctx, m, event.GainExperience{CharacterID: 1, LoadoutID: 1, WorldID: ps2.Emerald, ZoneID: 2, Timestamp: now.Add(-time.Hour)})
+	handlePushEvent(ctx, m, event.GainExperience{CharacterID: 2, LoadoutID: 1, WorldID: ps2.Emerald, ZoneID: 2, Timestamp: now})
+	// character 3 was seeded from its online status instead of an event
+	m.players.players[3] = seededPlayer(ps2.Emerald, ps2.VS, now.Add(-time.Hour))
+	m.logouts.Seen(3, ps2.Emerald, now.Add(-time.Hour))
+
+	countPlayers(m)
+	if _, found := m.players.players[1]; found {
+		t.Errorf("character 1 is still counted after the stale timeout")
+	}
+	if _, found := m.players.players[2]; !found {
+		t.Errorf("character 2 was removed before the stale timeout")
+	}
+	if _, found := m.players.players[3]; found {
+		t.Errorf("seeded character 3 is still counted after the stale timeout")
+	}
+}
@@ -0,0 +1,67 @@
+package state
+
+import "time"
+
+// Source is where the values of a notification came from.
+type Source uint8
+
+const (
+	SourceUnknown    Source = iota
+	SourceWebsocket         // SourceWebsocket is the census event stream
+	SourceCensusPoll        // SourceCensusPoll is a census REST query, like the map polls
+	SourcePS2Alerts         // SourcePS2Alerts is the ps2alerts API
+	SourceCheckpoint        // SourceCheckpoint is a checkpoint restored by [Manager.Restore]
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceWebsocket:
+		return "websocket"
+	case SourceCensusPoll:
+		return "census"
+	case SourcePS2Alerts:
+		return "ps2alerts"
+	case SourceCheckpoint:
+		return "checkpoint"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Source) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *Source) UnmarshalText(text []byte) error {
+	for _, source := range []Source{SourceWebsocket, SourceCensusPoll, SourcePS2Alerts, SourceCheckpoint} {
+		if string(text) == source.String() {
+			*s = source
+			return nil
+		}
+	}
+	*s = SourceUnknown
+	return nil
+}
+
+// Provenance describes where the values of a notification came from and how current they are,
+// so that consumers can show something like "last updated 7m ago (census degraded)".
+type Provenance struct {
+	Source Source `json:"source"`
+
+	// Observed is the time of the observation the values came from,
+	// such as the timestamp of an event or the time census answered a poll.
+	// It's zero if nothing has been observed yet.
+	Observed time.Time `json:"observed"`
+
+	// Stale is set while census map polls are failing.
+	// The Manager relies on polling to correct for missed events,
+	// so values may have drifted from the game even if Observed is recent.
+	Stale bool `json:"stale"`
+}
+
+// provenance returns a Provenance for values observed from source at observed.
+func (manager *Manager) provenance(source Source, observed time.Time) Provenance {
+	return Provenance{
+		Source:   source,
+		Observed: observed,
+		Stale:    manager.mapPollFailing.Load(),
+	}
+}
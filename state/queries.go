@@ -1,34 +1,98 @@
 package state
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/Travis-Britz/ps2"
 )
 
+// State returns a copy of the state of every tracked world.
+// It's the same as [Manager.GlobalSnapshot] without a context.
 func (manager *Manager) State() (GlobalState, error) {
-	question := managerQuery[GlobalState]{
-		queryFn: func(manager *Manager) GlobalState {
-			return manager.state.Clone()
-		},
-		result: make(chan GlobalState, 1),
-	}
+	return manager.GlobalSnapshot(context.Background())
+}
 
-	if err := manager.query(question); err != nil {
-		return GlobalState{}, err
-	}
-	result := <-question.result
-	return result, nil
+// GlobalSnapshot returns a copy of the state of every tracked world.
+// The copy is safe to keep and modify.
+func (manager *Manager) GlobalSnapshot(ctx context.Context) (GlobalState, error) {
+	return askContext(ctx, manager, func(manager *Manager) GlobalState {
+		return manager.state.Clone()
+	})
 }
 
-func (manager *Manager) WorldState(world ps2.WorldID) (WorldState, error) {
-	gState, err := manager.State()
+// WorldState returns a copy of the state of a tracked world.
+func (manager *Manager) WorldState(ctx context.Context, world ps2.WorldID) (WorldState, error) {
+	ws, err := askContext(ctx, manager, func(manager *Manager) WorldState {
+		return manager.state.getWorld(world).Clone()
+	})
 	if err != nil {
 		return WorldState{}, err
 	}
-	wState := gState.getWorld(world)
-	if wState.WorldID == 0 {
+	if ws.WorldID == 0 {
 		return WorldState{}, fmt.Errorf("manager.WorldState: world %d not found", world)
 	}
-	return wState, nil
+	return ws, nil
+}
+
+// ZoneState returns a copy of the state of a tracked zone.
+func (manager *Manager) ZoneState(ctx context.Context, world ps2.WorldID, zone ps2.ZoneInstanceID) (ZoneState, error) {
+	zs, err := askContext(ctx, manager, func(manager *Manager) *ZoneState {
+		z := manager.state.getZoneptr(uniqueZone{world, zone})
+		if z == nil {
+			return nil
+		}
+		c := z.Clone()
+		return &c
+	})
+	if err != nil {
+		return ZoneState{}, err
+	}
+	if zs == nil {
+		return ZoneState{}, fmt.Errorf("manager.ZoneState: zone %d on world %d is not tracked", zone, world)
+	}
+	return *zs, nil
+}
+
+// ActiveAlerts returns copies of the alerts that haven't ended on every tracked world,
+// ordered by when they started.
+func (manager *Manager) ActiveAlerts(ctx context.Context) ([]EventState, error) {
+	return askContext(ctx, manager, func(manager *Manager) []EventState {
+		alerts := make([]EventState, 0, len(manager.alerts))
+		for _, event := range manager.alerts {
+			if event.Ended == nil {
+				alerts = append(alerts, event.Clone())
+			}
+		}
+		slices.SortFunc(alerts, func(a, b EventState) int { return a.Started.Compare(b.Started) })
+		return alerts
+	})
+}
+
+// FacilityHolds returns how long each facility in a tracked zone has been held,
+// and whether it appears to be traded back and forth.
+// Results are sorted by region.
+func (manager *Manager) FacilityHolds(ctx context.Context, world ps2.WorldID, zone ps2.ZoneInstanceID) ([]FacilityHold, error) {
+	id := uniqueZone{world, zone}
+	holds, err := askContext(ctx, manager, func(manager *Manager) []FacilityHold {
+		if !manager.state.isTracking(id) {
+			return nil
+		}
+		now := time.Now()
+		holds := make([]FacilityHold, 0, len(manager.holds[id]))
+		for region, h := range manager.holds[id] {
+			holds = append(holds, h.report(region, now))
+		}
+		slices.SortFunc(holds, func(a, b FacilityHold) int { return int(a.RegionID) - int(b.RegionID) })
+		return holds
+	})
+	if err != nil {
+		return nil, err
+	}
+	if holds == nil {
+		return nil, fmt.Errorf("manager.FacilityHolds: zone %d on world %d is not tracked", zone, world)
+	}
+	return holds, nil
 }
@@ -0,0 +1,164 @@
+package state
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/ps2alerts"
+)
+
+// Alerts are reported by census MetagameEvents and by ps2alerts,
+// which can arrive in any order.
+// Both are reconciled into one EventState by their ps2.MetagameEventInstanceID:
+// census is authoritative for when an alert started and ended,
+// and ps2alerts is authoritative for its score, victor, and bracket.
+// ps2alerts may fill in the start and end of an alert until census reports them,
+// but never overwrites them afterwards,
+// and responses describing a running alert are ignored once ps2alerts has reported its final result.
+
+// alertSource is where the data of an alert came from.
+type alertSource uint8
+
+const (
+	fromNone alertSource = iota
+	fromPS2Alerts
+	fromCensus
+)
+
+// handleAlertStarted reconciles a census MetagameEvent that started an alert.
+func handleAlertStarted(manager *Manager, e event.MetagameEvent) {
+	id := e.EventInstanceID()
+	zid := uniqueZone{WorldID: e.WorldID, ZoneInstanceID: e.ZoneID}
+	// events from the push service arrive within seconds;
+	// older ones come from Bootstrap and are missing the start of the alert
+	partial := time.Since(e.Timestamp) > time.Minute
+
+	// if the zone has any other events we need to remove them
+	// e.g. sudden death started immediately after a meltdown tie
+	replaceZoneAlerts(manager, id, zid)
+
+	event, known := manager.alerts[id]
+	if !known {
+		eventData := manager.gameData.GetEvent(e.MetagameEventID)
+		event = newEvent(id, e.ZoneID, eventData.MetagameEventID, e.Timestamp, manager.gameData)
+		manager.alerts[id] = event
+		trackAlert(manager, event, partial)
+	} else if t := manager.alertTrackers[id]; t != nil && !partial {
+		// ps2alerts only got here first by a few moments
+		t.partial = false
+	}
+	manager.state.setEvent(zid, event)
+
+	before := event.Clone()
+	event.Started = e.Timestamp
+	event.startedBy = fromCensus
+	if !known || !reflect.DeepEqual(before, *event) {
+		event.Provenance = manager.provenance(SourceWebsocket, e.Timestamp)
+		emitEventUpdate(manager, (*event).Clone())
+	}
+}
+
+// handleAlertEnded reconciles a census MetagameEvent that ended or cancelled an alert.
+func handleAlertEnded(manager *Manager, e event.MetagameEvent) {
+	event := manager.alerts[e.EventInstanceID()]
+	if event == nil || event.endedBy == fromCensus {
+		return
+	}
+	event.Ended = &e.Timestamp
+	event.endedBy = fromCensus
+	event.Timestamp = e.Timestamp
+	scoreEnded(event, e)
+
+	// the victor reported by ps2alerts or a continent lock is kept
+	if event.Victor == 0 {
+		nc := event.Score.NC
+		vs := event.Score.VS
+		tr := event.Score.TR
+
+		if nc > vs && nc > tr {
+			event.Victor = NC
+		}
+		if vs > nc && vs > tr {
+			event.Victor = VS
+		}
+		if tr > nc && tr > vs {
+			event.Victor = TR
+		}
+	}
+	event.Provenance = manager.provenance(SourceWebsocket, e.Timestamp)
+	emitEventUpdate(manager, (*event).Clone())
+	finishAlert(manager, event)
+}
+
+// handlePS2AlertsResponse reconciles an alert reported by ps2alerts.
+func handlePS2AlertsResponse(manager *Manager, ps2aInstance ps2alerts.Alert) {
+	id := ps2aInstance.InstanceID
+	zid := uniqueZone{
+		WorldID:        ps2aInstance.World,
+		ZoneInstanceID: ps2aInstance.Zone,
+	}
+	event, known := manager.alerts[id]
+	if !known {
+		// a slow response can describe an alert that was already replaced by a newer one
+		if zone := manager.state.getZoneptr(zid); zone != nil && zone.Event != nil && !zone.Event.Started.Before(ps2aInstance.TimeStarted) {
+			return
+		}
+		replaceZoneAlerts(manager, id, zid)
+		eventData := manager.gameData.GetEvent(ps2aInstance.CensusMetagameEventType)
+		event = newEvent(id, ps2aInstance.Zone, eventData.MetagameEventID, ps2aInstance.TimeStarted, manager.gameData)
+		event.startedBy = fromPS2Alerts
+		manager.alerts[id] = event
+		manager.state.setEvent(zid, event)
+		trackAlert(manager, event, true)
+	}
+
+	if event.final && ps2aInstance.TimeEnded == nil {
+		// a slow response from before the alert ended
+		return
+	}
+
+	before := event.Clone()
+	if event.startedBy != fromCensus {
+		event.Started = ps2aInstance.TimeStarted
+		event.startedBy = fromPS2Alerts
+	}
+	if ps2aInstance.TimeEnded != nil && event.endedBy != fromCensus {
+		ended := *ps2aInstance.TimeEnded
+		event.Ended = &ended
+		event.endedBy = fromPS2Alerts
+	}
+	event.final = ps2aInstance.TimeEnded != nil
+	event.Score = score{
+		NC: float64(ps2aInstance.Result.Nc),
+		TR: float64(ps2aInstance.Result.Tr),
+		VS: float64(ps2aInstance.Result.Vs),
+	}
+	if ps2aInstance.Result.Victor != nil {
+		event.Victor = *ps2aInstance.Result.Victor
+	}
+	event.Bracket = ps2aInstance.Bracket
+
+	// provenance is only updated with the data,
+	// so unchanged responses don't look like changes
+	if !known || !reflect.DeepEqual(before, *event) {
+		event.Provenance = manager.provenance(SourcePS2Alerts, time.Now())
+		emitEventUpdate(manager, (*event).Clone())
+	}
+	if event.final {
+		finishAlert(manager, event)
+	}
+}
+
+// replaceZoneAlerts removes every alert in zone other than id.
+// The zone's EventState isn't cleared because the caller will replace it.
+func replaceZoneAlerts(manager *Manager, id ps2.MetagameEventInstanceID, zone uniqueZone) {
+	for alertID, alertData := range manager.alerts {
+		if alertID != id && alertID.WorldID == zone.WorldID && alertData.MapID == zone.ZoneInstanceID {
+			finishAlert(manager, alertData)
+			delete(manager.alerts, alertID)
+			delete(manager.alertTrackers, alertID)
+		}
+	}
+}
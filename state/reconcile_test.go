@@ -0,0 +1,158 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/event"
+	"github.com/Travis-Britz/ps2/ps2alerts"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+type testStore struct{}
+
+func (testStore) GetContinent(id ps2.ContinentID) census.Zone {
+	return census.Zone{ContinentID: id}
+}
+func (testStore) ListContinents() []census.Zone {
+	return []census.Zone{{ContinentID: ps2.Indar, ZoneID: 2}}
+}
+func (testStore) GetWorld(id ps2.WorldID) census.World {
+	return census.World{WorldID: id}
+}
+func (testStore) ListWorlds() []census.World { return []census.World{{WorldID: ps2.Emerald}} }
+func (testStore) GetEvent(id ps2.MetagameEventID) census.MetagameEvent {
+	return census.MetagameEvent{MetagameEventID: id, Duration: 90}
+}
+func (testStore) GetFacility(ps2.FacilityID) census.Facility       { return census.Facility{} }
+func (testStore) GetPlayerFaction(ps2.CharacterID) ps2.FactionID   { return 0 }
+func (testStore) SavePlayerFaction(ps2.CharacterID, ps2.FactionID) {}
+func (testStore) GetFacilityRegion(ps2.FacilityID) ps2.RegionID    { return 0 }
+func (testStore) GetMap(ps2.ContinentID) (psmap.Map, error)        { return psmap.Map{}, nil }
+
+func TestAlertReconciliation(t *testing.T) {
+	id := ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 1234}
+	zone := ps2.ZoneInstanceID(ps2.Indar)
+	start := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	censusEnd := start.Add(80 * time.Minute)
+	ps2aEnd := censusEnd.Add(3 * time.Second)
+	victor := VS
+
+	censusStarted := event.MetagameEvent{
+		InstanceID:         id.InstanceID,
+		MetagameEventID:    147,
+		MetagameEventState: ps2.Started,
+		Timestamp:          start,
+		WorldID:            id.WorldID,
+		ZoneID:             zone,
+	}
+	censusEnded := censusStarted
+	censusEnded.MetagameEventState = ps2.Ended
+	censusEnded.Timestamp = censusEnd
+
+	running := ps2alerts.Alert{
+		World:                   id.WorldID,
+		InstanceID:              id,
+		Zone:                    zone,
+		TimeStarted:             start.Add(2 * time.Second),
+		CensusMetagameEventType: 147,
+		Bracket:                 4,
+	}
+	running.Result.Vs, running.Result.Nc, running.Result.Tr = 40, 30, 25
+	finished := running
+	finished.TimeEnded = &ps2aEnd
+	finished.Result.Vs, finished.Result.Nc, finished.Result.Tr = 45, 30, 20
+	finished.Result.Victor = &victor
+
+	type step func(*Manager)
+	censusStep := func(e event.MetagameEvent) step {
+		return func(m *Manager) { handleMetagame(context.Background(), m, e) }
+	}
+	ps2alertsStep := func(a ps2alerts.Alert) step {
+		return func(m *Manager) { handlePS2AlertsResponse(m, a) }
+	}
+
+	tt := map[string]struct {
+		Steps []step
+	}{
+		"census first":            {[]step{censusStep(censusStarted), ps2alertsStep(running), censusStep(censusEnded), ps2alertsStep(finished)}},
+		"ps2alerts first":         {[]step{ps2alertsStep(running), censusStep(censusStarted), ps2alertsStep(finished), censusStep(censusEnded)}},
+		"stale ps2alerts running": {[]step{censusStep(censusStarted), ps2alertsStep(finished), censusStep(censusEnded), ps2alertsStep(running)}},
+		"duplicate census":        {[]step{censusStep(censusStarted), ps2alertsStep(finished), censusStep(censusStarted), censusStep(censusEnded), censusStep(censusEnded)}},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m := New(testStore{}, nil)
+			var updates []EventState
+			m.OnEventUpdate(func(e EventState) { updates = append(updates, e) })
+			for _, s := range tc.Steps {
+				s(m)
+			}
+
+			if len(m.alerts) != 1 {
+				t.Fatalf("got %d alerts; want 1", len(m.alerts))
+			}
+			got := m.alerts[id]
+			if got == nil {
+				t.Fatalf("alert %s is missing", id)
+			}
+			if zoneEvent := m.state.getZoneptr(uniqueZone{id.WorldID, zone}).Event; zoneEvent != got {
+				t.Errorf("zone event is not the reconciled alert")
+			}
+			if !got.Started.Equal(start) {
+				t.Errorf("got started %v; want census time %v", got.Started, start)
+			}
+			if got.Ended == nil || !got.Ended.Equal(censusEnd) {
+				t.Errorf("got ended %v; want census time %v", got.Ended, censusEnd)
+			}
+			if got.Score != (score{VS: 45, NC: 30, TR: 20}) || got.Victor != VS || got.Bracket != 4 {
+				t.Errorf("got score %+v, victor %v, bracket %v; want the final ps2alerts result", got.Score, got.Victor, got.Bracket)
+			}
+
+			// consumers must never see an alert un-end or move its start once census has reported it
+			var ended bool
+			for i, u := range updates {
+				if u.ID != id {
+					t.Errorf("update %d is for alert %s", i, u.ID)
+				}
+				if ended && u.Ended == nil {
+					t.Errorf("update %d cleared the end of the alert", i)
+				}
+				ended = ended || u.Ended != nil
+				if u.startedBy == fromCensus && !u.Started.Equal(start) {
+					t.Errorf("update %d has started %v; want %v", i, u.Started, start)
+				}
+			}
+			if last := updates[len(updates)-1]; !last.Ended.Equal(censusEnd) {
+				t.Errorf("last update has ended %v; want %v", last.Ended, censusEnd)
+			}
+		})
+	}
+}
+
+func TestStalePS2AlertsResponse(t *testing.T) {
+	m := New(testStore{}, nil)
+	zone := ps2.ZoneInstanceID(ps2.Indar)
+	now := time.Now().Truncate(time.Second)
+	handleMetagame(context.Background(), m, event.MetagameEvent{
+		InstanceID:         2,
+		MetagameEventID:    147,
+		MetagameEventState: ps2.Started,
+		Timestamp:          now,
+		WorldID:            ps2.Emerald,
+		ZoneID:             zone,
+	})
+	old := ps2.MetagameEventInstanceID{WorldID: ps2.Emerald, InstanceID: 1}
+	handlePS2AlertsResponse(m, ps2alerts.Alert{
+		World:       ps2.Emerald,
+		InstanceID:  old,
+		Zone:        zone,
+		TimeStarted: now.Add(-2 * time.Hour),
+	})
+	if _, found := m.alerts[old]; found || len(m.alerts) != 1 {
+		t.Errorf("an alert that started before the current alert in the zone was added")
+	}
+}
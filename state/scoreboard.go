@@ -0,0 +1,107 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+// Scoreboard is continent ownership across every tracked world,
+// like the global map screen in-game.
+type Scoreboard struct {
+	Worlds    []WorldScoreboard `json:"worlds"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+type WorldScoreboard struct {
+	WorldID ps2.WorldID `json:"world_id"`
+	Name    string      `json:"name"`
+
+	// Locked continents are owned by the faction that locked them.
+	Locked []ContinentScore `json:"locked"`
+
+	// Unlocked continents (including unstable ones) are led by the faction with the most territory.
+	Unlocked []ContinentScore `json:"unlocked"`
+}
+
+type ContinentScore struct {
+	MapID          ps2.ZoneInstanceID `json:"census_map_id"`
+	ZoneID         ps2.ZoneID         `json:"zone_id"`
+	Name           string             `json:"name"`
+	ContinentState psmap.Status       `json:"continent_state"`
+
+	// FactionID is the owner of a locked continent or the leader of an unlocked one.
+	// FactionID is 0 when the territory lead is tied or the map is unavailable.
+	FactionID ps2.FactionID `json:"faction_id"`
+
+	// Territory is the percentage of territory owned by each faction on an unlocked continent.
+	Territory map[ps2.FactionID]float32 `json:"territory,omitempty"`
+
+	// Since is the time of the last lock or unlock, if known.
+	Since *time.Time `json:"since"`
+
+	// Event is the running alert, if any.
+	Event *EventState `json:"event"`
+}
+
+// Scoreboard returns the owner of every locked continent and the territory leader of every unlocked continent,
+// grouped by world.
+func (manager *Manager) Scoreboard(ctx context.Context) (Scoreboard, error) {
+	return askContext(ctx, manager, func(manager *Manager) Scoreboard {
+		board := Scoreboard{Timestamp: time.Now()}
+		for _, world := range manager.state.Worlds {
+			ws := WorldScoreboard{WorldID: world.WorldID, Name: world.Name}
+			for _, zone := range world.Zones {
+				zone = zone.Clone()
+				cs := ContinentScore{
+					MapID:          zone.MapID,
+					ZoneID:         zone.ZoneID,
+					Name:           zone.ZoneName,
+					ContinentState: zone.ContinentState,
+				}
+				if zone.Event != nil && zone.Event.Ended == nil {
+					cs.Event = zone.Event
+				}
+				if zone.ContinentState == psmap.Locked {
+					cs.FactionID = zone.OwningFaction
+					cs.Since = zone.LastLock
+					ws.Locked = append(ws.Locked, cs)
+					continue
+				}
+				cs.Since = zone.LastUnlock
+				id := uniqueZone{world.WorldID, zone.MapID}
+				if mapp, err := manager.gameData.GetMap(id.ZoneID()); err == nil {
+					if summary, err := psmap.Summarize(mapp, zone.Regions); err == nil {
+						cs.Territory = summary.Territory
+						cs.FactionID = leader(summary.Territory)
+					}
+				}
+				ws.Unlocked = append(ws.Unlocked, cs)
+			}
+			board.Worlds = append(board.Worlds, ws)
+		}
+		return board
+	})
+}
+
+// leader returns the playable faction with the most territory,
+// or 0 if there is a tie for the lead.
+func leader(territory map[ps2.FactionID]float32) ps2.FactionID {
+	var lead ps2.FactionID
+	var best float32
+	tied := false
+	for _, f := range []ps2.FactionID{VS, NC, TR} {
+		switch t := territory[f]; {
+		case t > best:
+			lead, best, tied = f, t, false
+		case t == best && t > 0:
+			tied = true
+		}
+	}
+	if tied {
+		return 0
+	}
+	return lead
+}
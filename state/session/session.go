@@ -0,0 +1,313 @@
+// Package session tracks the play session of every character seen in the event stream,
+// from PlayerLogin to logout, for per-player statistics.
+//
+// It consumes the same events as state.Manager, so both can be attached to the same client:
+//
+//	inferrer := event.NewLogoutInferrer(event.DefaultLogoutWindow)
+//	inferrer.AttachHandlers(client)
+//	tracker := session.New()
+//	tracker.AttachHandlers(client)
+//	tracker.AttachInferrer(inferrer)
+//	tracker.OnSessionEnded(func(s session.Session) { db.SaveSession(ctx, s) })
+//	go inferrer.Run(ctx)
+//
+// Unlike event.SessionTracker, which follows a few chosen characters from their next login,
+// a Tracker keeps a session for every character it sees, including characters that were already online,
+// and adds the teamkills, lost vehicles, and continents of each session.
+package session
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+// Session holds the stats of one character from login to logout.
+type Session struct {
+	CharacterID ps2.CharacterID
+	WorldID     ps2.WorldID
+
+	// Start is the time of the PlayerLogin,
+	// or the first event seen for characters that were already online.
+	Start time.Time
+
+	// End is the time of the logout, or zero while the session is in progress.
+	// For inferred logouts it's the last time the character was seen.
+	End time.Time
+
+	// LastSeen is the time of the last event for the character.
+	LastSeen time.Time
+
+	// Partial is true when the character was already online when it was first seen,
+	// so the session is missing everything before Start.
+	Partial bool
+
+	// Inferred is true when the session ended without a PlayerLogout,
+	// because the character logged in again or stopped appearing in events.
+	Inferred bool
+
+	Kills            int // Kills doesn't include suicides or teamkills
+	Teamkills        int
+	Deaths           int // Deaths includes suicides
+	VehicleKills     int // VehicleKills are vehicles destroyed that were owned by other characters
+	VehiclesLost     int
+	ExperienceTicks  int     // ExperienceTicks is the number of GainExperience events
+	Score            float64 // Score is the sum of experience gained
+	FacilityCaptures int
+	FacilityDefends  int
+
+	// ZoneTime is the time spent on each continent,
+	// counted from the first event on a continent until the first event somewhere else.
+	ZoneTime map[ps2.ContinentID]time.Duration
+
+	zone      ps2.ContinentID // zone is the continent of the latest event
+	zoneSince time.Time       // zoneSince is when the character was first seen on zone
+}
+
+// Duration returns the length of the session,
+// up to the last time the character was seen for sessions in progress.
+func (s Session) Duration() time.Duration {
+	end := s.End
+	if end.IsZero() {
+		end = s.LastSeen
+	}
+	return max(end.Sub(s.Start), 0)
+}
+
+// KDR returns the kills per death of the session,
+// or the number of kills when there are no deaths.
+func (s Session) KDR() float64 {
+	if s.Deaths == 0 {
+		return float64(s.Kills)
+	}
+	return float64(s.Kills) / float64(s.Deaths)
+}
+
+// ScorePerMinute returns the average score per minute of the session.
+func (s Session) ScorePerMinute() float64 {
+	minutes := s.Duration().Minutes()
+	if minutes < 1 {
+		return s.Score
+	}
+	return s.Score / minutes
+}
+
+// SessionEnded converts s to the summary an event.SessionTracker emits,
+// for code that handles sessions from both.
+func (s Session) SessionEnded() event.SessionEnded {
+	return event.SessionEnded{
+		CharacterID:      s.CharacterID,
+		WorldID:          s.WorldID,
+		Start:            s.Start,
+		End:              s.End,
+		Duration:         s.Duration(),
+		Inferred:         s.Inferred,
+		Kills:            s.Kills,
+		Deaths:           s.Deaths,
+		VehicleKills:     s.VehicleKills,
+		ExperienceTicks:  s.ExperienceTicks,
+		Experience:       s.Score,
+		FacilityCaptures: s.FacilityCaptures,
+		FacilityDefends:  s.FacilityDefends,
+		Timestamp:        s.End,
+	}
+}
+
+func (s Session) clone() Session {
+	s.ZoneTime = maps.Clone(s.ZoneTime)
+	return s
+}
+
+// see records that the character was seen on zone at t.
+func (s *Session) see(zone ps2.ZoneInstanceID, t time.Time) {
+	if t.After(s.LastSeen) {
+		s.LastSeen = t
+	}
+	continent := zone.ZoneID()
+	if zone == 0 || continent == s.zone {
+		return
+	}
+	s.leaveZone(t)
+	s.zone = continent
+	s.zoneSince = t
+}
+
+// leaveZone adds the time since the character arrived on its current zone to ZoneTime.
+func (s *Session) leaveZone(t time.Time) {
+	if s.zone == 0 {
+		return
+	}
+	s.ZoneTime[s.zone] += max(t.Sub(s.zoneSince), 0)
+}
+
+// Tracker keeps a [Session] for every character seen in the event stream.
+// It's safe for concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	sessions map[ps2.CharacterID]*Session
+	handlers []func(Session)
+}
+
+// New creates a Tracker.
+func New() *Tracker {
+	return &Tracker{
+		sessions: make(map[ps2.CharacterID]*Session),
+	}
+}
+
+// OnSessionEnded registers f to be called with every ended session,
+// such as to save it to a database.
+// f is called after the tracker's lock is released, so it may call the tracker.
+func (t *Tracker) OnSessionEnded(f func(Session)) {
+	t.mu.Lock()
+	t.handlers = append(t.handlers, f)
+	t.mu.Unlock()
+}
+
+// AttachHandlers observes the events that sessions count from client, such as a *wsc.Client.
+// The client should be subscribed to them for all characters.
+func (t *Tracker) AttachHandlers(client interface{ AddHandler(any) }) {
+	client.AddHandler(func(e event.PlayerLogin) { t.Observe(e) })
+	client.AddHandler(func(e event.PlayerLogout) { t.Observe(e) })
+	client.AddHandler(func(e event.Death) { t.Observe(e) })
+	client.AddHandler(func(e event.VehicleDestroy) { t.Observe(e) })
+	client.AddHandler(func(e event.GainExperience) { t.Observe(e) })
+	client.AddHandler(func(e event.PlayerFacilityCapture) { t.Observe(e) })
+	client.AddHandler(func(e event.PlayerFacilityDefend) { t.Observe(e) })
+}
+
+// AttachInferrer ends the sessions of characters that l infers have logged out.
+func (t *Tracker) AttachInferrer(l *event.LogoutInferrer) {
+	l.OnLogoutInferred(t.ObserveInferred)
+}
+
+// Observe adds e to the sessions of the characters involved.
+// A PlayerLogin starts a new session and a PlayerLogout ends one.
+// Any other event for a character without a session starts a partial session.
+func (t *Tracker) Observe(e event.Typer) {
+	var ended []Session
+	t.mu.Lock()
+	switch e := e.(type) {
+	case event.PlayerLogin:
+		if s := t.sessions[e.CharacterID]; s != nil {
+			// the logout was missed
+			ended = append(ended, t.end(s, s.LastSeen, true))
+		}
+		t.sessions[e.CharacterID] = &Session{
+			CharacterID: e.CharacterID,
+			WorldID:     e.WorldID,
+			Start:       e.Timestamp,
+			LastSeen:    e.Timestamp,
+			ZoneTime:    make(map[ps2.ContinentID]time.Duration),
+		}
+	case event.PlayerLogout:
+		if s := t.sessions[e.CharacterID]; s != nil {
+			ended = append(ended, t.end(s, e.Timestamp, false))
+		}
+	case event.Death:
+		t.session(e.CharacterID, e.WorldID, e.ZoneID, e.Timestamp).Deaths++
+		switch {
+		case e.AttackerCharacterID == 0, e.IsSuicide():
+		case e.IsTeamkill():
+			t.session(e.AttackerCharacterID, e.WorldID, e.ZoneID, e.Timestamp).Teamkills++
+		default:
+			t.session(e.AttackerCharacterID, e.WorldID, e.ZoneID, e.Timestamp).Kills++
+		}
+	case event.VehicleDestroy:
+		if e.CharacterID != 0 {
+			t.session(e.CharacterID, e.WorldID, e.ZoneID, e.Timestamp).VehiclesLost++
+		}
+		if e.AttackerCharacterID != 0 && e.AttackerCharacterID != e.CharacterID {
+			t.session(e.AttackerCharacterID, e.WorldID, e.ZoneID, e.Timestamp).VehicleKills++
+		}
+	case event.GainExperience:
+		s := t.session(e.CharacterID, e.WorldID, e.ZoneID, e.Timestamp)
+		s.ExperienceTicks++
+		s.Score += e.Amount
+	case event.PlayerFacilityCapture:
+		t.session(e.CharacterID, e.WorldID, e.ZoneID, e.Timestamp).FacilityCaptures++
+	case event.PlayerFacilityDefend:
+		t.session(e.CharacterID, e.WorldID, e.ZoneID, e.Timestamp).FacilityDefends++
+	}
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	for _, s := range ended {
+		for _, h := range handlers {
+			h(s)
+		}
+	}
+}
+
+// session returns the session of id after recording that it was seen on zone at ts,
+// starting a partial session if it doesn't have one.
+// t.mu must be held.
+func (t *Tracker) session(id ps2.CharacterID, world ps2.WorldID, zone ps2.ZoneInstanceID, ts time.Time) *Session {
+	s := t.sessions[id]
+	if s == nil {
+		s = &Session{
+			CharacterID: id,
+			WorldID:     world,
+			Start:       ts,
+			LastSeen:    ts,
+			Partial:     true,
+			ZoneTime:    make(map[ps2.ContinentID]time.Duration),
+		}
+		t.sessions[id] = s
+	}
+	s.see(zone, ts)
+	return s
+}
+
+// end removes s from the tracker and returns a copy of it ended at at.
+// t.mu must be held.
+func (t *Tracker) end(s *Session, at time.Time, inferred bool) Session {
+	delete(t.sessions, s.CharacterID)
+	s.leaveZone(at)
+	s.End = at
+	s.Inferred = inferred
+	return s.clone()
+}
+
+// Session returns the session in progress for id.
+func (t *Tracker) Session(id ps2.CharacterID) (Session, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.sessions[id]
+	if s == nil {
+		return Session{}, false
+	}
+	return s.clone(), true
+}
+
+// Sessions returns every session in progress.
+func (t *Tracker) Sessions() []Session {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sessions := make([]Session, 0, len(t.sessions))
+	for _, s := range t.sessions {
+		sessions = append(sessions, s.clone())
+	}
+	return sessions
+}
+
+// ObserveInferred ends the session of the character in e when it was last seen,
+// unless the character has been seen since.
+func (t *Tracker) ObserveInferred(e event.LogoutInferred) {
+	var ended []Session
+	t.mu.Lock()
+	if s := t.sessions[e.CharacterID]; s != nil && !s.LastSeen.After(e.LastSeen) {
+		ended = append(ended, t.end(s, e.LastSeen, true))
+	}
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	for _, s := range ended {
+		for _, h := range handlers {
+			h(s)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/event"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := New()
+	var ended []Session
+	tracker.OnSessionEnded(func(s Session) { ended = append(ended, s) })
+	inferrer := event.NewLogoutInferrer(0)
+	tracker.AttachInferrer(inferrer)
+	observe := func(e event.Typer) {
+		tracker.Observe(e)
+		inferrer.Observe(e)
+	}
+
+	const me, them ps2.CharacterID = 1, 2
+	start := time.Unix(1709037000, 0)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	indar := ps2.ZoneInstanceID(ps2.Indar)
+	amerish := ps2.ZoneInstanceID(ps2.Amerish)
+
+	observe(event.PlayerLogin{CharacterID: me, WorldID: ps2.Emerald, Timestamp: at(0)})
+	observe(event.Death{CharacterID: them, AttackerCharacterID: me, TeamID: ps2.TR, AttackerTeamID: ps2.VS, ZoneID: indar, Timestamp: at(1)})
+	observe(event.Death{CharacterID: them, AttackerCharacterID: me, TeamID: ps2.TR, AttackerTeamID: ps2.VS, ZoneID: indar, Timestamp: at(2)})
+	observe(event.GainExperience{CharacterID: me, Amount: 200, ZoneID: indar, Timestamp: at(2)})
+	observe(event.Death{CharacterID: me, AttackerCharacterID: them, TeamID: ps2.VS, AttackerTeamID: ps2.TR, ZoneID: amerish, Timestamp: at(6)})
+	observe(event.VehicleDestroy{CharacterID: me, AttackerCharacterID: them, ZoneID: amerish, Timestamp: at(8)})
+	observe(event.PlayerLogout{CharacterID: me, Timestamp: at(10)})
+
+	if len(ended) != 1 {
+		t.Fatalf("got %d ended sessions; want 1", len(ended))
+	}
+	s := ended[0]
+	if s.Kills != 2 || s.Deaths != 1 || s.VehiclesLost != 1 || s.KDR() != 2 {
+		t.Errorf("got %d kills, %d deaths, %d vehicles lost; want 2, 1, 1", s.Kills, s.Deaths, s.VehiclesLost)
+	}
+	if s.Partial || s.Inferred || s.Duration() != 10*time.Minute || s.ScorePerMinute() != 20 {
+		t.Errorf("got %+v; want a complete 10 minute session with 20 score per minute", s)
+	}
+	if s.ZoneTime[ps2.Indar] != 5*time.Minute || s.ZoneTime[ps2.Amerish] != 4*time.Minute {
+		t.Errorf("got zone time %v; want 5m on Indar and 4m on Amerish", s.ZoneTime)
+	}
+
+	// the other character was already online, and never logs out
+	other, ok := tracker.Session(them)
+	if !ok || !other.Partial || other.Kills != 1 || other.VehicleKills != 1 || other.Deaths != 2 {
+		t.Errorf("got %+v, %t; want a partial session with 1 kill, 1 vehicle kill, and 2 deaths", other, ok)
+	}
+	inferrer.Sweep(at(10).Add(event.DefaultLogoutWindow))
+	if len(ended) != 2 || !ended[1].Inferred || ended[1].End != at(8) {
+		t.Errorf("got %+v; want the stale session ended when the character was last seen", ended)
+	}
+	if _, ok := tracker.Session(them); ok {
+		t.Error("the stale session is still in progress")
+	}
+
+	summary := ended[0].SessionEnded()
+	if summary.Kills != 2 || summary.Deaths != 1 || summary.Experience != 200 || summary.ExperienceTicks != 1 || summary.Duration != 10*time.Minute || summary.Inferred {
+		t.Errorf("got summary %+v; want the first session", summary)
+	}
+}
+
+func TestTrackerInferredAfterEvent(t *testing.T) {
+	tracker := New()
+	var ended []Session
+	tracker.OnSessionEnded(func(s Session) { ended = append(ended, s) })
+
+	start := time.Unix(1709037000, 0)
+	tracker.Observe(event.PlayerLogin{CharacterID: 1, WorldID: ps2.Emerald, Timestamp: start})
+	tracker.Observe(event.GainExperience{CharacterID: 1, Amount: 10, Timestamp: start.Add(3 * time.Hour)})
+	// a logout inferred by an inferrer that missed the latest event is ignored
+	tracker.ObserveInferred(event.LogoutInferred{CharacterID: 1, WorldID: ps2.Emerald, LastSeen: start, Timestamp: start.Add(2 * time.Hour)})
+	if len(ended) != 0 {
+		t.Fatalf("got %+v; want the session kept after a newer event", ended)
+	}
+	tracker.ObserveInferred(event.LogoutInferred{CharacterID: 1, WorldID: ps2.Emerald, LastSeen: start.Add(3 * time.Hour), Timestamp: start.Add(5 * time.Hour)})
+	if len(ended) != 1 || !ended[0].Inferred || ended[0].Duration() != 3*time.Hour {
+		t.Errorf("got %+v; want a 3 hour inferred session", ended)
+	}
+	// characters without a session are ignored
+	tracker.ObserveInferred(event.LogoutInferred{CharacterID: 2, LastSeen: start})
+	if len(ended) != 1 {
+		t.Errorf("got %d sessions ended; want 1", len(ended))
+	}
+}
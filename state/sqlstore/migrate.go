@@ -0,0 +1,68 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// migrations are the schema changes in the order they are applied.
+// Released migrations must never be edited; add new ones to the end.
+var migrations = []func(Dialect) []string{
+	func(d Dialect) []string {
+		return []string{
+			`CREATE TABLE ps2_player_faction (
+	character_id BIGINT PRIMARY KEY,
+	faction_id   INTEGER NOT NULL,
+	updated_at   BIGINT NOT NULL
+)`,
+			fmt.Sprintf(`CREATE TABLE ps2_static (
+	kind       TEXT NOT NULL,
+	id         BIGINT NOT NULL,
+	payload    %s NOT NULL,
+	updated_at BIGINT NOT NULL,
+	PRIMARY KEY (kind, id)
+)`, d.JSONType()),
+		}
+	},
+}
+
+// Migrate creates or updates the tables used by the Store.
+// The applied version is kept in the ps2_schema_version table,
+// and each migration runs in its own transaction.
+func (s *Store) Migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ps2_schema_version (version INTEGER NOT NULL, applied_at BIGINT NOT NULL)"); err != nil {
+		return fmt.Errorf("sqlstore.Migrate: %w", err)
+	}
+	var version int
+	if err := s.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM ps2_schema_version").Scan(&version); err != nil {
+		return fmt.Errorf("sqlstore.Migrate: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("sqlstore.Migrate: database schema version %d is newer than the supported version %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		if err := s.migrate(ctx, i+1, migrations[i](s.dialect)); err != nil {
+			return fmt.Errorf("sqlstore.Migrate: version %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) migrate(ctx context.Context, version int, statements []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+	insert := fmt.Sprintf("INSERT INTO ps2_schema_version (version, applied_at) VALUES (%s, %s)", s.dialect.Placeholder(1), s.dialect.Placeholder(2))
+	if _, err := tx.ExecContext(ctx, insert, version, time.Now().Unix()); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
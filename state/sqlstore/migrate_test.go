@@ -0,0 +1,71 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		dialect     Dialect
+		payload     string
+		placeholder string
+	}{
+		{Postgres, "payload    JSONB NOT NULL", "VALUES ($1, $2)"},
+		{SQLite, "payload    TEXT NOT NULL", "VALUES (?, ?)"},
+	} {
+		s, tb, fake := open(t, test.dialect, Options{})
+		if err := s.Migrate(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if tb.version != int64(len(migrations)) {
+			t.Errorf("got version %d; want %d", tb.version, len(migrations))
+		}
+		if commits, _ := fake.Transactions(); commits != len(migrations) {
+			t.Errorf("got %d transactions; want one per migration", commits)
+		}
+		var created []string
+		for _, stmt := range fake.Statements() {
+			switch {
+			case strings.HasPrefix(stmt.Query, "CREATE TABLE ps2_static"):
+				if !strings.Contains(stmt.Query, test.payload) {
+					t.Errorf("got %q; want %q", stmt.Query, test.payload)
+				}
+				created = append(created, "ps2_static")
+			case strings.HasPrefix(stmt.Query, "CREATE TABLE ps2_player_faction"):
+				created = append(created, "ps2_player_faction")
+			case strings.HasPrefix(stmt.Query, "INSERT INTO ps2_schema_version"):
+				if !strings.Contains(stmt.Query, test.placeholder) {
+					t.Errorf("got %q; want %q", stmt.Query, test.placeholder)
+				}
+			}
+		}
+		if len(created) != 2 {
+			t.Errorf("got tables %v created; want ps2_player_faction and ps2_static", created)
+		}
+
+		// an up to date database is left alone
+		fake.Reset()
+		if err := s.Migrate(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for _, stmt := range fake.Statements() {
+			if !strings.HasPrefix(stmt.Query, "CREATE TABLE IF NOT EXISTS ps2_schema_version") {
+				t.Errorf("got %q migrating an up to date database", stmt.Query)
+			}
+		}
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	s, tb, fake := open(t, Postgres, Options{})
+	tb.version = int64(len(migrations) + 1)
+	if err := s.Migrate(context.Background()); err == nil {
+		t.Fatal("expected an error for a database migrated by a newer version")
+	}
+	if commits, _ := fake.Transactions(); commits != 0 {
+		t.Errorf("got %d transactions; want none", commits)
+	}
+}
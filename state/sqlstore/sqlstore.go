@@ -0,0 +1,479 @@
+// Package sqlstore implements the game data store required by [state.New] with a SQL database.
+//
+// Static game data (zones, worlds, metagame events, facilities, and map data) is downloaded from census by [Store.Refresh],
+// saved to the database, and kept in memory so that the Manager never waits on the database for it.
+// Player factions are written in batches by [Store.Run] and read from the database on demand.
+//
+// The package only depends on database/sql;
+// register a driver for Postgres or SQLite in the calling program:
+//
+//	db, _ := sql.Open("pgx", dsn)
+//	store := sqlstore.New(db, sqlstore.Postgres, sqlstore.Options{})
+//	if err := store.Migrate(ctx); err != nil { ... }
+//	if err := store.Load(ctx); err != nil { ... }
+//	if store.Empty() {
+//		if err := store.Refresh(ctx, censusClient); err != nil { ... }
+//	}
+//	go store.Run(ctx)
+//	manager := state.New(store, censusClient)
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/internal/sqldialect"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+// Dialect selects the SQL syntax for a database.
+// It's the same type as sqlsink.Dialect.
+type Dialect = sqldialect.Dialect
+
+const (
+	Postgres = sqldialect.Postgres
+	SQLite   = sqldialect.SQLite
+)
+
+// Kinds of static data stored in the ps2_static table.
+const (
+	kindZone          = "zone"
+	kindWorld         = "world"
+	kindMetagameEvent = "metagame_event"
+	kindFacility      = "facility"
+	kindMap           = "map"
+)
+
+// Options configures a [Store].
+// Zero values use the defaults.
+type Options struct {
+	// Timeout limits each player faction lookup.
+	// The default is 5 seconds.
+	Timeout time.Duration
+
+	// BatchSize is the number of player factions written per transaction.
+	// The default is 500.
+	BatchSize int
+
+	// FlushInterval is the longest a saved player faction waits before it is written.
+	// The default is 5 seconds.
+	FlushInterval time.Duration
+}
+
+// Store is a game data store backed by a SQL database.
+// It is safe for concurrent use.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	opts    Options
+
+	mu              sync.RWMutex
+	continents      map[ps2.ContinentID]census.Zone
+	worlds          map[ps2.WorldID]census.World
+	events          map[ps2.MetagameEventID]census.MetagameEvent
+	facilities      map[ps2.FacilityID]census.Facility
+	facilityRegions map[ps2.FacilityID]ps2.RegionID
+	maps            map[ps2.ContinentID]psmap.Map
+	factions        map[ps2.CharacterID]ps2.FactionID // factions are saved but not yet written
+	onError         func(error)
+}
+
+// New creates a Store for db.
+// Call [Store.Migrate] and [Store.Load] before using it.
+func New(db *sql.DB, dialect Dialect, opts Options) *Store {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	return &Store{
+		db:              db,
+		dialect:         dialect,
+		opts:            opts,
+		continents:      make(map[ps2.ContinentID]census.Zone),
+		worlds:          make(map[ps2.WorldID]census.World),
+		events:          make(map[ps2.MetagameEventID]census.MetagameEvent),
+		facilities:      make(map[ps2.FacilityID]census.Facility),
+		facilityRegions: make(map[ps2.FacilityID]ps2.RegionID),
+		maps:            make(map[ps2.ContinentID]psmap.Map),
+		factions:        make(map[ps2.CharacterID]ps2.FactionID),
+		onError:         func(error) {},
+	}
+}
+
+// OnError sets a function to be called when a lookup or write fails.
+// The methods used by the Manager have no way to return errors,
+// so they report them here and return zero values.
+func (s *Store) OnError(f func(error)) {
+	s.onError = f
+}
+
+// Load reads the static game data saved by a previous [Store.Refresh] into memory.
+func (s *Store) Load(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, "SELECT kind, payload FROM ps2_static")
+	if err != nil {
+		return fmt.Errorf("sqlstore.Load: %w", err)
+	}
+	defer rows.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for rows.Next() {
+		var kind, payload string
+		if err := rows.Scan(&kind, &payload); err != nil {
+			return fmt.Errorf("sqlstore.Load: %w", err)
+		}
+		if err := s.add(kind, []byte(payload)); err != nil {
+			return fmt.Errorf("sqlstore.Load: %s: %w", kind, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("sqlstore.Load: %w", err)
+	}
+	return nil
+}
+
+// add decodes a row of static data into memory.
+// s.mu must be held.
+func (s *Store) add(kind string, payload []byte) error {
+	switch kind {
+	case kindZone:
+		var z census.Zone
+		if err := json.Unmarshal(payload, &z); err != nil {
+			return err
+		}
+		s.continents[z.ContinentID] = z
+	case kindWorld:
+		var w census.World
+		if err := json.Unmarshal(payload, &w); err != nil {
+			return err
+		}
+		s.worlds[w.WorldID] = w
+	case kindMetagameEvent:
+		var e census.MetagameEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		s.events[e.MetagameEventID] = e
+	case kindFacility:
+		var f census.Facility
+		if err := json.Unmarshal(payload, &f); err != nil {
+			return err
+		}
+		s.facilities[f.FacilityID] = f
+	case kindMap:
+		var m psmap.Map
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return err
+		}
+		cont, err := m.ZoneID.ContinentID()
+		if err != nil {
+			return err
+		}
+		s.maps[cont] = m
+		for _, r := range m.Regions {
+			if r.FacilityID != 0 {
+				s.facilityRegions[r.FacilityID] = r.RegionID
+			}
+		}
+	}
+	return nil
+}
+
+// Empty reports whether no static game data has been loaded,
+// which means [Store.Refresh] has never been called for the database.
+func (s *Store) Empty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.continents) == 0 && len(s.worlds) == 0
+}
+
+// staticRow is a row of the ps2_static table.
+type staticRow struct {
+	kind    string
+	id      int64
+	payload []byte
+}
+
+// Refresh downloads static game data for PC from census, saves it to the database, and loads it into memory.
+// A nil client uses census.DefaultClient.
+// Game data rarely changes outside of game updates, so this only needs to be called occasionally.
+func (s *Store) Refresh(ctx context.Context, client *census.Client) error {
+	var (
+		zones      []census.Zone
+		worlds     []census.World
+		events     []census.MetagameEvent
+		facilities []census.Facility
+	)
+	if err := census.LoadCollection(ctx, client, &zones); err != nil {
+		return fmt.Errorf("sqlstore.Refresh: zones: %w", err)
+	}
+	if err := census.LoadCollection(ctx, client, &worlds); err != nil {
+		return fmt.Errorf("sqlstore.Refresh: worlds: %w", err)
+	}
+	if err := census.LoadCollection(ctx, client, &events); err != nil {
+		return fmt.Errorf("sqlstore.Refresh: metagame events: %w", err)
+	}
+	if err := census.LoadCollection(ctx, client, &facilities); err != nil {
+		return fmt.Errorf("sqlstore.Refresh: facilities: %w", err)
+	}
+	maps, err := psmap.GetAllMapData(ctx, ps2.PC)
+	if err != nil {
+		return fmt.Errorf("sqlstore.Refresh: maps: %w", err)
+	}
+
+	var static []staticRow
+	add := func(kind string, id int64, v any) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("sqlstore.Refresh: %s %d: %w", kind, id, err)
+		}
+		static = append(static, staticRow{kind, id, b})
+		return nil
+	}
+	for _, z := range zones {
+		if err := add(kindZone, int64(z.ContinentID), z); err != nil {
+			return err
+		}
+	}
+	for _, w := range worlds {
+		if err := add(kindWorld, int64(w.WorldID), w); err != nil {
+			return err
+		}
+	}
+	for _, e := range events {
+		if err := add(kindMetagameEvent, int64(e.MetagameEventID), e); err != nil {
+			return err
+		}
+	}
+	for _, f := range facilities {
+		// the collection is shared with regions that don't have a facility
+		if f.FacilityID == 0 {
+			continue
+		}
+		if err := add(kindFacility, int64(f.FacilityID), f); err != nil {
+			return err
+		}
+	}
+	for _, m := range maps {
+		if err := add(kindMap, int64(m.ZoneID), m); err != nil {
+			return err
+		}
+	}
+
+	if err := s.saveStatic(ctx, static); err != nil {
+		return fmt.Errorf("sqlstore.Refresh: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range static {
+		if err := s.add(r.kind, r.payload); err != nil {
+			return fmt.Errorf("sqlstore.Refresh: %s %d: %w", r.kind, r.id, err)
+		}
+	}
+	return nil
+}
+
+// saveStatic replaces the rows of every kind in static.
+func (s *Store) saveStatic(ctx context.Context, static []staticRow) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	deleted := make(map[string]bool)
+	now := time.Now().Unix()
+	insert := fmt.Sprintf("INSERT INTO ps2_static (kind, id, payload, updated_at) VALUES (%s, %s, %s, %s)",
+		s.dialect.Placeholder(1), s.dialect.Placeholder(2), s.dialect.Placeholder(3), s.dialect.Placeholder(4))
+	for _, r := range static {
+		if !deleted[r.kind] {
+			deleted[r.kind] = true
+			if _, err := tx.ExecContext(ctx, "DELETE FROM ps2_static WHERE kind = "+s.dialect.Placeholder(1), r.kind); err != nil {
+				return errors.Join(fmt.Errorf("delete %s: %w", r.kind, err), tx.Rollback())
+			}
+		}
+		if _, err := tx.ExecContext(ctx, insert, r.kind, r.id, string(r.payload), now); err != nil {
+			return errors.Join(fmt.Errorf("insert %s %d: %w", r.kind, r.id, err), tx.Rollback())
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetContinent(id ps2.ContinentID) census.Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.continents[id]
+}
+
+// ListContinents returns the continents with territory.
+// Other zones, like VR training, are only returned by GetContinent.
+func (s *Store) ListContinents() []census.Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var zones []census.Zone
+	for id, z := range s.continents {
+		if ps2.IsPlayableZone(id) {
+			zones = append(zones, z)
+		}
+	}
+	return zones
+}
+
+func (s *Store) GetWorld(id ps2.WorldID) census.World {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.worlds[id]
+}
+
+func (s *Store) ListWorlds() []census.World {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	worlds := make([]census.World, 0, len(s.worlds))
+	for _, w := range s.worlds {
+		worlds = append(worlds, w)
+	}
+	return worlds
+}
+
+func (s *Store) GetEvent(id ps2.MetagameEventID) census.MetagameEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events[id]
+}
+
+func (s *Store) GetFacility(id ps2.FacilityID) census.Facility {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.facilities[id]
+}
+
+// GetFacilityRegion returns the region of a facility from the stored map data,
+// falling back to the tables built into the ps2 package.
+func (s *Store) GetFacilityRegion(id ps2.FacilityID) ps2.RegionID {
+	s.mu.RLock()
+	region, found := s.facilityRegions[id]
+	s.mu.RUnlock()
+	if found {
+		return region
+	}
+	region, _ = ps2.FacilityRegion(id)
+	return region
+}
+
+func (s *Store) GetMap(id ps2.ContinentID) (psmap.Map, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, found := s.maps[id]
+	if !found {
+		return psmap.Map{}, fmt.Errorf("sqlstore.GetMap: no map data for continent %d", id)
+	}
+	return m, nil
+}
+
+// GetPlayerFaction returns the saved faction of a character,
+// or 0 if it has never been saved.
+func (s *Store) GetPlayerFaction(id ps2.CharacterID) ps2.FactionID {
+	s.mu.RLock()
+	faction, found := s.factions[id]
+	s.mu.RUnlock()
+	if found {
+		return faction
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+	err := s.db.QueryRowContext(ctx, "SELECT faction_id FROM ps2_player_faction WHERE character_id = "+s.dialect.Placeholder(1), int64(id)).Scan(&faction)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.onError(fmt.Errorf("sqlstore.GetPlayerFaction: %w", err))
+	}
+	return faction
+}
+
+// SavePlayerFaction queues the faction of a character to be written by [Store.Run].
+// It never blocks, since it's called from the Manager's event loop.
+func (s *Store) SavePlayerFaction(id ps2.CharacterID, faction ps2.FactionID) {
+	s.mu.Lock()
+	s.factions[id] = faction
+	s.mu.Unlock()
+}
+
+// Run writes saved player factions until ctx is cancelled,
+// then writes whatever is left before returning.
+// Failed writes are kept and retried on the next flush.
+func (s *Store) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := s.flush(ctx); err != nil {
+				return fmt.Errorf("sqlstore.Store.Run: %w", err)
+			}
+			return nil
+		case <-ticker.C:
+			if err := s.flush(ctx); err != nil {
+				s.onError(fmt.Errorf("sqlstore.Store.Run: %w", err))
+			}
+		}
+	}
+}
+
+// flush writes every queued player faction, one transaction per batch.
+func (s *Store) flush(ctx context.Context) error {
+	s.mu.RLock()
+	batch := make(map[ps2.CharacterID]ps2.FactionID, min(len(s.factions), s.opts.BatchSize))
+	for id, faction := range s.factions {
+		batch[id] = faction
+	}
+	s.mu.RUnlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	upsert := fmt.Sprintf("INSERT INTO ps2_player_faction (character_id, faction_id, updated_at) VALUES (%s, %s, %s) "+
+		"ON CONFLICT (character_id) DO UPDATE SET faction_id = excluded.faction_id, updated_at = excluded.updated_at",
+		s.dialect.Placeholder(1), s.dialect.Placeholder(2), s.dialect.Placeholder(3))
+	now := time.Now().Unix()
+	ids := make([]ps2.CharacterID, 0, len(batch))
+	for id := range batch {
+		ids = append(ids, id)
+	}
+	for start := 0; start < len(ids); start += s.opts.BatchSize {
+		chunk := ids[start:min(start+s.opts.BatchSize, len(ids))]
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin: %w", err)
+		}
+		for _, id := range chunk {
+			if _, err := tx.ExecContext(ctx, upsert, int64(id), int(batch[id]), now); err != nil {
+				return errors.Join(fmt.Errorf("upsert: %w", err), tx.Rollback())
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit: %w", err)
+		}
+		s.mu.Lock()
+		for _, id := range chunk {
+			// the faction may have been saved again while the batch was being written
+			if s.factions[id] == batch[id] {
+				delete(s.factions, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
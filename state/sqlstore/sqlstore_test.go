@@ -0,0 +1,234 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/internal/sqltest"
+	"github.com/Travis-Britz/ps2/psmap"
+)
+
+// tables keeps an in-memory copy of the tables written by a Store,
+// so that what the Store writes can be read back.
+type tables struct {
+	mu       sync.Mutex
+	version  int64
+	static   map[string]map[int64]string
+	factions map[int64]int64
+}
+
+// open returns a Store backed by a fake database holding tables.
+func open(t *testing.T, dialect Dialect, opts Options) (*Store, *tables, *sqltest.DB) {
+	t.Helper()
+	tb := &tables{static: make(map[string]map[int64]string), factions: make(map[int64]int64)}
+	fake := &sqltest.DB{Exec: tb.exec, Query: tb.query}
+	db := fake.Open()
+	t.Cleanup(func() { db.Close() })
+	s := New(db, dialect, opts)
+	s.OnError(func(err error) { t.Errorf("OnError: %v", err) })
+	return s, tb, fake
+}
+
+func (tb *tables) exec(query string, args []any) error {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO ps2_schema_version"):
+		tb.version = args[0].(int64)
+	case strings.HasPrefix(query, "DELETE FROM ps2_static"):
+		delete(tb.static, args[0].(string))
+	case strings.HasPrefix(query, "INSERT INTO ps2_static"):
+		kind := args[0].(string)
+		if tb.static[kind] == nil {
+			tb.static[kind] = make(map[int64]string)
+		}
+		tb.static[kind][args[1].(int64)] = args[2].(string)
+	case strings.HasPrefix(query, "INSERT INTO ps2_player_faction"):
+		tb.factions[args[0].(int64)] = args[1].(int64)
+	}
+	return nil
+}
+
+func (tb *tables) query(query string, args []any) (sqltest.Rows, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT COALESCE(MAX(version), 0)"):
+		return sqltest.Rows{Columns: []string{"version"}, Values: [][]any{{tb.version}}}, nil
+	case strings.HasPrefix(query, "SELECT kind, payload FROM ps2_static"):
+		r := sqltest.Rows{Columns: []string{"kind", "payload"}}
+		for kind, rows := range tb.static {
+			for _, payload := range rows {
+				r.Values = append(r.Values, []any{kind, payload})
+			}
+		}
+		return r, nil
+	case strings.HasPrefix(query, "SELECT faction_id FROM ps2_player_faction"):
+		r := sqltest.Rows{Columns: []string{"faction_id"}}
+		if faction, found := tb.factions[args[0].(int64)]; found {
+			r.Values = append(r.Values, []any{faction})
+		}
+		return r, nil
+	}
+	return sqltest.Rows{}, fmt.Errorf("unexpected query %q", query)
+}
+
+func TestStaticRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, _, fake := open(t, Postgres, Options{})
+
+	zones := []census.Zone{
+		{ZoneID: 2, Code: "Indar", HexSize: 115, GeometryID: 2},
+		{ZoneID: 401, Code: "Sanctuary", HexSize: 200, GeometryID: 402, Dynamic: true},
+	}
+	world := census.World{WorldID: ps2.Emerald, State: "online", Name: ps2.Localization{ps2.En: "Emerald"}}
+	indar := psmap.Map{ZoneID: 2, Size: 8192, HexSize: 115, Regions: []psmap.Region{
+		{RegionID: 2201, Name: "The Crown", FacilityID: 210002},
+		{RegionID: 2202},
+	}}
+
+	var static []staticRow
+	add := func(kind string, id int64, v any) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		static = append(static, staticRow{kind, id, b})
+	}
+	for _, z := range zones {
+		add(kindZone, int64(z.ZoneID), z)
+	}
+	add(kindWorld, int64(world.WorldID), world)
+	add(kindMap, int64(indar.ZoneID), indar)
+	if err := s.saveStatic(ctx, static); err != nil {
+		t.Fatal(err)
+	}
+	if commits, _ := fake.Transactions(); commits != 1 {
+		t.Errorf("got %d transactions; want static data saved in 1", commits)
+	}
+	for _, stmt := range fake.Statements() {
+		if strings.HasPrefix(stmt.Query, "INSERT") && !strings.Contains(stmt.Query, "VALUES ($1, $2, $3, $4)") {
+			t.Errorf("got %q; want Postgres placeholders", stmt.Query)
+		}
+	}
+
+	// saving a kind again replaces every row of that kind
+	add(kindWorld, int64(ps2.Osprey), census.World{WorldID: ps2.Osprey})
+	if err := s.saveStatic(ctx, static[len(static)-1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New(s.db, Postgres, Options{})
+	if !loaded.Empty() {
+		t.Fatal("new store isn't empty")
+	}
+	if err := loaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Empty() {
+		t.Fatal("store is empty after Load")
+	}
+	if z := loaded.GetContinent(ps2.Indar); z.ZoneID != 2 || z.HexSize != 115 {
+		t.Errorf("got Indar %+v", z)
+	}
+	// dynamic zones are keyed by their geometry
+	if z := loaded.GetContinent(402); z.ZoneID != 401 || !z.ZoneInfo().Dynamic {
+		t.Errorf("got continent 402 %+v; want zone 401", z)
+	}
+	if w := loaded.GetWorld(ps2.Emerald); w.WorldID != 0 {
+		t.Errorf("got Emerald %+v; want it replaced by the second save", w)
+	}
+	if worlds := loaded.ListWorlds(); len(worlds) != 1 || worlds[0].WorldID != ps2.Osprey {
+		t.Errorf("got worlds %+v; want Connery", worlds)
+	}
+	m, err := loaded.GetMap(ps2.Indar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Regions) != 2 || m.Size != 8192 {
+		t.Errorf("got map %+v", m)
+	}
+	if r := loaded.GetFacilityRegion(210002); r != 2201 {
+		t.Errorf("got region %d for The Crown; want 2201", r)
+	}
+	if _, err := loaded.GetMap(ps2.Esamir); err == nil {
+		t.Error("expected an error for a continent without map data")
+	}
+}
+
+func TestPlayerFactionFlush(t *testing.T) {
+	ctx := context.Background()
+	s, tb, fake := open(t, SQLite, Options{BatchSize: 2})
+
+	s.SavePlayerFaction(1, ps2.VS)
+	s.SavePlayerFaction(2, ps2.NC)
+	s.SavePlayerFaction(3, ps2.TR)
+	// saved factions are returned before they are written
+	if f := s.GetPlayerFaction(2); f != ps2.NC {
+		t.Errorf("got faction %d for a queued character; want NC", f)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Fatalf("got %d statements before flushing; want none", len(fake.Statements()))
+	}
+
+	if err := s.flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if commits, _ := fake.Transactions(); commits != 2 {
+		t.Errorf("got %d transactions; want 3 factions written in batches of 2", commits)
+	}
+	for _, stmt := range fake.Statements() {
+		if !strings.Contains(stmt.Query, "VALUES (?, ?, ?) ON CONFLICT (character_id) DO UPDATE") {
+			t.Errorf("got %q; want an upsert with SQLite placeholders", stmt.Query)
+		}
+	}
+	if len(s.factions) != 0 {
+		t.Errorf("got %d factions still queued after flushing; want 0", len(s.factions))
+	}
+	if tb.factions[3] != int64(ps2.TR) {
+		t.Errorf("got %v written; want character 3 as TR", tb.factions)
+	}
+
+	// written factions are read back from the database
+	if f := s.GetPlayerFaction(1); f != ps2.VS {
+		t.Errorf("got faction %d for a written character; want VS", f)
+	}
+	if f := s.GetPlayerFaction(4); f != 0 {
+		t.Errorf("got faction %d for an unknown character; want 0", f)
+	}
+
+	// writing a character again updates it
+	s.SavePlayerFaction(1, ps2.NSO)
+	if err := s.flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tb.factions[1] != int64(ps2.NSO) {
+		t.Errorf("got faction %d for character 1; want NSO", tb.factions[1])
+	}
+}
+
+func TestPlayerFactionFlushFailure(t *testing.T) {
+	ctx := context.Background()
+	s, _, fake := open(t, Postgres, Options{})
+	fail := errors.New("connection reset")
+	fake.Exec = func(string, []any) error { return fail }
+
+	s.SavePlayerFaction(1, ps2.VS)
+	if err := s.flush(ctx); !errors.Is(err, fail) {
+		t.Fatalf("got error %v; want %v", err, fail)
+	}
+	if _, rollbacks := fake.Transactions(); rollbacks != 1 {
+		t.Errorf("got %d rollbacks; want 1", rollbacks)
+	}
+	// failed writes are kept for the next flush
+	if f := s.factions[1]; f != ps2.VS {
+		t.Errorf("got queued faction %d; want VS kept after a failed write", f)
+	}
+}
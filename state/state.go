@@ -1,12 +1,12 @@
 package state
 
 import (
-	"encoding/json"
 	"maps"
 	"time"
 
 	"github.com/Travis-Britz/ps2"
 	"github.com/Travis-Britz/ps2/census"
+	"github.com/Travis-Britz/ps2/ps2alerts"
 	"github.com/Travis-Britz/ps2/psmap"
 )
 
@@ -56,7 +56,8 @@ func (state *GlobalState) trackZone(world census.World, id ps2.ZoneInstanceID, c
 	state.trackZone(world, id, cont) // is there any version of input that can infinitely recurse? idk i'm too tired to think about it right now
 }
 
-func (state *GlobalState) setWorldPop(id ps2.WorldID, count popCounter) {
+// setWorldPop sets world population from counts by home faction, by team, and of NSO players by team.
+func (state *GlobalState) setWorldPop(id ps2.WorldID, count, teams, nsoTeams popCounter) {
 	for i, w := range state.Worlds {
 		if w.WorldID == id {
 			pop := worldpop{}
@@ -65,6 +66,8 @@ func (state *GlobalState) setWorldPop(id ps2.WorldID, count popCounter) {
 			pop.VS = count[VS]
 			pop.TR = count[TR]
 			pop.NSO = count[NSO]
+			pop.Teams = zonepop{VS: teams[VS], NC: teams[NC], TR: teams[TR]}
+			pop.NSOTeams = zonepop{VS: nsoTeams[VS], NC: nsoTeams[NC], TR: nsoTeams[TR]}
 			state.Worlds[i].Population = pop
 		}
 	}
@@ -164,8 +167,12 @@ func (state *WorldState) trackZone(id ps2.ZoneInstanceID, zoneData census.Zone)
 		MapID:    id,
 		ZoneID:   zoneData.ZoneID,
 		ZoneName: zoneData.Name.String(),
-		Regions:  make(psmap.State),
-		Cutoff:   make(map[ps2.RegionID]bool),
+		Regions: psmap.State{
+			ZoneID:    id,
+			WorldID:   state.WorldID,
+			Territory: make(map[ps2.RegionID]ps2.FactionID),
+		},
+		Cutoff: make(map[ps2.RegionID]bool),
 	}
 	state.Zones = append(state.Zones, new)
 }
@@ -179,10 +186,20 @@ func (original WorldState) Clone() (new WorldState) {
 	return new
 }
 
+// worldpop counts players by home faction,
+// with NSO players counted separately from the team they're playing for.
 type worldpop struct {
 	zonepop
 	NSO     int `json:"nso"`
 	Unknown int `json:"unknown"`
+
+	// Teams counts players by the team they're currently fighting for,
+	// which includes NSO players on each team.
+	// Players without a known team aren't counted.
+	Teams zonepop `json:"teams"`
+
+	// NSOTeams counts only NSO players by the team they're currently fighting for.
+	NSOTeams zonepop `json:"nso_teams"`
 }
 type zonepop struct {
 	VS int `json:"vs"`
@@ -230,7 +247,8 @@ func (original ZoneState) Clone() (new ZoneState) {
 		l := *original.LastUnlock
 		new.LastUnlock = &l
 	}
-	new.Regions = maps.Clone(original.Regions)
+	new.Regions.Territory = maps.Clone(original.Regions.Territory)
+	new.Cutoff = maps.Clone(original.Cutoff)
 	return new
 }
 
@@ -240,7 +258,7 @@ type EventState struct {
 	MetagameEventID  ps2.MetagameEventID         `json:"metagame_event_id"`
 	EventName        string                      `json:"name"`
 	EventDescription string                      `json:"description"`
-	EventDuration    time.Duration               `json:"duration"` // displayed in seconds
+	EventDuration    ps2.Seconds                 `json:"duration"`
 	IsContinentLock  bool                        `json:"is_continent_lock"`
 	IsTerritory      bool                        `json:"is_territory"`
 	StartingFaction  ps2.FactionID               `json:"starting_faction"` // 0 for event types that aren't started by a faction
@@ -249,14 +267,15 @@ type EventState struct {
 	Victor           ps2.FactionID               `json:"victor"`    // faction will be 0 when ended is nil
 	Started          time.Time                   `json:"started"`
 	Ended            *time.Time                  `json:"ended"`
-	Timestamp        time.Time                   `json:"-"` // Timestamp is the time this data was last updated
-}
+	Bracket          ps2alerts.Bracket           `json:"bracket,omitempty"` // Bracket is the population bracket reported by ps2alerts, or 0 if unknown
+	Timestamp        time.Time                   `json:"-"`                 // Timestamp is the time this data was last updated
+	Provenance       Provenance                  `json:"provenance"`        // Provenance is the source of the most recent change
+
+	startedBy alertSource // startedBy is the source that set Started
+	endedBy   alertSource // endedBy is the source that set Ended
+	final     bool        // final is set once ps2alerts has reported the final result
 
-func (event EventState) MarshalJSON() ([]byte, error) {
-	type shadowType EventState // prevent recursion
-	shadowCopy := shadowType(event)
-	shadowCopy.EventDuration /= time.Second // this is the reason we need to change behavior for marshaling
-	return json.Marshal(shadowCopy)
+	scoreChanged bool // scoreChanged is set when kills or experience changed Score since the last update
 }
 
 func (original EventState) Clone() (new EventState) {
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SlowConsumerPolicy decides what happens to a notification when a subscription's buffer is full.
+// The Manager never waits for subscribers.
+type SlowConsumerPolicy uint8
+
+const (
+	DropNewest SlowConsumerPolicy = iota // DropNewest drops the new notification
+	DropOldest                           // DropOldest drops the oldest buffered notification to make room for the new one
+	Disconnect                           // Disconnect closes the subscription
+)
+
+// SubscribeOptions configures a subscription.
+// The zero value buffers 16 notifications and drops new ones when the buffer is full.
+type SubscribeOptions struct {
+	Buffer int
+	Policy SlowConsumerPolicy
+}
+
+const defaultSubscriptionBuffer = 16
+
+// Subscription delivers Manager notifications on C.
+// C is closed by [Subscription.Unsubscribe],
+// when the Manager stops running,
+// or when a Disconnect subscription falls behind.
+//
+// Notifications share maps with other subscribers and must not be modified.
+type Subscription[T any] struct {
+	C <-chan T
+
+	c       chan T
+	policy  SlowConsumerPolicy
+	set     *subscribers[T]
+	dropped atomic.Uint64
+}
+
+// Unsubscribe stops delivery and closes C.
+// It's safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.set.remove(s)
+}
+
+// Dropped returns the number of notifications that were dropped because the buffer was full.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// subscribers is the set of subscriptions for one type of notification.
+// Subscriptions are added and removed from any goroutine and published to from the Manager's goroutine.
+type subscribers[T any] struct {
+	mu     sync.Mutex
+	subs   map[*Subscription[T]]struct{}
+	closed bool
+}
+
+func (set *subscribers[T]) add(opts SubscribeOptions) *Subscription[T] {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultSubscriptionBuffer
+	}
+	c := make(chan T, opts.Buffer)
+	s := &Subscription[T]{C: c, c: c, policy: opts.Policy, set: set}
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.closed {
+		close(c)
+		return s
+	}
+	if set.subs == nil {
+		set.subs = make(map[*Subscription[T]]struct{})
+	}
+	set.subs[s] = struct{}{}
+	return s
+}
+
+func (set *subscribers[T]) remove(s *Subscription[T]) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if _, found := set.subs[s]; found {
+		delete(set.subs, s)
+		close(s.c)
+	}
+}
+
+func (set *subscribers[T]) publish(v T) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for s := range set.subs {
+		select {
+		case s.c <- v:
+			continue
+		default:
+		}
+		s.dropped.Add(1)
+		switch s.policy {
+		case DropOldest:
+			// publish holds the lock, so nothing else can fill the space made here
+			select {
+			case <-s.c:
+			default:
+			}
+			s.c <- v
+		case Disconnect:
+			delete(set.subs, s)
+			close(s.c)
+		}
+	}
+}
+
+// close closes every subscription, and any that are added later.
+func (set *subscribers[T]) close() {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for s := range set.subs {
+		close(s.c)
+	}
+	clear(set.subs)
+	set.closed = true
+}
+
+// SubscribePopulation returns a subscription to the totals sent to [Manager.OnPopulationTotal].
+// Unlike the On functions it can be called at any time.
+func (manager *Manager) SubscribePopulation(opts SubscribeOptions) *Subscription[PopulationTotal] {
+	return manager.populationSubs.add(opts)
+}
+
+// SubscribeTerritoryChange returns a subscription to the changes sent to [Manager.OnTerritoryChange].
+// Unlike the On functions it can be called at any time.
+func (manager *Manager) SubscribeTerritoryChange(opts SubscribeOptions) *Subscription[TerritoryChange] {
+	return manager.territoryChangeSubs.add(opts)
+}
+
+// SubscribeZoneStatusChange returns a subscription to the changes sent to [Manager.OnZoneStatusChange].
+// Unlike the On functions it can be called at any time.
+func (manager *Manager) SubscribeZoneStatusChange(opts SubscribeOptions) *Subscription[ZoneStatusChange] {
+	return manager.zoneStatusChangeSubs.add(opts)
+}
+
+// SubscribeEventUpdate returns a subscription to the updates sent to [Manager.OnEventUpdate].
+// Unlike the On functions it can be called at any time.
+func (manager *Manager) SubscribeEventUpdate(opts SubscribeOptions) *Subscription[EventState] {
+	return manager.eventUpdateSubs.add(opts)
+}
+
+// closeSubscriptions closes every subscription when the Manager stops.
+func (manager *Manager) closeSubscriptions() {
+	manager.populationSubs.close()
+	manager.territoryChangeSubs.close()
+	manager.zoneStatusChangeSubs.close()
+	manager.eventUpdateSubs.close()
+}
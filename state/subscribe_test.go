@@ -0,0 +1,60 @@
+package state
+
+import "testing"
+
+func TestSubscriptionPolicies(t *testing.T) {
+	var set subscribers[int]
+	newest := set.add(SubscribeOptions{Buffer: 2, Policy: DropNewest})
+	oldest := set.add(SubscribeOptions{Buffer: 2, Policy: DropOldest})
+	disconnect := set.add(SubscribeOptions{Buffer: 2, Policy: Disconnect})
+	for i := 1; i <= 3; i++ {
+		set.publish(i)
+	}
+
+	drain := func(s *Subscription[int]) (got []int) {
+		for {
+			select {
+			case v, ok := <-s.C:
+				if !ok {
+					return append(got, -1)
+				}
+				got = append(got, v)
+			default:
+				return got
+			}
+		}
+	}
+	check := func(name string, s *Subscription[int], want []int) {
+		t.Helper()
+		got := drain(s)
+		if len(got) != len(want) {
+			t.Errorf("%s: got %v; want %v", name, got, want)
+			return
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v; want %v", name, got, want)
+				return
+			}
+		}
+		if s.Dropped() != 1 {
+			t.Errorf("%s: got %d dropped; want 1", name, s.Dropped())
+		}
+	}
+	check("DropNewest", newest, []int{1, 2})
+	check("DropOldest", oldest, []int{2, 3})
+	check("Disconnect", disconnect, []int{1, 2, -1}) // -1 marks the closed channel
+
+	newest.Unsubscribe()
+	newest.Unsubscribe()
+	if _, ok := <-newest.C; ok {
+		t.Errorf("expected an unsubscribed channel to be closed")
+	}
+	set.close()
+	if _, ok := <-oldest.C; ok {
+		t.Errorf("expected close to close every subscription")
+	}
+	if _, ok := <-set.add(SubscribeOptions{}).C; ok {
+		t.Errorf("expected subscriptions added after close to be closed")
+	}
+}
@@ -0,0 +1,234 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// zoneLookupTTL is how long a zone is left alone after it was checked for tracking.
+const zoneLookupTTL = time.Hour
+
+// defaultZoneLookupTimeout limits the census request made to check a zone for tracking.
+const defaultZoneLookupTimeout = 30 * time.Second
+
+// Reasons a zone isn't checked again for tracking.
+const (
+	reasonNotPlayable    = "not a playable zone"
+	reasonAlreadyTracked = "already tracked"
+	reasonLookupPending  = "census lookup pending"
+	reasonLookupFailed   = "census lookup failed"
+	reasonNoMapData      = "census returned no map data"
+	reasonTracked        = "tracked after census lookup"
+)
+
+// TrackingDecisions explains which zones the Manager is tracking and why others are not.
+type TrackingDecisions struct {
+	Tracked []TrackedZone `json:"tracked"`
+
+	// Suppressed are the zones that were checked recently
+	// and won't be checked again until their Until time, even if they have events.
+	Suppressed []ZoneLookup `json:"suppressed"`
+
+	// LookupErrors are the most recent failed census lookup of each zone,
+	// including lookups that are no longer suppressed.
+	LookupErrors []ZoneLookup `json:"lookup_errors"`
+}
+
+// TrackedZone is a zone with state kept by the Manager.
+type TrackedZone struct {
+	WorldID ps2.WorldID        `json:"world_id"`
+	ZoneID  ps2.ZoneInstanceID `json:"zone_id"`
+}
+
+// ZoneLookup is the result of checking whether a zone seen in an event should be tracked.
+type ZoneLookup struct {
+	WorldID   ps2.WorldID        `json:"world_id"`
+	ZoneID    ps2.ZoneInstanceID `json:"zone_id"`
+	CheckedAt time.Time          `json:"checked_at"`
+	Until     time.Time          `json:"until"`
+	Reason    string             `json:"reason"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// zoneLookup is a cached decision about tracking a zone.
+type zoneLookup struct {
+	at     time.Time
+	reason string
+
+	// err is the last failed census lookup of the zone, which may be older than at.
+	err   error
+	errAt time.Time
+}
+
+type zoneLookupResult struct {
+	zone   uniqueZone
+	reason string
+	err    error
+}
+
+// TrackingDecisions returns the zones being tracked,
+// the zones that were recently checked and skipped,
+// and the last census lookup error of each zone.
+func (manager *Manager) TrackingDecisions(ctx context.Context) (TrackingDecisions, error) {
+	return askContext(ctx, manager, func(manager *Manager) TrackingDecisions {
+		return trackingDecisions(manager, time.Now())
+	})
+}
+
+func trackingDecisions(manager *Manager, now time.Time) TrackingDecisions {
+	var d TrackingDecisions
+	for world, zones := range manager.state.listZones() {
+		for _, zone := range zones {
+			d.Tracked = append(d.Tracked, TrackedZone{WorldID: world, ZoneID: zone})
+		}
+	}
+	for zone, l := range manager.zoneLookups {
+		report := ZoneLookup{
+			WorldID:   zone.WorldID,
+			ZoneID:    zone.ZoneInstanceID,
+			CheckedAt: l.at,
+			Until:     l.at.Add(zoneLookupTTL),
+			Reason:    l.reason,
+		}
+		if l.err != nil {
+			d.LookupErrors = append(d.LookupErrors, ZoneLookup{
+				WorldID:   zone.WorldID,
+				ZoneID:    zone.ZoneInstanceID,
+				CheckedAt: l.errAt,
+				Until:     l.errAt.Add(zoneLookupTTL),
+				Reason:    reasonLookupFailed,
+				Error:     census.RedactURL(l.err.Error()),
+			})
+		}
+		if now.Before(report.Until) && !manager.state.isTracking(zone) {
+			d.Suppressed = append(d.Suppressed, report)
+		}
+	}
+	slices.SortFunc(d.Tracked, func(a, b TrackedZone) int {
+		if a.WorldID != b.WorldID {
+			return int(a.WorldID) - int(b.WorldID)
+		}
+		return int(a.ZoneID) - int(b.ZoneID)
+	})
+	byZone := func(a, b ZoneLookup) int {
+		if a.WorldID != b.WorldID {
+			return int(a.WorldID) - int(b.WorldID)
+		}
+		return int(a.ZoneID) - int(b.ZoneID)
+	}
+	slices.SortFunc(d.Suppressed, byZone)
+	slices.SortFunc(d.LookupErrors, byZone)
+	return d
+}
+
+// ForceTrack starts tracking a zone immediately,
+// bypassing the playable zone check and any suppressed lookup.
+// The zone's territory is then requested from census;
+// the zone stays tracked even if that request fails,
+// and its territory is filled by the next map poll.
+func (manager *Manager) ForceTrack(ctx context.Context, world ps2.WorldID, zone ps2.ZoneInstanceID) error {
+	id := uniqueZone{world, zone}
+	_, err := askContext(ctx, manager, func(manager *Manager) struct{} {
+		delete(manager.zoneLookups, id)
+		trackZone(manager, id)
+		return struct{}{}
+	})
+	if err != nil {
+		return fmt.Errorf("manager.ForceTrack: %w", err)
+	}
+
+	zm, err := census.GetMap(ctx, manager.census, world, zone)
+	if err != nil {
+		return fmt.Errorf("manager.ForceTrack: %w", err)
+	}
+	for _, z := range zm {
+		select {
+		case manager.mapUpdates <- z:
+		case <-manager.unavailable:
+			return errGoneHome
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// checkZone checks whether a zone should start being actively tracked.
+func checkZone(ctx context.Context, manager *Manager, zone uniqueZone) {
+	// we can short-circuit any zones checked recently
+	if l, found := manager.zoneLookups[zone]; found && time.Since(l.at) < zoneLookupTTL {
+		return
+	}
+	lookup := manager.zoneLookups[zone]
+	lookup.at = time.Now()
+	defer func() { manager.zoneLookups[zone] = lookup }()
+
+	// we're not concerned with tracking non-playable zones like VR-Training
+	if !ps2.IsPlayableZone(zone.ZoneID()) {
+		lookup.reason = reasonNotPlayable
+		return
+	}
+
+	// if the zone is being tracked we don't need to do anything
+	if manager.state.isTracking(zone) {
+		lookup.reason = reasonAlreadyTracked
+		return
+	}
+
+	// if other checks passed, then send it to the census api.
+	// active zones will be sent back on the mapData channel and be intitialized for tracking in the consumer of that channel.
+	lookup.reason = reasonLookupPending
+	go func() {
+		result := zoneLookupResult{zone: zone, reason: reasonTracked}
+		// the result is sent under ctx rather than the lookup timeout,
+		// which is already done when the lookup timed out
+		defer func() {
+			select {
+			case manager.zoneLookupResults <- result:
+			case <-manager.unavailable:
+			case <-ctx.Done():
+			}
+		}()
+		lookupCtx, stop := context.WithTimeout(ctx, manager.zoneLookupTimeout)
+		zm, err := census.GetMap(lookupCtx, manager.census, zone.WorldID, zone.ZoneInstanceID)
+		stop()
+		if err != nil {
+			result.reason, result.err = reasonLookupFailed, err
+			return
+		}
+		if len(zm) == 0 {
+			result.reason = reasonNoMapData
+			return
+		}
+		for _, z := range zm {
+			select {
+			case manager.mapUpdates <- z:
+			case <-manager.unavailable:
+				result.reason, result.err = reasonLookupFailed, errGoneHome
+				return
+			case <-ctx.Done():
+				result.reason, result.err = reasonLookupFailed, ctx.Err()
+				return
+			}
+		}
+	}()
+}
+
+// handleZoneLookup records the outcome of a census lookup started by checkZone.
+func handleZoneLookup(manager *Manager, result zoneLookupResult) {
+	lookup, found := manager.zoneLookups[result.zone]
+	if !found {
+		// ForceTrack cleared the lookup while it was running
+		return
+	}
+	lookup.reason = result.reason
+	if result.err != nil {
+		lookup.err, lookup.errAt = result.err, lookup.at
+	}
+	manager.zoneLookups[result.zone] = lookup
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Travis-Britz/ps2"
+	"github.com/Travis-Britz/ps2/census"
+)
+
+// stalledServer never responds, so requests only end when their context is done.
+type stalledServer struct{}
+
+func (stalledServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestZoneLookupTimeout(t *testing.T) {
+	client := &census.Client{ServiceID: "example"}
+	client.SetHTTPClient(&http.Client{Transport: stalledServer{}})
+	m := New(testStore{}, client)
+	m.zoneLookupTimeout = 10 * time.Millisecond
+
+	// Hossin isn't tracked by testStore, so it's looked up with census
+	zone := uniqueZone{ps2.Emerald, ps2.ZoneInstanceID(ps2.Hossin)}
+	checkZone(context.Background(), m, zone)
+	select {
+	case result := <-m.zoneLookupResults:
+		handleZoneLookup(m, result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the timed out lookup was never reported")
+	}
+
+	d := trackingDecisions(m, time.Now())
+	if len(d.LookupErrors) != 1 {
+		t.Fatalf("got %d lookup errors; want 1", len(d.LookupErrors))
+	}
+	if got := d.LookupErrors[0]; got.WorldID != zone.WorldID || got.ZoneID != zone.ZoneInstanceID || got.Error == "" {
+		t.Errorf("got %+v; want the error of zone %v", got, zone)
+	}
+	for _, s := range d.Suppressed {
+		if s.Reason == reasonLookupPending {
+			t.Errorf("zone %d is still pending after its lookup failed", s.ZoneID)
+		}
+	}
+}
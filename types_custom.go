@@ -3,6 +3,8 @@ package ps2
 import (
 	"bytes"
 	"fmt"
+	"strconv"
+	"time"
 )
 
 // Environment represents a game server production environment.
@@ -56,6 +58,10 @@ const (
 	PlayerFacilityDefend
 	SkillAdded
 	FishScan
+
+	// LogoutInferred is derived by event.LogoutInferrer and never sent by census,
+	// so it isn't returned by AllEvents.
+	LogoutInferred
 )
 
 var events = map[Event]string{
@@ -75,6 +81,68 @@ var events = map[Event]string{
 	PlayerFacilityDefend:  "PlayerFacilityDefend",
 	SkillAdded:            "SkillAdded",
 	FishScan:              "FishScan",
+	LogoutInferred:        "LogoutInferred",
+}
+
+// AllEvents returns every known event type.
+// The Unknown event is not included.
+func AllEvents() []Event {
+	return []Event{
+		ContinentLock,
+		PlayerLogin,
+		PlayerLogout,
+		GainExperience,
+		VehicleDestroy,
+		Death,
+		AchievementEarned,
+		BattleRankUp,
+		ItemAdded,
+		Metagame,
+		FacilityControl,
+		PlayerFacilityCapture,
+		PlayerFacilityDefend,
+		SkillAdded,
+		FishScan,
+	}
+}
+
+// IsCharacterEvent reports whether e is about a character,
+// meaning it can be received by subscribing to the character.
+func IsCharacterEvent(e Event) bool {
+	switch e {
+	case PlayerLogin,
+		PlayerLogout,
+		GainExperience,
+		VehicleDestroy,
+		Death,
+		AchievementEarned,
+		BattleRankUp,
+		ItemAdded,
+		PlayerFacilityCapture,
+		PlayerFacilityDefend,
+		SkillAdded,
+		FishScan:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsWorldEvent reports whether e can be received by subscribing to a world.
+// PlayerLogin and PlayerLogout are both character and world events.
+func IsWorldEvent(e Event) bool {
+	switch e {
+	case ContinentLock, Metagame, FacilityControl, PlayerLogin, PlayerLogout:
+		return true
+	default:
+		return false
+	}
+}
+
+// RequiresWorldSubscription reports whether e is only sent to subscriptions that include worlds,
+// such that a subscription listing only characters will never receive it.
+func RequiresWorldSubscription(e Event) bool {
+	return IsWorldEvent(e) && !IsCharacterEvent(e)
 }
 
 func (t Event) EventName() string { return t.String() }
@@ -95,3 +163,55 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 func (e Event) MarshalJSON() ([]byte, error) {
 	return []byte("\"" + e.String() + "\""), nil
 }
+
+// Seconds is a duration represented in json as a whole number of seconds.
+// Census sends these as strings, e.g. "5400",
+// and both strings and numbers are accepted when unmarshaling.
+type Seconds time.Duration
+
+func (s Seconds) Duration() time.Duration { return time.Duration(s) }
+func (s Seconds) String() string          { return time.Duration(s).String() }
+
+func (s Seconds) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(time.Duration(s)/time.Second), 10), nil
+}
+
+func (s *Seconds) UnmarshalJSON(data []byte) error {
+	n, err := parseCensusInt(data)
+	if err != nil {
+		return fmt.Errorf("ps2.Seconds.UnmarshalJSON: %w", err)
+	}
+	*s = Seconds(time.Duration(n) * time.Second)
+	return nil
+}
+
+// Minutes is a duration represented in json as a whole number of minutes,
+// such as the duration_minutes field of census metagame events.
+// Both strings and numbers are accepted when unmarshaling.
+type Minutes time.Duration
+
+func (m Minutes) Duration() time.Duration { return time.Duration(m) }
+func (m Minutes) String() string          { return time.Duration(m).String() }
+
+func (m Minutes) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(time.Duration(m)/time.Minute), 10), nil
+}
+
+func (m *Minutes) UnmarshalJSON(data []byte) error {
+	n, err := parseCensusInt(data)
+	if err != nil {
+		return fmt.Errorf("ps2.Minutes.UnmarshalJSON: %w", err)
+	}
+	*m = Minutes(time.Duration(n) * time.Minute)
+	return nil
+}
+
+// parseCensusInt parses a json integer that may be quoted.
+// Empty strings and null are 0.
+func parseCensusInt(data []byte) (int64, error) {
+	data = bytes.Trim(data, "\"")
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return 0, nil
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
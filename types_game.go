@@ -16,6 +16,13 @@ type AchievementID int
 type ExperienceID int
 type ExperienceAwardTypeID int
 type SkillID int
+type DirectiveID int
+type DirectiveTreeID int
+type DirectiveTreeCategoryID int
+
+// DirectiveTierID is the tier of a directive tree, from 1 for the first tier.
+// Tier IDs are shared between trees; a tier is only unique together with its DirectiveTreeID.
+type DirectiveTierID int
 type VehicleID uint16
 
 func (v VehicleID) String() string {
@@ -179,6 +186,13 @@ func (e EntityID) ID() (id any, set bool) {
 
 type OutfitID int64
 
+// OutfitWarID identifies an Outfit Wars season on a world.
+type OutfitWarID int
+
+// OutfitWarRoundID identifies a round of an Outfit Wars season,
+// such as a qualifier or the playoffs.
+type OutfitWarRoundID int64
+
 // ContinentID is a pseudo-ID type that represents either a ZoneID or GeometryID.
 // It is more of a conceptual type of ID than a direct implementation.
 // It does not exist as a distinct type anywhere in the game or in the census API,
@@ -231,34 +245,24 @@ type OutfitID int64
 // GeometryID can only be converted to ZoneInstanceID if the ephemeral instance counter is known.
 type ContinentID uint16
 
-// ZoneID looks up the ZoneID for c and returns an error if no data is available.
+// ZoneID looks up the ZoneID for c in the zone registry and returns an error if no data is available.
+// See [RegisterZone].
 func (c ContinentID) ZoneID() (ZoneID, error) {
-	switch c {
-	case Indar:
-		return 2, nil
-	case Hossin:
-		return 4, nil
-	case Amerish:
-		return 6, nil
-	case Esamir:
-		return 8, nil
-	case Nexus:
-		return 10, nil
-	case Extinction:
-		return 11, nil
-	case Desolation2:
-		return 12, nil
-	case Ascension:
-		return 13, nil
-	case Koltyr:
-		return 14, nil
-	case Oshur:
-		return 344, nil
-	case Desolation:
-		return 338, nil
-	default:
+	z, found := LookupContinent(c)
+	if !found || z.ZoneID == 0 {
 		return 0, errors.New("no data")
 	}
+	return z.ZoneID, nil
+}
+
+// GeometryID looks up the GeometryID for c in the zone registry and returns an error if no data is available.
+// See [GeometryID.ContinentID].
+func (c ContinentID) GeometryID() (GeometryID, error) {
+	z, found := LookupContinent(c)
+	if !found || z.GeometryID == 0 {
+		return 0, errors.New("no data")
+	}
+	return z.GeometryID, nil
 }
 
 // ZoneInstanceID attempts to convert a ContinentID back to an instanced zone ID.
@@ -300,6 +304,9 @@ func (c ContinentID) String() string {
 	case Tutorial:
 		return "Tutorial"
 	default:
+		if z, found := LookupContinent(c); found && z.Name != "" {
+			return z.Name
+		}
 		return strconv.Itoa(int(c))
 	}
 }
@@ -340,33 +347,13 @@ func (c ContinentID) GoString() string {
 // See the docs for [ContinentID].
 type ZoneID uint16
 
+// ContinentID looks up the ContinentID for z in the zone registry and returns an error if no data is available.
 func (z ZoneID) ContinentID() (ContinentID, error) {
-	switch z {
-	case 2:
-		return Indar, nil
-	case 4:
-		return Hossin, nil
-	case 6:
-		return Amerish, nil
-	case 8:
-		return Esamir, nil
-	case 10:
-		return Nexus, nil
-	case 11:
-		return Extinction, nil
-	case 12:
-		return Desolation2, nil
-	case 13:
-		return Ascension, nil
-	case 14:
-		return Koltyr, nil
-	case 344:
-		return Oshur, nil
-	case 338:
-		return Desolation, nil
-	default:
+	zone, found := LookupZoneID(z)
+	if !found {
 		return 0, errors.New("no data")
 	}
+	return zone.ContinentID, nil
 }
 
 func (z ZoneID) String() string   { return strconv.Itoa(int(z)) }
@@ -382,6 +369,21 @@ func (g GeometryID) ZoneInstanceID(counter uint16) ZoneInstanceID {
 func (g GeometryID) GoString() string { return strconv.Itoa(int(g)) }
 func (g GeometryID) String() string   { return strconv.Itoa(int(g)) }
 
+// ContinentID looks up the ContinentID for g and returns an error if no data is available.
+// Events from instanced zones carry a GeometryID in the lower bits of [ZoneInstanceID],
+// so this can classify instanced zones without querying census.
+//
+// The zone registry ships with data from the census zone collection and can be extended with [RegisterZone].
+// GeometryIDs are not unique in that collection;
+// the zone registered first is returned.
+func (g GeometryID) ContinentID() (ContinentID, error) {
+	z, found := LookupGeometry(g)
+	if !found {
+		return 0, errors.New("no data")
+	}
+	return z.ContinentID, nil
+}
+
 // ZoneInstanceID represents a (possibly) instanced Continent ID.
 // ZoneInstanceID is the zone_id provided in realtime events as well as the ID expected by the /map endpoint.
 //
@@ -550,9 +552,11 @@ func (f FactionID) String() string {
 //
 // The default unmarshaling behavior would normally be enough,
 // but FactionID being used as an array index in multiple locations might cause a panic if an out of range value were somehow returned.
+//
+// Census sends IDs as strings, so quoted values are accepted.
 func (id *FactionID) UnmarshalJSON(data []byte) error {
 	var i uint8
-	if err := json.Unmarshal(data, &i); err != nil {
+	if err := json.Unmarshal(bytes.Trim(data, `"`), &i); err != nil {
 		return fmt.Errorf("ps2.FactionID.UnmarshalJSON: %w", err)
 	}
 	if i > uint8(NSO) {
@@ -703,6 +707,7 @@ type Currency struct {
 	InventoryCap string       `json:"inventory_cap"`
 }
 type FireModeID int
+type WeaponID int
 type RegionID int
 
 type Locale string
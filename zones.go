@@ -0,0 +1,138 @@
+package ps2
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// ZoneInfo describes a zone for the conversions between [ContinentID], [ZoneID], and [GeometryID].
+// See the docs for [ContinentID].
+type ZoneInfo struct {
+	ContinentID ContinentID
+	ZoneID      ZoneID // ZoneID is 0 when unknown
+	GeometryID  GeometryID
+	Dynamic     bool   // Dynamic zones are instanced and identified in events by GeometryID
+	Name        string // Name is the English name of the zone
+}
+
+// zoneRegistry is the lookup table behind the zone conversion methods.
+// It's filled with defaultZones and extended with [RegisterZone].
+var zoneRegistry = struct {
+	sync.RWMutex
+	byContinent map[ContinentID]ZoneInfo
+	byZone      map[ZoneID]ContinentID
+	byGeometry  map[GeometryID]ContinentID
+}{
+	byContinent: make(map[ContinentID]ZoneInfo),
+	byZone:      make(map[ZoneID]ContinentID),
+	byGeometry:  make(map[GeometryID]ContinentID),
+}
+
+// defaultZones is the zone data from the census zone collection at the time of writing.
+var defaultZones = []ZoneInfo{
+	{ContinentID: Indar, ZoneID: 2, GeometryID: 2, Name: "Indar"},
+	{ContinentID: Hossin, ZoneID: 4, GeometryID: 4, Name: "Hossin"},
+	{ContinentID: Amerish, ZoneID: 6, GeometryID: 6, Name: "Amerish"},
+	{ContinentID: Esamir, ZoneID: 8, GeometryID: 8, Name: "Esamir"},
+	{ContinentID: Nexus, ZoneID: 10, GeometryID: 10, Dynamic: true, Name: "Nexus"},
+	{ContinentID: Extinction, ZoneID: 11, GeometryID: 11, Name: "Extinction"},
+	{ContinentID: Desolation2, ZoneID: 12, GeometryID: 12, Name: "Desolation2"},
+	{ContinentID: Ascension, ZoneID: 13, GeometryID: 13, Name: "Ascension"},
+	{ContinentID: Koltyr, ZoneID: 14, GeometryID: 14, Dynamic: true, Name: "Koltyr"},
+	{ContinentID: Oshur, ZoneID: 344, GeometryID: 344, Name: "Oshur"},
+	{ContinentID: Desolation, ZoneID: 338, GeometryID: 361, Dynamic: true, Name: "Desolation"},
+	{ContinentID: Sanctuary, GeometryID: 362, Dynamic: true, Name: "Sanctuary"},
+	{ContinentID: Tutorial, GeometryID: 364, Dynamic: true, Name: "Tutorial"},
+}
+
+func init() {
+	for _, z := range defaultZones {
+		RegisterZone(z)
+	}
+}
+
+// RegisterZone adds z to the zone lookup table,
+// replacing any zone already registered with the same ContinentID.
+// When z.ContinentID is 0 it's derived from z.Dynamic the same way as census.Zone,
+// so zones from the census zone collection can be registered as they are discovered.
+//
+// GeometryIDs aren't unique in the census zone collection.
+// When zones share a GeometryID, lookups by GeometryID return the zone registered first.
+//
+// RegisterZone is safe for concurrent use.
+func RegisterZone(z ZoneInfo) {
+	if z.ContinentID == 0 {
+		if z.Dynamic {
+			z.ContinentID = ContinentID(z.GeometryID)
+		} else {
+			z.ContinentID = ContinentID(z.ZoneID)
+		}
+	}
+	if z.ContinentID == 0 {
+		return
+	}
+
+	zoneRegistry.Lock()
+	defer zoneRegistry.Unlock()
+	if old, found := zoneRegistry.byContinent[z.ContinentID]; found {
+		if zoneRegistry.byZone[old.ZoneID] == old.ContinentID {
+			delete(zoneRegistry.byZone, old.ZoneID)
+		}
+		if zoneRegistry.byGeometry[old.GeometryID] == old.ContinentID {
+			delete(zoneRegistry.byGeometry, old.GeometryID)
+		}
+	}
+	zoneRegistry.byContinent[z.ContinentID] = z
+	if z.ZoneID != 0 {
+		zoneRegistry.byZone[z.ZoneID] = z.ContinentID
+	}
+	if _, taken := zoneRegistry.byGeometry[z.GeometryID]; !taken && z.GeometryID != 0 {
+		zoneRegistry.byGeometry[z.GeometryID] = z.ContinentID
+	}
+}
+
+// Zones returns every registered zone sorted by ContinentID.
+func Zones() []ZoneInfo {
+	zoneRegistry.RLock()
+	defer zoneRegistry.RUnlock()
+	zones := make([]ZoneInfo, 0, len(zoneRegistry.byContinent))
+	for _, z := range zoneRegistry.byContinent {
+		zones = append(zones, z)
+	}
+	slices.SortFunc(zones, func(a, b ZoneInfo) int { return cmp.Compare(a.ContinentID, b.ContinentID) })
+	return zones
+}
+
+// LookupContinent returns the registered zone for c,
+// or false if c isn't registered.
+func LookupContinent(c ContinentID) (ZoneInfo, bool) {
+	zoneRegistry.RLock()
+	defer zoneRegistry.RUnlock()
+	z, found := zoneRegistry.byContinent[c]
+	return z, found
+}
+
+// LookupZoneID returns the registered zone with zone ID z,
+// or false if no zone with that ID is registered.
+func LookupZoneID(z ZoneID) (ZoneInfo, bool) {
+	zoneRegistry.RLock()
+	defer zoneRegistry.RUnlock()
+	c, found := zoneRegistry.byZone[z]
+	if !found {
+		return ZoneInfo{}, false
+	}
+	return zoneRegistry.byContinent[c], true
+}
+
+// LookupGeometry returns the registered zone with geometry ID g,
+// or false if no zone with that ID is registered.
+func LookupGeometry(g GeometryID) (ZoneInfo, bool) {
+	zoneRegistry.RLock()
+	defer zoneRegistry.RUnlock()
+	c, found := zoneRegistry.byGeometry[g]
+	if !found {
+		return ZoneInfo{}, false
+	}
+	return zoneRegistry.byContinent[c], true
+}